@@ -0,0 +1,22 @@
+--UP
+ALTER TABLE tasks ADD COLUMN done INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN completed_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN due_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+
+UPDATE tasks
+SET position = (SELECT COUNT(*)
+                FROM tasks t
+                WHERE t.list_id = tasks.list_id
+                  AND (t.created_at < tasks.created_at OR (t.created_at = tasks.created_at AND t.id <= tasks.id)));
+
+CREATE INDEX idx_tasks_list_position ON tasks (list_id, position);
+
+--DOWN
+DROP INDEX idx_tasks_list_position;
+ALTER TABLE tasks DROP COLUMN position;
+ALTER TABLE tasks DROP COLUMN priority;
+ALTER TABLE tasks DROP COLUMN due_at;
+ALTER TABLE tasks DROP COLUMN completed_at;
+ALTER TABLE tasks DROP COLUMN done;
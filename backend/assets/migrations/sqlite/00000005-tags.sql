@@ -0,0 +1,153 @@
+--UP
+CREATE TABLE tags (
+                      id TEXT PRIMARY KEY,
+                      owner_id TEXT NOT NULL,
+                      name TEXT NOT NULL,
+                      description TEXT NOT NULL DEFAULT '',
+                      created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                      updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                      UNIQUE (owner_id, name),
+                      FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE categories (
+                            id TEXT PRIMARY KEY,
+                            owner_id TEXT NOT NULL,
+                            name TEXT NOT NULL,
+                            description TEXT NOT NULL DEFAULT '',
+                            created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                            updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                            UNIQUE (owner_id, name),
+                            FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE locations (
+                           id TEXT PRIMARY KEY,
+                           owner_id TEXT NOT NULL,
+                           name TEXT NOT NULL,
+                           description TEXT NOT NULL DEFAULT '',
+                           created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                           updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                           UNIQUE (owner_id, name),
+                           FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE task_tags (
+                           task_id TEXT NOT NULL,
+                           tag_id TEXT NOT NULL,
+                           PRIMARY KEY (task_id, tag_id),
+                           FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+                           FOREIGN KEY (tag_id) REFERENCES tags (id) ON DELETE CASCADE
+);
+
+CREATE TABLE task_categories (
+                                 task_id TEXT NOT NULL,
+                                 category_id TEXT NOT NULL,
+                                 PRIMARY KEY (task_id, category_id),
+                                 FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+                                 FOREIGN KEY (category_id) REFERENCES categories (id) ON DELETE CASCADE
+);
+
+CREATE TABLE task_locations (
+                                task_id TEXT NOT NULL,
+                                location_id TEXT NOT NULL,
+                                PRIMARY KEY (task_id, location_id),
+                                FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+                                FOREIGN KEY (location_id) REFERENCES locations (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_tags_tag ON task_tags (tag_id);
+CREATE INDEX idx_task_categories_category ON task_categories (category_id);
+CREATE INDEX idx_task_locations_location ON task_locations (location_id);
+
+-- Split the comma-joined values of existing tasks, one row per task and value.
+-- Values are owned by the owner of the list the task belongs to.
+CREATE TEMP TABLE csv_values AS
+WITH RECURSIVE split(task_id, owner_id, kind, name, rest) AS (
+    SELECT t.id, l.owner_id, 'tag', '', t.tags || ','
+    FROM tasks t INNER JOIN lists l ON t.list_id = l.id
+    WHERE COALESCE(t.tags, '') <> ''
+    UNION ALL
+    SELECT t.id, l.owner_id, 'category', '', t.category || ','
+    FROM tasks t INNER JOIN lists l ON t.list_id = l.id
+    WHERE COALESCE(t.category, '') <> ''
+    UNION ALL
+    SELECT t.id, l.owner_id, 'location', '', t.location || ','
+    FROM tasks t INNER JOIN lists l ON t.list_id = l.id
+    WHERE COALESCE(t.location, '') <> ''
+    UNION ALL
+    SELECT task_id, owner_id, kind,
+           TRIM(SUBSTR(rest, 1, INSTR(rest, ',') - 1)),
+           SUBSTR(rest, INSTR(rest, ',') + 1)
+    FROM split
+    WHERE rest <> ''
+)
+SELECT DISTINCT task_id, owner_id, kind, name
+FROM split
+WHERE name <> '';
+
+-- Random version 4 UUIDs, same format as the ones generated by the application.
+INSERT INTO tags (id, owner_id, name)
+SELECT LOWER(HEX(RANDOMBLOB(4)) || '-' || HEX(RANDOMBLOB(2)) || '-4' || SUBSTR(HEX(RANDOMBLOB(2)), 2) || '-' ||
+             SUBSTR('89ab', 1 + (ABS(RANDOM()) % 4), 1) || SUBSTR(HEX(RANDOMBLOB(2)), 2) || '-' || HEX(RANDOMBLOB(6))),
+       owner_id, name
+FROM (SELECT DISTINCT owner_id, name FROM csv_values WHERE kind = 'tag');
+
+INSERT INTO categories (id, owner_id, name)
+SELECT LOWER(HEX(RANDOMBLOB(4)) || '-' || HEX(RANDOMBLOB(2)) || '-4' || SUBSTR(HEX(RANDOMBLOB(2)), 2) || '-' ||
+             SUBSTR('89ab', 1 + (ABS(RANDOM()) % 4), 1) || SUBSTR(HEX(RANDOMBLOB(2)), 2) || '-' || HEX(RANDOMBLOB(6))),
+       owner_id, name
+FROM (SELECT DISTINCT owner_id, name FROM csv_values WHERE kind = 'category');
+
+INSERT INTO locations (id, owner_id, name)
+SELECT LOWER(HEX(RANDOMBLOB(4)) || '-' || HEX(RANDOMBLOB(2)) || '-4' || SUBSTR(HEX(RANDOMBLOB(2)), 2) || '-' ||
+             SUBSTR('89ab', 1 + (ABS(RANDOM()) % 4), 1) || SUBSTR(HEX(RANDOMBLOB(2)), 2) || '-' || HEX(RANDOMBLOB(6))),
+       owner_id, name
+FROM (SELECT DISTINCT owner_id, name FROM csv_values WHERE kind = 'location');
+
+INSERT INTO task_tags (task_id, tag_id)
+SELECT v.task_id, g.id
+FROM csv_values v INNER JOIN tags g ON g.owner_id = v.owner_id AND g.name = v.name
+WHERE v.kind = 'tag';
+
+INSERT INTO task_categories (task_id, category_id)
+SELECT v.task_id, c.id
+FROM csv_values v INNER JOIN categories c ON c.owner_id = v.owner_id AND c.name = v.name
+WHERE v.kind = 'category';
+
+INSERT INTO task_locations (task_id, location_id)
+SELECT v.task_id, o.id
+FROM csv_values v INNER JOIN locations o ON o.owner_id = v.owner_id AND o.name = v.name
+WHERE v.kind = 'location';
+
+DROP TABLE csv_values;
+
+ALTER TABLE tasks DROP COLUMN category;
+ALTER TABLE tasks DROP COLUMN tags;
+ALTER TABLE tasks DROP COLUMN location;
+
+--DOWN
+ALTER TABLE tasks ADD COLUMN category TEXT[];
+ALTER TABLE tasks ADD COLUMN tags TEXT[];
+ALTER TABLE tasks ADD COLUMN location TEXT[];
+
+UPDATE tasks
+SET category = (SELECT GROUP_CONCAT(c.name, ',')
+                FROM task_categories tc INNER JOIN categories c ON tc.category_id = c.id
+                WHERE tc.task_id = tasks.id),
+    tags     = (SELECT GROUP_CONCAT(g.name, ',')
+                FROM task_tags tt INNER JOIN tags g ON tt.tag_id = g.id
+                WHERE tt.task_id = tasks.id),
+    location = (SELECT GROUP_CONCAT(o.name, ',')
+                FROM task_locations tl INNER JOIN locations o ON tl.location_id = o.id
+                WHERE tl.task_id = tasks.id);
+
+DROP INDEX idx_task_locations_location;
+DROP INDEX idx_task_categories_category;
+DROP INDEX idx_task_tags_tag;
+DROP TABLE task_locations;
+DROP TABLE task_categories;
+DROP TABLE task_tags;
+DROP TABLE locations;
+DROP TABLE categories;
+DROP TABLE tags;
@@ -0,0 +1,7 @@
+--UP
+CREATE UNIQUE INDEX idx_users_username ON users (username COLLATE NOCASE);
+CREATE UNIQUE INDEX idx_users_email ON users (email COLLATE NOCASE);
+
+--DOWN
+DROP INDEX idx_users_email;
+DROP INDEX idx_users_username;
@@ -0,0 +1,17 @@
+--UP
+CREATE TABLE sessions (
+                          id TEXT PRIMARY KEY,
+                          user_id TEXT NOT NULL,
+                          refresh_id TEXT NOT NULL,
+                          expires_at TIMESTAMP NOT NULL,
+                          revoked_at TIMESTAMP,
+                          created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                          updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                          FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_sessions_user ON sessions (user_id);
+
+--DOWN
+DROP INDEX idx_sessions_user;
+DROP TABLE sessions;
@@ -0,0 +1,20 @@
+--UP
+CREATE TABLE api_keys (
+                          id TEXT PRIMARY KEY,
+                          user_id TEXT NOT NULL,
+                          name TEXT NOT NULL,
+                          prefix TEXT NOT NULL,
+                          hash TEXT NOT NULL UNIQUE,
+                          scope TEXT NOT NULL,
+                          last_used_at TIMESTAMP,
+                          revoked_at TIMESTAMP,
+                          created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                          updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                          FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_api_keys_user ON api_keys (user_id);
+
+--DOWN
+DROP INDEX idx_api_keys_user;
+DROP TABLE api_keys;
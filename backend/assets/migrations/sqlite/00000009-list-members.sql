@@ -0,0 +1,39 @@
+--UP
+CREATE TABLE list_members (
+                              list_id TEXT NOT NULL,
+                              user_id TEXT NOT NULL,
+                              role TEXT NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+                              created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                              updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                              PRIMARY KEY (list_id, user_id),
+                              FOREIGN KEY (list_id) REFERENCES lists (id) ON DELETE CASCADE,
+                              FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_list_members_user ON list_members (user_id);
+
+-- Current owners become members of their lists
+INSERT INTO list_members (list_id, user_id, role, created_at, updated_at)
+SELECT id, owner_id, 'owner', created_at, updated_at
+FROM lists;
+
+CREATE TABLE list_invitations (
+                                  id TEXT PRIMARY KEY,
+                                  list_id TEXT NOT NULL,
+                                  email TEXT NOT NULL COLLATE NOCASE,
+                                  role TEXT NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+                                  invited_by TEXT NOT NULL,
+                                  expires_at TIMESTAMP NOT NULL,
+                                  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                  FOREIGN KEY (list_id) REFERENCES lists (id) ON DELETE CASCADE,
+                                  FOREIGN KEY (invited_by) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_list_invitations_email ON list_invitations (email);
+
+--DOWN
+DROP INDEX idx_list_invitations_email;
+DROP TABLE list_invitations;
+DROP INDEX idx_list_members_user;
+DROP TABLE list_members;
@@ -0,0 +1,130 @@
+--UP
+-- Searchable documents, one per list and task. Their rowids are stable and key the full-text index.
+CREATE TABLE search_docs (
+                             rowid INTEGER PRIMARY KEY,
+                             kind TEXT NOT NULL CHECK (kind IN ('list', 'task')),
+                             item_id TEXT NOT NULL UNIQUE,
+                             list_id TEXT NOT NULL
+);
+
+CREATE INDEX idx_search_docs_list ON search_docs (list_id);
+
+-- Requires SQLite built with FTS5, the sqlite_fts5 build tag of go-sqlite3.
+CREATE VIRTUAL TABLE search_fts USING fts5(
+    name,
+    description,
+    tags,
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+-- Lists
+
+CREATE TRIGGER search_lists_insert AFTER INSERT ON lists
+BEGIN
+    INSERT INTO search_docs (kind, item_id, list_id) VALUES ('list', new.id, new.id);
+    INSERT INTO search_fts (rowid, name, description, tags)
+    VALUES ((SELECT rowid FROM search_docs WHERE item_id = new.id), new.name, new.description, '');
+END;
+
+CREATE TRIGGER search_lists_update AFTER UPDATE OF name, description ON lists
+BEGIN
+    UPDATE search_fts
+    SET name = new.name, description = new.description
+    WHERE rowid = (SELECT rowid FROM search_docs WHERE item_id = new.id);
+END;
+
+-- Tasks of the list are removed as well, their own triggers may not fire if foreign keys are off.
+CREATE TRIGGER search_lists_delete AFTER DELETE ON lists
+BEGIN
+    DELETE FROM search_fts WHERE rowid IN (SELECT rowid FROM search_docs WHERE list_id = old.id);
+    DELETE FROM search_docs WHERE list_id = old.id;
+END;
+
+-- Tasks
+
+CREATE TRIGGER search_tasks_insert AFTER INSERT ON tasks
+BEGIN
+    INSERT INTO search_docs (kind, item_id, list_id) VALUES ('task', new.id, new.list_id);
+    INSERT INTO search_fts (rowid, name, description, tags)
+    VALUES ((SELECT rowid FROM search_docs WHERE item_id = new.id), new.name, new.description, '');
+END;
+
+CREATE TRIGGER search_tasks_update AFTER UPDATE OF name, description, list_id ON tasks
+BEGIN
+    UPDATE search_docs SET list_id = new.list_id WHERE item_id = new.id;
+    UPDATE search_fts
+    SET name = new.name, description = new.description
+    WHERE rowid = (SELECT rowid FROM search_docs WHERE item_id = new.id);
+END;
+
+CREATE TRIGGER search_tasks_delete AFTER DELETE ON tasks
+BEGIN
+    DELETE FROM search_fts WHERE rowid = (SELECT rowid FROM search_docs WHERE item_id = old.id);
+    DELETE FROM search_docs WHERE item_id = old.id;
+END;
+
+-- Tags, the tags column holds the names of the tags of a task separated by spaces.
+
+CREATE TRIGGER search_task_tags_insert AFTER INSERT ON task_tags
+BEGIN
+    UPDATE search_fts
+    SET tags = (SELECT COALESCE(GROUP_CONCAT(g.name, ' '), '')
+                FROM task_tags tt INNER JOIN tags g ON g.id = tt.tag_id
+                WHERE tt.task_id = new.task_id)
+    WHERE rowid = (SELECT rowid FROM search_docs WHERE item_id = new.task_id);
+END;
+
+CREATE TRIGGER search_task_tags_delete AFTER DELETE ON task_tags
+BEGIN
+    UPDATE search_fts
+    SET tags = (SELECT COALESCE(GROUP_CONCAT(g.name, ' '), '')
+                FROM task_tags tt INNER JOIN tags g ON g.id = tt.tag_id
+                WHERE tt.task_id = old.task_id)
+    WHERE rowid = (SELECT rowid FROM search_docs WHERE item_id = old.task_id);
+END;
+
+CREATE TRIGGER search_tags_update AFTER UPDATE OF name ON tags
+BEGIN
+    UPDATE search_fts
+    SET tags = (SELECT COALESCE(GROUP_CONCAT(g.name, ' '), '')
+                FROM task_tags tt INNER JOIN tags g ON g.id = tt.tag_id
+                WHERE tt.task_id = (SELECT item_id FROM search_docs d WHERE d.rowid = search_fts.rowid))
+    WHERE rowid IN (SELECT d.rowid
+                    FROM search_docs d INNER JOIN task_tags tt ON tt.task_id = d.item_id
+                    WHERE tt.tag_id = new.id);
+END;
+
+-- Index current lists and tasks
+
+INSERT INTO search_docs (kind, item_id, list_id)
+SELECT 'list', id, id FROM lists;
+
+INSERT INTO search_docs (kind, item_id, list_id)
+SELECT 'task', id, list_id FROM tasks;
+
+INSERT INTO search_fts (rowid, name, description, tags)
+SELECT d.rowid, l.name, l.description, ''
+FROM search_docs d INNER JOIN lists l ON l.id = d.item_id
+WHERE d.kind = 'list';
+
+INSERT INTO search_fts (rowid, name, description, tags)
+SELECT d.rowid, t.name, t.description,
+       (SELECT COALESCE(GROUP_CONCAT(g.name, ' '), '')
+        FROM task_tags tt INNER JOIN tags g ON g.id = tt.tag_id
+        WHERE tt.task_id = t.id)
+FROM search_docs d INNER JOIN tasks t ON t.id = d.item_id
+WHERE d.kind = 'task';
+
+--DOWN
+DROP TRIGGER search_tags_update;
+DROP TRIGGER search_task_tags_delete;
+DROP TRIGGER search_task_tags_insert;
+DROP TRIGGER search_tasks_delete;
+DROP TRIGGER search_tasks_update;
+DROP TRIGGER search_tasks_insert;
+DROP TRIGGER search_lists_delete;
+DROP TRIGGER search_lists_update;
+DROP TRIGGER search_lists_insert;
+DROP TABLE search_fts;
+DROP INDEX idx_search_docs_list;
+DROP TABLE search_docs;
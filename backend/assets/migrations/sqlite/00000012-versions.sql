@@ -0,0 +1,31 @@
+--UP
+-- Versions are incremented by the statements changing lists and tasks, clients send them back to detect lost updates.
+ALTER TABLE lists ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- The tasks of a list are versioned as a collection on their own, so that conditional reads of them can be answered
+-- without changing the version of the list, which only covers its own fields.
+ALTER TABLE lists ADD COLUMN tasks_version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TRIGGER lists_tasks_version_insert AFTER INSERT ON tasks
+BEGIN
+    UPDATE lists SET tasks_version = tasks_version + 1 WHERE id = new.list_id;
+END;
+
+CREATE TRIGGER lists_tasks_version_update AFTER UPDATE ON tasks
+BEGIN
+    UPDATE lists SET tasks_version = tasks_version + 1 WHERE id IN (old.list_id, new.list_id);
+END;
+
+CREATE TRIGGER lists_tasks_version_delete AFTER DELETE ON tasks
+BEGIN
+    UPDATE lists SET tasks_version = tasks_version + 1 WHERE id = old.list_id;
+END;
+
+--DOWN
+DROP TRIGGER lists_tasks_version_delete;
+DROP TRIGGER lists_tasks_version_update;
+DROP TRIGGER lists_tasks_version_insert;
+ALTER TABLE lists DROP COLUMN tasks_version;
+ALTER TABLE tasks DROP COLUMN version;
+ALTER TABLE lists DROP COLUMN version;
@@ -0,0 +1,23 @@
+--UP
+-- Responses to requests made with an Idempotency-Key, replayed when the same user retries them on the same endpoint.
+-- A row without status belongs to a request still being processed.
+CREATE TABLE idempotency_keys (
+                          user_id TEXT NOT NULL,
+                          method TEXT NOT NULL,
+                          path TEXT NOT NULL,
+                          key TEXT NOT NULL,
+                          fingerprint TEXT NOT NULL,
+                          status INTEGER NOT NULL DEFAULT 0,
+                          header TEXT,
+                          body BLOB,
+                          created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                          expires_at TIMESTAMP NOT NULL,
+                          PRIMARY KEY (user_id, method, path, key),
+                          FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
+
+--DOWN
+DROP INDEX idx_idempotency_keys_expires_at;
+DROP TABLE idempotency_keys;
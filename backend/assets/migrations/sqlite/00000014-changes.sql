@@ -0,0 +1,98 @@
+--UP
+-- Change log read by syncing clients, one row per list, task and membership, sequenced again on every change.
+-- Deleted items keep their row as a tombstone. Tasks moved to another list leave a tombstone in the one they left.
+-- AUTOINCREMENT keeps sequence numbers increasing, replaced rows never give theirs back.
+CREATE TABLE changes (
+                         seq INTEGER PRIMARY KEY AUTOINCREMENT,
+                         kind TEXT NOT NULL CHECK (kind IN ('list', 'task', 'member')),
+                         item_id TEXT NOT NULL,
+                         list_id TEXT NOT NULL,
+                         deleted INTEGER NOT NULL DEFAULT 0,
+                         UNIQUE (kind, item_id, list_id)
+);
+
+CREATE INDEX idx_changes_list ON changes (list_id);
+
+-- Lists
+
+CREATE TRIGGER changes_lists_insert AFTER INSERT ON lists
+BEGIN
+    INSERT OR REPLACE INTO changes (kind, item_id, list_id, deleted) VALUES ('list', new.id, new.id, 0);
+END;
+
+-- Changes of its tasks only bump the version of the collection, the list itself is unchanged.
+CREATE TRIGGER changes_lists_update AFTER UPDATE ON lists WHEN new.version <> old.version
+BEGIN
+    INSERT OR REPLACE INTO changes (kind, item_id, list_id, deleted) VALUES ('list', new.id, new.id, 0);
+END;
+
+-- Tasks and members of the list are removed as well, their own triggers may not fire if foreign keys are off.
+CREATE TRIGGER changes_lists_delete AFTER DELETE ON lists
+BEGIN
+    INSERT OR REPLACE INTO changes (kind, item_id, list_id, deleted)
+    SELECT kind, item_id, list_id, 1 FROM changes WHERE list_id = old.id AND deleted = 0 ORDER BY seq;
+END;
+
+-- Tasks
+
+CREATE TRIGGER changes_tasks_insert AFTER INSERT ON tasks
+BEGIN
+    INSERT OR REPLACE INTO changes (kind, item_id, list_id, deleted) VALUES ('task', new.id, new.list_id, 0);
+END;
+
+CREATE TRIGGER changes_tasks_update AFTER UPDATE ON tasks
+BEGIN
+    INSERT OR REPLACE INTO changes (kind, item_id, list_id, deleted)
+    SELECT 'task', old.id, old.list_id, 1 WHERE old.list_id <> new.list_id;
+    INSERT OR REPLACE INTO changes (kind, item_id, list_id, deleted) VALUES ('task', new.id, new.list_id, 0);
+END;
+
+CREATE TRIGGER changes_tasks_delete AFTER DELETE ON tasks
+BEGIN
+    INSERT OR REPLACE INTO changes (kind, item_id, list_id, deleted) VALUES ('task', old.id, old.list_id, 1);
+END;
+
+-- Members, the item is the user. A new member gets the whole list, its items are sequenced again.
+-- A role change is a change of the list as read by the member.
+
+CREATE TRIGGER changes_members_insert AFTER INSERT ON list_members
+BEGIN
+    INSERT OR REPLACE INTO changes (kind, item_id, list_id, deleted) VALUES ('member', new.user_id, new.list_id, 0);
+    INSERT OR REPLACE INTO changes (kind, item_id, list_id, deleted)
+    SELECT kind, item_id, list_id, 0 FROM changes
+    WHERE list_id = new.list_id AND kind IN ('list', 'task') AND deleted = 0 ORDER BY seq;
+END;
+
+CREATE TRIGGER changes_members_update AFTER UPDATE OF role ON list_members
+BEGIN
+    INSERT OR REPLACE INTO changes (kind, item_id, list_id, deleted) VALUES ('list', new.list_id, new.list_id, 0);
+END;
+
+CREATE TRIGGER changes_members_delete AFTER DELETE ON list_members
+BEGIN
+    INSERT OR REPLACE INTO changes (kind, item_id, list_id, deleted) VALUES ('member', old.user_id, old.list_id, 1);
+END;
+
+-- Log current lists, tasks and members
+
+INSERT INTO changes (kind, item_id, list_id)
+SELECT 'list', id, id FROM lists;
+
+INSERT INTO changes (kind, item_id, list_id)
+SELECT 'task', id, list_id FROM tasks;
+
+INSERT INTO changes (kind, item_id, list_id)
+SELECT 'member', user_id, list_id FROM list_members;
+
+--DOWN
+DROP TRIGGER changes_members_delete;
+DROP TRIGGER changes_members_update;
+DROP TRIGGER changes_members_insert;
+DROP TRIGGER changes_tasks_delete;
+DROP TRIGGER changes_tasks_update;
+DROP TRIGGER changes_tasks_insert;
+DROP TRIGGER changes_lists_delete;
+DROP TRIGGER changes_lists_update;
+DROP TRIGGER changes_lists_insert;
+DROP INDEX idx_changes_list;
+DROP TABLE changes;
@@ -0,0 +1,46 @@
+--UP
+-- Webhooks of a user, for all the lists the user is a member of or for a single one. Events is a JSON array of types.
+CREATE TABLE webhooks (
+                          id TEXT PRIMARY KEY,
+                          user_id TEXT NOT NULL,
+                          list_id TEXT,
+                          url TEXT NOT NULL,
+                          events TEXT NOT NULL,
+                          secret TEXT NOT NULL,
+                          created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                          updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                          FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+                          FOREIGN KEY (list_id) REFERENCES lists (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_webhooks_user ON webhooks (user_id);
+CREATE INDEX idx_webhooks_list ON webhooks (list_id);
+
+-- Delivery queue and log. Pending deliveries are sent once next_attempt_at is due, it is moved forward while one is
+-- being sent so that no other worker picks it up, and by the backoff when it fails.
+CREATE TABLE webhook_deliveries (
+                          id TEXT PRIMARY KEY,
+                          webhook_id TEXT NOT NULL,
+                          event_type TEXT NOT NULL,
+                          payload BLOB NOT NULL,
+                          status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+                          attempts INTEGER NOT NULL DEFAULT 0,
+                          next_attempt_at TIMESTAMP,
+                          last_attempt_at TIMESTAMP,
+                          response_status INTEGER NOT NULL DEFAULT 0,
+                          error TEXT NOT NULL DEFAULT '',
+                          created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                          updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                          FOREIGN KEY (webhook_id) REFERENCES webhooks (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, created_at);
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+
+--DOWN
+DROP INDEX idx_webhook_deliveries_due;
+DROP INDEX idx_webhook_deliveries_webhook;
+DROP TABLE webhook_deliveries;
+DROP INDEX idx_webhooks_list;
+DROP INDEX idx_webhooks_user;
+DROP TABLE webhooks;
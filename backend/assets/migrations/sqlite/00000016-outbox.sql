@@ -0,0 +1,110 @@
+--UP
+-- Domain events written by the statements changing lists, tasks and members, so that they are recorded in the same
+-- transaction as the change or not at all. The dispatcher delivers them in order to in-process subscribers and sets
+-- done_at once all of them handled it. Failed events are delivered again at next_attempt_at, NULL is due right away,
+-- until failed_at is set after the last attempt. Events of a list wait for the earlier ones still pending.
+-- item_id is the task or the member the event is about, the list itself otherwise. user_id is the user that made
+-- the change, set by the repo in the same transaction, empty if unknown.
+CREATE TABLE outbox (
+                        id INTEGER PRIMARY KEY AUTOINCREMENT,
+                        type TEXT NOT NULL,
+                        list_id TEXT NOT NULL,
+                        item_id TEXT NOT NULL,
+                        user_id TEXT NOT NULL DEFAULT '',
+                        payload TEXT NOT NULL DEFAULT '{}',
+                        attempts INTEGER NOT NULL DEFAULT 0,
+                        next_attempt_at TIMESTAMP,
+                        error TEXT NOT NULL DEFAULT '',
+                        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                        done_at TIMESTAMP,
+                        failed_at TIMESTAMP
+);
+
+CREATE INDEX idx_outbox_pending ON outbox (done_at, failed_at, id);
+CREATE INDEX idx_outbox_list ON outbox (list_id, id);
+
+-- Subscribers that handled an event, retries only deliver it to the others.
+CREATE TABLE outbox_handled (
+                                event_id INTEGER NOT NULL,
+                                subscriber TEXT NOT NULL,
+                                handled_at TIMESTAMP NOT NULL,
+                                PRIMARY KEY (event_id, subscriber),
+                                FOREIGN KEY (event_id) REFERENCES outbox (id) ON DELETE CASCADE
+);
+
+-- Lists
+
+CREATE TRIGGER outbox_lists_insert AFTER INSERT ON lists
+BEGIN
+    INSERT INTO outbox (type, list_id, item_id, payload)
+    VALUES ('list.created', new.id, new.id, json_object('name', new.name, 'owner_id', new.owner_id));
+END;
+
+-- Lists are updated along with their tasks to get a new version, only changes of their own columns are reported.
+CREATE TRIGGER outbox_lists_update AFTER UPDATE OF name, description ON lists
+BEGIN
+    INSERT INTO outbox (type, list_id, item_id, payload)
+    VALUES ('list.updated', new.id, new.id, json_object('name', new.name, 'version', new.version));
+END;
+
+-- Tasks and members of the list report their own removal before it.
+CREATE TRIGGER outbox_lists_delete AFTER DELETE ON lists
+BEGIN
+    INSERT INTO outbox (type, list_id, item_id, payload)
+    VALUES ('list.deleted', old.id, old.id, json_object('name', old.name, 'owner_id', old.owner_id));
+END;
+
+-- Tasks, statements that only reposition them keep their version and are not reported.
+
+CREATE TRIGGER outbox_tasks_insert AFTER INSERT ON tasks
+BEGIN
+    INSERT INTO outbox (type, list_id, item_id, payload)
+    VALUES ('task.created', new.list_id, new.id, json_object('name', new.name));
+END;
+
+CREATE TRIGGER outbox_tasks_update AFTER UPDATE ON tasks WHEN new.version <> old.version
+BEGIN
+    INSERT INTO outbox (type, list_id, item_id, payload)
+    SELECT 'task.moved', new.list_id, new.id, json_object('name', new.name, 'from_list_id', old.list_id)
+    WHERE new.list_id <> old.list_id;
+    INSERT INTO outbox (type, list_id, item_id, payload)
+    SELECT 'task.updated', new.list_id, new.id, json_object('name', new.name, 'version', new.version)
+    WHERE new.list_id = old.list_id;
+    INSERT INTO outbox (type, list_id, item_id, payload)
+    SELECT 'task.completed', new.list_id, new.id, json_object('name', new.name, 'completed_at', new.completed_at)
+    WHERE new.done AND NOT old.done;
+END;
+
+CREATE TRIGGER outbox_tasks_delete AFTER DELETE ON tasks
+BEGIN
+    INSERT INTO outbox (type, list_id, item_id, payload)
+    VALUES ('task.deleted', old.list_id, old.id, json_object('name', old.name));
+END;
+
+-- Members, owners are added along with their list and are not reported.
+
+CREATE TRIGGER outbox_members_insert AFTER INSERT ON list_members WHEN new.role <> 'owner'
+BEGIN
+    INSERT INTO outbox (type, list_id, item_id, payload)
+    VALUES ('list.shared', new.list_id, new.user_id, json_object('role', new.role));
+END;
+
+CREATE TRIGGER outbox_members_delete AFTER DELETE ON list_members WHEN old.role <> 'owner'
+BEGIN
+    INSERT INTO outbox (type, list_id, item_id, payload)
+    VALUES ('list.unshared', old.list_id, old.user_id, json_object('role', old.role));
+END;
+
+--DOWN
+DROP TRIGGER outbox_members_delete;
+DROP TRIGGER outbox_members_insert;
+DROP TRIGGER outbox_tasks_delete;
+DROP TRIGGER outbox_tasks_update;
+DROP TRIGGER outbox_tasks_insert;
+DROP TRIGGER outbox_lists_delete;
+DROP TRIGGER outbox_lists_update;
+DROP TRIGGER outbox_lists_insert;
+DROP TABLE outbox_handled;
+DROP INDEX idx_outbox_list;
+DROP INDEX idx_outbox_pending;
+DROP TABLE outbox;
@@ -1,11 +1,11 @@
 --SEED
 INSERT INTO users (id, username, name, email, password, created_at, updated_at)
-VALUES ('0792b97b-4f88-42a8-a035-1d0aad0ae7f8', 'user1', 'User 1', 'user1@example.com', 'password1', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+VALUES ('0792b97b-4f88-42a8-a035-1d0aad0ae7f8', 'user1', 'User 1', 'user1@example.com', 'pbkdf2-sha256$310000$W5BoNmkrSMTTMVJBbaJpXA$rzFevZ8RWDWLM6BonTfULxo4se5wp5LzwoVv2emHJk4', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
 
 --SEED
 INSERT INTO users (id, username, name, email, password, created_at, updated_at)
-VALUES ('b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'user2', 'User 2', 'user2@example.com', 'password2', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+VALUES ('b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'user2', 'User 2', 'user2@example.com', 'pbkdf2-sha256$310000$LPH7TXdb4/IicQjdoi4SgA$KZDPcr8zI6bhtbL884ebv7gbTNLCugu0vx7c6wehs60', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
 
 --SEED
 INSERT INTO users (id, username, name, email, password, created_at, updated_at)
-VALUES ('7d399e9e-9df0-4dcb-a733-3d4a8be80123', 'user3', 'User 3', 'user3@example.com', 'password3', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+VALUES ('7d399e9e-9df0-4dcb-a733-3d4a8be80123', 'user3', 'User 3', 'user3@example.com', 'pbkdf2-sha256$310000$XuTw9Uo9y26+oAFTaAuZtA$JbE4dD5IeATu18aBs7EFKM8ZjkEfoDRysoklMaxvBK0', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
@@ -1,19 +1,19 @@
 --SEED
-INSERT INTO tasks (id, list_id, name, description, category, tags, location, created_at, updated_at)
-VALUES ('c0d1dbdb-b65e-4c4d-8f92-7b3ed6250123', 'cdc7a443-3c6a-431b-b45a-b14735953a19', 'Task 1', 'Task 1 Description', 'Category 1', 'Tag 1,Tag 2', 'Location 1', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+INSERT INTO tasks (id, list_id, name, description, created_at, updated_at)
+VALUES ('c0d1dbdb-b65e-4c4d-8f92-7b3ed6250123', 'cdc7a443-3c6a-431b-b45a-b14735953a19', 'Task 1', 'Task 1 Description', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
 
 --SEED
-INSERT INTO tasks (id, list_id, name, description, category, tags, location, created_at, updated_at)
-VALUES ('4b87ef82-d16b-4b5d-b1a0-3a8366120123', '70a4a418-7b2b-4c2b-95b3-4e1656c81234', 'Task 2', 'Task 2 Description', 'Category 2', 'Tag 3,Tag 4', 'Location 2,Location 3', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+INSERT INTO tasks (id, list_id, name, description, created_at, updated_at)
+VALUES ('4b87ef82-d16b-4b5d-b1a0-3a8366120123', '70a4a418-7b2b-4c2b-95b3-4e1656c81234', 'Task 2', 'Task 2 Description', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
 
 --SEED
-INSERT INTO tasks (id, list_id, name, description, category, tags, location, created_at, updated_at)
-VALUES ('d6ff256b-9f79-42be-99ea-835c586e0123', 'dd7edff4-9b0d-4e92-80e2-1db98b4b0123', 'Task 3', 'Task 3 Description', 'Category 1,Category 3', 'Tag 2,Tag 5', 'Location 1,Location 4', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+INSERT INTO tasks (id, list_id, name, description, created_at, updated_at)
+VALUES ('d6ff256b-9f79-42be-99ea-835c586e0123', 'dd7edff4-9b0d-4e92-80e2-1db98b4b0123', 'Task 3', 'Task 3 Description', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
 
 --SEED
-INSERT INTO tasks (id, list_id, name, description, category, tags, location, created_at, updated_at)
-VALUES ('e8b13d39-894e-487b-9f9e-19a5c2490123', 'd9d5d7d0-dc10-48a9-a580-7d4293f11234', 'Task 4', 'Task 4 Description', 'Category 4', 'Tag 6,Tag 7', 'Location 5', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+INSERT INTO tasks (id, list_id, name, description, created_at, updated_at)
+VALUES ('e8b13d39-894e-487b-9f9e-19a5c2490123', 'd9d5d7d0-dc10-48a9-a580-7d4293f11234', 'Task 4', 'Task 4 Description', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
 
 --SEED
-INSERT INTO tasks (id, list_id, name, description, category, tags, location, created_at, updated_at)
-VALUES ('af6c62da-54ad-414f-9462-c5229a5b0123', 'ddc09e08-286b-47a7-bcd0-8b8d3bdc0123', 'Task 5', 'Task 5 Description', 'Category 2,Category 3', 'Tag 4,Tag 8', 'Location 6,Location 7', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+INSERT INTO tasks (id, list_id, name, description, created_at, updated_at)
+VALUES ('af6c62da-54ad-414f-9462-c5229a5b0123', 'ddc09e08-286b-47a7-bcd0-8b8d3bdc0123', 'Task 5', 'Task 5 Description', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
@@ -0,0 +1,199 @@
+--SEED
+INSERT INTO tags (id, owner_id, name, description, created_at, updated_at)
+VALUES ('bd1ae901-e240-4aee-bfcf-3873f041fcb4', '0792b97b-4f88-42a8-a035-1d0aad0ae7f8', 'Tag 1', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO tags (id, owner_id, name, description, created_at, updated_at)
+VALUES ('e2d48e43-efa7-4d88-8319-f0eb283bf982', '0792b97b-4f88-42a8-a035-1d0aad0ae7f8', 'Tag 2', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO tags (id, owner_id, name, description, created_at, updated_at)
+VALUES ('e8f4fc63-eaf7-457f-a127-85c7072a0220', '7d399e9e-9df0-4dcb-a733-3d4a8be80123', 'Tag 4', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO tags (id, owner_id, name, description, created_at, updated_at)
+VALUES ('82868af3-8acb-40c5-b321-c3b9748eb7ec', '7d399e9e-9df0-4dcb-a733-3d4a8be80123', 'Tag 8', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO tags (id, owner_id, name, description, created_at, updated_at)
+VALUES ('e6571c15-edc1-4a78-99c8-201d889aa99b', 'b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'Tag 2', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO tags (id, owner_id, name, description, created_at, updated_at)
+VALUES ('1093fc0d-4006-4b8d-9951-e600819c1d4a', 'b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'Tag 3', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO tags (id, owner_id, name, description, created_at, updated_at)
+VALUES ('4430741a-ff02-4872-a4bc-1629a8616793', 'b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'Tag 4', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO tags (id, owner_id, name, description, created_at, updated_at)
+VALUES ('8f555ecc-7dd2-489b-a874-5e684da08170', 'b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'Tag 5', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO tags (id, owner_id, name, description, created_at, updated_at)
+VALUES ('5a38caaa-2935-43ab-ad6f-dd2cfe61eca1', 'b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'Tag 6', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO tags (id, owner_id, name, description, created_at, updated_at)
+VALUES ('f3c6916c-c849-4e0d-9d41-d49bee04c16e', 'b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'Tag 7', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO categories (id, owner_id, name, description, created_at, updated_at)
+VALUES ('7d2cad58-154e-4bff-8d6c-b0544a252981', '0792b97b-4f88-42a8-a035-1d0aad0ae7f8', 'Category 1', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO categories (id, owner_id, name, description, created_at, updated_at)
+VALUES ('09e03baa-0a00-4ff4-a043-318fcbf29164', '7d399e9e-9df0-4dcb-a733-3d4a8be80123', 'Category 2', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO categories (id, owner_id, name, description, created_at, updated_at)
+VALUES ('0819544a-a6ca-4385-a0da-1604791a7cc1', '7d399e9e-9df0-4dcb-a733-3d4a8be80123', 'Category 3', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO categories (id, owner_id, name, description, created_at, updated_at)
+VALUES ('f9d0d834-bdf9-4ced-849f-9cf10c791597', 'b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'Category 1', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO categories (id, owner_id, name, description, created_at, updated_at)
+VALUES ('a111041c-3e2b-45fc-bad4-cbe590d73de3', 'b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'Category 2', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO categories (id, owner_id, name, description, created_at, updated_at)
+VALUES ('8a7e0b0c-18c3-401b-82c3-1405ab3fde5c', 'b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'Category 3', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO categories (id, owner_id, name, description, created_at, updated_at)
+VALUES ('98bdd8e3-f75e-46cc-858e-cb2aef52a4b5', 'b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'Category 4', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO locations (id, owner_id, name, description, created_at, updated_at)
+VALUES ('88573099-c0ca-42bd-9430-58ac6b5e64e0', '0792b97b-4f88-42a8-a035-1d0aad0ae7f8', 'Location 1', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO locations (id, owner_id, name, description, created_at, updated_at)
+VALUES ('1bb36679-c11b-413c-854a-7238b76eaa99', '7d399e9e-9df0-4dcb-a733-3d4a8be80123', 'Location 6', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO locations (id, owner_id, name, description, created_at, updated_at)
+VALUES ('901dc56e-8be0-452c-a6f9-526140998e39', '7d399e9e-9df0-4dcb-a733-3d4a8be80123', 'Location 7', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO locations (id, owner_id, name, description, created_at, updated_at)
+VALUES ('6c4867ba-b5d7-400f-ab05-309454e1f44b', 'b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'Location 1', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO locations (id, owner_id, name, description, created_at, updated_at)
+VALUES ('d23b40d4-3c15-464d-a377-60037b189fde', 'b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'Location 2', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO locations (id, owner_id, name, description, created_at, updated_at)
+VALUES ('ddd5246d-ae02-4a1e-9aec-d9d37b36cd38', 'b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'Location 3', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO locations (id, owner_id, name, description, created_at, updated_at)
+VALUES ('66cd634b-7dce-4d6a-9a97-891bf51b09e6', 'b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'Location 4', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO locations (id, owner_id, name, description, created_at, updated_at)
+VALUES ('5ec21ca4-7fed-483a-a62b-d9bb0e94a9cf', 'b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'Location 5', '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO task_tags (task_id, tag_id)
+VALUES ('c0d1dbdb-b65e-4c4d-8f92-7b3ed6250123', 'bd1ae901-e240-4aee-bfcf-3873f041fcb4');
+
+--SEED
+INSERT INTO task_tags (task_id, tag_id)
+VALUES ('c0d1dbdb-b65e-4c4d-8f92-7b3ed6250123', 'e2d48e43-efa7-4d88-8319-f0eb283bf982');
+
+--SEED
+INSERT INTO task_tags (task_id, tag_id)
+VALUES ('4b87ef82-d16b-4b5d-b1a0-3a8366120123', '1093fc0d-4006-4b8d-9951-e600819c1d4a');
+
+--SEED
+INSERT INTO task_tags (task_id, tag_id)
+VALUES ('4b87ef82-d16b-4b5d-b1a0-3a8366120123', '4430741a-ff02-4872-a4bc-1629a8616793');
+
+--SEED
+INSERT INTO task_tags (task_id, tag_id)
+VALUES ('d6ff256b-9f79-42be-99ea-835c586e0123', 'e6571c15-edc1-4a78-99c8-201d889aa99b');
+
+--SEED
+INSERT INTO task_tags (task_id, tag_id)
+VALUES ('d6ff256b-9f79-42be-99ea-835c586e0123', '8f555ecc-7dd2-489b-a874-5e684da08170');
+
+--SEED
+INSERT INTO task_tags (task_id, tag_id)
+VALUES ('e8b13d39-894e-487b-9f9e-19a5c2490123', '5a38caaa-2935-43ab-ad6f-dd2cfe61eca1');
+
+--SEED
+INSERT INTO task_tags (task_id, tag_id)
+VALUES ('e8b13d39-894e-487b-9f9e-19a5c2490123', 'f3c6916c-c849-4e0d-9d41-d49bee04c16e');
+
+--SEED
+INSERT INTO task_tags (task_id, tag_id)
+VALUES ('af6c62da-54ad-414f-9462-c5229a5b0123', 'e8f4fc63-eaf7-457f-a127-85c7072a0220');
+
+--SEED
+INSERT INTO task_tags (task_id, tag_id)
+VALUES ('af6c62da-54ad-414f-9462-c5229a5b0123', '82868af3-8acb-40c5-b321-c3b9748eb7ec');
+
+--SEED
+INSERT INTO task_categories (task_id, category_id)
+VALUES ('c0d1dbdb-b65e-4c4d-8f92-7b3ed6250123', '7d2cad58-154e-4bff-8d6c-b0544a252981');
+
+--SEED
+INSERT INTO task_categories (task_id, category_id)
+VALUES ('4b87ef82-d16b-4b5d-b1a0-3a8366120123', 'a111041c-3e2b-45fc-bad4-cbe590d73de3');
+
+--SEED
+INSERT INTO task_categories (task_id, category_id)
+VALUES ('d6ff256b-9f79-42be-99ea-835c586e0123', 'f9d0d834-bdf9-4ced-849f-9cf10c791597');
+
+--SEED
+INSERT INTO task_categories (task_id, category_id)
+VALUES ('d6ff256b-9f79-42be-99ea-835c586e0123', '8a7e0b0c-18c3-401b-82c3-1405ab3fde5c');
+
+--SEED
+INSERT INTO task_categories (task_id, category_id)
+VALUES ('e8b13d39-894e-487b-9f9e-19a5c2490123', '98bdd8e3-f75e-46cc-858e-cb2aef52a4b5');
+
+--SEED
+INSERT INTO task_categories (task_id, category_id)
+VALUES ('af6c62da-54ad-414f-9462-c5229a5b0123', '09e03baa-0a00-4ff4-a043-318fcbf29164');
+
+--SEED
+INSERT INTO task_categories (task_id, category_id)
+VALUES ('af6c62da-54ad-414f-9462-c5229a5b0123', '0819544a-a6ca-4385-a0da-1604791a7cc1');
+
+--SEED
+INSERT INTO task_locations (task_id, location_id)
+VALUES ('c0d1dbdb-b65e-4c4d-8f92-7b3ed6250123', '88573099-c0ca-42bd-9430-58ac6b5e64e0');
+
+--SEED
+INSERT INTO task_locations (task_id, location_id)
+VALUES ('4b87ef82-d16b-4b5d-b1a0-3a8366120123', 'd23b40d4-3c15-464d-a377-60037b189fde');
+
+--SEED
+INSERT INTO task_locations (task_id, location_id)
+VALUES ('4b87ef82-d16b-4b5d-b1a0-3a8366120123', 'ddd5246d-ae02-4a1e-9aec-d9d37b36cd38');
+
+--SEED
+INSERT INTO task_locations (task_id, location_id)
+VALUES ('d6ff256b-9f79-42be-99ea-835c586e0123', '6c4867ba-b5d7-400f-ab05-309454e1f44b');
+
+--SEED
+INSERT INTO task_locations (task_id, location_id)
+VALUES ('d6ff256b-9f79-42be-99ea-835c586e0123', '66cd634b-7dce-4d6a-9a97-891bf51b09e6');
+
+--SEED
+INSERT INTO task_locations (task_id, location_id)
+VALUES ('e8b13d39-894e-487b-9f9e-19a5c2490123', '5ec21ca4-7fed-483a-a62b-d9bb0e94a9cf');
+
+--SEED
+INSERT INTO task_locations (task_id, location_id)
+VALUES ('af6c62da-54ad-414f-9462-c5229a5b0123', '1bb36679-c11b-413c-854a-7238b76eaa99');
+
+--SEED
+INSERT INTO task_locations (task_id, location_id)
+VALUES ('af6c62da-54ad-414f-9462-c5229a5b0123', '901dc56e-8be0-452c-a6f9-526140998e39');
@@ -0,0 +1,19 @@
+--SEED
+INSERT INTO list_members (list_id, user_id, role, created_at, updated_at)
+VALUES ('cdc7a443-3c6a-431b-b45a-b14735953a19', '0792b97b-4f88-42a8-a035-1d0aad0ae7f8', 'owner', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO list_members (list_id, user_id, role, created_at, updated_at)
+VALUES ('70a4a418-7b2b-4c2b-95b3-4e1656c81234', 'b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'owner', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO list_members (list_id, user_id, role, created_at, updated_at)
+VALUES ('dd7edff4-9b0d-4e92-80e2-1db98b4b0123', 'b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'owner', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO list_members (list_id, user_id, role, created_at, updated_at)
+VALUES ('d9d5d7d0-dc10-48a9-a580-7d4293f11234', 'b1c20e60-ec1c-4fae-97b9-b4d0578b0123', 'owner', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+--SEED
+INSERT INTO list_members (list_id, user_id, role, created_at, updated_at)
+VALUES ('ddc09e08-286b-47a7-bcd0-8b8d3bdc0123', '7d399e9e-9df0-4dcb-a733-3d4a8be80123', 'owner', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
@@ -0,0 +1,4 @@
+--SEED
+UPDATE users
+SET role = 'admin'
+WHERE id = '7d399e9e-9df0-4dcb-a733-3d4a8be80123';
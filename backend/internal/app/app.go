@@ -6,16 +6,21 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/vanillazen/stl/backend/internal/domain/model"
 	"github.com/vanillazen/stl/backend/internal/domain/port"
 	"github.com/vanillazen/stl/backend/internal/domain/service"
 	"github.com/vanillazen/stl/backend/internal/infra/db"
 	"github.com/vanillazen/stl/backend/internal/infra/db/sqlite"
+	"github.com/vanillazen/stl/backend/internal/infra/events"
 	http2 "github.com/vanillazen/stl/backend/internal/infra/http"
+	"github.com/vanillazen/stl/backend/internal/infra/mail"
 	migrator "github.com/vanillazen/stl/backend/internal/infra/migration"
 	mig "github.com/vanillazen/stl/backend/internal/infra/migration/sqlite"
+	"github.com/vanillazen/stl/backend/internal/infra/outbox"
 	sqliterepo "github.com/vanillazen/stl/backend/internal/infra/repo/sqlite"
 	"github.com/vanillazen/stl/backend/internal/infra/seed"
 	sqlite2 "github.com/vanillazen/stl/backend/internal/infra/seed/sqlite"
+	"github.com/vanillazen/stl/backend/internal/infra/webhook"
 
 	"github.com/vanillazen/stl/backend/internal/sys"
 	"github.com/vanillazen/stl/backend/internal/sys/config"
@@ -33,9 +38,21 @@ type App struct {
 	http       *http2.Server
 	db         db.DB
 	repo       port.ListRepo
+	userRepo   port.UserRepo
+	idemRepo   port.IdempotencyRepo
+	hookRepo   port.WebhookRepo
+	outboxRepo port.OutboxRepo
+	mailer     port.Mailer
+	events     *events.Hub
+	dispatcher *webhook.Dispatcher
+	bus        *outbox.Bus
+	policy     *service.Policy
 	migrator   migrator.Migrator
 	seeder     seed.Seeder
 	svc        service.ListService
+	userSvc    service.UserService
+	authSvc    service.AuthService
+	hookSvc    service.WebhookService
 	apiDoc     string
 }
 
@@ -92,12 +109,40 @@ func (app *App) Setup(ctx context.Context) error {
 
 	// Repos
 	app.repo = sqliterepo.NewListRepo(app.db, app.opts...)
+	app.userRepo = sqliterepo.NewUserRepo(app.db, app.opts...)
+	app.idemRepo = sqliterepo.NewIdempotencyRepo(app.db, app.opts...)
+	app.hookRepo = sqliterepo.NewWebhookRepo(app.db, app.opts...)
+	app.outboxRepo = sqliterepo.NewOutboxRepo(app.db, app.opts...)
+
+	// Mail
+	app.mailer = mail.NewMailer(app.opts...)
+
+	// Events
+	app.events = events.NewHub(app.opts...)
+
+	// Webhooks
+	app.dispatcher = webhook.NewDispatcher(app.hookRepo, app.opts...)
+
+	// Domain events, subscribers are added to the bus once the services are set up, before it starts
+	app.bus = outbox.NewBus(app.outboxRepo, app.opts...)
+
+	// Authorization
+	app.policy = service.NewPolicy(app.repo, app.opts...)
 
 	// Services
-	app.svc = service.NewService(app.repo, app.opts...)
+	app.svc = service.NewService(app.repo, app.policy, app.mailer, app.events, app.hookRepo, app.opts...)
+	app.userSvc = service.NewUserService(app.userRepo, app.policy, app.opts...)
+	app.authSvc = service.NewAuthService(app.userRepo, app.opts...)
+	app.hookSvc = service.NewWebhookService(app.hookRepo, app.policy, app.opts...)
+
+	// Subscribers, their names record which ones handled each event and must not change
+	app.bus.Subscribe("event-hub", app.svc.PublishEvent,
+		model.ListUpdated, model.ListDeleted, model.TaskCreated, model.TaskUpdated, model.TaskMoved, model.TaskDeleted)
+	app.bus.Subscribe("webhooks", app.svc.EnqueueDeliveries,
+		model.ListUpdated, model.TaskCreated, model.TaskUpdated, model.TaskCompleted, model.TaskMoved, model.TaskDeleted)
 
 	// HTTP Server
-	app.http = http2.NewServer(app.svc, app.apiDoc, app.opts...)
+	app.http = http2.NewServer(app.svc, app.userSvc, app.authSvc, app.hookSvc, app.idemRepo, app.apiDoc, app.opts...)
 
 	err := app.http.Setup(ctx)
 	if err != nil {
@@ -143,14 +188,41 @@ func (app *App) Start(ctx context.Context) (err error) {
 		return err
 	}
 
+	err = app.userSvc.Start(ctx)
+	if err != nil {
+		app.Log().Errorf("%s start error: %s", app.Name(), err)
+		return err
+	}
+
+	err = app.authSvc.Start(ctx)
+	if err != nil {
+		app.Log().Errorf("%s start error: %s", app.Name(), err)
+		return err
+	}
+
+	err = app.hookSvc.Start(ctx)
+	if err != nil {
+		app.Log().Errorf("%s start error: %s", app.Name(), err)
+		return err
+	}
+
 	// Blocking non-sequential start
 	app.supervisor.AddTasks(
 		app.http.Start,
+		app.http.Realtime().Start,
+		app.events.Start,
+		app.bus.Start,
+		app.bus.Purge,
 		//app.grpc.Start,
 	)
 
+	// Webhook delivery workers
+	app.supervisor.AddTasks(app.dispatcher.Workers()...)
+
 	app.supervisor.AddShutdownTasks(
 		app.http.Stop,
+		app.http.Realtime().Stop,
+		app.events.Stop,
 		//app.grpc.Start,
 	)
 
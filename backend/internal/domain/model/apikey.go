@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+const (
+	// ReadScope only allows safe requests, those that do not modify resources.
+	ReadScope = "read"
+	// ReadWriteScope allows every request the owner of the key can make.
+	ReadWriteScope = "read-write"
+)
+
+var Scopes = []string{ReadScope, ReadWriteScope}
+
+type (
+	// APIKey is a long-lived credential for non interactive callers.
+	// Only a hash of the key is stored, Prefix is kept to let owners tell their keys apart.
+	APIKey struct {
+		ID
+		UserID     ID
+		Name       string
+		Prefix     string
+		Hash       string
+		Scope      string
+		LastUsedAt time.Time
+		RevokedAt  time.Time
+		Audit
+	}
+)
+
+// Active returns true if the key has not been revoked.
+func (k APIKey) Active() bool {
+	return k.RevokedAt.IsZero()
+}
+
+// Allows returns true if the key scope permits requests that modify resources when write is set.
+func (k APIKey) Allows(write bool) bool {
+	return !write || k.Scope == ReadWriteScope
+}
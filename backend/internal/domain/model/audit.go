@@ -6,6 +6,9 @@ type (
 	Audit struct {
 		CreatedAt time.Time
 		UpdatedAt time.Time
+		// Version is incremented on every change of lists and tasks, it is zero for other resources.
+		// UpdatedAt can not tell apart changes made within the same clock tick.
+		Version int
 	}
 )
 
@@ -0,0 +1,41 @@
+package model
+
+type (
+	// TaskOpKind is the change a batch operation makes to a task.
+	TaskOpKind string
+
+	// TaskOp is an operation of a task batch.
+	// Task holds the fields of created and updated tasks, and the ID and version, zero for any, of the task
+	// the other operations apply to. Moved tasks go to ToListID, at the task position or else at its end.
+	TaskOp struct {
+		Kind     TaskOpKind
+		Task     Task
+		ToListID string
+	}
+
+	// TaskOpResult is the outcome of a batch operation, the task as left by it or the error that prevented it.
+	TaskOpResult struct {
+		Task Task
+		Err  error
+	}
+)
+
+const (
+	CreateTaskOp TaskOpKind = "create"
+	UpdateTaskOp TaskOpKind = "update"
+	DeleteTaskOp TaskOpKind = "delete"
+	MoveTaskOp   TaskOpKind = "move"
+)
+
+var TaskOpKinds = []TaskOpKind{CreateTaskOp, UpdateTaskOp, DeleteTaskOp, MoveTaskOp}
+
+// Valid returns true if the kind is one of TaskOpKinds.
+func (k TaskOpKind) Valid() bool {
+	for _, kind := range TaskOpKinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
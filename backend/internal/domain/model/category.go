@@ -0,0 +1,11 @@
+package model
+
+type (
+	Category struct {
+		ID
+		OwnerID     ID
+		Name        string
+		Description string
+		Audit
+	}
+)
@@ -0,0 +1,79 @@
+package model
+
+import "time"
+
+type (
+	// EventType names the change an event reports, as "resource.change".
+	EventType string
+
+	// Event is a change made to a list or to one of its tasks, published to the subscribers of the list.
+	// ID is assigned on publishing, it increases with every event so that subscribers can resume after the last
+	// one they got. List and Task hold the resource as read when publishing the event, deletions only have their IDs.
+	// They reflect the changes made up to then, which may be later than the one the event reports.
+	Event struct {
+		ID     uint64
+		Type   EventType
+		ListID string
+		TaskID string
+		UserID string
+		List   *List
+		Task   *Task
+		At     time.Time
+	}
+)
+
+const (
+	ListUpdatedEvent EventType = "list.updated"
+	ListDeletedEvent EventType = "list.deleted"
+	TaskCreatedEvent EventType = "task.created"
+	TaskUpdatedEvent EventType = "task.updated"
+	TaskDeletedEvent EventType = "task.deleted"
+	// TaskMovedEvent is published to both lists, the task ListID is the one it went to.
+	TaskMovedEvent EventType = "task.moved"
+	// TaskCompletedEvent is only delivered to webhooks, after the update or move of the task that completed it.
+	TaskCompletedEvent EventType = "task.completed"
+	// ResetEvent tells a resuming subscriber that events were lost and the list must be read again.
+	ResetEvent EventType = "reset"
+)
+
+// NewListEvent returns an event of a change to a list, deletions have no list.
+func NewListEvent(typ EventType, listID string, list *List, userID string) Event {
+	e := Event{
+		Type:   typ,
+		ListID: listID,
+		UserID: userID,
+		At:     time.Now().UTC(),
+	}
+
+	// Role is the one of the user that made the change, not of the subscribers
+	if list != nil {
+		l := *list
+		l.Role = ""
+		e.List = &l
+	}
+
+	return e
+}
+
+// NewTaskEvent returns an event of a change to a task of a list, deletions have no task.
+func NewTaskEvent(typ EventType, listID, taskID string, task *Task, userID string) Event {
+	return Event{
+		Type:   typ,
+		ListID: listID,
+		TaskID: taskID,
+		UserID: userID,
+		Task:   task,
+		At:     time.Now().UTC(),
+	}
+}
+
+// Webhook returns true if webhooks can subscribe to the event type.
+func (typ EventType) Webhook() bool {
+	for _, t := range WebhookEvents {
+		if typ == t {
+			return true
+		}
+	}
+
+	return false
+}
@@ -15,7 +15,7 @@ func NewID(uid uuid.UUID) ID {
 }
 
 func (i *ID) GenID(id ...uuid.UUID) error {
-	if i.UUID != uuid.Nil {
+	if i.UUID.Val != "" && !i.UUID.Nil() {
 		return nil // already has a value assigned
 	}
 
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+type (
+	// IdempotencyRecord is the response to a request made with an idempotency key, the same user retrying
+	// the request on the same endpoint with that key gets it back instead of running the request again.
+	// Fingerprint identifies the request payload, a zero Status marks a request still being processed.
+	IdempotencyRecord struct {
+		UserID      string
+		Method      string
+		Path        string
+		Key         string
+		Fingerprint string
+		Status      int
+		Header      map[string][]string
+		Body        []byte
+		CreatedAt   time.Time
+		ExpiresAt   time.Time
+	}
+)
+
+// Completed returns true if the response of the request was recorded.
+func (r IdempotencyRecord) Completed() bool {
+	return r.Status != 0
+}
@@ -6,7 +6,11 @@ type (
 		Name        string
 		Description string
 		Owner       User
-		Tasks       []Task
+		// Role of the user the list was read for.
+		Role  Role
+		Tasks []Task
+		// TasksVersion is incremented on every change of the tasks of the list, which leave its Version unchanged.
+		TasksVersion int
 		Audit
 	}
 )
@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+// Role a user has on a shared list, each one includes the permissions of the previous ones.
+// Viewers can read the list and its tasks, editors can also change them and
+// owners can also delete the list and manage its members.
+type Role string
+
+const (
+	ViewerRole Role = "viewer"
+	EditorRole Role = "editor"
+	OwnerRole  Role = "owner"
+)
+
+var Roles = []Role{ViewerRole, EditorRole, OwnerRole}
+
+type (
+	// Member is a user with access to a list.
+	Member struct {
+		ListID ID
+		User   User
+		Role   Role
+		Audit
+	}
+
+	// Invitation to become a member of a list, sent by email.
+	// It is accepted by the user registered with that email.
+	Invitation struct {
+		ID
+		ListID    ID
+		ListName  string
+		Email     string
+		Role      Role
+		InvitedBy ID
+		ExpiresAt time.Time
+		Audit
+	}
+)
+
+// Valid returns true if the role is one of the known roles.
+func (r Role) Valid() bool {
+	return r.rank() > 0
+}
+
+// Includes returns true if the role grants at least the permissions of other.
+func (r Role) Includes(other Role) bool {
+	return r.Valid() && r.rank() >= other.rank()
+}
+
+func (r Role) rank() int {
+	for i, role := range Roles {
+		if r == role {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// Expired returns true if the invitation can no longer be accepted at the given time.
+func (i Invitation) Expired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
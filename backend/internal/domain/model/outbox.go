@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+type (
+	// DomainEventType names the change a domain event reports, as "resource.change".
+	DomainEventType string
+
+	// DomainEvent is a change of state recorded in the outbox in the same transaction as the change, so that it is
+	// delivered to in-process subscribers at least once even if the process stops right after it.
+	// ID increases with every event, subscribers can use it to skip the ones they already handled.
+	// ItemID is the task or the member the event is about, the list itself otherwise. UserID is the user that made
+	// the change, if known. Payload is a JSON object with the fields of the item that describe the change, as they
+	// were when it was written. Subscribers read the rest of the item when they handle the event.
+	// Handled has the names of the subscribers that already handled the event, they do not get it again on retries.
+	// Events still failing after the last attempt are set FailedAt and kept, but no longer delivered.
+	DomainEvent struct {
+		ID            uint64
+		Type          DomainEventType
+		ListID        string
+		ItemID        string
+		UserID        string
+		Payload       []byte
+		Attempts      int
+		NextAttemptAt time.Time
+		Error         string
+		Handled       []string
+		CreatedAt     time.Time
+		DoneAt        time.Time
+		FailedAt      time.Time
+	}
+)
+
+const (
+	ListCreated DomainEventType = "list.created"
+	ListUpdated DomainEventType = "list.updated"
+	// ListDeleted follows the events of the removal of its tasks and members.
+	ListDeleted DomainEventType = "list.deleted"
+	// ListShared reports a member added to a list, other than its owner.
+	ListShared DomainEventType = "list.shared"
+	// ListUnshared reports a member removed from a list, other than its owner.
+	ListUnshared DomainEventType = "list.unshared"
+	TaskCreated  DomainEventType = "task.created"
+	TaskUpdated  DomainEventType = "task.updated"
+	// TaskCompleted follows the update or move of the task that completed it.
+	TaskCompleted DomainEventType = "task.completed"
+	// TaskMoved is reported to the list the task went to, its payload has the one it left.
+	TaskMoved   DomainEventType = "task.moved"
+	TaskDeleted DomainEventType = "task.deleted"
+)
+
+// Done returns true if all subscribers handled the event.
+func (e DomainEvent) Done() bool {
+	return !e.DoneAt.IsZero()
+}
+
+// Failed returns true if the event is no longer delivered, as some subscriber failed all the attempts.
+func (e DomainEvent) Failed() bool {
+	return !e.FailedAt.IsZero()
+}
+
+// HandledBy returns true if the subscriber already handled the event.
+func (e DomainEvent) HandledBy(subscriber string) bool {
+	for _, name := range e.Handled {
+		if name == subscriber {
+			return true
+		}
+	}
+
+	return false
+}
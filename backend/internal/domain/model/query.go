@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// Fields collections can be sorted by, clients use these names in the sort parameter.
+var (
+	ListSortFields = []string{"name", "created_at", "updated_at"}
+	TaskSortFields = []string{"position", "name", "priority", "done", "due_at", "completed_at", "created_at", "updated_at"}
+)
+
+type (
+	// Page selects a window of a collection.
+	Page struct {
+		Limit  int
+		Offset int
+	}
+
+	// Sort orders a collection by one of its sort fields, descending if Desc.
+	Sort struct {
+		Field string
+		Desc  bool
+	}
+
+	// ListQuery selects the lists of a user, a zero Role matches any of them.
+	ListQuery struct {
+		Page
+		Sort []Sort
+		Role Role
+	}
+
+	// TaskQuery selects the tasks of a list, zero fields do not filter.
+	TaskQuery struct {
+		Page
+		Sort      []Sort
+		Tag       string
+		Done      *bool
+		Priority  *Priority
+		DueBefore time.Time
+		DueAfter  time.Time
+	}
+)
@@ -0,0 +1,30 @@
+package model
+
+// SearchKind is the type of item a search result is.
+type SearchKind string
+
+const (
+	ListSearchKind SearchKind = "list"
+	TaskSearchKind SearchKind = "task"
+)
+
+type (
+	// SearchQuery selects the lists and tasks matching all the terms, the last one also as a prefix.
+	SearchQuery struct {
+		Page
+		Terms []string
+	}
+
+	// SearchResult is a list or task matching a search.
+	// Name and Snippet have the matching terms enclosed in highlight marks.
+	SearchResult struct {
+		Kind     SearchKind
+		ItemID   ID
+		ListID   ID
+		ListName string
+		Name     string
+		Snippet  string
+		Tags     string
+		Rank     float64
+	}
+)
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+type (
+	// Session groups the tokens issued on a login so that they can be refreshed and revoked together.
+	// RefreshID identifies the only refresh token currently valid for the session.
+	Session struct {
+		ID
+		UserID    ID
+		RefreshID string
+		ExpiresAt time.Time
+		RevokedAt time.Time
+		Audit
+	}
+)
+
+// Active returns true if the session has been neither revoked nor expired at the given time.
+func (s Session) Active(now time.Time) bool {
+	return s.RevokedAt.IsZero() && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,81 @@
+package model
+
+type (
+	// ChangeKind is the type of item a change is about.
+	ChangeKind string
+
+	// Change is the current state of a list or task, or its tombstone if it was deleted.
+	// Seq increases with every change, so clients apply them in order and keep the last one to sync from.
+	// A deleted task is only removed from ListID, a task moved to another list leaves a tombstone in the one it left.
+	Change struct {
+		Seq     uint64
+		Kind    ChangeKind
+		ItemID  string
+		ListID  string
+		Deleted bool
+		List    *List
+		Task    *Task
+	}
+
+	// ChangeSet is a page of the changes made to the lists of a user after a sequence number.
+	// Seq is the one to sync from next, More is true if there are changes left after it.
+	// Reset is true if the changes could not follow the requested sequence number, they start over from the
+	// beginning and clients drop what they have.
+	ChangeSet struct {
+		Changes []Change
+		Seq     uint64
+		More    bool
+		Reset   bool
+	}
+
+	// MutationOp is the change a mutation pushed by a client makes.
+	MutationOp string
+
+	// Mutation is a change made by a client while offline, to a list or to a task.
+	// Created items keep the ID the client gave them, if any. Other mutations apply to the item with the ID at the
+	// version the client changed, zero for any.
+	Mutation struct {
+		Op   MutationOp
+		Kind ChangeKind
+		List List
+		Task Task
+	}
+)
+
+const (
+	ListChange ChangeKind = "list"
+	TaskChange ChangeKind = "task"
+)
+
+const (
+	CreateMutation MutationOp = "create"
+	UpdateMutation MutationOp = "update"
+	DeleteMutation MutationOp = "delete"
+)
+
+var (
+	ChangeKinds = []ChangeKind{ListChange, TaskChange}
+	MutationOps = []MutationOp{CreateMutation, UpdateMutation, DeleteMutation}
+)
+
+// Valid returns true if the kind is one of ChangeKinds.
+func (k ChangeKind) Valid() bool {
+	for _, kind := range ChangeKinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Valid returns true if the op is one of MutationOps.
+func (op MutationOp) Valid() bool {
+	for _, o := range MutationOps {
+		if op == o {
+			return true
+		}
+	}
+
+	return false
+}
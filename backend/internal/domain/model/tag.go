@@ -0,0 +1,13 @@
+package model
+
+type (
+	Tag struct {
+		ID
+		OwnerID     ID
+		Name        string
+		Description string
+		// Usage is the number of tasks the tag is attached to.
+		Usage int
+		Audit
+	}
+)
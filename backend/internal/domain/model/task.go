@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type (
 	Task struct {
 		ID
@@ -9,6 +11,26 @@ type (
 		Category    StringSlice
 		Tags        StringSlice
 		Location    StringSlice
+		Done        bool
+		CompletedAt time.Time
+		DueAt       time.Time
+		Priority    Priority
+		Position    int
 		Audit
 	}
+
+	// Priority ranks a task, PriorityNone is the default.
+	Priority int
 )
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+// Valid returns true if priority is one of the known values.
+func (p Priority) Valid() bool {
+	return p >= PriorityNone && p <= PriorityHigh
+}
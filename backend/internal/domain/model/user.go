@@ -1,11 +1,22 @@
 package model
 
+// SystemRole of a user across the whole system, as opposed to the Role it has on a shared list.
+type SystemRole string
+
+const (
+	UserSystemRole  SystemRole = "user"
+	AdminSystemRole SystemRole = "admin"
+)
+
 type (
 	User struct {
 		ID
 		Username string
 		Name     string
 		Email    string
+		// Password holds the plain password on registration and its hash once stored.
 		Password string
+		Role     SystemRole
+		Audit
 	}
 )
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"net"
+	"strings"
+	"time"
+)
+
+type (
+	// Webhook delivers the events of the lists of its user to a URL, or those of a single list if ListID is set.
+	// Events are only delivered while the user is a member of their list. Deliveries are signed with Secret,
+	// so that receivers can check they come from the service.
+	Webhook struct {
+		ID
+		UserID ID
+		ListID ID
+		URL    string
+		Events []EventType
+		Secret string
+		Audit
+	}
+
+	// DeliveryStatus is the state of a webhook delivery.
+	DeliveryStatus string
+
+	// WebhookDelivery is an event sent to a webhook, it is kept as a log entry once done.
+	// Pending deliveries are sent at NextAttemptAt, failed attempts are retried later until none are left.
+	// ResponseStatus and Error describe the last attempt.
+	WebhookDelivery struct {
+		ID
+		WebhookID      ID
+		EventType      EventType
+		Payload        []byte
+		Status         DeliveryStatus
+		Attempts       int
+		NextAttemptAt  time.Time
+		LastAttemptAt  time.Time
+		ResponseStatus int
+		Error          string
+		Audit
+		// Webhook is only loaded for deliveries claimed to be sent.
+		Webhook Webhook
+	}
+)
+
+const (
+	PendingDelivery   DeliveryStatus = "pending"
+	SucceededDelivery DeliveryStatus = "succeeded"
+	FailedDelivery    DeliveryStatus = "failed"
+)
+
+// WebhookEvents are the event types webhooks can subscribe to.
+// Deleted lists are not delivered, their members are gone along with them.
+var WebhookEvents = []EventType{
+	ListUpdatedEvent,
+	TaskCreatedEvent,
+	TaskUpdatedEvent,
+	TaskCompletedEvent,
+	TaskDeletedEvent,
+	TaskMovedEvent,
+}
+
+// internalNets are the networks other than loopback, private, link-local and multicast ones that webhooks
+// cannot be delivered to: "this" network (RFC 1122) and shared address space (RFC 6598).
+var internalNets = []*net.IPNet{
+	{IP: net.IPv4(0, 0, 0, 0), Mask: net.CIDRMask(8, 32)},
+	{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)},
+}
+
+// WebhookHostAllowed returns false for hosts webhooks cannot be delivered to, so that they cannot be used to reach
+// the network of the service: localhost names and addresses rejected by WebhookIPAllowed.
+// Other names are checked once resolved, when delivering.
+func WebhookHostAllowed(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+	return ip == nil || WebhookIPAllowed(ip)
+}
+
+// WebhookIPAllowed returns false for loopback, private, link-local, unspecified, multicast and other internal
+// addresses, webhooks are only delivered to public ones.
+func WebhookIPAllowed(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+
+	for _, n := range internalNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Done returns true if the delivery will not be attempted again.
+func (d WebhookDelivery) Done() bool {
+	return d.Status != PendingDelivery
+}
@@ -0,0 +1,38 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+)
+
+func TestWebhookHostAllowed(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected bool
+	}{
+		{host: "hooks.example.com", expected: true},
+		{host: "93.184.216.34", expected: true},
+		{host: "2606:2800:220:1:248:1893:25c8:1946", expected: true},
+		{host: "localhost", expected: false},
+		{host: "api.LOCALHOST.", expected: false},
+		{host: "127.0.0.1", expected: false},
+		{host: "::1", expected: false},
+		{host: "10.0.0.8", expected: false},
+		{host: "172.16.4.1", expected: false},
+		{host: "192.168.1.1", expected: false},
+		{host: "169.254.169.254", expected: false},
+		{host: "fe80::1", expected: false},
+		{host: "fd00::1", expected: false},
+		{host: "0.0.0.0", expected: false},
+		{host: "100.64.0.1", expected: false},
+		{host: "::ffff:127.0.0.1", expected: false},
+		{host: "224.0.0.1", expected: false},
+	}
+
+	for _, test := range tests {
+		if got := model.WebhookHostAllowed(test.host); got != test.expected {
+			t.Errorf("Host %s: expected allowed %t, got %t", test.host, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package port
+
+import (
+	"fmt"
+
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+)
+
+type (
+	// NotFoundErr is returned when a resource does not exist or is not visible to the user.
+	NotFoundErr struct {
+		Resource string
+		ID       string
+	}
+
+	// UnauthenticatedErr is returned when credentials or tokens are missing, invalid, expired or revoked.
+	UnauthenticatedErr struct {
+		Reason string
+	}
+
+	// ExistsErr is returned when a resource is created with the ID of an existing one.
+	ExistsErr struct {
+		Resource string
+		ID       string
+	}
+
+	// ForbiddenErr is returned when the user is not allowed to perform an action on a resource it can see.
+	ForbiddenErr struct {
+		Reason string
+	}
+
+	// VersionErr is returned when a change is conditioned to a version of a resource that is no longer the current one.
+	VersionErr struct {
+		Resource string
+		ID       string
+		Current  int
+	}
+
+	// TaskOpErr is returned when an operation of a task batch fails, Index is its position in the batch.
+	// Its kind is the one of the error of the operation.
+	TaskOpErr struct {
+		Index int
+		Op    string
+		Err   error
+	}
+)
+
+var (
+	ListNotFoundErr        = NotFoundErr{Resource: "list"}
+	TaskNotFoundErr        = NotFoundErr{Resource: "task"}
+	TagNotFoundErr         = NotFoundErr{Resource: "tag"}
+	UserNotFoundErr        = NotFoundErr{Resource: "user"}
+	SessionNotFoundErr     = NotFoundErr{Resource: "session"}
+	APIKeyNotFoundErr      = NotFoundErr{Resource: "api key"}
+	MemberNotFoundErr      = NotFoundErr{Resource: "member"}
+	InvitationNotFoundErr  = NotFoundErr{Resource: "invitation"}
+	IdempotencyNotFoundErr = NotFoundErr{Resource: "idempotency key"}
+	WebhookNotFoundErr     = NotFoundErr{Resource: "webhook"}
+	DeliveryNotFoundErr    = NotFoundErr{Resource: "delivery"}
+	EventNotFoundErr       = NotFoundErr{Resource: "event"}
+
+	ListExistsErr = ExistsErr{Resource: "list"}
+	TaskExistsErr = ExistsErr{Resource: "task"}
+
+	VersionMismatchErr = VersionErr{}
+
+	IdempotencyKeyUsedErr = errors.NewKind(errors.Conflict, "idempotency key already used")
+)
+
+func NewListNotFoundErr(listID string) NotFoundErr {
+	return NotFoundErr{Resource: ListNotFoundErr.Resource, ID: listID}
+}
+
+func NewTaskNotFoundErr(taskID string) NotFoundErr {
+	return NotFoundErr{Resource: TaskNotFoundErr.Resource, ID: taskID}
+}
+
+func NewTagNotFoundErr(tagID string) NotFoundErr {
+	return NotFoundErr{Resource: TagNotFoundErr.Resource, ID: tagID}
+}
+
+func NewUserNotFoundErr(userID string) NotFoundErr {
+	return NotFoundErr{Resource: UserNotFoundErr.Resource, ID: userID}
+}
+
+func NewSessionNotFoundErr(sessionID string) NotFoundErr {
+	return NotFoundErr{Resource: SessionNotFoundErr.Resource, ID: sessionID}
+}
+
+func NewAPIKeyNotFoundErr(keyID string) NotFoundErr {
+	return NotFoundErr{Resource: APIKeyNotFoundErr.Resource, ID: keyID}
+}
+
+func NewMemberNotFoundErr(userID string) NotFoundErr {
+	return NotFoundErr{Resource: MemberNotFoundErr.Resource, ID: userID}
+}
+
+func NewInvitationNotFoundErr(invitationID string) NotFoundErr {
+	return NotFoundErr{Resource: InvitationNotFoundErr.Resource, ID: invitationID}
+}
+
+func NewIdempotencyNotFoundErr(key string) NotFoundErr {
+	return NotFoundErr{Resource: IdempotencyNotFoundErr.Resource, ID: key}
+}
+
+func NewWebhookNotFoundErr(webhookID string) NotFoundErr {
+	return NotFoundErr{Resource: WebhookNotFoundErr.Resource, ID: webhookID}
+}
+
+func NewDeliveryNotFoundErr(deliveryID string) NotFoundErr {
+	return NotFoundErr{Resource: DeliveryNotFoundErr.Resource, ID: deliveryID}
+}
+
+func NewEventNotFoundErr(eventID string) NotFoundErr {
+	return NotFoundErr{Resource: EventNotFoundErr.Resource, ID: eventID}
+}
+
+func (e NotFoundErr) Error() string {
+	if e.ID == "" {
+		return fmt.Sprintf("%s not found", e.Resource)
+	}
+
+	return fmt.Sprintf("%s '%s' not found", e.Resource, e.ID)
+}
+
+// Is reports a match when target is a NotFoundErr for the same resource.
+// A target without ID, like ListNotFoundErr, matches any ID.
+func (e NotFoundErr) Is(target error) bool {
+	t, ok := target.(NotFoundErr)
+	if !ok {
+		return false
+	}
+
+	return t.Resource == e.Resource && (t.ID == "" || t.ID == e.ID)
+}
+
+func (e NotFoundErr) Kind() errors.Kind {
+	return errors.NotFound
+}
+
+func NewListExistsErr(listID string) ExistsErr {
+	return ExistsErr{Resource: ListExistsErr.Resource, ID: listID}
+}
+
+func NewTaskExistsErr(taskID string) ExistsErr {
+	return ExistsErr{Resource: TaskExistsErr.Resource, ID: taskID}
+}
+
+func (e ExistsErr) Error() string {
+	return fmt.Sprintf("%s '%s' already exists", e.Resource, e.ID)
+}
+
+// Is reports a match when target is an ExistsErr for the same resource.
+// A target without ID, like ListExistsErr, matches any ID.
+func (e ExistsErr) Is(target error) bool {
+	t, ok := target.(ExistsErr)
+	if !ok {
+		return false
+	}
+
+	return t.Resource == e.Resource && (t.ID == "" || t.ID == e.ID)
+}
+
+func (e ExistsErr) Kind() errors.Kind {
+	return errors.Conflict
+}
+
+func NewUnauthenticatedErr(reason string) UnauthenticatedErr {
+	return UnauthenticatedErr{Reason: reason}
+}
+
+func (e UnauthenticatedErr) Error() string {
+	return fmt.Sprintf("unauthenticated: %s", e.Reason)
+}
+
+func (e UnauthenticatedErr) Kind() errors.Kind {
+	return errors.Unauthenticated
+}
+
+func NewForbiddenErr(reason string) ForbiddenErr {
+	return ForbiddenErr{Reason: reason}
+}
+
+func (e ForbiddenErr) Error() string {
+	return fmt.Sprintf("forbidden: %s", e.Reason)
+}
+
+func (e ForbiddenErr) Kind() errors.Kind {
+	return errors.Forbidden
+}
+
+func NewVersionErr(resource, id string, current int) VersionErr {
+	return VersionErr{Resource: resource, ID: id, Current: current}
+}
+
+func (e VersionErr) Error() string {
+	return fmt.Sprintf("%s '%s' was changed, current version is %d", e.Resource, e.ID, e.Current)
+}
+
+// Is reports a match when target is a VersionErr for the same resource.
+// A target without resource, like VersionMismatchErr, matches any of them.
+func (e VersionErr) Is(target error) bool {
+	t, ok := target.(VersionErr)
+	if !ok {
+		return false
+	}
+
+	return t.Resource == "" || (t.Resource == e.Resource && (t.ID == "" || t.ID == e.ID))
+}
+
+func (e VersionErr) Kind() errors.Kind {
+	return errors.Conflict
+}
+
+func NewTaskOpErr(index int, op string, err error) TaskOpErr {
+	return TaskOpErr{Index: index, Op: op, Err: err}
+}
+
+func (e TaskOpErr) Error() string {
+	return fmt.Sprintf("operation %d (%s): %s", e.Index, e.Op, e.Err)
+}
+
+func (e TaskOpErr) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,42 @@
+package port
+
+import (
+	"context"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+)
+
+type (
+	// EventHub delivers the changes made to lists to the subscribers of each list.
+	EventHub interface {
+		// Publish assigns the event its ID and delivers it to the subscribers of its list.
+		Publish(ctx context.Context, e model.Event)
+		// Subscribe to the events of a list. If lastEventID is not zero, the events of the list published after it
+		// are returned as missed. If some of them are no longer kept, a single ResetEvent with the ID of the last
+		// published event is returned instead.
+		Subscribe(listID, userID string, lastEventID uint64) (sub Subscription, missed []model.Event)
+		// Unsubscribe ends the subscriptions of the user to the list, as when the user is no longer a member of it.
+		Unsubscribe(listID, userID string)
+	}
+
+	// Subscription to the events of a list.
+	Subscription interface {
+		// Events published since subscribing, the channel is closed when the subscription ends.
+		// Subscribers that do not keep up are dropped, they can subscribe again from their last event.
+		Events() <-chan model.Event
+		// Close ends the subscription.
+		Close()
+	}
+
+	// EventBus delivers the domain events recorded in the outbox to in-process subscribers, at least once and in
+	// order for each list.
+	EventBus interface {
+		// Subscribe the handler to the domain events of the given types, or to all of them if none are given.
+		// The name identifies the subscriber in logs and records the events it handled, so it must not change.
+		Subscribe(name string, handler DomainEventHandler, types ...model.DomainEventType)
+	}
+
+	// DomainEventHandler handles a domain event. Events are delivered again when it fails, until the maximum number
+	// of attempts, as well as when the process stops before they are done, so handlers must be idempotent.
+	DomainEventHandler func(ctx context.Context, event model.DomainEvent) error
+)
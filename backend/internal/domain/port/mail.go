@@ -16,10 +16,11 @@ type (
 	// Email WIP
 	// Not final structure
 	Email struct {
-		From EmailAddress
-		To   []EmailAddress
-		CC   []EmailAddress
-		BC   []EmailAddress
-		Body []byte
+		From    EmailAddress
+		To      []EmailAddress
+		CC      []EmailAddress
+		BC      []EmailAddress
+		Subject string
+		Body    []byte
 	}
 )
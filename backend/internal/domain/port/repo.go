@@ -2,6 +2,7 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"github.com/vanillazen/stl/backend/internal/domain/model"
 	"github.com/vanillazen/stl/backend/internal/infra/db"
@@ -18,25 +19,151 @@ type (
 		Repo
 		// CreateList in persistence
 		CreateList(ctx context.Context, list model.List) (model.List, error)
+		// GetLists selected by the query from persistence, along with the total number of lists matching it
+		GetLists(ctx context.Context, userID string, q model.ListQuery) (lists []model.List, total int, err error)
 		// GetList from persistence
 		GetList(ctx context.Context, userID, listID string, preload ...bool) (list model.List, err error)
-		//// UpdateList in persistence
-		//UpdateList(ctx context.Context, task model.List, userID string) error
-		//// DeleteList in persistence
-		//DeleteList(ctx context.Context, listID, userID string) error
-		//
-		//// AddTask in persistence
-		//AddTask(ctx context.Context, list string, task model.Task, userID string) (model.Task, error)
-		//// AddTasks in persistence
-		//AddTasks(ctx context.Context, list string, task []model.Task, userID string) error
-		//// GetTask from persistence
-		//GetTask(ctx context.Context, taskID, userID string) (user model.Task, err error)
-		//// UpdateTask in persistence
-		//UpdateTask(ctx context.Context, task *model.Task, userID string) error
-		//// DeleteTask in persistence
-		//DeleteTask(ctx context.Context, taskID, userID string) error
-		//
+		// UpdateList in persistence, if the list version is not zero it must match the stored one
+		UpdateList(ctx context.Context, list model.List, userID string) (model.List, error)
+		// DeleteList in persistence, if version is not zero it must match the stored one
+		DeleteList(ctx context.Context, listID, userID string, version int) error
+
+		// AddTask in persistence
+		AddTask(ctx context.Context, listID string, task model.Task, userID string) (model.Task, error)
+		// AddTasks in persistence
+		AddTasks(ctx context.Context, listID string, tasks []model.Task, userID string) ([]model.Task, error)
+		// GetTasks selected by the query from persistence, along with the total number of tasks matching it
+		GetTasks(ctx context.Context, listID, userID string, q model.TaskQuery) (tasks []model.Task, total int, err error)
+		// GetTask from persistence
+		GetTask(ctx context.Context, listID, taskID, userID string) (task model.Task, err error)
+		// UpdateTask in persistence, if the task version is not zero it must match the stored one
+		UpdateTask(ctx context.Context, task model.Task, userID string) (model.Task, error)
+		// ToggleTask completion state in persistence, if version is not zero it must match the stored one
+		ToggleTask(ctx context.Context, listID, taskID, userID string, version int) (task model.Task, err error)
+		// DeleteTask in persistence, if version is not zero it must match the stored one
+		DeleteTask(ctx context.Context, listID, taskID, userID string, version int) error
+		// BatchTasks applies the operations to tasks of the list in a single transaction.
+		// If atomic, the first failed operation undoes the others and its TaskOpErr is returned,
+		// otherwise each operation succeeds or fails on its own and its result holds the error.
+		BatchTasks(ctx context.Context, listID string, ops []model.TaskOp, userID string, atomic bool) (results []model.TaskOpResult, err error)
+
+		// GetTags from persistence
+		GetTags(ctx context.Context, userID string) (tags []model.Tag, err error)
+		// GetTag from persistence
+		GetTag(ctx context.Context, tagID, userID string) (tag model.Tag, err error)
+		// AttachTag to a task in persistence, the tag is created if it does not exist yet
+		AttachTag(ctx context.Context, listID, taskID, name, userID string) (tag model.Tag, err error)
+		// DetachTag from a task in persistence
+		DetachTag(ctx context.Context, listID, taskID, tagID, userID string) error
+
+		// GetMember returns the membership of the user in the list, ListNotFoundErr if there is none
+		GetMember(ctx context.Context, listID, userID string) (member model.Member, err error)
+		// GetMembers of the list from persistence
+		GetMembers(ctx context.Context, listID string) (members []model.Member, err error)
+		// UpdateMember role in persistence as the user, a ForbiddenErr if it demotes the last owner of the list
+		UpdateMember(ctx context.Context, member model.Member, userID string) (model.Member, error)
+		// DeleteMember from persistence as the user, a ForbiddenErr if it is the last owner of the list
+		DeleteMember(ctx context.Context, listID, memberID, userID string) error
+
+		// CreateInvitation in persistence
+		CreateInvitation(ctx context.Context, invitation model.Invitation) (model.Invitation, error)
+		// GetInvitation from persistence
+		GetInvitation(ctx context.Context, invitationID string) (invitation model.Invitation, err error)
+		// GetInvitations sent to the email from persistence
+		GetInvitations(ctx context.Context, email string) (invitations []model.Invitation, err error)
+		// AcceptInvitation makes the user a member of the list with the invitation role and deletes it
+		AcceptInvitation(ctx context.Context, invitation model.Invitation, userID string) (model.Member, error)
+		// DeleteInvitation from persistence
+		DeleteInvitation(ctx context.Context, invitationID string) error
+
+		// Search the lists and tasks the user is a member of, best matches first, along with the total number of matches
+		Search(ctx context.Context, userID string, q model.SearchQuery) (results []model.SearchResult, total int, err error)
+
+		// GetChanges made to the lists the user is a member of after the sequence number, limit at most, oldest first
+		GetChanges(ctx context.Context, userID string, since uint64, limit int) (changes model.ChangeSet, err error)
+
+		// GetUser from persistence
+		GetUser(ctx context.Context, userID string) (user model.User, err error)
+	}
+
+	UserRepo interface {
+		Repo
+		// CreateUser in persistence
+		CreateUser(ctx context.Context, user model.User) (model.User, error)
 		// GetUser from persistence
 		GetUser(ctx context.Context, userID string) (user model.User, err error)
+		// GetUserByUsername from persistence
+		GetUserByUsername(ctx context.Context, username string) (user model.User, err error)
+		// GetUserByEmail from persistence
+		GetUserByEmail(ctx context.Context, email string) (user model.User, err error)
+
+		// CreateSession in persistence
+		CreateSession(ctx context.Context, session model.Session) (model.Session, error)
+		// GetSession from persistence
+		GetSession(ctx context.Context, sessionID string) (session model.Session, err error)
+		// RotateSession replaces the refresh ID of an active session if it still matches refreshID
+		RotateSession(ctx context.Context, sessionID, refreshID, newRefreshID string, expiresAt time.Time) error
+		// RevokeSession in persistence
+		RevokeSession(ctx context.Context, sessionID, userID string) error
+		// CreateAPIKey in persistence
+		CreateAPIKey(ctx context.Context, key model.APIKey) (model.APIKey, error)
+		// GetAPIKeys owned by the user from persistence
+		GetAPIKeys(ctx context.Context, userID string) (keys []model.APIKey, err error)
+		// GetAPIKeyByHash from persistence
+		GetAPIKeyByHash(ctx context.Context, hash string) (key model.APIKey, err error)
+		// RevokeAPIKey in persistence
+		RevokeAPIKey(ctx context.Context, keyID, userID string) error
+		// TouchAPIKey records the key was used at the given time
+		TouchAPIKey(ctx context.Context, keyID string, usedAt time.Time) error
+	}
+
+	IdempotencyRepo interface {
+		Repo
+		// CreateIdempotencyRecord in persistence, IdempotencyKeyUsedErr if an unexpired one has the same key
+		CreateIdempotencyRecord(ctx context.Context, record model.IdempotencyRecord) error
+		// GetIdempotencyRecord from persistence
+		GetIdempotencyRecord(ctx context.Context, userID, method, path, key string) (record model.IdempotencyRecord, err error)
+		// CompleteIdempotencyRecord stores the response of the request in persistence
+		CompleteIdempotencyRecord(ctx context.Context, record model.IdempotencyRecord) error
+		// DeleteIdempotencyRecord from persistence, the key can be used again
+		DeleteIdempotencyRecord(ctx context.Context, record model.IdempotencyRecord) error
+		// DeleteExpiredIdempotencyRecords from persistence, returning how many were deleted
+		DeleteExpiredIdempotencyRecords(ctx context.Context, now time.Time) (int64, error)
+	}
+
+	WebhookRepo interface {
+		Repo
+		// CreateWebhook in persistence
+		CreateWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error)
+		// GetWebhooks of the user from persistence
+		GetWebhooks(ctx context.Context, userID string) (webhooks []model.Webhook, err error)
+		// GetWebhook of the user from persistence
+		GetWebhook(ctx context.Context, webhookID, userID string) (webhook model.Webhook, err error)
+		// DeleteWebhook of the user from persistence, along with its deliveries
+		DeleteWebhook(ctx context.Context, webhookID, userID string) error
+
+		// EnqueueDeliveries of the payload of an event of the list to the webhooks subscribed to its type,
+		// returning how many were enqueued
+		EnqueueDeliveries(ctx context.Context, listID string, typ model.EventType, payload []byte) (int, error)
+		// GetDeliveries of a webhook of the user from persistence, latest first, limit at most
+		GetDeliveries(ctx context.Context, webhookID, userID string, limit int) (deliveries []model.WebhookDelivery, err error)
+		// Redeliver enqueues a new delivery with the event of a delivery of a webhook of the user
+		Redeliver(ctx context.Context, deliveryID, webhookID, userID string) (delivery model.WebhookDelivery, err error)
+		// ClaimDeliveries returns the pending deliveries due at the given time, limit at most, along with their
+		// webhooks. They are not due again until the lease ends, unless they are updated before
+		ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) (deliveries []model.WebhookDelivery, err error)
+		// UpdateDelivery records an attempt of a delivery in persistence
+		UpdateDelivery(ctx context.Context, delivery model.WebhookDelivery) error
+	}
+
+	OutboxRepo interface {
+		Repo
+		// GetPendingEvents returns the domain events neither done nor failed that are due at the given time, in the
+		// order they were recorded, limit at most. Events of a list waiting for an earlier one not due yet are left out.
+		GetPendingEvents(ctx context.Context, now time.Time, limit int) (events []model.DomainEvent, err error)
+		// UpdateEvent records a delivery of a domain event in persistence, along with the subscribers that handled it
+		UpdateEvent(ctx context.Context, event model.DomainEvent) error
+		// DeleteDoneEvents from persistence done before the given time, returning how many were deleted
+		DeleteDoneEvents(ctx context.Context, before time.Time) (int64, error)
 	}
 )
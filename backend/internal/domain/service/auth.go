@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/password"
+	"github.com/vanillazen/stl/backend/internal/sys/token"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+	t "github.com/vanillazen/stl/backend/internal/transport"
+)
+
+const (
+	defaultAccessTTL  = 15 * time.Minute
+	defaultRefreshTTL = 30 * 24 * time.Hour
+	tokenType         = "Bearer"
+)
+
+type (
+	AuthService interface {
+		sys.Core
+		Login(ctx context.Context, req t.LoginReq) t.LoginRes
+		RefreshToken(ctx context.Context, req t.RefreshTokenReq) t.RefreshTokenRes
+		Logout(ctx context.Context, req t.LogoutReq) t.LogoutRes
+		Authenticate(ctx context.Context, req t.AuthenticateReq) t.AuthenticateRes
+		CheckAuth(ctx context.Context, req t.CheckAuthReq) t.CheckAuthRes
+		CreateAPIKey(ctx context.Context, req t.CreateAPIKeyReq) t.CreateAPIKeyRes
+		GetAPIKeys(ctx context.Context, req t.GetAPIKeysReq) t.GetAPIKeysRes
+		RevokeAPIKey(ctx context.Context, req t.RevokeAPIKeyReq) t.RevokeAPIKeyRes
+	}
+
+	Auth struct {
+		*sys.SimpleCore
+		repo       port.UserRepo
+		key        []byte
+		accessTTL  time.Duration
+		refreshTTL time.Duration
+	}
+)
+
+var (
+	// dummyHash is verified against when a login names an unknown user
+	// so that it takes as long as one with a wrong password.
+	dummyHash     string
+	dummyHashOnce sync.Once
+)
+
+func NewAuthService(rr port.UserRepo, opts ...sys.Option) *Auth {
+	svc := &Auth{
+		SimpleCore: sys.NewCore("auth-service", opts...),
+		repo:       rr,
+		accessTTL:  defaultAccessTTL,
+		refreshTTL: defaultRefreshTTL,
+	}
+
+	svc.configure(svc.Cfg())
+
+	return svc
+}
+
+func (as *Auth) configure(cfg *config.Config) {
+	if cfg != nil {
+		as.key = []byte(cfg.GetString(config.Key.AuthTokenKey))
+
+		if secs := cfg.GetInt(config.Key.AuthAccessTTL); secs > 0 {
+			as.accessTTL = time.Duration(secs) * time.Second
+		}
+
+		if secs := cfg.GetInt(config.Key.AuthRefreshTTL); secs > 0 {
+			as.refreshTTL = time.Duration(secs) * time.Second
+		}
+	}
+
+	if len(as.key) == 0 {
+		// Tokens signed with a random key become invalid when the process restarts.
+		as.Log().Errorf("%s: no token signing key configured, using a random one", as.Name())
+		as.key = make([]byte, 32)
+		_, _ = rand.Read(as.key)
+	}
+}
+
+func (as *Auth) Login(ctx context.Context, req t.LoginReq) (res t.LoginRes) {
+	identity := req.Identity()
+
+	user, err := as.Repo().GetUserByUsername(ctx, identity)
+	if errors.Is(err, port.UserNotFoundErr) {
+		user, err = as.Repo().GetUserByEmail(ctx, identity)
+	}
+
+	if errors.Is(err, port.UserNotFoundErr) {
+		dummyHashOnce.Do(func() { dummyHash, _ = password.Hash("") })
+		_, _ = password.Verify(dummyHash, req.Password)
+
+		err = port.NewUnauthenticatedErr("invalid credentials")
+		return t.NewLoginRes(nil, errors.Wrap(err, "login error"), as.Cfg())
+	}
+	if err != nil {
+		return t.NewLoginRes(nil, errors.Wrap(err, "login error"), as.Cfg())
+	}
+
+	ok, err := password.Verify(user.Password, req.Password)
+	if err != nil || !ok {
+		err = port.NewUnauthenticatedErr("invalid credentials")
+		return t.NewLoginRes(nil, errors.Wrap(err, "login error"), as.Cfg())
+	}
+
+	now := time.Now().UTC()
+	session := model.Session{
+		UserID:    user.ID,
+		RefreshID: uuid.NewUUID().Val,
+		ExpiresAt: now.Add(as.refreshTTL),
+	}
+
+	session, err = as.Repo().CreateSession(ctx, session)
+	if err != nil {
+		return t.NewLoginRes(nil, errors.Wrap(err, "login error"), as.Cfg())
+	}
+
+	tokens, err := as.issueTokens(session, now)
+	if err != nil {
+		return t.NewLoginRes(nil, errors.Wrap(err, "login error"), as.Cfg())
+	}
+
+	res = t.NewLoginRes(nil, nil, as.Cfg())
+	res.FromTokens(tokens)
+
+	return res
+}
+
+// RefreshToken exchanges a refresh token for a new pair of tokens.
+// Each refresh token can only be used once, presenting an already used one revokes its session.
+func (as *Auth) RefreshToken(ctx context.Context, req t.RefreshTokenReq) (res t.RefreshTokenRes) {
+	now := time.Now().UTC()
+
+	claims, err := token.Parse(req.RefreshToken, token.RefreshType, as.key, now)
+	if err != nil {
+		err = port.NewUnauthenticatedErr(tokenErrReason(err))
+		return t.NewRefreshTokenRes(nil, errors.Wrap(err, "refresh token error"), as.Cfg())
+	}
+
+	session, err := as.Repo().GetSession(ctx, claims.SessionID)
+	if err != nil || !session.Active(now) || session.UserID.String() != claims.Subject {
+		err = port.NewUnauthenticatedErr("session expired or revoked")
+		return t.NewRefreshTokenRes(nil, errors.Wrap(err, "refresh token error"), as.Cfg())
+	}
+
+	if session.RefreshID != claims.ID {
+		// A reused refresh token may have been stolen.
+		_ = as.Repo().RevokeSession(ctx, session.ID.String(), claims.Subject)
+
+		err = port.NewUnauthenticatedErr("refresh token already used")
+		return t.NewRefreshTokenRes(nil, errors.Wrap(err, "refresh token error"), as.Cfg())
+	}
+
+	refreshID := uuid.NewUUID().Val
+	expiresAt := now.Add(as.refreshTTL)
+
+	err = as.Repo().RotateSession(ctx, session.ID.String(), claims.ID, refreshID, expiresAt)
+	if errors.Is(err, port.SessionNotFoundErr) {
+		err = port.NewUnauthenticatedErr("refresh token already used")
+		return t.NewRefreshTokenRes(nil, errors.Wrap(err, "refresh token error"), as.Cfg())
+	}
+	if err != nil {
+		return t.NewRefreshTokenRes(nil, errors.Wrap(err, "refresh token error"), as.Cfg())
+	}
+
+	session.RefreshID = refreshID
+	session.ExpiresAt = expiresAt
+
+	tokens, err := as.issueTokens(session, now)
+	if err != nil {
+		return t.NewRefreshTokenRes(nil, errors.Wrap(err, "refresh token error"), as.Cfg())
+	}
+
+	res = t.NewRefreshTokenRes(nil, nil, as.Cfg())
+	res.FromTokens(tokens)
+
+	return res
+}
+
+// Logout revokes the session, its access and refresh tokens are rejected from then on.
+func (as *Auth) Logout(ctx context.Context, req t.LogoutReq) (res t.LogoutRes) {
+	err := as.Repo().RevokeSession(ctx, req.SessionID, req.UserID)
+	if err != nil {
+		return t.NewLogoutRes(nil, errors.Wrap(err, "logout error"), as.Cfg())
+	}
+
+	return t.NewLogoutRes(nil, nil, as.Cfg())
+}
+
+// Authenticate verifies an access token or an API key and returns the user and session or key it was issued for.
+func (as *Auth) Authenticate(ctx context.Context, req t.AuthenticateReq) (res t.AuthenticateRes) {
+	if req.APIKey != "" {
+		return as.authenticateAPIKey(ctx, req.APIKey)
+	}
+
+	now := time.Now().UTC()
+
+	claims, err := token.Parse(req.AccessToken, token.AccessType, as.key, now)
+	if err != nil {
+		err = port.NewUnauthenticatedErr(tokenErrReason(err))
+		return t.NewAuthenticateRes(nil, errors.Wrap(err, "authenticate error"), as.Cfg())
+	}
+
+	session, err := as.Repo().GetSession(ctx, claims.SessionID)
+	if err != nil || !session.Active(now) || session.UserID.String() != claims.Subject {
+		err = port.NewUnauthenticatedErr("session expired or revoked")
+		return t.NewAuthenticateRes(nil, errors.Wrap(err, "authenticate error"), as.Cfg())
+	}
+
+	res = t.NewAuthenticateRes(nil, nil, as.Cfg())
+	res.UserID = claims.Subject
+	res.SessionID = claims.SessionID
+
+	return res
+}
+
+func (as *Auth) authenticateAPIKey(ctx context.Context, apiKey string) (res t.AuthenticateRes) {
+	if !token.IsAPIKey(apiKey) {
+		err := port.NewUnauthenticatedErr("invalid api key")
+		return t.NewAuthenticateRes(nil, errors.Wrap(err, "authenticate error"), as.Cfg())
+	}
+
+	key, err := as.Repo().GetAPIKeyByHash(ctx, token.HashAPIKey(apiKey))
+	if errors.Is(err, port.APIKeyNotFoundErr) || (err == nil && !key.Active()) {
+		err = port.NewUnauthenticatedErr("invalid or revoked api key")
+		return t.NewAuthenticateRes(nil, errors.Wrap(err, "authenticate error"), as.Cfg())
+	}
+	if err != nil {
+		return t.NewAuthenticateRes(nil, errors.Wrap(err, "authenticate error"), as.Cfg())
+	}
+
+	// Failing to record the use of a key must not reject the request
+	err = as.Repo().TouchAPIKey(ctx, key.ID.String(), time.Now().UTC())
+	if err != nil {
+		as.Log().Error(errors.Wrap(err, "authenticate error"))
+	}
+
+	res = t.NewAuthenticateRes(nil, nil, as.Cfg())
+	res.UserID = key.UserID.String()
+	res.APIKeyID = key.ID.String()
+	res.Scope = key.Scope
+
+	return res
+}
+
+// CheckAuth verifies that the session or API key the caller authenticated with is still active.
+// Long lived connections call it from time to time, as they outlive the check made when they were opened.
+// Failing to read them is returned as is, so that callers can tell it apart from an inactive credential.
+func (as *Auth) CheckAuth(ctx context.Context, req t.CheckAuthReq) (res t.CheckAuthRes) {
+	var active bool
+	var err error
+	reason := "session expired or revoked"
+
+	switch {
+	case req.SessionID != "":
+		var session model.Session
+		session, err = as.Repo().GetSession(ctx, req.SessionID)
+		active = err == nil && session.Active(time.Now().UTC()) && session.UserID.String() == req.UserID
+
+	case req.APIKeyID != "":
+		var keys []model.APIKey
+		keys, err = as.Repo().GetAPIKeys(ctx, req.UserID)
+		reason = "api key revoked"
+		for _, key := range keys {
+			if key.ID.String() == req.APIKeyID {
+				active = key.Active()
+			}
+		}
+	}
+
+	if err != nil && !errors.Is(err, port.SessionNotFoundErr) {
+		return t.NewCheckAuthRes(nil, errors.Wrap(err, "check auth error"), as.Cfg())
+	}
+
+	if !active {
+		err = port.NewUnauthenticatedErr(reason)
+		return t.NewCheckAuthRes(nil, errors.Wrap(err, "check auth error"), as.Cfg())
+	}
+
+	return t.NewCheckAuthRes(nil, nil, as.Cfg())
+}
+
+// CreateAPIKey issues a new API key for the user.
+// The key is only returned here, just its hash is stored.
+func (as *Auth) CreateAPIKey(ctx context.Context, req t.CreateAPIKeyReq) (res t.CreateAPIKeyRes) {
+	// Transport to Model
+	key := req.ToAPIKey()
+
+	// Validate model
+	v := NewAPIKeyValidator(key)
+
+	err := v.ValidateForCreate()
+	if err != nil {
+		return t.NewCreateAPIKeyRes(v.Errors, err, as.Cfg())
+	}
+
+	apiKey, prefix, err := token.NewAPIKey()
+	if err != nil {
+		return t.NewCreateAPIKeyRes(nil, errors.Wrap(err, "create api key error"), as.Cfg())
+	}
+
+	key.Prefix = prefix
+	key.Hash = token.HashAPIKey(apiKey)
+
+	// Persist it
+	key, err = as.Repo().CreateAPIKey(ctx, key)
+	if err != nil {
+		return t.NewCreateAPIKeyRes(nil, errors.Wrap(err, "create api key error"), as.Cfg())
+	}
+
+	res = t.NewCreateAPIKeyRes(nil, nil, as.Cfg())
+	res.FromAPIKey(key, apiKey)
+
+	return res
+}
+
+func (as *Auth) GetAPIKeys(ctx context.Context, req t.GetAPIKeysReq) (res t.GetAPIKeysRes) {
+	keys, err := as.Repo().GetAPIKeys(ctx, req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "get api keys error")
+		return t.NewGetAPIKeysRes(nil, err, as.Cfg(), keys)
+	}
+
+	return t.NewGetAPIKeysRes(nil, nil, as.Cfg(), keys)
+}
+
+func (as *Auth) RevokeAPIKey(ctx context.Context, req t.RevokeAPIKeyReq) (res t.RevokeAPIKeyRes) {
+	err := as.Repo().RevokeAPIKey(ctx, req.APIKeyID, req.UserID)
+	if err != nil {
+		return t.NewRevokeAPIKeyRes(nil, errors.Wrap(err, "revoke api key error"), as.Cfg())
+	}
+
+	return t.NewRevokeAPIKeyRes(nil, nil, as.Cfg())
+}
+
+func (as *Auth) issueTokens(session model.Session, now time.Time) (tokens t.Tokens, err error) {
+	userID := session.UserID.String()
+	sessionID := session.ID.String()
+
+	accessExpiresAt := now.Add(as.accessTTL)
+	if accessExpiresAt.After(session.ExpiresAt) {
+		accessExpiresAt = session.ExpiresAt
+	}
+
+	access := token.NewClaims(token.AccessType, userID, sessionID, "", now, accessExpiresAt.Sub(now))
+	tokens.AccessToken, err = token.Sign(access, as.key)
+	if err != nil {
+		return tokens, err
+	}
+
+	refresh := token.NewClaims(token.RefreshType, userID, sessionID, session.RefreshID, now, session.ExpiresAt.Sub(now))
+	tokens.RefreshToken, err = token.Sign(refresh, as.key)
+	if err != nil {
+		return tokens, err
+	}
+
+	tokens.TokenType = tokenType
+	tokens.ExpiresAt = accessExpiresAt
+	tokens.RefreshExpiresAt = session.ExpiresAt
+
+	return tokens, nil
+}
+
+func tokenErrReason(err error) string {
+	if errors.Is(err, token.ExpiredTokenErr) {
+		return "expired token"
+	}
+
+	return "invalid token"
+}
+
+func (as *Auth) Repo() port.UserRepo {
+	return as.repo
+}
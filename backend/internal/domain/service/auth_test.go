@@ -0,0 +1,103 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/service"
+	repo "github.com/vanillazen/stl/backend/internal/infra/repo/sqlite"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+	t "github.com/vanillazen/stl/backend/internal/transport"
+)
+
+func TestCheckAuth(tt *testing.T) {
+	db, opts := newTestDB(tt)
+	ctx := context.Background()
+	r := repo.NewUserRepo(db, opts...)
+	auth := service.NewAuthService(r, opts...)
+
+	user1 := model.NewID(uuid.MustParse(user1ID))
+	session, err := r.CreateSession(ctx, model.Session{UserID: user1, RefreshID: "r1", ExpiresAt: time.Now().UTC().Add(time.Hour)})
+	if err != nil {
+		tt.Fatalf("Test setup error: %s", err)
+	}
+
+	expired, err := r.CreateSession(ctx, model.Session{UserID: user1, RefreshID: "r2", ExpiresAt: time.Now().UTC().Add(-time.Hour)})
+	if err != nil {
+		tt.Fatalf("Test setup error: %s", err)
+	}
+
+	key, err := r.CreateAPIKey(ctx, model.APIKey{UserID: user1, Name: "Key", Prefix: "stl_test", Hash: "hash", Scope: "read"})
+	if err != nil {
+		tt.Fatalf("Test setup error: %s", err)
+	}
+
+	tests := []struct {
+		name        string
+		req         t.CheckAuthReq
+		revoke      bool
+		expectedErr error
+	}{
+		{
+			name: "Active session",
+			req:  t.CheckAuthReq{UserID: user1ID, SessionID: session.ID.String()},
+		},
+		{
+			name:        "Session of another user",
+			req:         t.CheckAuthReq{UserID: user2ID, SessionID: session.ID.String()},
+			expectedErr: errors.Unauthenticated,
+		},
+		{
+			name:        "Expired session",
+			req:         t.CheckAuthReq{UserID: user1ID, SessionID: expired.ID.String()},
+			expectedErr: errors.Unauthenticated,
+		},
+		{
+			name:        "Unknown session",
+			req:         t.CheckAuthReq{UserID: user1ID, SessionID: noneID},
+			expectedErr: errors.Unauthenticated,
+		},
+		{
+			name: "Active api key",
+			req:  t.CheckAuthReq{UserID: user1ID, APIKeyID: key.ID.String()},
+		},
+		{
+			name:        "Api key of another user",
+			req:         t.CheckAuthReq{UserID: user2ID, APIKeyID: key.ID.String()},
+			expectedErr: errors.Unauthenticated,
+		},
+		{
+			name:        "Revoked session",
+			req:         t.CheckAuthReq{UserID: user1ID, SessionID: session.ID.String()},
+			revoke:      true,
+			expectedErr: errors.Unauthenticated,
+		},
+		{
+			name:        "Revoked api key",
+			req:         t.CheckAuthReq{UserID: user1ID, APIKeyID: key.ID.String()},
+			revoke:      true,
+			expectedErr: errors.Unauthenticated,
+		},
+		{
+			name:        "No credential",
+			req:         t.CheckAuthReq{UserID: user1ID},
+			expectedErr: errors.Unauthenticated,
+		},
+	}
+
+	for _, test := range tests {
+		tt.Run(test.name, func(tt *testing.T) {
+			if test.revoke {
+				_ = auth.Logout(ctx, t.LogoutReq{UserID: user1ID, SessionID: session.ID.String()})
+				_ = auth.RevokeAPIKey(ctx, t.RevokeAPIKeyReq{UserID: user1ID, APIKeyID: key.ID.String()})
+			}
+
+			res := auth.CheckAuth(ctx, test.req)
+
+			checkKind(tt, res.Err(), test.expectedErr)
+		})
+	}
+}
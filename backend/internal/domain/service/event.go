@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	t "github.com/vanillazen/stl/backend/internal/transport"
+)
+
+// SubscribeEvents subscribes the user to the changes of a list, resuming after the last event the client got if any.
+func (rs *List) SubscribeEvents(ctx context.Context, req t.SubscribeEventsReq) (res t.SubscribeEventsRes) {
+	_, err := rs.Policy().Authorize(ctx, ListRead, req.UserID, Target{ListID: req.ListID})
+	if err != nil {
+		err = errors.Wrap(err, "subscribe events error")
+		return t.NewSubscribeEventsRes(nil, err, rs.Cfg())
+	}
+
+	if rs.events == nil {
+		err = errors.New("subscribe events error: no event hub")
+		return t.NewSubscribeEventsRes(nil, err, rs.Cfg())
+	}
+
+	sub, missed := rs.events.Subscribe(req.ListID, req.UserID, req.LastEventID)
+
+	res = t.NewSubscribeEventsRes(nil, nil, rs.Cfg())
+	res.FromSubscription(sub.Events(), sub.Close, missed)
+
+	return res
+}
+
+// PublishEvent is the event bus subscriber that publishes the changes of lists and tasks recorded in the outbox to
+// the subscribers of their lists, as events of the hub. Nothing is published without an event hub.
+func (rs *List) PublishEvent(ctx context.Context, e model.DomainEvent) error {
+	if rs.events == nil {
+		return nil
+	}
+
+	events, err := rs.listEvents(ctx, e)
+	if err != nil {
+		return errors.Wrap(err, "publish event error")
+	}
+
+	for _, ev := range events {
+		rs.events.Publish(ctx, ev)
+	}
+
+	return nil
+}
+
+// EnqueueDeliveries is the event bus subscriber that queues the changes of lists and tasks recorded in the outbox
+// for the webhooks subscribed to them, moves for those of both lists. Failing to queue them fails the event, so
+// that the bus tries again. Nothing is queued without a webhook repo.
+func (rs *List) EnqueueDeliveries(ctx context.Context, e model.DomainEvent) error {
+	if rs.webhooks == nil {
+		return nil
+	}
+
+	events, err := rs.listEvents(ctx, e)
+	if err != nil {
+		return errors.Wrap(err, "enqueue deliveries error")
+	}
+
+	for _, ev := range events {
+		if !ev.Type.Webhook() {
+			continue
+		}
+
+		payload, err := json.Marshal(t.NewWebhookEvent(ev))
+		if err != nil {
+			return errors.Wrap(err, "enqueue deliveries error")
+		}
+
+		_, err = rs.webhooks.EnqueueDeliveries(ctx, ev.ListID, ev.Type, payload)
+		if err != nil {
+			return errors.Wrap(err, "enqueue deliveries error")
+		}
+	}
+
+	return nil
+}
+
+// listEvents returns the events the domain event is published as, none for those neither list subscribers nor
+// webhooks get. Moves are published to both lists. The list or task is read as it is now with the access of the
+// user that made the change, events of resources removed since or changed by an unknown user only have their IDs.
+// It is not a snapshot: an event delivered after later changes carries the resource as they left it, so that a
+// task.completed event may have a task that is no longer done. The type and time tell what happened and when.
+func (rs *List) listEvents(ctx context.Context, e model.DomainEvent) (events []model.Event, err error) {
+	var ev model.Event
+
+	switch e.Type {
+	case model.ListUpdated:
+		list, err := rs.currentList(ctx, e)
+		if err != nil {
+			return nil, err
+		}
+
+		ev = model.NewListEvent(model.ListUpdatedEvent, e.ListID, list, e.UserID)
+
+	case model.ListDeleted:
+		ev = model.NewListEvent(model.ListDeletedEvent, e.ListID, nil, e.UserID)
+
+	case model.TaskCreated, model.TaskUpdated, model.TaskCompleted, model.TaskMoved:
+		task, err := rs.currentTask(ctx, e)
+		if err != nil {
+			return nil, err
+		}
+
+		ev = model.NewTaskEvent(taskEventTypes[e.Type], e.ListID, e.ItemID, task, e.UserID)
+
+	case model.TaskDeleted:
+		ev = model.NewTaskEvent(model.TaskDeletedEvent, e.ListID, e.ItemID, nil, e.UserID)
+
+	default:
+		return nil, nil
+	}
+
+	ev.At = e.CreatedAt
+	events = append(events, ev)
+
+	if e.Type == model.TaskMoved {
+		var moved struct {
+			FromListID string `json:"from_list_id"`
+		}
+
+		err = json.Unmarshal(e.Payload, &moved)
+		if err != nil {
+			return nil, err
+		}
+
+		ev.ListID = moved.FromListID
+		events = append(events, ev)
+	}
+
+	return events, nil
+}
+
+// taskEventTypes are the event types of the domain events of tasks that have the task.
+var taskEventTypes = map[model.DomainEventType]model.EventType{
+	model.TaskCreated:   model.TaskCreatedEvent,
+	model.TaskUpdated:   model.TaskUpdatedEvent,
+	model.TaskCompleted: model.TaskCompletedEvent,
+	model.TaskMoved:     model.TaskMovedEvent,
+}
+
+// currentList returns the list of the event, nil if it was removed or the user is unknown or no longer a member.
+func (rs *List) currentList(ctx context.Context, e model.DomainEvent) (*model.List, error) {
+	if e.UserID == "" {
+		return nil, nil
+	}
+
+	list, err := rs.Repo().GetList(ctx, e.UserID, e.ListID)
+	if errors.Is(err, port.ListNotFoundErr) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+// currentTask returns the task of the event, nil if it was removed or the user is unknown or no longer a member.
+func (rs *List) currentTask(ctx context.Context, e model.DomainEvent) (*model.Task, error) {
+	if e.UserID == "" {
+		return nil, nil
+	}
+
+	task, err := rs.Repo().GetTask(ctx, e.ListID, e.ItemID, e.UserID)
+	if errors.Is(err, port.TaskNotFoundErr) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	t "github.com/vanillazen/stl/backend/internal/transport"
+)
+
+const (
+	invitationTTL = 7 * 24 * time.Hour
+)
+
+func (rs *List) GetMembers(ctx context.Context, req t.GetMembersReq) (res t.GetMembersRes) {
+	_, err := rs.Policy().Authorize(ctx, MemberRead, req.UserID, Target{ListID: req.ListID})
+	if err != nil {
+		err = errors.Wrap(err, "get members error")
+		return t.NewGetMembersRes(nil, err, rs.Cfg(), nil)
+	}
+
+	members, err := rs.Repo().GetMembers(ctx, req.ListID)
+	if err != nil {
+		err = errors.Wrap(err, "get members error")
+		return t.NewGetMembersRes(nil, err, rs.Cfg(), nil)
+	}
+
+	return t.NewGetMembersRes(nil, nil, rs.Cfg(), members)
+}
+
+// InviteMember creates an invitation to the list and emails it.
+// The invitation is accepted by the user registered with that email, now or later on.
+func (rs *List) InviteMember(ctx context.Context, req t.InviteMemberReq) (res t.InviteMemberRes) {
+	// Transport to Model
+	invitation := req.ToInvitation()
+
+	// Validate model
+	v := NewInvitationValidator(invitation)
+
+	err := v.ValidateForCreate()
+	if err != nil {
+		return t.NewInviteMemberRes(v.Errors, err, rs.Cfg())
+	}
+
+	_, err = rs.Policy().Authorize(ctx, ListShare, req.UserID, Target{ListID: req.ListID})
+	if err != nil {
+		err = errors.Wrap(err, "invite member error")
+		return t.NewInviteMemberRes(nil, err, rs.Cfg())
+	}
+
+	list, err := rs.Repo().GetList(ctx, req.UserID, req.ListID)
+	if err != nil {
+		err = errors.Wrap(err, "invite member error")
+		return t.NewInviteMemberRes(nil, err, rs.Cfg())
+	}
+
+	inviter, err := rs.Repo().GetUser(ctx, req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "invite member error")
+		return t.NewInviteMemberRes(nil, err, rs.Cfg())
+	}
+
+	invitation.ListName = list.Name
+	invitation.ExpiresAt = time.Now().UTC().Add(invitationTTL)
+
+	// Persist it
+	invitation, err = rs.Repo().CreateInvitation(ctx, invitation)
+	if err != nil {
+		err = errors.Wrap(err, "invite member error")
+		return t.NewInviteMemberRes(nil, err, rs.Cfg())
+	}
+
+	// Invitees can still find it among their invitations if the email is not delivered
+	err = rs.sendInvitation(ctx, invitation, inviter)
+	if err != nil {
+		rs.Log().Error(errors.Wrap(err, "invite member error"))
+	}
+
+	res = t.NewInviteMemberRes(nil, nil, rs.Cfg())
+	res.FromInvitation(invitation)
+
+	return res
+}
+
+func (rs *List) UpdateMember(ctx context.Context, req t.UpdateMemberReq) (res t.UpdateMemberRes) {
+	// Transport to Model
+	member := req.ToMember()
+
+	// Validate model
+	v := NewMemberValidator(member)
+
+	err := v.ValidateForUpdate()
+	if err != nil {
+		return t.NewUpdateMemberRes(v.Errors, err, rs.Cfg())
+	}
+
+	_, err = rs.Policy().Authorize(ctx, ListShare, req.UserID, Target{ListID: req.ListID})
+	if err != nil {
+		err = errors.Wrap(err, "update member error")
+		return t.NewUpdateMemberRes(nil, err, rs.Cfg())
+	}
+
+	// Persist it, the last owner cannot be demoted
+	member, err = rs.Repo().UpdateMember(ctx, member, req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "update member error")
+		return t.NewUpdateMemberRes(nil, err, rs.Cfg())
+	}
+
+	res = t.NewUpdateMemberRes(nil, nil, rs.Cfg())
+	res.FromMember(member)
+
+	return res
+}
+
+// RemoveMember removes a member from the list.
+// Owners can remove anyone and any member can remove itself, as long as the list keeps an owner.
+func (rs *List) RemoveMember(ctx context.Context, req t.RemoveMemberReq) (res t.RemoveMemberRes) {
+	action := ListShare
+	if req.MemberID == req.UserID {
+		action = MemberLeave
+	}
+
+	_, err := rs.Policy().Authorize(ctx, action, req.UserID, Target{ListID: req.ListID})
+	if err != nil {
+		err = errors.Wrap(err, "remove member error")
+		return t.NewRemoveMemberRes(nil, err, rs.Cfg())
+	}
+
+	// The last owner cannot be removed
+	err = rs.Repo().DeleteMember(ctx, req.ListID, req.MemberID, req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "remove member error")
+		return t.NewRemoveMemberRes(nil, err, rs.Cfg())
+	}
+
+	// Subscriptions were authorized while a member, they end with the membership
+	if rs.events != nil {
+		rs.events.Unsubscribe(req.ListID, req.MemberID)
+	}
+
+	return t.NewRemoveMemberRes(nil, nil, rs.Cfg())
+}
+
+// GetInvitations returns the invitations sent to the email of the user.
+func (rs *List) GetInvitations(ctx context.Context, req t.GetInvitationsReq) (res t.GetInvitationsRes) {
+	_, err := rs.Policy().Authorize(ctx, InvitationRead, req.UserID, Target{})
+	if err != nil {
+		err = errors.Wrap(err, "get invitations error")
+		return t.NewGetInvitationsRes(nil, err, rs.Cfg(), nil)
+	}
+
+	user, err := rs.Repo().GetUser(ctx, req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "get invitations error")
+		return t.NewGetInvitationsRes(nil, err, rs.Cfg(), nil)
+	}
+
+	invitations, err := rs.Repo().GetInvitations(ctx, user.Email)
+	if err != nil {
+		err = errors.Wrap(err, "get invitations error")
+		return t.NewGetInvitationsRes(nil, err, rs.Cfg(), nil)
+	}
+
+	return t.NewGetInvitationsRes(nil, nil, rs.Cfg(), invitations)
+}
+
+// AcceptInvitation makes the user a member of the list, only the user registered with the invited email can do it.
+func (rs *List) AcceptInvitation(ctx context.Context, req t.AcceptInvitationReq) (res t.AcceptInvitationRes) {
+	_, err := rs.Policy().Authorize(ctx, InvitationAccept, req.UserID, Target{})
+	if err != nil {
+		err = errors.Wrap(err, "accept invitation error")
+		return t.NewAcceptInvitationRes(nil, err, rs.Cfg())
+	}
+
+	invitation, err := rs.invitationFor(ctx, req.InvitationID, req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "accept invitation error")
+		return t.NewAcceptInvitationRes(nil, err, rs.Cfg())
+	}
+
+	if invitation.Expired(time.Now().UTC()) {
+		err = port.NewForbiddenErr("invitation expired")
+		err = errors.Wrap(err, "accept invitation error")
+		return t.NewAcceptInvitationRes(nil, err, rs.Cfg())
+	}
+
+	member, err := rs.Repo().AcceptInvitation(ctx, invitation, req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "accept invitation error")
+		return t.NewAcceptInvitationRes(nil, err, rs.Cfg())
+	}
+
+	res = t.NewAcceptInvitationRes(nil, nil, rs.Cfg())
+	res.FromMember(member)
+
+	return res
+}
+
+// DeclineInvitation deletes an invitation, it can be done by its recipient or by an owner of the list.
+func (rs *List) DeclineInvitation(ctx context.Context, req t.DeclineInvitationReq) (res t.DeclineInvitationRes) {
+	_, err := rs.invitationFor(ctx, req.InvitationID, req.UserID)
+	if errors.Is(err, port.InvitationNotFoundErr) {
+		var invitation model.Invitation
+
+		invitation, err = rs.Repo().GetInvitation(ctx, req.InvitationID)
+		if err == nil {
+			_, err = rs.Policy().Authorize(ctx, ListShare, req.UserID, Target{ListID: invitation.ListID.String()})
+		}
+		if errors.Is(err, port.ListNotFoundErr) {
+			err = port.NewInvitationNotFoundErr(req.InvitationID)
+		}
+	}
+	if err != nil {
+		err = errors.Wrap(err, "decline invitation error")
+		return t.NewDeclineInvitationRes(nil, err, rs.Cfg())
+	}
+
+	err = rs.Repo().DeleteInvitation(ctx, req.InvitationID)
+	if err != nil {
+		err = errors.Wrap(err, "decline invitation error")
+		return t.NewDeclineInvitationRes(nil, err, rs.Cfg())
+	}
+
+	return t.NewDeclineInvitationRes(nil, nil, rs.Cfg())
+}
+
+// invitationFor returns the invitation if it was sent to the email of the user.
+// Invitations sent to others are reported as not found.
+func (rs *List) invitationFor(ctx context.Context, invitationID, userID string) (invitation model.Invitation, err error) {
+	invitation, err = rs.Repo().GetInvitation(ctx, invitationID)
+	if err != nil {
+		return invitation, err
+	}
+
+	user, err := rs.Repo().GetUser(ctx, userID)
+	if err != nil {
+		return invitation, err
+	}
+
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return invitation, port.NewInvitationNotFoundErr(invitationID)
+	}
+
+	return invitation, nil
+}
+
+func (rs *List) sendInvitation(ctx context.Context, invitation model.Invitation, inviter model.User) error {
+	if rs.Mailer() == nil {
+		return errors.New("no mailer available")
+	}
+
+	body := fmt.Sprintf("%s (%s) invited you to the list \"%s\" as %s.\n\n"+
+		"Sign in with this email address and accept the invitation %s before %s.\n",
+		inviter.Name, inviter.Username, invitation.ListName, invitation.Role,
+		invitation.ID.String(), invitation.ExpiresAt.Format(time.RFC1123))
+
+	email := port.Email{
+		From:    port.EmailAddress(rs.Cfg().GetString(config.Key.MailFrom)),
+		To:      []port.EmailAddress{port.EmailAddress(invitation.Email)},
+		Subject: fmt.Sprintf("Invitation to the list \"%s\"", invitation.ListName),
+		Body:    []byte(body),
+	}
+
+	return rs.Mailer().SendMail(ctx, email)
+}
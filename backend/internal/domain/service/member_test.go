@@ -0,0 +1,274 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+	t "github.com/vanillazen/stl/backend/internal/transport"
+)
+
+func TestUpdateMember(tt *testing.T) {
+	tests := []struct {
+		name         string
+		req          t.UpdateMemberReq
+		expectedRole model.Role
+		expectedErr  error
+	}{
+		{
+			name:         "Owner changes a role",
+			req:          t.UpdateMemberReq{UserID: user1ID, ListID: list1ID, MemberID: user2ID, Role: "viewer"},
+			expectedRole: model.ViewerRole,
+		},
+		{
+			name:         "Owner promotes to owner",
+			req:          t.UpdateMemberReq{UserID: user1ID, ListID: list1ID, MemberID: user2ID, Role: "owner"},
+			expectedRole: model.OwnerRole,
+		},
+		{
+			name:        "Last owner demotes itself",
+			req:         t.UpdateMemberReq{UserID: user1ID, ListID: list1ID, MemberID: user1ID, Role: "editor"},
+			expectedErr: errors.Forbidden,
+		},
+		{
+			name:        "Editor changes a role",
+			req:         t.UpdateMemberReq{UserID: user2ID, ListID: list1ID, MemberID: user2ID, Role: "owner"},
+			expectedErr: errors.Forbidden,
+		},
+		{
+			name:        "Unknown role",
+			req:         t.UpdateMemberReq{UserID: user1ID, ListID: list1ID, MemberID: user2ID, Role: "admin"},
+			expectedErr: errors.Invalid,
+		},
+		{
+			name:        "Not a member",
+			req:         t.UpdateMemberReq{UserID: user3ID, ListID: list1ID, MemberID: user2ID, Role: "viewer"},
+			expectedErr: port.ListNotFoundErr,
+		},
+		{
+			name:        "Unknown member",
+			req:         t.UpdateMemberReq{UserID: user1ID, ListID: list1ID, MemberID: user3ID, Role: "viewer"},
+			expectedErr: port.MemberNotFoundErr,
+		},
+	}
+
+	for _, test := range tests {
+		tt.Run(test.name, func(tt *testing.T) {
+			env := newTestEnv(tt)
+			env.addMember(tt, list1ID, user2ID, model.EditorRole)
+
+			res := env.svc.UpdateMember(context.Background(), test.req)
+
+			checkErr(tt, res.Err(), test.expectedErr)
+
+			if test.expectedErr == nil && res.Member.Role != string(test.expectedRole) {
+				tt.Fatalf("Role: expected '%s', got '%s'", test.expectedRole, res.Member.Role)
+			}
+		})
+	}
+}
+
+func TestRemoveMember(tt *testing.T) {
+	tests := []struct {
+		name        string
+		req         t.RemoveMemberReq
+		owners      []string
+		expectedErr error
+	}{
+		{
+			name: "Owner removes a member",
+			req:  t.RemoveMemberReq{UserID: user1ID, ListID: list1ID, MemberID: user2ID},
+		},
+		{
+			name: "Member leaves",
+			req:  t.RemoveMemberReq{UserID: user2ID, ListID: list1ID, MemberID: user2ID},
+		},
+		{
+			name:   "Owner leaves another owner",
+			req:    t.RemoveMemberReq{UserID: user1ID, ListID: list1ID, MemberID: user1ID},
+			owners: []string{user3ID},
+		},
+		{
+			name:        "Last owner leaves",
+			req:         t.RemoveMemberReq{UserID: user1ID, ListID: list1ID, MemberID: user1ID},
+			expectedErr: errors.Forbidden,
+		},
+		{
+			name:        "Member removes another one",
+			req:         t.RemoveMemberReq{UserID: user2ID, ListID: list1ID, MemberID: user1ID},
+			expectedErr: errors.Forbidden,
+		},
+		{
+			name:        "Not a member",
+			req:         t.RemoveMemberReq{UserID: user3ID, ListID: list1ID, MemberID: user3ID},
+			expectedErr: port.ListNotFoundErr,
+		},
+	}
+
+	for _, test := range tests {
+		tt.Run(test.name, func(tt *testing.T) {
+			env := newTestEnv(tt)
+			env.addMember(tt, list1ID, user2ID, model.ViewerRole)
+			for _, owner := range test.owners {
+				env.addMember(tt, list1ID, owner, model.OwnerRole)
+			}
+
+			res := env.svc.RemoveMember(context.Background(), test.req)
+
+			checkErr(tt, res.Err(), test.expectedErr)
+
+			_, err := env.repo.GetMember(context.Background(), test.req.ListID, test.req.MemberID)
+			removed := errors.Is(err, port.ListNotFoundErr)
+			if test.req.MemberID != user3ID && removed != (test.expectedErr == nil) {
+				tt.Fatalf("Member: expected removed %t, got error '%v'", test.expectedErr == nil, err)
+			}
+		})
+	}
+}
+
+func TestInviteMember(tt *testing.T) {
+	tests := []struct {
+		name        string
+		req         t.InviteMemberReq
+		expectedErr error
+	}{
+		{
+			name: "Owner",
+			req:  t.InviteMemberReq{UserID: user1ID, ListID: list1ID, Email: "user3@example.com", Role: "editor"},
+		},
+		{
+			name:        "Editor",
+			req:         t.InviteMemberReq{UserID: user2ID, ListID: list1ID, Email: "user3@example.com"},
+			expectedErr: errors.Forbidden,
+		},
+		{
+			name:        "Not a member",
+			req:         t.InviteMemberReq{UserID: user3ID, ListID: list1ID, Email: "user3@example.com"},
+			expectedErr: port.ListNotFoundErr,
+		},
+	}
+
+	for _, test := range tests {
+		tt.Run(test.name, func(tt *testing.T) {
+			env := newTestEnv(tt)
+			env.addMember(tt, list1ID, user2ID, model.EditorRole)
+
+			res := env.svc.InviteMember(context.Background(), test.req)
+
+			checkErr(tt, res.Err(), test.expectedErr)
+		})
+	}
+}
+
+func TestAcceptInvitation(tt *testing.T) {
+	tests := []struct {
+		name        string
+		userID      string
+		expiresAt   time.Time
+		expectedErr error
+	}{
+		{
+			name:      "Invited user",
+			userID:    user2ID,
+			expiresAt: time.Now().UTC().Add(time.Hour),
+		},
+		{
+			name:        "User with another email",
+			userID:      user3ID,
+			expiresAt:   time.Now().UTC().Add(time.Hour),
+			expectedErr: port.InvitationNotFoundErr,
+		},
+		{
+			name:        "Expired",
+			userID:      user2ID,
+			expiresAt:   time.Now().UTC().Add(-time.Hour),
+			expectedErr: errors.Forbidden,
+		},
+	}
+
+	for _, test := range tests {
+		tt.Run(test.name, func(tt *testing.T) {
+			env := newTestEnv(tt)
+			invitationID := env.invite(tt, list1ID, "User2@example.com", test.expiresAt)
+
+			res := env.svc.AcceptInvitation(context.Background(), t.AcceptInvitationReq{UserID: test.userID, InvitationID: invitationID})
+
+			checkErr(tt, res.Err(), test.expectedErr)
+
+			// Only the invited user becomes a member
+			_, err := env.repo.GetMember(context.Background(), list1ID, test.userID)
+			if (err == nil) != (test.expectedErr == nil) {
+				tt.Fatalf("Member: expected a member %t, got error '%v'", test.expectedErr == nil, err)
+			}
+		})
+	}
+}
+
+func TestDeclineInvitation(tt *testing.T) {
+	tests := []struct {
+		name        string
+		userID      string
+		expectedErr error
+	}{
+		{
+			name:   "Invited user",
+			userID: user2ID,
+		},
+		{
+			name:   "Owner of the list",
+			userID: user1ID,
+		},
+		{
+			name:        "Editor of the list",
+			userID:      user3ID,
+			expectedErr: errors.Forbidden,
+		},
+	}
+
+	for _, test := range tests {
+		tt.Run(test.name, func(tt *testing.T) {
+			env := newTestEnv(tt)
+			env.addMember(tt, list1ID, user3ID, model.EditorRole)
+			invitationID := env.invite(tt, list1ID, "user2@example.com", time.Now().UTC().Add(time.Hour))
+
+			res := env.svc.DeclineInvitation(context.Background(), t.DeclineInvitationReq{UserID: test.userID, InvitationID: invitationID})
+
+			checkErr(tt, res.Err(), test.expectedErr)
+
+			_, err := env.repo.GetInvitation(context.Background(), invitationID)
+			declined := errors.Is(err, port.InvitationNotFoundErr)
+			if declined != (test.expectedErr == nil) {
+				tt.Fatalf("Invitation: expected declined %t, got error '%v'", test.expectedErr == nil, err)
+			}
+		})
+	}
+
+	// Users that are neither invited nor members of the list cannot tell it exists
+	env := newTestEnv(tt)
+	invitationID := env.invite(tt, list1ID, "user2@example.com", time.Now().UTC().Add(time.Hour))
+
+	res := env.svc.DeclineInvitation(context.Background(), t.DeclineInvitationReq{UserID: user3ID, InvitationID: invitationID})
+	checkErr(tt, res.Err(), port.InvitationNotFoundErr)
+}
+
+// invite creates an invitation of user 1 to the list as a viewer, returning its ID.
+func (env testEnv) invite(tt *testing.T, listID, email string, expiresAt time.Time) string {
+	tt.Helper()
+
+	invitation, err := env.repo.CreateInvitation(context.Background(), model.Invitation{
+		ListID:    model.NewID(uuid.MustParse(listID)),
+		Email:     email,
+		Role:      model.ViewerRole,
+		InvitedBy: model.NewID(uuid.MustParse(user1ID)),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		tt.Fatalf("Test setup error: %s", err)
+	}
+
+	return invitation.ID.String()
+}
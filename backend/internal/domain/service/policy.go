@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/sys"
+)
+
+// Action is an operation on a resource, named after the resource and the verb, i.e.: "task.update".
+type Action string
+
+const (
+	ListIndex        Action = "list.index"
+	ListCreate       Action = "list.create"
+	ListRead         Action = "list.read"
+	ListUpdate       Action = "list.update"
+	ListDelete       Action = "list.delete"
+	ListShare        Action = "list.share"
+	ListWebhook      Action = "list.webhook"
+	MemberRead       Action = "member.read"
+	MemberLeave      Action = "member.leave"
+	TaskRead         Action = "task.read"
+	TaskCreate       Action = "task.create"
+	TaskUpdate       Action = "task.update"
+	TaskDelete       Action = "task.delete"
+	TagRead          Action = "tag.read"
+	InvitationRead   Action = "invitation.read"
+	InvitationAccept Action = "invitation.accept"
+	UserRead         Action = "user.read"
+	UserFind         Action = "user.find"
+	AdminUsersManage Action = "admin.users.manage"
+	Search           Action = "search"
+	WebhookManage    Action = "webhook.manage"
+)
+
+type (
+	// Target is the resource an action is performed on, fields not involved in the action are left empty.
+	Target struct {
+		ListID string
+		UserID string
+	}
+
+	// Rule allows an action by returning nil.
+	// It denies it with a ForbiddenErr, or with a NotFoundErr if the target must not be disclosed to the subject.
+	Rule func(ctx context.Context, s *Subject, target Target) error
+
+	// Subject is the user performing an action, its details are loaded on demand and only once.
+	Subject struct {
+		UserID  string
+		store   port.ListRepo
+		user    *model.User
+		members map[string]model.Member
+	}
+
+	// Grant is the outcome of an allowed action.
+	Grant struct {
+		// Member is the membership of the user in the target list, if a rule checked it.
+		Member model.Member
+	}
+
+	// Policy decides whether a user can perform an action, keeping permission logic out of queries and handlers.
+	// Every action has a single rule and actions without one are denied.
+	Policy struct {
+		*sys.SimpleCore
+		store port.ListRepo
+		mu    sync.RWMutex
+		rules map[Action]Rule
+	}
+)
+
+func NewPolicy(store port.ListRepo, opts ...sys.Option) *Policy {
+	p := &Policy{
+		SimpleCore: sys.NewCore("policy", opts...),
+		store:      store,
+		rules:      map[Action]Rule{},
+	}
+
+	p.registerDefaults()
+
+	return p
+}
+
+// registerDefaults sets the rules of the built-in actions.
+// System roles do not grant access to lists, admins see the lists they are members of like everyone else.
+func (p *Policy) registerDefaults() {
+	for _, a := range []Action{ListIndex, ListCreate, TagRead, InvitationRead, InvitationAccept, UserFind, Search, WebhookManage} {
+		p.Register(a, Authenticated())
+	}
+
+	for _, a := range []Action{ListRead, MemberRead, MemberLeave, TaskRead} {
+		p.Register(a, ListRole(model.ViewerRole))
+	}
+
+	for _, a := range []Action{ListUpdate, ListWebhook, TaskCreate, TaskUpdate, TaskDelete} {
+		p.Register(a, ListRole(model.EditorRole))
+	}
+
+	for _, a := range []Action{ListDelete, ListShare} {
+		p.Register(a, ListRole(model.OwnerRole))
+	}
+
+	p.Register(AdminUsersManage, SystemRole(model.AdminSystemRole))
+	p.Register(UserRead, AnyOf(Self(), p.Delegate(AdminUsersManage)))
+}
+
+// Register sets the rule of an action, replacing the previous one.
+func (p *Policy) Register(action Action, rule Rule) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.rules[action] = rule
+}
+
+// Authorize returns nil error if the user is allowed to perform the action on the target.
+func (p *Policy) Authorize(ctx context.Context, action Action, userID string, target Target) (grant Grant, err error) {
+	rule, ok := p.rule(action)
+	if !ok {
+		p.Log().Errorf("no rule for action '%s'", action)
+		return grant, port.NewForbiddenErr(fmt.Sprintf("%s not allowed", action))
+	}
+
+	s := p.subject(userID)
+
+	err = rule(ctx, s, target)
+	if err != nil {
+		return grant, err
+	}
+
+	grant.Member = s.members[target.ListID]
+
+	return grant, nil
+}
+
+// Delegate returns a rule that allows whatever the rule of another action allows.
+// The rule is looked up when evaluated, so it follows later registrations.
+func (p *Policy) Delegate(action Action) Rule {
+	return func(ctx context.Context, s *Subject, target Target) error {
+		rule, ok := p.rule(action)
+		if !ok {
+			return port.NewForbiddenErr(fmt.Sprintf("%s not allowed", action))
+		}
+
+		return rule(ctx, s, target)
+	}
+}
+
+func (p *Policy) rule(action Action) (rule Rule, ok bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	rule, ok = p.rules[action]
+	return rule, ok
+}
+
+func (p *Policy) subject(userID string) *Subject {
+	return &Subject{
+		UserID:  userID,
+		store:   p.store,
+		members: map[string]model.Member{},
+	}
+}
+
+// User returns the user performing the action.
+func (s *Subject) User(ctx context.Context) (model.User, error) {
+	if s.user != nil {
+		return *s.user, nil
+	}
+
+	user, err := s.store.GetUser(ctx, s.UserID)
+	if err != nil {
+		return user, err
+	}
+
+	s.user = &user
+	return user, nil
+}
+
+// Member returns the membership of the user in a list, it returns ListNotFoundErr if there is none.
+func (s *Subject) Member(ctx context.Context, listID string) (model.Member, error) {
+	if m, ok := s.members[listID]; ok {
+		return m, nil
+	}
+
+	member, err := s.store.GetMember(ctx, listID, s.UserID)
+	if err != nil {
+		return member, err
+	}
+
+	s.members[listID] = member
+	return member, nil
+}
+
+// Rules
+
+// Authenticated allows any identified user.
+func Authenticated() Rule {
+	return func(ctx context.Context, s *Subject, target Target) error {
+		if s.UserID == "" {
+			return port.NewUnauthenticatedErr("no user")
+		}
+
+		return nil
+	}
+}
+
+// Self allows users to act on their own user.
+func Self() Rule {
+	return func(ctx context.Context, s *Subject, target Target) error {
+		if s.UserID == "" || s.UserID != target.UserID {
+			return port.NewForbiddenErr("not allowed on other users")
+		}
+
+		return nil
+	}
+}
+
+// ListRole allows members of the target list whose role includes the given one.
+// Non members get a ListNotFoundErr, so the list is not disclosed to them.
+func ListRole(role model.Role) Rule {
+	return func(ctx context.Context, s *Subject, target Target) error {
+		member, err := s.Member(ctx, target.ListID)
+		if err != nil {
+			return err
+		}
+
+		if !member.Role.Includes(role) {
+			return port.NewForbiddenErr(fmt.Sprintf("%s role required", role))
+		}
+
+		return nil
+	}
+}
+
+// SystemRole allows users with the given system role.
+func SystemRole(role model.SystemRole) Rule {
+	return func(ctx context.Context, s *Subject, target Target) error {
+		user, err := s.User(ctx)
+		if err != nil {
+			return err
+		}
+
+		if user.Role != role {
+			return port.NewForbiddenErr(fmt.Sprintf("%s system role required", role))
+		}
+
+		return nil
+	}
+}
+
+// AnyOf allows the action if any of the rules does, they are evaluated in order.
+// If none does it returns the denial of the first one.
+func AnyOf(rules ...Rule) Rule {
+	return func(ctx context.Context, s *Subject, target Target) error {
+		var first error
+		for _, rule := range rules {
+			err := rule(ctx, s, target)
+			if err == nil {
+				return nil
+			}
+
+			if first == nil {
+				first = err
+			}
+		}
+
+		if first == nil {
+			return port.NewForbiddenErr("no rule allows it")
+		}
+
+		return first
+	}
+}
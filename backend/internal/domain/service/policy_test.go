@@ -0,0 +1,277 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/domain/service"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+)
+
+// listRoles are the roles of the users in list 1 the policy is tested with.
+var listRoles = map[model.Role]string{
+	model.OwnerRole:  user1ID,
+	model.EditorRole: user2ID,
+	model.ViewerRole: user3ID,
+}
+
+func TestAuthorizeListRoles(tt *testing.T) {
+	// The least role each action on a list requires
+	actions := map[service.Action]model.Role{
+		service.ListRead:    model.ViewerRole,
+		service.MemberRead:  model.ViewerRole,
+		service.MemberLeave: model.ViewerRole,
+		service.TaskRead:    model.ViewerRole,
+		service.ListUpdate:  model.EditorRole,
+		service.ListWebhook: model.EditorRole,
+		service.TaskCreate:  model.EditorRole,
+		service.TaskUpdate:  model.EditorRole,
+		service.TaskDelete:  model.EditorRole,
+		service.ListDelete:  model.OwnerRole,
+		service.ListShare:   model.OwnerRole,
+	}
+
+	env := newTestEnv(tt)
+	env.addMember(tt, list1ID, user2ID, model.EditorRole)
+	env.addMember(tt, list1ID, user3ID, model.ViewerRole)
+
+	for action, required := range actions {
+		for role, userID := range listRoles {
+			tt.Run(fmt.Sprintf("%s as %s", action, role), func(tt *testing.T) {
+				grant, err := env.policy.Authorize(context.Background(), action, userID, service.Target{ListID: list1ID})
+
+				if !role.Includes(required) {
+					if errors.KindOf(err) != errors.Forbidden {
+						tt.Fatalf("Error: expected forbidden, got '%v'", err)
+					}
+					return
+				}
+
+				if err != nil {
+					tt.Fatalf("Error: expected none, got '%v'", err)
+				}
+
+				if grant.Member.Role != role || grant.Member.User.ID.String() != userID {
+					tt.Fatalf("Grant: expected %s membership, got %+v", role, grant.Member)
+				}
+			})
+		}
+
+		// Lists are not disclosed to non members, whether they exist or not
+		for _, listID := range []string{list2ID, noneID} {
+			tt.Run(fmt.Sprintf("%s of list %s by non member", action, listID), func(tt *testing.T) {
+				_, err := env.policy.Authorize(context.Background(), action, user1ID, service.Target{ListID: listID})
+
+				checkErr(tt, err, port.ListNotFoundErr)
+				if errors.KindOf(err) != errors.NotFound {
+					tt.Fatalf("Error kind: expected not found, got '%s'", errors.KindOf(err))
+				}
+			})
+		}
+	}
+}
+
+func TestAuthorize(tt *testing.T) {
+	tests := []struct {
+		name        string
+		action      service.Action
+		userID      string
+		target      service.Target
+		expectedErr error
+	}{
+		{
+			name:        "Action without rule",
+			action:      service.Action("list.archive"),
+			userID:      user1ID,
+			target:      service.Target{ListID: list1ID},
+			expectedErr: errors.Forbidden,
+		},
+		{
+			name:   "Authenticated user",
+			action: service.ListCreate,
+			userID: user1ID,
+		},
+		{
+			name:        "Anonymous user",
+			action:      service.ListCreate,
+			expectedErr: errors.Unauthenticated,
+		},
+		{
+			name:   "Admin action by admin",
+			action: service.AdminUsersManage,
+			userID: user3ID,
+		},
+		{
+			name:        "Admin action by user",
+			action:      service.AdminUsersManage,
+			userID:      user1ID,
+			expectedErr: errors.Forbidden,
+		},
+		{
+			name:        "Admin action by unknown user",
+			action:      service.AdminUsersManage,
+			userID:      noneID,
+			expectedErr: port.UserNotFoundErr,
+		},
+		{
+			name:   "Own user",
+			action: service.UserRead,
+			userID: user1ID,
+			target: service.Target{UserID: user1ID},
+		},
+		{
+			name:        "Another user",
+			action:      service.UserRead,
+			userID:      user1ID,
+			target:      service.Target{UserID: user2ID},
+			expectedErr: errors.Forbidden,
+		},
+		{
+			name:   "Another user by admin",
+			action: service.UserRead,
+			userID: user3ID,
+			target: service.Target{UserID: user2ID},
+		},
+		{
+			name:        "Another user anonymously",
+			action:      service.UserRead,
+			target:      service.Target{},
+			expectedErr: errors.Forbidden,
+		},
+	}
+
+	env := newTestEnv(tt)
+
+	for _, test := range tests {
+		tt.Run(test.name, func(tt *testing.T) {
+			_, err := env.policy.Authorize(context.Background(), test.action, test.userID, test.target)
+
+			checkKind(tt, err, test.expectedErr)
+		})
+	}
+}
+
+func TestRules(tt *testing.T) {
+	const (
+		selfOrOwner  service.Action = "test.self-or-owner"
+		delegated    service.Action = "test.delegated"
+		delegatedNew service.Action = "test.delegated-new"
+		none         service.Action = "test.none"
+		unregistered service.Action = "test.unregistered"
+	)
+
+	env := newTestEnv(tt)
+	env.addMember(tt, list1ID, user2ID, model.EditorRole)
+
+	p := env.policy
+	p.Register(selfOrOwner, service.AnyOf(service.Self(), service.ListRole(model.OwnerRole)))
+	p.Register(delegated, p.Delegate(service.ListShare))
+	p.Register(delegatedNew, p.Delegate(unregistered))
+	p.Register(none, service.AnyOf())
+
+	tests := []struct {
+		name        string
+		action      service.Action
+		userID      string
+		target      service.Target
+		register    service.Rule
+		expectedErr error
+	}{
+		{
+			name:   "Any of, first allows",
+			action: selfOrOwner,
+			userID: user2ID,
+			target: service.Target{UserID: user2ID, ListID: list1ID},
+		},
+		{
+			name:   "Any of, second allows",
+			action: selfOrOwner,
+			userID: user1ID,
+			target: service.Target{UserID: user2ID, ListID: list1ID},
+		},
+		{
+			name:        "Any of, none allows",
+			action:      selfOrOwner,
+			userID:      user2ID,
+			target:      service.Target{UserID: user1ID, ListID: list1ID},
+			expectedErr: errors.Forbidden,
+		},
+		{
+			// The denial of the first rule is returned, not the not found of the second
+			name:        "Any of, first denial",
+			action:      selfOrOwner,
+			userID:      user3ID,
+			target:      service.Target{UserID: user1ID, ListID: list1ID},
+			expectedErr: errors.Forbidden,
+		},
+		{
+			name:        "Any of no rules",
+			action:      none,
+			userID:      user1ID,
+			expectedErr: errors.Forbidden,
+		},
+		{
+			name:   "Delegate allows",
+			action: delegated,
+			userID: user1ID,
+			target: service.Target{ListID: list1ID},
+		},
+		{
+			name:        "Delegate denies",
+			action:      delegated,
+			userID:      user2ID,
+			target:      service.Target{ListID: list1ID},
+			expectedErr: errors.Forbidden,
+		},
+		{
+			name:        "Delegate to non member",
+			action:      delegated,
+			userID:      user1ID,
+			target:      service.Target{ListID: list2ID},
+			expectedErr: port.ListNotFoundErr,
+		},
+		{
+			name:        "Delegate to action without rule",
+			action:      delegatedNew,
+			userID:      user1ID,
+			expectedErr: errors.Forbidden,
+		},
+		{
+			// Delegates look the rule up when evaluated
+			name:     "Delegate to action registered later",
+			action:   delegatedNew,
+			userID:   user1ID,
+			register: service.Authenticated(),
+		},
+	}
+
+	for _, test := range tests {
+		tt.Run(test.name, func(tt *testing.T) {
+			if test.register != nil {
+				p.Register(unregistered, test.register)
+			}
+
+			_, err := p.Authorize(context.Background(), test.action, test.userID, test.target)
+
+			checkKind(tt, err, test.expectedErr)
+		})
+	}
+}
+
+// checkKind is checkErr for errors returned unwrapped, whose kind is not matched by errors.Is.
+func checkKind(tt *testing.T, err, expected error) {
+	tt.Helper()
+
+	kind, ok := expected.(errors.Kind)
+	if !ok {
+		checkErr(tt, err, expected)
+		return
+	}
+
+	if errors.KindOf(err) != kind {
+		tt.Fatalf("Error: expected '%v', got '%v'", expected, err)
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/validator"
+	t "github.com/vanillazen/stl/backend/internal/transport"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// QueryValidator turns the collection parameters sent by clients into repo queries.
+// Unknown filters are ignored, malformed values are reported as validation errors.
+type QueryValidator struct {
+	validator.Validator
+	Query t.Query
+}
+
+func NewQueryValidator(q t.Query) QueryValidator {
+	return QueryValidator{
+		Validator: validator.NewValidator(),
+		Query:     q,
+	}
+}
+
+// ListQuery returns the query of a lists collection request.
+func (v QueryValidator) ListQuery() (q model.ListQuery, page t.Page) {
+	q.Page, page = v.page()
+	q.Sort = v.sort(model.ListSortFields)
+
+	if role, ok := v.Query.Filters["role"]; ok {
+		q.Role = model.Role(role)
+		if !q.Role.Valid() {
+			v.Errors.Add("role", validator.ValidatorMsg.NotAllowedErrMsg)
+		}
+	}
+
+	return q, page
+}
+
+// TaskQuery returns the query of a tasks collection request.
+func (v QueryValidator) TaskQuery() (q model.TaskQuery, page t.Page) {
+	q.Page, page = v.page()
+	q.Sort = v.sort(model.TaskSortFields)
+
+	q.Tag = strings.TrimSpace(v.Query.Filters["tag"])
+
+	if val, ok := v.Query.Filters["done"]; ok {
+		done, err := strconv.ParseBool(val)
+		if err != nil {
+			v.Errors.Add("done", validator.ValidatorMsg.NotValidErrMsg)
+		}
+		q.Done = &done
+	}
+
+	if val, ok := v.Query.Filters["priority"]; ok {
+		n, err := strconv.Atoi(val)
+		priority := model.Priority(n)
+		if err != nil || !priority.Valid() {
+			v.Errors.Add("priority", validator.ValidatorMsg.OutOfRangeErrMsg)
+		}
+		q.Priority = &priority
+	}
+
+	q.DueBefore = v.time("due_before")
+	q.DueAfter = v.time("due_after")
+
+	return q, page
+}
+
+// SearchQuery returns the query of a search request, its terms are the words of the text.
+// Sorting is not supported, results are ranked by relevance.
+func (v QueryValidator) SearchQuery(text string) (q model.SearchQuery, page t.Page) {
+	q.Page, page = v.page()
+
+	q.Terms = strings.Fields(text)
+	if len(q.Terms) == 0 {
+		v.Errors.Add("q", validator.ValidatorMsg.RequiredErrMsg)
+	}
+
+	if v.Query.Sort != "" {
+		v.Errors.Add("sort", validator.ValidatorMsg.NotAllowedErrMsg)
+	}
+
+	return q, page
+}
+
+func (v QueryValidator) page() (p model.Page, page t.Page) {
+	page = t.Page{Number: 1, Limit: DefaultPageSize}
+
+	if v.Query.Limit != "" {
+		limit, err := strconv.Atoi(v.Query.Limit)
+		if err != nil || !v.ValidateRange(limit, 1, MaxPageSize) {
+			v.Errors.Add("limit", validator.ValidatorMsg.OutOfRangeErrMsg)
+		}
+		page.Limit = limit
+	}
+
+	if v.Query.Page != "" {
+		number, err := strconv.Atoi(v.Query.Page)
+		if err != nil || number < 1 {
+			v.Errors.Add("page", validator.ValidatorMsg.OutOfRangeErrMsg)
+		}
+		page.Number = number
+	}
+
+	p = model.Page{
+		Limit:  page.Limit,
+		Offset: (page.Number - 1) * page.Limit,
+	}
+
+	return p, page
+}
+
+// sort parses the sort parameter, i.e.: "-created_at,name".
+func (v QueryValidator) sort(fields []string) (sort []model.Sort) {
+	if strings.TrimSpace(v.Query.Sort) == "" {
+		return nil
+	}
+
+	for _, field := range strings.Split(v.Query.Sort, ",") {
+		field = strings.TrimSpace(field)
+
+		var s model.Sort
+		s.Field, s.Desc = strings.CutPrefix(field, "-")
+		if !contains(fields, s.Field) {
+			v.Errors.Add("sort", validator.ValidatorMsg.NotAllowedErrMsg)
+			continue
+		}
+
+		sort = append(sort, s)
+	}
+
+	return sort
+}
+
+// time parses a filter holding either a date or an RFC 3339 timestamp.
+func (v QueryValidator) time(filter string) time.Time {
+	val, ok := v.Query.Filters[filter]
+	if !ok {
+		return time.Time{}
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		parsed, err := time.Parse(layout, val)
+		if err == nil {
+			return parsed
+		}
+	}
+
+	v.Errors.Add(filter, validator.ValidatorMsg.NotValidErrMsg)
+	return time.Time{}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
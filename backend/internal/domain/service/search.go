@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	t "github.com/vanillazen/stl/backend/internal/transport"
+)
+
+// Search looks up the text in the names, descriptions and tags of the lists the user is a member of and their tasks.
+func (rs *List) Search(ctx context.Context, req t.SearchReq) (res t.SearchRes) {
+	// Validate query
+	v := NewQueryValidator(req.Query)
+	query, page := v.SearchQuery(req.Text)
+	if v.HasErrors() {
+		err := errors.NewKind(errors.Invalid, "query has errors")
+		return t.NewSearchRes(v.Errors, err, rs.Cfg(), nil, page)
+	}
+
+	_, err := rs.Policy().Authorize(ctx, Search, req.UserID, Target{})
+	if err != nil {
+		err = errors.Wrap(err, "search error")
+		return t.NewSearchRes(nil, err, rs.Cfg(), nil, page)
+	}
+
+	results, total, err := rs.Repo().Search(ctx, req.UserID, query)
+	if err != nil {
+		err = errors.Wrap(err, "search error")
+		return t.NewSearchRes(nil, err, rs.Cfg(), nil, page)
+	}
+
+	page.Total = total
+
+	return t.NewSearchRes(nil, nil, rs.Cfg(), results, page)
+}
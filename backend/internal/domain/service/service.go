@@ -2,75 +2,159 @@ package service
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/vanillazen/stl/backend/internal/domain/model"
 	"github.com/vanillazen/stl/backend/internal/domain/port"
 	"github.com/vanillazen/stl/backend/internal/sys"
 	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/patch"
+	"github.com/vanillazen/stl/backend/internal/sys/validator"
 	t "github.com/vanillazen/stl/backend/internal/transport"
 )
 
+// MaxBatchOps is the number of operations a task batch can have at most.
+const MaxBatchOps = 100
+
 type (
 	ListService interface {
 		sys.Core
 		CreateList(ctx context.Context, req t.CreateListReq) t.CreateListRes
+		GetLists(ctx context.Context, req t.GetListsReq) t.GetListsRes
 		GetList(ctx context.Context, req t.GetListReq) t.GetListRes
-		//UpdateList(...)
-		//DeleteList(...)
-		//AddTask(...)
-		//AddTasks(...)
-		//GetTask(...)
-		//UpdateTask(...)
-		//DeleteTask(...)
+		UpdateList(ctx context.Context, req t.UpdateListReq) t.UpdateListRes
+		PatchList(ctx context.Context, req t.PatchListReq) t.PatchListRes
+		DeleteList(ctx context.Context, req t.DeleteListReq) t.DeleteListRes
+		AddTask(ctx context.Context, req t.AddTaskReq) t.AddTaskRes
+		AddTasks(ctx context.Context, req t.AddTasksReq) t.AddTasksRes
+		BatchTasks(ctx context.Context, req t.BatchTasksReq) t.BatchTasksRes
+		GetTasks(ctx context.Context, req t.GetTasksReq) t.GetTasksRes
+		GetTask(ctx context.Context, req t.GetTaskReq) t.GetTaskRes
+		UpdateTask(ctx context.Context, req t.UpdateTaskReq) t.UpdateTaskRes
+		PatchTask(ctx context.Context, req t.PatchTaskReq) t.PatchTaskRes
+		ToggleTask(ctx context.Context, req t.ToggleTaskReq) t.ToggleTaskRes
+		DeleteTask(ctx context.Context, req t.DeleteTaskReq) t.DeleteTaskRes
+		GetTags(ctx context.Context, req t.GetTagsReq) t.GetTagsRes
+		GetTag(ctx context.Context, req t.GetTagReq) t.GetTagRes
+		AttachTag(ctx context.Context, req t.AttachTagReq) t.AttachTagRes
+		DetachTag(ctx context.Context, req t.DetachTagReq) t.DetachTagRes
+		GetMembers(ctx context.Context, req t.GetMembersReq) t.GetMembersRes
+		InviteMember(ctx context.Context, req t.InviteMemberReq) t.InviteMemberRes
+		UpdateMember(ctx context.Context, req t.UpdateMemberReq) t.UpdateMemberRes
+		RemoveMember(ctx context.Context, req t.RemoveMemberReq) t.RemoveMemberRes
+		GetInvitations(ctx context.Context, req t.GetInvitationsReq) t.GetInvitationsRes
+		AcceptInvitation(ctx context.Context, req t.AcceptInvitationReq) t.AcceptInvitationRes
+		DeclineInvitation(ctx context.Context, req t.DeclineInvitationReq) t.DeclineInvitationRes
+		Search(ctx context.Context, req t.SearchReq) t.SearchRes
+		SubscribeEvents(ctx context.Context, req t.SubscribeEventsReq) t.SubscribeEventsRes
+		PublishEvent(ctx context.Context, e model.DomainEvent) error
+		EnqueueDeliveries(ctx context.Context, e model.DomainEvent) error
+		PullChanges(ctx context.Context, req t.PullChangesReq) t.PullChangesRes
+		PushChanges(ctx context.Context, req t.PushChangesReq) t.PushChangesRes
 		//GetUser(...)
 	}
 
 	List struct {
 		*sys.SimpleCore
-		repo   port.ListRepo
-		mailer port.Mailer
+		repo     port.ListRepo
+		policy   *Policy
+		mailer   port.Mailer
+		events   port.EventHub
+		webhooks port.WebhookRepo
 	}
 )
 
-func NewService(rr port.ListRepo, opts ...sys.Option) *List {
+// NewService returns the list service. Changes are published to the event hub by PublishEvent and queued for
+// delivery to the webhooks subscribed to them by EnqueueDeliveries, as the event bus delivers their domain events.
+func NewService(rr port.ListRepo, policy *Policy, mailer port.Mailer, events port.EventHub, webhooks port.WebhookRepo, opts ...sys.Option) *List {
 	return &List{
 		SimpleCore: sys.NewCore("list-service", opts...),
 		repo:       rr,
-		mailer:     nil, // Interface not implemented yet
+		policy:     policy,
+		mailer:     mailer,
+		events:     events,
+		webhooks:   webhooks,
 	}
 }
 
 func (rs *List) CreateList(ctx context.Context, req t.CreateListReq) (res t.CreateListRes) {
-	// Transport to Model
-	list := req.ToList()
+	list, valErrs, err := rs.createList(ctx, req.ToList(), req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "create list error")
+		return t.NewCreateListRes(valErrs, err, rs.Cfg())
+	}
 
+	res = t.NewCreateListRes(nil, nil, rs.Cfg())
+	res.FromList(list)
+
+	return res
+}
+
+// createList validates and persists a new list owned by the user, returning the validation errors if any.
+func (rs *List) createList(ctx context.Context, list model.List, userID string) (model.List, validator.ValErrorSet, error) {
 	// Validate model
 	v := NewListValidator(list)
 
 	err := v.ValidateForCreate()
 	if err != nil {
-		return t.NewCreateListRes(v.Errors, err, rs.Cfg())
+		return list, v.Errors, err
+	}
+
+	_, err = rs.Policy().Authorize(ctx, ListCreate, userID, Target{})
+	if err != nil {
+		return list, nil, err
 	}
 
 	// Set Owner
-	user, err := rs.Repo().GetUser(ctx, req.UserID)
+	user, err := rs.Repo().GetUser(ctx, userID)
 	if err != nil {
-		err = errors.Wrap(err, "create list error")
-		return t.NewCreateListRes(nil, err, rs.Cfg())
+		return list, nil, err
 	}
 
 	list.Owner = user
 
 	// Persist it
-	_, err = rs.Repo().CreateList(ctx, list)
+	list, err = rs.Repo().CreateList(ctx, list)
 	if err != nil {
-		err = errors.Wrap(err, "create list error")
-		return t.NewCreateListRes(nil, err, rs.Cfg())
+		return list, nil, err
+	}
+
+	return list, nil, nil
+}
+
+func (rs *List) GetLists(ctx context.Context, req t.GetListsReq) (res t.GetListsRes) {
+	// Validate query
+	v := NewQueryValidator(req.Query)
+	query, page := v.ListQuery()
+	if v.HasErrors() {
+		err := errors.NewKind(errors.Invalid, "query has errors")
+		return t.NewGetListsRes(v.Errors, err, rs.Cfg(), nil, page)
 	}
 
-	return t.NewCreateListRes(nil, nil, nil)
+	_, err := rs.Policy().Authorize(ctx, ListIndex, req.UserID, Target{})
+	if err != nil {
+		err = errors.Wrap(err, "get lists error")
+		return t.NewGetListsRes(nil, err, rs.Cfg(), nil, page)
+	}
+
+	lists, total, err := rs.Repo().GetLists(ctx, req.UserID, query)
+	if err != nil {
+		err = errors.Wrap(err, "get lists error")
+		return t.NewGetListsRes(nil, err, rs.Cfg(), nil, page)
+	}
+
+	page.Total = total
+
+	return t.NewGetListsRes(nil, nil, rs.Cfg(), lists, page)
 }
 
 func (rs *List) GetList(ctx context.Context, req t.GetListReq) (res t.GetListRes) {
+	_, err := rs.Policy().Authorize(ctx, ListRead, req.UserID, Target{ListID: req.ListID})
+	if err != nil {
+		err = errors.Wrap(err, "get list error")
+		return t.NewGetListRes(nil, err, rs.Cfg(), model.List{})
+	}
+
 	list, err := rs.Repo().GetList(ctx, req.UserID, req.ListID, true)
 	if err != nil {
 		err = errors.Wrap(err, "get list error")
@@ -80,6 +164,562 @@ func (rs *List) GetList(ctx context.Context, req t.GetListReq) (res t.GetListRes
 	return t.NewGetListRes(nil, nil, rs.Cfg(), list)
 }
 
+func (rs *List) UpdateList(ctx context.Context, req t.UpdateListReq) (res t.UpdateListRes) {
+	list, valErrs, err := rs.updateList(ctx, req)
+	if err != nil {
+		err = errors.Wrap(err, "update list error")
+		return t.NewUpdateListRes(valErrs, err, rs.Cfg())
+	}
+
+	res = t.NewUpdateListRes(nil, nil, rs.Cfg())
+	res.FromList(list)
+
+	return res
+}
+
+// PatchList applies a merge patch or JSON patch to the editable fields of a list and updates it with the result.
+// The update is conditioned to the patched version, so changes made meanwhile are not overwritten.
+func (rs *List) PatchList(ctx context.Context, req t.PatchListReq) (res t.PatchListRes) {
+	_, err := rs.Policy().Authorize(ctx, ListUpdate, req.UserID, Target{ListID: req.ListID})
+	if err != nil {
+		err = errors.Wrap(err, "patch list error")
+		return t.NewPatchListRes(nil, err, rs.Cfg())
+	}
+
+	list, err := rs.Repo().GetList(ctx, req.UserID, req.ListID)
+	if err == nil && req.Version != 0 && req.Version != list.Version {
+		err = port.NewVersionErr(port.ListNotFoundErr.Resource, req.ListID, list.Version)
+	}
+	if err != nil {
+		err = errors.Wrap(err, "patch list error")
+		return t.NewPatchListRes(nil, err, rs.Cfg())
+	}
+
+	update, err := patch.Apply(t.NewUpdateListReq(list), req.MediaType, req.Patch)
+	if err != nil {
+		err = errors.Wrap(err, "patch list error")
+		return t.NewPatchListRes(nil, err, rs.Cfg())
+	}
+
+	// Identifiers are not part of the patched document
+	update.UserID = req.UserID
+	update.ListID = req.ListID
+	update.Version = list.Version
+
+	list, valErrs, err := rs.updateList(ctx, update)
+	if err != nil {
+		err = errors.Wrap(err, "patch list error")
+		return t.NewPatchListRes(valErrs, err, rs.Cfg())
+	}
+
+	res = t.NewPatchListRes(nil, nil, rs.Cfg())
+	res.FromList(list)
+
+	return res
+}
+
+// updateList validates and persists the list of an update request, returning the validation errors if any.
+func (rs *List) updateList(ctx context.Context, req t.UpdateListReq) (list model.List, valErrs validator.ValErrorSet, err error) {
+	// Transport to Model
+	list = req.ToList()
+
+	// Validate model
+	v := NewListValidator(list)
+
+	err = v.ValidateForUpdate()
+	if err != nil {
+		return list, v.Errors, err
+	}
+
+	grant, err := rs.Policy().Authorize(ctx, ListUpdate, req.UserID, Target{ListID: req.ListID})
+	if err != nil {
+		return list, nil, err
+	}
+
+	// Persist it
+	list, err = rs.Repo().UpdateList(ctx, list, req.UserID)
+	if err != nil {
+		return list, nil, err
+	}
+
+	list.Role = grant.Member.Role
+
+	return list, nil, nil
+}
+
+func (rs *List) DeleteList(ctx context.Context, req t.DeleteListReq) (res t.DeleteListRes) {
+	_, err := rs.Policy().Authorize(ctx, ListDelete, req.UserID, Target{ListID: req.ListID})
+	if err != nil {
+		err = errors.Wrap(err, "delete list error")
+		return t.NewDeleteListRes(nil, err, rs.Cfg())
+	}
+
+	err = rs.Repo().DeleteList(ctx, req.ListID, req.UserID, req.Version)
+	if err != nil {
+		err = errors.Wrap(err, "delete list error")
+		return t.NewDeleteListRes(nil, err, rs.Cfg())
+	}
+
+	return t.NewDeleteListRes(nil, nil, rs.Cfg())
+}
+
+func (rs *List) AddTask(ctx context.Context, req t.AddTaskReq) (res t.AddTaskRes) {
+	task, valErrs, err := rs.addTask(ctx, req.ListID, req.ToTask(), req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "add task error")
+		return t.NewAddTaskRes(valErrs, err, rs.Cfg())
+	}
+
+	res = t.NewAddTaskRes(nil, nil, rs.Cfg())
+	res.FromTask(task)
+
+	return res
+}
+
+// addTask validates and persists a new task of the list, returning the validation errors if any.
+func (rs *List) addTask(ctx context.Context, listID string, task model.Task, userID string) (model.Task, validator.ValErrorSet, error) {
+	// Validate model
+	v := NewTaskValidator(task)
+
+	err := v.ValidateForCreate()
+	if err != nil {
+		return task, v.Errors, err
+	}
+
+	_, err = rs.Policy().Authorize(ctx, TaskCreate, userID, Target{ListID: listID})
+	if err != nil {
+		return task, nil, err
+	}
+
+	// Persist it
+	task, err = rs.Repo().AddTask(ctx, listID, task, userID)
+	if err != nil {
+		return task, nil, err
+	}
+
+	return task, nil, nil
+}
+
+func (rs *List) AddTasks(ctx context.Context, req t.AddTasksReq) (res t.AddTasksRes) {
+	// Transport to Model
+	tasks := req.ToTasks()
+
+	// Validate models
+	valErrSet := validator.ValErrorSet{}
+	for i, task := range tasks {
+		v := NewTaskValidator(task)
+
+		err := v.ValidateForCreate()
+		if err != nil {
+			for field, msgs := range v.Errors {
+				key := fmt.Sprintf("Tasks[%d].%s", i, field)
+				valErrSet[key] = append(valErrSet[key], msgs...)
+			}
+		}
+	}
+
+	if !valErrSet.IsEmpty() {
+		err := errors.NewKind(errors.Invalid, "tasks have errors")
+		return t.NewAddTasksRes(valErrSet, err, rs.Cfg())
+	}
+
+	_, err := rs.Policy().Authorize(ctx, TaskCreate, req.UserID, Target{ListID: req.ListID})
+	if err != nil {
+		err = errors.Wrap(err, "add tasks error")
+		return t.NewAddTasksRes(nil, err, rs.Cfg())
+	}
+
+	// Persist them
+	tasks, err = rs.Repo().AddTasks(ctx, req.ListID, tasks, req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "add tasks error")
+		return t.NewAddTasksRes(nil, err, rs.Cfg())
+	}
+
+	res = t.NewAddTasksRes(nil, nil, rs.Cfg())
+	res.FromTasks(tasks)
+
+	return res
+}
+
+// BatchTasks validates and authorizes every operation of the batch before applying them in a single transaction.
+// Atomic batches fail as a whole on the first invalid, forbidden or failed operation, best effort ones only skip it
+// and report its error in its result.
+func (rs *List) BatchTasks(ctx context.Context, req t.BatchTasksReq) (res t.BatchTasksRes) {
+	// Transport to Model
+	ops := req.ToTaskOps()
+	atomic := !req.BestEffort
+
+	if len(ops) == 0 || len(ops) > MaxBatchOps {
+		valErrSet := validator.ValErrorSet{}
+		valErrSet.Add("Ops", validator.ValidatorMsg.OutOfRangeErrMsg)
+		err := errors.NewKind(errors.Invalid, "batch has errors")
+		return t.NewBatchTasksRes(valErrSet, err, rs.Cfg())
+	}
+
+	// Validate models
+	results := make([]t.TaskOpRes, len(ops))
+	valErrSet := validator.ValErrorSet{}
+	for i, op := range ops {
+		results[i] = t.NewTaskOpRes(op.Kind, nil, nil, rs.Cfg())
+
+		v := NewTaskOpValidator(op)
+
+		err := v.ValidateForBatch()
+		if err != nil {
+			results[i] = t.NewTaskOpRes(op.Kind, v.Errors, err, rs.Cfg())
+			for field, msgs := range v.Errors {
+				key := fmt.Sprintf("Ops[%d].%s", i, field)
+				valErrSet[key] = append(valErrSet[key], msgs...)
+			}
+		}
+	}
+
+	if atomic && !valErrSet.IsEmpty() {
+		err := errors.NewKind(errors.Invalid, "operations have errors")
+		return t.NewBatchTasksRes(valErrSet, err, rs.Cfg())
+	}
+
+	// Authorize the valid ones, batches repeat the same few checks so their outcome is kept
+	decisions := map[taskOpCheck]error{}
+	var valid []model.TaskOp
+	var indexes []int
+	for i, op := range ops {
+		if results[i].Err() != nil {
+			continue
+		}
+
+		err := rs.authorizeTaskOp(ctx, req.ListID, op, req.UserID, decisions)
+		if err != nil && atomic {
+			err = errors.Wrap(port.NewTaskOpErr(i, string(op.Kind), err), "batch tasks error")
+			return t.NewBatchTasksRes(nil, err, rs.Cfg())
+		}
+
+		if err != nil {
+			results[i] = t.NewTaskOpRes(op.Kind, nil, err, rs.Cfg())
+			continue
+		}
+
+		valid = append(valid, op)
+		indexes = append(indexes, i)
+	}
+
+	// Persist them
+	applied, err := rs.Repo().BatchTasks(ctx, req.ListID, valid, req.UserID, atomic)
+	if err != nil {
+		err = errors.Wrap(err, "batch tasks error")
+		return t.NewBatchTasksRes(nil, err, rs.Cfg())
+	}
+
+	for j, i := range indexes {
+		if applied[j].Err != nil {
+			results[i] = t.NewTaskOpRes(ops[i].Kind, nil, applied[j].Err, rs.Cfg())
+			continue
+		}
+
+		if ops[i].Kind != model.DeleteTaskOp {
+			results[i].FromTask(applied[j].Task)
+		}
+	}
+
+	res = t.NewBatchTasksRes(nil, nil, rs.Cfg())
+	res.Results = results
+
+	return res
+}
+
+// taskOpCheck is an action on the tasks of a list a batch operation needs to be allowed.
+type taskOpCheck struct {
+	action Action
+	listID string
+}
+
+// authorizeTaskOp checks that the user can apply the operation to a task of the list.
+// Moved tasks are updated in the list they leave and created in the one they go to.
+func (rs *List) authorizeTaskOp(ctx context.Context, listID string, op model.TaskOp, userID string, decisions map[taskOpCheck]error) error {
+	var checks []taskOpCheck
+	switch op.Kind {
+	case model.CreateTaskOp:
+		checks = []taskOpCheck{{TaskCreate, listID}}
+	case model.UpdateTaskOp:
+		checks = []taskOpCheck{{TaskUpdate, listID}}
+	case model.DeleteTaskOp:
+		checks = []taskOpCheck{{TaskDelete, listID}}
+	case model.MoveTaskOp:
+		checks = []taskOpCheck{{TaskUpdate, listID}, {TaskCreate, op.ToListID}}
+	}
+
+	for _, c := range checks {
+		err, ok := decisions[c]
+		if !ok {
+			_, err = rs.Policy().Authorize(ctx, c.action, userID, Target{ListID: c.listID})
+			decisions[c] = err
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (rs *List) GetTasks(ctx context.Context, req t.GetTasksReq) (res t.GetTasksRes) {
+	// Validate query
+	v := NewQueryValidator(req.Query)
+	query, page := v.TaskQuery()
+	if v.HasErrors() {
+		err := errors.NewKind(errors.Invalid, "query has errors")
+		return t.NewGetTasksRes(v.Errors, err, rs.Cfg(), nil, page)
+	}
+
+	_, err := rs.Policy().Authorize(ctx, TaskRead, req.UserID, Target{ListID: req.ListID})
+	if err != nil {
+		err = errors.Wrap(err, "get tasks error")
+		return t.NewGetTasksRes(nil, err, rs.Cfg(), nil, page)
+	}
+
+	// The version is read before the tasks, so that a change in between never gets the new tasks the old version.
+	list, err := rs.Repo().GetList(ctx, req.UserID, req.ListID)
+	if err != nil {
+		err = errors.Wrap(err, "get tasks error")
+		return t.NewGetTasksRes(nil, err, rs.Cfg(), nil, page)
+	}
+
+	tasks, total, err := rs.Repo().GetTasks(ctx, req.ListID, req.UserID, query)
+	if err != nil {
+		err = errors.Wrap(err, "get tasks error")
+		return t.NewGetTasksRes(nil, err, rs.Cfg(), nil, page)
+	}
+
+	page.Total = total
+
+	res = t.NewGetTasksRes(nil, nil, rs.Cfg(), tasks, page)
+	res.Version = list.TasksVersion
+
+	return res
+}
+
+func (rs *List) GetTask(ctx context.Context, req t.GetTaskReq) (res t.GetTaskRes) {
+	_, err := rs.Policy().Authorize(ctx, TaskRead, req.UserID, Target{ListID: req.ListID})
+	if err != nil {
+		err = errors.Wrap(err, "get task error")
+		return t.NewGetTaskRes(nil, err, rs.Cfg(), model.Task{})
+	}
+
+	task, err := rs.Repo().GetTask(ctx, req.ListID, req.TaskID, req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "get task error")
+		return t.NewGetTaskRes(nil, err, rs.Cfg(), task)
+	}
+
+	return t.NewGetTaskRes(nil, nil, rs.Cfg(), task)
+}
+
+func (rs *List) UpdateTask(ctx context.Context, req t.UpdateTaskReq) (res t.UpdateTaskRes) {
+	task, valErrs, err := rs.updateTask(ctx, req)
+	if err != nil {
+		err = errors.Wrap(err, "update task error")
+		return t.NewUpdateTaskRes(valErrs, err, rs.Cfg())
+	}
+
+	res = t.NewUpdateTaskRes(nil, nil, rs.Cfg())
+	res.FromTask(task)
+
+	return res
+}
+
+// PatchTask applies a merge patch or JSON patch to the editable fields of a task and updates it with the result.
+// The update is conditioned to the patched version, so changes made meanwhile are not overwritten.
+func (rs *List) PatchTask(ctx context.Context, req t.PatchTaskReq) (res t.PatchTaskRes) {
+	_, err := rs.Policy().Authorize(ctx, TaskUpdate, req.UserID, Target{ListID: req.ListID})
+	if err != nil {
+		err = errors.Wrap(err, "patch task error")
+		return t.NewPatchTaskRes(nil, err, rs.Cfg())
+	}
+
+	task, err := rs.Repo().GetTask(ctx, req.ListID, req.TaskID, req.UserID)
+	if err == nil && req.Version != 0 && req.Version != task.Version {
+		err = port.NewVersionErr(port.TaskNotFoundErr.Resource, req.TaskID, task.Version)
+	}
+	if err != nil {
+		err = errors.Wrap(err, "patch task error")
+		return t.NewPatchTaskRes(nil, err, rs.Cfg())
+	}
+
+	update, err := patch.Apply(t.NewUpdateTaskReq(task), req.MediaType, req.Patch)
+	if err != nil {
+		err = errors.Wrap(err, "patch task error")
+		return t.NewPatchTaskRes(nil, err, rs.Cfg())
+	}
+
+	// Identifiers are not part of the patched document
+	update.UserID = req.UserID
+	update.ListID = req.ListID
+	update.TaskID = req.TaskID
+	update.Version = task.Version
+
+	task, valErrs, err := rs.updateTask(ctx, update)
+	if err != nil {
+		err = errors.Wrap(err, "patch task error")
+		return t.NewPatchTaskRes(valErrs, err, rs.Cfg())
+	}
+
+	res = t.NewPatchTaskRes(nil, nil, rs.Cfg())
+	res.FromTask(task)
+
+	return res
+}
+
+// updateTask validates and persists the task of an update request, returning the validation errors if any.
+func (rs *List) updateTask(ctx context.Context, req t.UpdateTaskReq) (task model.Task, valErrs validator.ValErrorSet, err error) {
+	// Transport to Model
+	task = req.ToTask()
+
+	// Validate model
+	v := NewTaskValidator(task)
+
+	err = v.ValidateForUpdate()
+	if err != nil {
+		return task, v.Errors, err
+	}
+
+	_, err = rs.Policy().Authorize(ctx, TaskUpdate, req.UserID, Target{ListID: req.ListID})
+	if err != nil {
+		return task, nil, err
+	}
+
+	// Persist it
+	task, err = rs.Repo().UpdateTask(ctx, task, req.UserID)
+	if err != nil {
+		return task, nil, err
+	}
+
+	return task, nil, nil
+}
+
+func (rs *List) ToggleTask(ctx context.Context, req t.ToggleTaskReq) (res t.ToggleTaskRes) {
+	_, err := rs.Policy().Authorize(ctx, TaskUpdate, req.UserID, Target{ListID: req.ListID})
+	if err != nil {
+		err = errors.Wrap(err, "toggle task error")
+		return t.NewToggleTaskRes(nil, err, rs.Cfg())
+	}
+
+	task, err := rs.Repo().ToggleTask(ctx, req.ListID, req.TaskID, req.UserID, req.Version)
+	if err != nil {
+		err = errors.Wrap(err, "toggle task error")
+		return t.NewToggleTaskRes(nil, err, rs.Cfg())
+	}
+
+	res = t.NewToggleTaskRes(nil, nil, rs.Cfg())
+	res.FromTask(task)
+
+	return res
+}
+
+func (rs *List) DeleteTask(ctx context.Context, req t.DeleteTaskReq) (res t.DeleteTaskRes) {
+	_, err := rs.Policy().Authorize(ctx, TaskDelete, req.UserID, Target{ListID: req.ListID})
+	if err != nil {
+		err = errors.Wrap(err, "delete task error")
+		return t.NewDeleteTaskRes(nil, err, rs.Cfg())
+	}
+
+	err = rs.Repo().DeleteTask(ctx, req.ListID, req.TaskID, req.UserID, req.Version)
+	if err != nil {
+		err = errors.Wrap(err, "delete task error")
+		return t.NewDeleteTaskRes(nil, err, rs.Cfg())
+	}
+
+	return t.NewDeleteTaskRes(nil, nil, rs.Cfg())
+}
+
+func (rs *List) GetTags(ctx context.Context, req t.GetTagsReq) (res t.GetTagsRes) {
+	_, err := rs.Policy().Authorize(ctx, TagRead, req.UserID, Target{})
+	if err != nil {
+		err = errors.Wrap(err, "get tags error")
+		return t.NewGetTagsRes(nil, err, rs.Cfg(), nil)
+	}
+
+	tags, err := rs.Repo().GetTags(ctx, req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "get tags error")
+		return t.NewGetTagsRes(nil, err, rs.Cfg(), nil)
+	}
+
+	return t.NewGetTagsRes(nil, nil, rs.Cfg(), tags)
+}
+
+func (rs *List) GetTag(ctx context.Context, req t.GetTagReq) (res t.GetTagRes) {
+	_, err := rs.Policy().Authorize(ctx, TagRead, req.UserID, Target{})
+	if err != nil {
+		err = errors.Wrap(err, "get tag error")
+		return t.NewGetTagRes(nil, err, rs.Cfg(), model.Tag{})
+	}
+
+	tag, err := rs.Repo().GetTag(ctx, req.TagID, req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "get tag error")
+		return t.NewGetTagRes(nil, err, rs.Cfg(), tag)
+	}
+
+	return t.NewGetTagRes(nil, nil, rs.Cfg(), tag)
+}
+
+func (rs *List) AttachTag(ctx context.Context, req t.AttachTagReq) (res t.AttachTagRes) {
+	// Transport to Model
+	tag := req.ToTag()
+
+	// Validate model
+	v := NewTagValidator(tag)
+
+	err := v.ValidateForAttach()
+	if err != nil {
+		return t.NewAttachTagRes(v.Errors, err, rs.Cfg())
+	}
+
+	_, err = rs.Policy().Authorize(ctx, TaskUpdate, req.UserID, Target{ListID: req.ListID})
+	if err != nil {
+		err = errors.Wrap(err, "attach tag error")
+		return t.NewAttachTagRes(nil, err, rs.Cfg())
+	}
+
+	// Persist it
+	tag, err = rs.Repo().AttachTag(ctx, req.ListID, req.TaskID, tag.Name, req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "attach tag error")
+		return t.NewAttachTagRes(nil, err, rs.Cfg())
+	}
+
+	res = t.NewAttachTagRes(nil, nil, rs.Cfg())
+	res.FromTag(tag)
+
+	return res
+}
+
+func (rs *List) DetachTag(ctx context.Context, req t.DetachTagReq) (res t.DetachTagRes) {
+	_, err := rs.Policy().Authorize(ctx, TaskUpdate, req.UserID, Target{ListID: req.ListID})
+	if err != nil {
+		err = errors.Wrap(err, "detach tag error")
+		return t.NewDetachTagRes(nil, err, rs.Cfg())
+	}
+
+	err = rs.Repo().DetachTag(ctx, req.ListID, req.TaskID, req.TagID, req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "detach tag error")
+		return t.NewDetachTagRes(nil, err, rs.Cfg())
+	}
+
+	return t.NewDetachTagRes(nil, nil, rs.Cfg())
+}
+
 func (rs *List) Repo() port.ListRepo {
 	return rs.repo
 }
+
+func (rs *List) Policy() *Policy {
+	return rs.policy
+}
+
+func (rs *List) Mailer() port.Mailer {
+	return rs.mailer
+}
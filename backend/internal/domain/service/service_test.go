@@ -0,0 +1,404 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/domain/service"
+	"github.com/vanillazen/stl/backend/internal/infra/db/sqlite"
+	repo "github.com/vanillazen/stl/backend/internal/infra/repo/sqlite"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	l "github.com/vanillazen/stl/backend/internal/sys/log"
+	t "github.com/vanillazen/stl/backend/internal/transport"
+)
+
+const (
+	assetsDir = "../../../assets"
+
+	user1ID = "0792b97b-4f88-42a8-a035-1d0aad0ae7f8"
+	user2ID = "b1c20e60-ec1c-4fae-97b9-b4d0578b0123"
+	// user3ID is an admin.
+	user3ID = "7d399e9e-9df0-4dcb-a733-3d4a8be80123"
+	list1ID = "cdc7a443-3c6a-431b-b45a-b14735953a19"
+	list2ID = "70a4a418-7b2b-4c2b-95b3-4e1656c81234"
+	task1ID = "c0d1dbdb-b65e-4c4d-8f92-7b3ed6250123"
+	noneID  = "00000000-0000-4000-8000-000000000000"
+)
+
+// testEnv holds the services backed by a migrated and seeded in-memory database.
+type testEnv struct {
+	svc     *service.List
+	userSvc *service.User
+	policy  *service.Policy
+	repo    *repo.ListRepo
+	db      *sqlite.DB
+}
+
+func newTestEnv(tt *testing.T) testEnv {
+	tt.Helper()
+
+	db, opts := newTestDB(tt)
+	r := repo.NewListRepo(db, opts...)
+	policy := service.NewPolicy(r, opts...)
+
+	return testEnv{
+		svc:     service.NewService(r, policy, nil, nil, nil, opts...),
+		userSvc: service.NewUserService(repo.NewUserRepo(db, opts...), policy, opts...),
+		policy:  policy,
+		repo:    r,
+		db:      db,
+	}
+}
+
+// addMember makes the user a member of the list with the role.
+func (env testEnv) addMember(tt *testing.T, listID, userID string, role model.Role) {
+	tt.Helper()
+
+	env.exec(tt, `INSERT INTO list_members (list_id, user_id, role, created_at, updated_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`, listID, userID, role)
+}
+
+// listVersion returns the version of a list of user 1.
+func (env testEnv) listVersion(tt *testing.T, listID string) int {
+	tt.Helper()
+
+	list, err := env.repo.GetList(context.Background(), user1ID, listID)
+	if err != nil {
+		tt.Fatalf("Test setup error: %s", err)
+	}
+
+	return list.Version
+}
+
+func (env testEnv) exec(tt *testing.T, query string, args ...any) {
+	tt.Helper()
+
+	_, err := env.db.DB().Exec(query, args...)
+	if err != nil {
+		tt.Fatalf("Test setup error: %s", err)
+	}
+}
+
+// newTestDB returns a migrated and seeded in-memory database.
+func newTestDB(tt *testing.T) (*sqlite.DB, []sys.Option) {
+	tt.Helper()
+
+	cfg := &config.Config{}
+	cfg.SetValues(map[string]string{
+		config.Key.SQLiteFilePath: ":memory:",
+	})
+
+	opts := []sys.Option{
+		sys.WithConfig(cfg),
+		sys.WithLogger(l.NewLogger("error")),
+	}
+
+	db := sqlite.NewDB(opts...)
+	err := db.Connect(context.Background())
+	if err != nil {
+		tt.Fatalf("Test setup error: %s", err)
+	}
+
+	// Every connection to :memory: opens a new empty database.
+	db.DB().SetMaxOpenConns(1)
+	tt.Cleanup(func() { _ = db.DB().Close() })
+
+	for _, file := range assetFiles(tt, "migrations") {
+		execAsset(tt, db, file, func(content string) string {
+			up := strings.Split(content, "--DOWN")[0]
+			return strings.TrimPrefix(up, "--UP\n")
+		})
+	}
+
+	for _, file := range assetFiles(tt, "seeding") {
+		execAsset(tt, db, file, func(content string) string {
+			return strings.ReplaceAll(content, "--SEED", "")
+		})
+	}
+
+	return db, opts
+}
+
+// assetFiles returns the SQL files of an assets directory, in the order they are applied.
+func assetFiles(tt *testing.T, dir string) []string {
+	tt.Helper()
+
+	files, err := filepath.Glob(filepath.Join(assetsDir, dir, "sqlite", "*.sql"))
+	if err != nil || len(files) == 0 {
+		tt.Fatalf("Test setup error: no %s found", dir)
+	}
+
+	return files
+}
+
+func execAsset(tt *testing.T, db *sqlite.DB, file string, stmts func(content string) string) {
+	tt.Helper()
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		tt.Fatalf("Test setup error: %s", err)
+	}
+
+	_, err = db.DB().Exec(stmts(string(content)))
+	if err != nil {
+		tt.Fatalf("Test setup error: %s: %s", file, err)
+	}
+}
+
+// checkErr fails the test unless err is expected, or is nil if nothing is.
+func checkErr(tt *testing.T, err, expected error) {
+	tt.Helper()
+
+	if expected == nil {
+		if err != nil {
+			tt.Fatalf("Error: expected none, got '%v'", err)
+		}
+		return
+	}
+
+	if !errors.Is(err, expected) {
+		tt.Fatalf("Error: expected '%v', got '%v'", expected, err)
+	}
+}
+
+func TestCreateList(tt *testing.T) {
+	tests := []struct {
+		name           string
+		req            t.CreateListReq
+		expectedValErr string
+		expectedErr    error
+	}{
+		{
+			name: "Valid list",
+			req:  t.CreateListReq{UserID: user1ID, Name: "Groceries", Description: "Weekly"},
+		},
+		{
+			name:           "No name",
+			req:            t.CreateListReq{UserID: user1ID, Description: "Weekly"},
+			expectedValErr: "Name",
+			expectedErr:    errors.Invalid,
+		},
+		{
+			name:           "Name too short",
+			req:            t.CreateListReq{UserID: user1ID, Name: "G"},
+			expectedValErr: "Name",
+			expectedErr:    errors.Invalid,
+		},
+		{
+			name:        "No user",
+			req:         t.CreateListReq{Name: "Groceries"},
+			expectedErr: errors.Unauthenticated,
+		},
+		{
+			name:        "Unknown user",
+			req:         t.CreateListReq{UserID: noneID, Name: "Groceries"},
+			expectedErr: port.UserNotFoundErr,
+		},
+	}
+
+	for _, test := range tests {
+		tt.Run(test.name, func(tt *testing.T) {
+			env := newTestEnv(tt)
+
+			res := env.svc.CreateList(context.Background(), test.req)
+
+			checkErr(tt, res.Err(), test.expectedErr)
+
+			if test.expectedValErr != "" {
+				if len(res.ValidationErrors()[test.expectedValErr]) == 0 {
+					tt.Fatalf("Validation errors: expected '%s', got %v", test.expectedValErr, res.ValidationErrors())
+				}
+				return
+			}
+
+			if test.expectedErr != nil {
+				return
+			}
+
+			if res.ID == "" || res.UserID != test.req.UserID || res.Name != test.req.Name ||
+				res.Description != test.req.Description || res.Role != string(model.OwnerRole) || res.Version != 1 ||
+				res.CreatedAt.IsZero() {
+				tt.Fatalf("List: unexpected payload %+v", res)
+			}
+
+			// The creator can read it
+			list, err := env.repo.GetList(context.Background(), test.req.UserID, res.ID)
+			if err != nil || list.Name != test.req.Name {
+				tt.Fatalf("Stored list: expected '%s', got '%s' (%v)", test.req.Name, list.Name, err)
+			}
+		})
+	}
+}
+
+func TestUpdateList(tt *testing.T) {
+	tests := []struct {
+		name string
+		req  t.UpdateListReq
+		// current conditions the update to the version the list is at
+		current        bool
+		expectedValErr string
+		expectedErr    error
+	}{
+		{
+			name: "Owner",
+			req:  t.UpdateListReq{UserID: user1ID, ListID: list1ID, Name: "Renamed", Description: "New"},
+		},
+		{
+			name: "Editor",
+			req:  t.UpdateListReq{UserID: user2ID, ListID: list1ID, Name: "Renamed"},
+		},
+		{
+			name:    "Current version",
+			req:     t.UpdateListReq{UserID: user1ID, ListID: list1ID, Name: "Renamed"},
+			current: true,
+		},
+		{
+			name:        "Stale version",
+			req:         t.UpdateListReq{UserID: user1ID, ListID: list1ID, Name: "Renamed", Version: 99},
+			expectedErr: port.VersionMismatchErr,
+		},
+		{
+			name:           "No name",
+			req:            t.UpdateListReq{UserID: user1ID, ListID: list1ID},
+			expectedValErr: "Name",
+			expectedErr:    errors.Invalid,
+		},
+		{
+			name:        "Viewer",
+			req:         t.UpdateListReq{UserID: user3ID, ListID: list1ID, Name: "Renamed"},
+			expectedErr: errors.Forbidden,
+		},
+		{
+			name:        "Not a member",
+			req:         t.UpdateListReq{UserID: user1ID, ListID: list2ID, Name: "Renamed"},
+			expectedErr: port.ListNotFoundErr,
+		},
+		{
+			name:        "Unknown list",
+			req:         t.UpdateListReq{UserID: user1ID, ListID: noneID, Name: "Renamed"},
+			expectedErr: port.ListNotFoundErr,
+		},
+	}
+
+	for _, test := range tests {
+		tt.Run(test.name, func(tt *testing.T) {
+			env := newTestEnv(tt)
+			env.addMember(tt, list1ID, user2ID, model.EditorRole)
+			env.addMember(tt, list1ID, user3ID, model.ViewerRole)
+			version := env.listVersion(tt, list1ID)
+			if test.current {
+				test.req.Version = version
+			}
+
+			res := env.svc.UpdateList(context.Background(), test.req)
+
+			checkErr(tt, res.Err(), test.expectedErr)
+
+			if test.expectedValErr != "" && len(res.ValidationErrors()[test.expectedValErr]) == 0 {
+				tt.Fatalf("Validation errors: expected '%s', got %v", test.expectedValErr, res.ValidationErrors())
+			}
+
+			if test.expectedErr != nil {
+				return
+			}
+
+			if res.ID != test.req.ListID || res.Name != test.req.Name || res.Description != test.req.Description ||
+				res.Version != version+1 {
+				tt.Fatalf("List: unexpected payload %+v", res)
+			}
+		})
+	}
+}
+
+func TestDeleteList(tt *testing.T) {
+	tests := []struct {
+		name        string
+		req         t.DeleteListReq
+		current     bool
+		expectedErr error
+	}{
+		{
+			name: "Owner",
+			req:  t.DeleteListReq{UserID: user1ID, ListID: list1ID},
+		},
+		{
+			name:    "Current version",
+			req:     t.DeleteListReq{UserID: user1ID, ListID: list1ID},
+			current: true,
+		},
+		{
+			name:        "Stale version",
+			req:         t.DeleteListReq{UserID: user1ID, ListID: list1ID, Version: 99},
+			expectedErr: port.VersionMismatchErr,
+		},
+		{
+			name:        "Editor",
+			req:         t.DeleteListReq{UserID: user2ID, ListID: list1ID},
+			expectedErr: errors.Forbidden,
+		},
+		{
+			name:        "Not a member",
+			req:         t.DeleteListReq{UserID: user1ID, ListID: list2ID},
+			expectedErr: port.ListNotFoundErr,
+		},
+		{
+			name:        "Unknown list",
+			req:         t.DeleteListReq{UserID: user1ID, ListID: noneID},
+			expectedErr: port.ListNotFoundErr,
+		},
+	}
+
+	for _, test := range tests {
+		tt.Run(test.name, func(tt *testing.T) {
+			env := newTestEnv(tt)
+			env.addMember(tt, list1ID, user2ID, model.EditorRole)
+			if test.current {
+				test.req.Version = env.listVersion(tt, list1ID)
+			}
+
+			res := env.svc.DeleteList(context.Background(), test.req)
+
+			checkErr(tt, res.Err(), test.expectedErr)
+
+			_, err := env.repo.GetList(context.Background(), user1ID, list1ID)
+			deleted := errors.Is(err, port.ListNotFoundErr)
+			if deleted != (test.expectedErr == nil) {
+				tt.Fatalf("List 1: expected deleted %t, got error '%v'", test.expectedErr == nil, err)
+			}
+		})
+	}
+}
+
+func TestGetTasksVersion(tt *testing.T) {
+	env := newTestEnv(tt)
+	ctx := context.Background()
+	listVersion := env.listVersion(tt, list1ID)
+
+	res := env.svc.GetTasks(ctx, t.GetTasksReq{UserID: user1ID, ListID: list1ID})
+	checkErr(tt, res.Err(), nil)
+
+	added := env.svc.AddTask(ctx, t.AddTaskReq{UserID: user1ID, ListID: list1ID, Name: "New task"})
+	checkErr(tt, added.Err(), nil)
+
+	// Adding a task is a new version of the tasks, not of the list
+	changed := env.svc.GetTasks(ctx, t.GetTasksReq{UserID: user1ID, ListID: list1ID})
+	checkErr(tt, changed.Err(), nil)
+
+	if changed.Version != res.Version+1 {
+		tt.Errorf("Tasks version: expected %d, got %d", res.Version+1, changed.Version)
+	}
+
+	if version := env.listVersion(tt, list1ID); version != listVersion {
+		tt.Errorf("List version: expected %d, got %d", listVersion, version)
+	}
+
+	res = env.svc.GetTasks(ctx, t.GetTasksReq{UserID: user2ID, ListID: list1ID})
+	checkErr(tt, res.Err(), port.ListNotFoundErr)
+}
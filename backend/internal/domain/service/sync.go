@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/validator"
+	t "github.com/vanillazen/stl/backend/internal/transport"
+)
+
+const (
+	// MaxPullChanges is the number of changes pulled at once, clients pull again while there are more.
+	MaxPullChanges = 500
+	// MaxPushMutations is the number of mutations pushed at once.
+	MaxPushMutations = 100
+)
+
+// PullChanges returns the changes made to the lists of the user after the change token, and the token to pull
+// the next ones. Tokens are the sequence number of the last change, clients only keep and send them back.
+func (rs *List) PullChanges(ctx context.Context, req t.PullChangesReq) (res t.PullChangesRes) {
+	since, err := parseChangeToken(req.Token)
+	if err != nil {
+		valErrSet := validator.ValErrorSet{}
+		valErrSet.Add("Token", validator.ValidatorMsg.NotValidErrMsg)
+		return t.NewPullChangesRes(valErrSet, err, rs.Cfg())
+	}
+
+	_, err = rs.Policy().Authorize(ctx, ListIndex, req.UserID, Target{})
+	if err != nil {
+		err = errors.Wrap(err, "pull changes error")
+		return t.NewPullChangesRes(nil, err, rs.Cfg())
+	}
+
+	changes, err := rs.Repo().GetChanges(ctx, req.UserID, since, MaxPullChanges)
+	if err != nil {
+		err = errors.Wrap(err, "pull changes error")
+		return t.NewPullChangesRes(nil, err, rs.Cfg())
+	}
+
+	res = t.NewPullChangesRes(nil, nil, rs.Cfg())
+	res.FromChangeSet(changes, changeToken(changes.Seq))
+
+	return res
+}
+
+// PushChanges applies the mutations a client made offline in order, each one on its own as the others may have
+// been applied already. Mutations made to items changed meanwhile fail with a conflict that carries their current
+// state, deleting an item that is already gone succeeds unless the user lost access to it.
+func (rs *List) PushChanges(ctx context.Context, req t.PushChangesReq) (res t.PushChangesRes) {
+	// Transport to Model
+	mutations := req.ToMutations()
+
+	if len(mutations) == 0 || len(mutations) > MaxPushMutations {
+		valErrSet := validator.ValErrorSet{}
+		valErrSet.Add("Mutations", validator.ValidatorMsg.OutOfRangeErrMsg)
+		err := errors.NewKind(errors.Invalid, "push has errors")
+		return t.NewPushChangesRes(valErrSet, err, rs.Cfg())
+	}
+
+	results := make([]t.MutationRes, len(mutations))
+	for i, m := range mutations {
+		results[i] = rs.pushMutation(ctx, m, req.UserID)
+	}
+
+	res = t.NewPushChangesRes(nil, nil, rs.Cfg())
+	res.Results = results
+
+	return res
+}
+
+// pushMutation validates and applies a mutation through the same steps as the request making the same change.
+func (rs *List) pushMutation(ctx context.Context, m model.Mutation, userID string) (res t.MutationRes) {
+	// Validate model
+	v := NewMutationValidator(m)
+
+	err := v.ValidateForPush()
+	if err != nil {
+		return t.NewMutationRes(m, v.Errors, err, rs.Cfg())
+	}
+
+	var valErrs validator.ValErrorSet
+	var list model.List
+	var task model.Task
+
+	listID := m.Task.ListID.String()
+	switch {
+	case m.Kind == model.ListChange && m.Op == model.CreateMutation:
+		list, valErrs, err = rs.createList(ctx, m.List, userID)
+
+	case m.Kind == model.ListChange && m.Op == model.UpdateMutation:
+		req := t.NewUpdateListReq(m.List)
+		req.UserID = userID
+		list, valErrs, err = rs.updateList(ctx, req)
+
+	case m.Kind == model.ListChange && m.Op == model.DeleteMutation:
+		req := t.DeleteListReq{UserID: userID, ListID: m.List.ID.String(), Version: m.List.Version}
+		deleted := rs.DeleteList(ctx, req)
+		err = deleted.Err()
+
+	case m.Kind == model.TaskChange && m.Op == model.CreateMutation:
+		task, valErrs, err = rs.addTask(ctx, listID, m.Task, userID)
+
+	case m.Kind == model.TaskChange && m.Op == model.UpdateMutation:
+		req := t.NewUpdateTaskReq(m.Task)
+		req.UserID = userID
+		task, valErrs, err = rs.updateTask(ctx, req)
+
+	case m.Kind == model.TaskChange && m.Op == model.DeleteMutation:
+		req := t.DeleteTaskReq{UserID: userID, ListID: listID, TaskID: m.Task.ID.String(), Version: m.Task.Version}
+		deleted := rs.DeleteTask(ctx, req)
+		err = deleted.Err()
+	}
+
+	if m.Op == model.DeleteMutation && rs.gone(ctx, m, err) {
+		err = nil
+	}
+
+	if err != nil {
+		err = errors.Wrapf(err, "push %s %s error", m.Op, m.Kind)
+		res = t.NewMutationRes(m, valErrs, err, rs.Cfg())
+		rs.currentItem(ctx, &res, m, err, userID)
+		return res
+	}
+
+	res = t.NewMutationRes(m, nil, nil, rs.Cfg())
+	switch {
+	case m.Op == model.DeleteMutation:
+	case m.Kind == model.ListChange:
+		res.FromList(list)
+	case m.Kind == model.TaskChange:
+		res.FromTask(task)
+	}
+
+	return res
+}
+
+// gone reports whether the item of a delete mutation was not found because it no longer exists.
+// Tasks are only reported missing from the lists the user is still a member of, the list is reported missing
+// otherwise. Lists keep an owner as long as they exist, one without members has been deleted.
+func (rs *List) gone(ctx context.Context, m model.Mutation, err error) bool {
+	switch {
+	case m.Kind == model.TaskChange:
+		return errors.Is(err, port.TaskNotFoundErr)
+
+	case errors.Is(err, port.ListNotFoundErr):
+		members, err := rs.Repo().GetMembers(ctx, m.List.ID.String())
+		return err == nil && len(members) == 0
+	}
+
+	return false
+}
+
+// currentItem sets the current state of the item of a conflicting mutation, if the user can still read it.
+func (rs *List) currentItem(ctx context.Context, res *t.MutationRes, m model.Mutation, err error, userID string) {
+	if !errors.Is(err, port.VersionMismatchErr) && !errors.Is(err, port.ListExistsErr) && !errors.Is(err, port.TaskExistsErr) {
+		return
+	}
+
+	switch m.Kind {
+	case model.ListChange:
+		list, err := rs.Repo().GetList(ctx, userID, m.List.ID.String())
+		if err == nil {
+			res.FromList(list)
+		}
+
+	case model.TaskChange:
+		task, err := rs.Repo().GetTask(ctx, m.Task.ListID.String(), m.Task.ID.String(), userID)
+		if err == nil {
+			res.FromTask(task)
+		}
+	}
+}
+
+// changeToken returns the token of a change sequence number.
+func changeToken(seq uint64) string {
+	return strconv.FormatUint(seq, 10)
+}
+
+// parseChangeToken returns the change sequence number of a token, zero for none.
+func parseChangeToken(token string) (seq uint64, err error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	seq, err = strconv.ParseUint(token, 10, 64)
+	if err != nil {
+		return 0, errors.NewKind(errors.Invalid, "invalid change token")
+	}
+
+	return seq, nil
+}
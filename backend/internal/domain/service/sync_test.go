@@ -0,0 +1,161 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	t "github.com/vanillazen/stl/backend/internal/transport"
+)
+
+func TestPushChanges(tt *testing.T) {
+	tests := []struct {
+		name         string
+		userID       string
+		mutation     t.MutationReq
+		expectedErr  error
+		expectedItem string
+	}{
+		{
+			name:         "Stale list update",
+			userID:       user1ID,
+			mutation:     t.MutationReq{Op: "update", Kind: "list", ID: list1ID, Name: "Renamed", Version: 99},
+			expectedErr:  port.VersionMismatchErr,
+			expectedItem: list1ID,
+		},
+		{
+			name:         "Stale task update",
+			userID:       user1ID,
+			mutation:     t.MutationReq{Op: "update", Kind: "task", ID: task1ID, ListID: list1ID, Name: "Renamed", Version: 99},
+			expectedErr:  port.VersionMismatchErr,
+			expectedItem: task1ID,
+		},
+		{
+			name:     "Delete of a deleted task",
+			userID:   user1ID,
+			mutation: t.MutationReq{Op: "delete", Kind: "task", ID: noneID, ListID: list1ID},
+		},
+		{
+			name:     "Delete of a deleted list",
+			userID:   user1ID,
+			mutation: t.MutationReq{Op: "delete", Kind: "list", ID: noneID},
+		},
+		{
+			name:        "Task delete by non member",
+			userID:      user2ID,
+			mutation:    t.MutationReq{Op: "delete", Kind: "task", ID: task1ID, ListID: list1ID},
+			expectedErr: port.ListNotFoundErr,
+		},
+		{
+			name:        "List delete by non member",
+			userID:      user1ID,
+			mutation:    t.MutationReq{Op: "delete", Kind: "list", ID: list2ID},
+			expectedErr: port.ListNotFoundErr,
+		},
+		{
+			name:        "List update by non member",
+			userID:      user1ID,
+			mutation:    t.MutationReq{Op: "update", Kind: "list", ID: list2ID, Name: "Renamed"},
+			expectedErr: port.ListNotFoundErr,
+		},
+	}
+
+	for _, test := range tests {
+		tt.Run(test.name, func(tt *testing.T) {
+			env := newTestEnv(tt)
+
+			res := env.svc.PushChanges(context.Background(), t.PushChangesReq{UserID: test.userID, Mutations: []t.MutationReq{test.mutation}})
+			if err := res.Err(); err != nil {
+				tt.Fatalf("Error: expected none, got '%v'", err)
+			}
+
+			result := res.Results[0]
+			checkErr(tt, result.Err(), test.expectedErr)
+
+			// Conflicts carry the item as it currently is
+			if test.expectedItem == "" {
+				return
+			}
+
+			var id string
+			var version int
+			switch {
+			case result.List != nil:
+				id, version = result.List.ID, result.List.Version
+			case result.Task != nil:
+				id, version = result.Task.ID, result.Task.Version
+			}
+
+			if id != test.expectedItem || version == 0 || version == test.mutation.Version {
+				tt.Fatalf("Item: expected current '%s', got '%s' at version %d", test.expectedItem, id, version)
+			}
+		})
+	}
+}
+
+func TestPushChangesLostAccess(tt *testing.T) {
+	env := newTestEnv(tt)
+	env.addMember(tt, list1ID, user2ID, model.EditorRole)
+	ctx := context.Background()
+
+	push := func(m t.MutationReq) error {
+		res := env.svc.PushChanges(ctx, t.PushChangesReq{UserID: user2ID, Mutations: []t.MutationReq{m}})
+		if err := res.Err(); err != nil {
+			return err
+		}
+		return res.Results[0].Err()
+	}
+
+	deleted := t.MutationReq{Op: "delete", Kind: "task", ID: noneID, ListID: list1ID}
+	checkErr(tt, push(deleted), nil)
+
+	removed := env.svc.RemoveMember(ctx, t.RemoveMemberReq{UserID: user1ID, ListID: list1ID, MemberID: user2ID})
+	if err := removed.Err(); err != nil {
+		tt.Fatalf("Test setup error: %s", err)
+	}
+
+	// The list is not disclosed, not even as gone
+	checkErr(tt, push(deleted), port.ListNotFoundErr)
+	checkErr(tt, push(t.MutationReq{Op: "delete", Kind: "task", ID: task1ID, ListID: list1ID}), port.ListNotFoundErr)
+	checkErr(tt, push(t.MutationReq{Op: "update", Kind: "task", ID: task1ID, ListID: list1ID, Name: "Renamed"}), port.ListNotFoundErr)
+}
+
+func TestPullChangesRemovedMember(tt *testing.T) {
+	env := newTestEnv(tt)
+	env.addMember(tt, list1ID, user2ID, model.EditorRole)
+	ctx := context.Background()
+
+	res := env.svc.PullChanges(ctx, t.PullChangesReq{UserID: user2ID})
+	if err := res.Err(); err != nil {
+		tt.Fatalf("Error: expected none, got '%v'", err)
+	}
+
+	if !hasChange(res.Changes, list1ID, false) {
+		tt.Fatalf("Changes: expected list '%s', got %+v", list1ID, res.Changes)
+	}
+
+	removed := env.svc.RemoveMember(ctx, t.RemoveMemberReq{UserID: user1ID, ListID: list1ID, MemberID: user2ID})
+	if err := removed.Err(); err != nil {
+		tt.Fatalf("Test setup error: %s", err)
+	}
+
+	res = env.svc.PullChanges(ctx, t.PullChangesReq{UserID: user2ID, Token: res.Token})
+	if err := res.Err(); err != nil {
+		tt.Fatalf("Error: expected none, got '%v'", err)
+	}
+
+	if !hasChange(res.Changes, list1ID, true) {
+		tt.Fatalf("Changes: expected tombstone of list '%s', got %+v", list1ID, res.Changes)
+	}
+}
+
+// hasChange reports whether the changes have one of the list, deleted or not.
+func hasChange(changes []t.Change, listID string, deleted bool) bool {
+	for _, c := range changes {
+		if c.Kind == string(model.ListChange) && c.ID == listID && c.Deleted == deleted {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/password"
+	"github.com/vanillazen/stl/backend/internal/sys/validator"
+	t "github.com/vanillazen/stl/backend/internal/transport"
+)
+
+type (
+	UserService interface {
+		sys.Core
+		RegisterUser(ctx context.Context, req t.RegisterUserReq) t.RegisterUserRes
+		GetUser(ctx context.Context, req t.GetUserReq) t.GetUserRes
+		FindUser(ctx context.Context, req t.FindUserReq) t.FindUserRes
+	}
+
+	User struct {
+		*sys.SimpleCore
+		repo   port.UserRepo
+		policy *Policy
+	}
+)
+
+func NewUserService(rr port.UserRepo, policy *Policy, opts ...sys.Option) *User {
+	return &User{
+		SimpleCore: sys.NewCore("user-service", opts...),
+		repo:       rr,
+		policy:     policy,
+	}
+}
+
+// RegisterUser signs up a new user, it is open to anonymous callers so no action is authorized.
+func (us *User) RegisterUser(ctx context.Context, req t.RegisterUserReq) (res t.RegisterUserRes) {
+	// Transport to Model
+	user := req.ToUser()
+
+	// Validate model
+	v := NewUserValidator(user)
+
+	err := v.ValidateForRegister(req.PasswordConfirmation)
+	if err != nil {
+		return t.NewRegisterUserRes(v.Errors, err, us.Cfg())
+	}
+
+	// Username and email must be unique
+	err = us.checkAvailable(ctx, v)
+	if err != nil {
+		err = errors.Wrap(err, "register user error")
+		return t.NewRegisterUserRes(nil, err, us.Cfg())
+	}
+
+	if v.HasErrors() {
+		err = errors.NewKind(errors.Invalid, "user has errors")
+		return t.NewRegisterUserRes(v.Errors, err, us.Cfg())
+	}
+
+	// Only the hash is stored
+	user.Password, err = password.Hash(user.Password)
+	if err != nil {
+		err = errors.Wrap(err, "register user error")
+		return t.NewRegisterUserRes(nil, err, us.Cfg())
+	}
+
+	// Persist it
+	user, err = us.Repo().CreateUser(ctx, user)
+	if err != nil {
+		err = errors.Wrap(err, "register user error")
+		return t.NewRegisterUserRes(nil, err, us.Cfg())
+	}
+
+	res = t.NewRegisterUserRes(nil, nil, us.Cfg())
+	res.FromUser(user)
+
+	return res
+}
+
+func (us *User) GetUser(ctx context.Context, req t.GetUserReq) (res t.GetUserRes) {
+	_, err := us.Policy().Authorize(ctx, UserRead, req.CallerID, Target{UserID: req.UserID})
+	if err != nil {
+		err = errors.Wrap(err, "get user error")
+		return t.NewGetUserRes(nil, err, us.Cfg(), model.User{})
+	}
+
+	user, err := us.Repo().GetUser(ctx, req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "get user error")
+		return t.NewGetUserRes(nil, err, us.Cfg(), user)
+	}
+
+	return t.NewGetUserRes(nil, nil, us.Cfg(), user)
+}
+
+// FindUser looks a user up by username or email, only the user and admins can find it.
+func (us *User) FindUser(ctx context.Context, req t.FindUserReq) (res t.FindUserRes) {
+	user := req.ToUser()
+
+	_, err := us.Policy().Authorize(ctx, UserFind, req.CallerID, Target{})
+	if err != nil {
+		err = errors.Wrap(err, "find user error")
+		return t.NewFindUserRes(nil, err, us.Cfg(), user)
+	}
+
+	switch {
+	case user.Username != "":
+		user, err = us.Repo().GetUserByUsername(ctx, user.Username)
+
+	case user.Email != "":
+		user, err = us.Repo().GetUserByEmail(ctx, user.Email)
+
+	default:
+		valErrSet := validator.ValErrorSet{}
+		valErrSet.Add("Username", validator.ValidatorMsg.RequiredErrMsg)
+		err = errors.NewKind(errors.Invalid, "no username or email provided")
+		return t.NewFindUserRes(valErrSet, err, us.Cfg(), user)
+	}
+
+	if err != nil {
+		err = errors.Wrap(err, "find user error")
+		return t.NewFindUserRes(nil, err, us.Cfg(), model.User{})
+	}
+
+	// Users are read as by GetUser, others are not found so that lookups do not disclose who is registered
+	_, err = us.Policy().Authorize(ctx, UserRead, req.CallerID, Target{UserID: user.ID.String()})
+	if errors.KindOf(err) == errors.Forbidden {
+		err = port.NewUserNotFoundErr("")
+	}
+	if err != nil {
+		err = errors.Wrap(err, "find user error")
+		return t.NewFindUserRes(nil, err, us.Cfg(), model.User{})
+	}
+
+	return t.NewFindUserRes(nil, nil, us.Cfg(), user)
+}
+
+// checkAvailable adds a validation error for the username and email already in use.
+// It only returns an error if availability could not be checked.
+func (us *User) checkAvailable(ctx context.Context, v UserValidator) error {
+	_, err := us.Repo().GetUserByUsername(ctx, v.Model.Username)
+	if err == nil {
+		v.Errors["Username"] = append(v.Errors["Username"], validator.ValidatorMsg.TakenErrMsg)
+	} else if !errors.Is(err, port.UserNotFoundErr) {
+		return err
+	}
+
+	_, err = us.Repo().GetUserByEmail(ctx, v.Model.Email)
+	if err == nil {
+		v.Errors["Email"] = append(v.Errors["Email"], validator.ValidatorMsg.TakenErrMsg)
+	} else if !errors.Is(err, port.UserNotFoundErr) {
+		return err
+	}
+
+	return nil
+}
+
+func (us *User) Repo() port.UserRepo {
+	return us.repo
+}
+
+func (us *User) Policy() *Policy {
+	return us.policy
+}
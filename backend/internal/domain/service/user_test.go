@@ -0,0 +1,79 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	t "github.com/vanillazen/stl/backend/internal/transport"
+)
+
+func TestFindUser(tt *testing.T) {
+	tests := []struct {
+		name          string
+		req           t.FindUserReq
+		expectedID    string
+		expectedEmail string
+		expectedErr   error
+	}{
+		{
+			name:          "Self by username",
+			req:           t.FindUserReq{CallerID: user1ID, Username: "user1"},
+			expectedID:    user1ID,
+			expectedEmail: "user1@example.com",
+		},
+		{
+			name:          "Self by email",
+			req:           t.FindUserReq{CallerID: user1ID, Email: " User1@Example.com "},
+			expectedID:    user1ID,
+			expectedEmail: "user1@example.com",
+		},
+		{
+			name:          "Admin",
+			req:           t.FindUserReq{CallerID: user3ID, Username: "user2"},
+			expectedID:    user2ID,
+			expectedEmail: "user2@example.com",
+		},
+		{
+			name:        "Another user by username",
+			req:         t.FindUserReq{CallerID: user1ID, Username: "user2"},
+			expectedErr: port.UserNotFoundErr,
+		},
+		{
+			name:        "Another user by email",
+			req:         t.FindUserReq{CallerID: user1ID, Email: "user3@example.com"},
+			expectedErr: port.UserNotFoundErr,
+		},
+		{
+			name:        "Unknown user",
+			req:         t.FindUserReq{CallerID: user3ID, Username: "nobody"},
+			expectedErr: port.UserNotFoundErr,
+		},
+		{
+			name:        "No username or email",
+			req:         t.FindUserReq{CallerID: user1ID},
+			expectedErr: errors.Invalid,
+		},
+		{
+			name:        "No caller",
+			req:         t.FindUserReq{Username: "user1"},
+			expectedErr: errors.Unauthenticated,
+		},
+	}
+
+	env := newTestEnv(tt)
+
+	for _, test := range tests {
+		tt.Run(test.name, func(tt *testing.T) {
+			res := env.userSvc.FindUser(context.Background(), test.req)
+
+			checkErr(tt, res.Err(), test.expectedErr)
+
+			// Nothing about the user is disclosed on errors
+			if res.ID != test.expectedID || res.Email != test.expectedEmail {
+				tt.Fatalf("User: expected '%s' '%s', got '%s' '%s'", test.expectedID, test.expectedEmail, res.ID, res.Email)
+			}
+		})
+	}
+}
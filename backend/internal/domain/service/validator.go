@@ -1,9 +1,12 @@
 package service
 
 import (
-	"errors"
+	"math"
+	"net/url"
 
 	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
 	"github.com/vanillazen/stl/backend/internal/sys/validator"
 )
 
@@ -30,11 +33,19 @@ func (v ListValidator) ValidateForCreate() error {
 		return nil
 	}
 
-	return errors.New("list has errors")
+	return errors.NewKind(errors.Invalid, "list has errors")
 }
 
 func (v ListValidator) ValidateForUpdate() error {
-	return errors.New("not implemented yet")
+	// Name
+	ok0 := v.ValidateRequiredName()
+	ok1 := v.ValidateMinLengthName(2)
+
+	if ok0 && ok1 {
+		return nil
+	}
+
+	return errors.NewKind(errors.Invalid, "list has errors")
 }
 
 func (v ListValidator) ValidateRequiredName(errMsg ...string) (ok bool) {
@@ -87,3 +98,921 @@ func (v ListValidator) ValidateMaxLengthName(max int, errMsg ...string) (ok bool
 	v.Errors["Name"] = append(v.Errors["Name"], msg)
 	return false
 }
+
+type (
+	TaskValidator struct {
+		validator.Validator
+		Model model.Task
+	}
+)
+
+func NewTaskValidator(m model.Task) TaskValidator {
+	return TaskValidator{
+		Validator: validator.NewValidator(),
+		Model:     m,
+	}
+}
+
+func (v TaskValidator) ValidateForCreate() error {
+	// Name
+	ok0 := v.ValidateRequiredName()
+	ok1 := v.ValidateMinLengthName(2)
+	// Ranking
+	ok2 := v.ValidatePriority()
+	ok3 := v.ValidatePosition()
+	// Labels
+	ok4 := v.ValidateLabels()
+
+	if ok0 && ok1 && ok2 && ok3 && ok4 {
+		return nil
+	}
+
+	return errors.NewKind(errors.Invalid, "task has errors")
+}
+
+func (v TaskValidator) ValidateForUpdate() error {
+	// Name
+	ok0 := v.ValidateRequiredName()
+	ok1 := v.ValidateMinLengthName(2)
+	// Ranking
+	ok2 := v.ValidatePriority()
+	ok3 := v.ValidatePosition()
+	// Labels
+	ok4 := v.ValidateLabels()
+
+	if ok0 && ok1 && ok2 && ok3 && ok4 {
+		return nil
+	}
+
+	return errors.NewKind(errors.Invalid, "task has errors")
+}
+
+func (v TaskValidator) ValidateRequiredName(errMsg ...string) (ok bool) {
+	task := v.Model
+
+	ok = v.ValidateRequired(task.Name)
+	if ok {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.RequiredErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Name"] = append(v.Errors["Name"], msg)
+	return false
+}
+
+func (v TaskValidator) ValidateMinLengthName(min int, errMsg ...string) (ok bool) {
+	m := v.Model
+
+	ok = v.ValidateMinLength(m.Name, min)
+	if ok {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.MinLengthErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Name"] = append(v.Errors["Name"], msg)
+	return false
+}
+
+func (v TaskValidator) ValidatePriority(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	ok = m.Priority.Valid()
+	if ok {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.NotAllowedErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Priority"] = append(v.Errors["Priority"], msg)
+	return false
+}
+
+// ValidatePosition accepts zero as a request to keep or assign the default position.
+func (v TaskValidator) ValidatePosition(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	ok = v.ValidateRange(m.Position, 0, math.MaxInt32)
+	if ok {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.OutOfRangeErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Position"] = append(v.Errors["Position"], msg)
+	return false
+}
+
+// ValidateLabels checks that no category, tag or location has an empty name.
+func (v TaskValidator) ValidateLabels(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	msg := validator.ValidatorMsg.RequiredErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	ok = true
+	for field, names := range map[string][]string{
+		"Category": m.Category,
+		"Tags":     m.Tags,
+		"Location": m.Location,
+	} {
+		for _, name := range names {
+			if !v.ValidateRequired(name) {
+				v.Errors[field] = append(v.Errors[field], msg)
+				ok = false
+				break
+			}
+		}
+	}
+
+	return ok
+}
+
+type (
+	// TaskOpValidator checks an operation of a task batch, the task of creations and updates
+	// goes through the TaskValidator and its errors are reported under the same fields.
+	TaskOpValidator struct {
+		validator.Validator
+		Model model.TaskOp
+	}
+)
+
+func NewTaskOpValidator(m model.TaskOp) TaskOpValidator {
+	return TaskOpValidator{
+		Validator: validator.NewValidator(),
+		Model:     m,
+	}
+}
+
+func (v TaskOpValidator) ValidateForBatch() error {
+	// Kind
+	ok := v.ValidateKind()
+
+	// Task
+	task := v.taskValidator()
+
+	switch v.Model.Kind {
+	case model.CreateTaskOp:
+		ok = task.ValidateForCreate() == nil && ok
+	case model.UpdateTaskOp:
+		ok = v.ValidateTaskID() && ok
+		ok = task.ValidateForUpdate() == nil && ok
+	case model.DeleteTaskOp:
+		ok = v.ValidateTaskID() && ok
+	case model.MoveTaskOp:
+		ok = v.ValidateTaskID() && ok
+		ok = v.ValidateToListID() && ok
+		ok = task.ValidatePosition() && ok
+	}
+
+	if ok {
+		return nil
+	}
+
+	return errors.NewKind(errors.Invalid, "task operation has errors")
+}
+
+func (v TaskOpValidator) ValidateKind(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	if m.Kind.Valid() {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.NotAllowedErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Op"] = append(v.Errors["Op"], msg)
+	return false
+}
+
+func (v TaskOpValidator) ValidateTaskID(errMsg ...string) (ok bool) {
+	return v.validateID("TaskID", v.Model.Task.ID.String(), errMsg...)
+}
+
+func (v TaskOpValidator) ValidateToListID(errMsg ...string) (ok bool) {
+	return v.validateID("ToListID", v.Model.ToListID, errMsg...)
+}
+
+func (v TaskOpValidator) validateID(field, id string, errMsg ...string) (ok bool) {
+	if uuid.Validate(id) {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.NotValidErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors[field] = append(v.Errors[field], msg)
+	return false
+}
+
+// taskValidator returns a validator of the task of the operation that reports to the same errors.
+func (v TaskOpValidator) taskValidator() TaskValidator {
+	return TaskValidator{
+		Validator: v.Validator,
+		Model:     v.Model.Task,
+	}
+}
+
+type (
+	// MutationValidator checks a mutation pushed by a client, the list or task it creates or updates goes through
+	// the ListValidator or TaskValidator and their errors are reported under the same fields.
+	MutationValidator struct {
+		validator.Validator
+		Model model.Mutation
+	}
+)
+
+func NewMutationValidator(m model.Mutation) MutationValidator {
+	return MutationValidator{
+		Validator: validator.NewValidator(),
+		Model:     m,
+	}
+}
+
+func (v MutationValidator) ValidateForPush() error {
+	m := v.Model
+
+	// Op and kind
+	ok := v.ValidateOp()
+	ok = v.ValidateKind() && ok
+
+	// Item, created ones may have no ID yet
+	id := m.List.ID.String()
+	if m.Kind == model.TaskChange {
+		id = m.Task.ID.String()
+		ok = v.validateID("ListID", m.Task.ListID.String()) && ok
+	}
+
+	if m.Op != model.CreateMutation || id != "" {
+		ok = v.validateID("ID", id) && ok
+	}
+
+	var itemErr error
+	switch {
+	case m.Kind == model.ListChange && m.Op == model.CreateMutation:
+		itemErr = v.listValidator().ValidateForCreate()
+	case m.Kind == model.ListChange && m.Op == model.UpdateMutation:
+		itemErr = v.listValidator().ValidateForUpdate()
+	case m.Kind == model.TaskChange && m.Op == model.CreateMutation:
+		itemErr = v.taskValidator().ValidateForCreate()
+	case m.Kind == model.TaskChange && m.Op == model.UpdateMutation:
+		itemErr = v.taskValidator().ValidateForUpdate()
+	}
+
+	if ok && itemErr == nil {
+		return nil
+	}
+
+	return errors.NewKind(errors.Invalid, "mutation has errors")
+}
+
+func (v MutationValidator) ValidateOp(errMsg ...string) (ok bool) {
+	return v.validateValid("Op", v.Model.Op.Valid(), errMsg...)
+}
+
+func (v MutationValidator) ValidateKind(errMsg ...string) (ok bool) {
+	return v.validateValid("Kind", v.Model.Kind.Valid(), errMsg...)
+}
+
+func (v MutationValidator) validateValid(field string, valid bool, errMsg ...string) (ok bool) {
+	if valid {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.NotAllowedErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors[field] = append(v.Errors[field], msg)
+	return false
+}
+
+func (v MutationValidator) validateID(field, id string, errMsg ...string) (ok bool) {
+	if uuid.Validate(id) {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.NotValidErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors[field] = append(v.Errors[field], msg)
+	return false
+}
+
+// listValidator returns a validator of the list of the mutation that reports to the same errors.
+func (v MutationValidator) listValidator() ListValidator {
+	return ListValidator{
+		Validator: v.Validator,
+		Model:     v.Model.List,
+	}
+}
+
+// taskValidator returns a validator of the task of the mutation that reports to the same errors.
+func (v MutationValidator) taskValidator() TaskValidator {
+	return TaskValidator{
+		Validator: v.Validator,
+		Model:     v.Model.Task,
+	}
+}
+
+type (
+	TagValidator struct {
+		validator.Validator
+		Model model.Tag
+	}
+)
+
+func NewTagValidator(m model.Tag) TagValidator {
+	return TagValidator{
+		Validator: validator.NewValidator(),
+		Model:     m,
+	}
+}
+
+func (v TagValidator) ValidateForAttach() error {
+	// Name
+	ok0 := v.ValidateRequiredName()
+	ok1 := v.ValidateMaxLengthName(64)
+
+	if ok0 && ok1 {
+		return nil
+	}
+
+	return errors.NewKind(errors.Invalid, "tag has errors")
+}
+
+func (v TagValidator) ValidateRequiredName(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	ok = v.ValidateRequired(m.Name)
+	if ok {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.RequiredErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Name"] = append(v.Errors["Name"], msg)
+	return false
+}
+
+func (v TagValidator) ValidateMaxLengthName(max int, errMsg ...string) (ok bool) {
+	m := v.Model
+
+	ok = v.ValidateMaxLength(m.Name, max)
+	if ok {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.MaxLengthErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Name"] = append(v.Errors["Name"], msg)
+	return false
+}
+
+type (
+	UserValidator struct {
+		validator.Validator
+		Model model.User
+	}
+)
+
+func NewUserValidator(m model.User) UserValidator {
+	return UserValidator{
+		Validator: validator.NewValidator(),
+		Model:     m,
+	}
+}
+
+func (v UserValidator) ValidateForRegister(passwordConfirmation string) error {
+	// Username
+	ok0 := v.ValidateRequiredUsername()
+	ok1 := v.ValidateUsernameFormat(3, 32)
+	// Name
+	ok2 := v.ValidateRequiredName()
+	// Email
+	ok3 := v.ValidateRequiredEmail()
+	ok4 := v.ValidateEmailFormat()
+	// Password
+	ok5 := v.ValidatePassword(8, 128)
+	ok6 := v.ValidatePasswordConfirmation(passwordConfirmation)
+
+	if ok0 && ok1 && ok2 && ok3 && ok4 && ok5 && ok6 {
+		return nil
+	}
+
+	return errors.NewKind(errors.Invalid, "user has errors")
+}
+
+func (v UserValidator) ValidateRequiredUsername(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	ok = v.ValidateRequired(m.Username)
+	if ok {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.RequiredErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Username"] = append(v.Errors["Username"], msg)
+	return false
+}
+
+// ValidateUsernameFormat checks length and allowed characters, an empty username is left to ValidateRequiredUsername.
+func (v UserValidator) ValidateUsernameFormat(min, max int) (ok bool) {
+	m := v.Model
+
+	if m.Username == "" {
+		return true
+	}
+
+	if !v.ValidateMinLength(m.Username, min) {
+		v.Errors["Username"] = append(v.Errors["Username"], validator.ValidatorMsg.MinLengthErrMsg)
+		return false
+	}
+
+	if !v.ValidateMaxLength(m.Username, max) {
+		v.Errors["Username"] = append(v.Errors["Username"], validator.ValidatorMsg.MaxLengthErrMsg)
+		return false
+	}
+
+	if !v.ValidateUsername(m.Username) {
+		v.Errors["Username"] = append(v.Errors["Username"], validator.ValidatorMsg.NotUsernameErrMsg)
+		return false
+	}
+
+	return true
+}
+
+func (v UserValidator) ValidateRequiredName(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	ok = v.ValidateRequired(m.Name)
+	if ok {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.RequiredErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Name"] = append(v.Errors["Name"], msg)
+	return false
+}
+
+func (v UserValidator) ValidateRequiredEmail(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	ok = v.ValidateRequired(m.Email)
+	if ok {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.RequiredErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Email"] = append(v.Errors["Email"], msg)
+	return false
+}
+
+// ValidateEmailFormat checks the email address, an empty one is left to ValidateRequiredEmail.
+func (v UserValidator) ValidateEmailFormat(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	if m.Email == "" || v.ValidateEmail(m.Email) {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.NotEmailErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Email"] = append(v.Errors["Email"], msg)
+	return false
+}
+
+func (v UserValidator) ValidatePassword(min, max int) (ok bool) {
+	m := v.Model
+
+	if m.Password == "" {
+		v.Errors["Password"] = append(v.Errors["Password"], validator.ValidatorMsg.RequiredErrMsg)
+		return false
+	}
+
+	if !v.ValidateMinLength(m.Password, min) {
+		v.Errors["Password"] = append(v.Errors["Password"], validator.ValidatorMsg.MinLengthErrMsg)
+		return false
+	}
+
+	if !v.ValidateMaxLength(m.Password, max) {
+		v.Errors["Password"] = append(v.Errors["Password"], validator.ValidatorMsg.MaxLengthErrMsg)
+		return false
+	}
+
+	return true
+}
+
+func (v UserValidator) ValidatePasswordConfirmation(confirmation string, errMsg ...string) (ok bool) {
+	m := v.Model
+
+	ok = v.ValidateConfirmation(m.Password, confirmation)
+	if ok {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.NoMatchErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["PasswordConfirmation"] = append(v.Errors["PasswordConfirmation"], msg)
+	return false
+}
+
+type (
+	APIKeyValidator struct {
+		validator.Validator
+		Model model.APIKey
+	}
+)
+
+func NewAPIKeyValidator(m model.APIKey) APIKeyValidator {
+	return APIKeyValidator{
+		Validator: validator.NewValidator(),
+		Model:     m,
+	}
+}
+
+func (v APIKeyValidator) ValidateForCreate() error {
+	// Name
+	ok0 := v.ValidateRequiredName()
+	ok1 := v.ValidateMaxLengthName(64)
+	// Scope
+	ok2 := v.ValidateScope()
+
+	if ok0 && ok1 && ok2 {
+		return nil
+	}
+
+	return errors.NewKind(errors.Invalid, "api key has errors")
+}
+
+func (v APIKeyValidator) ValidateRequiredName(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	ok = v.ValidateRequired(m.Name)
+	if ok {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.RequiredErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Name"] = append(v.Errors["Name"], msg)
+	return false
+}
+
+func (v APIKeyValidator) ValidateMaxLengthName(max int, errMsg ...string) (ok bool) {
+	m := v.Model
+
+	ok = v.ValidateMaxLength(m.Name, max)
+	if ok {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.MaxLengthErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Name"] = append(v.Errors["Name"], msg)
+	return false
+}
+
+func (v APIKeyValidator) ValidateScope(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	for _, scope := range model.Scopes {
+		if m.Scope == scope {
+			return true
+		}
+	}
+
+	msg := validator.ValidatorMsg.NotAllowedErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Scope"] = append(v.Errors["Scope"], msg)
+	return false
+}
+
+type (
+	MemberValidator struct {
+		validator.Validator
+		Model model.Member
+	}
+)
+
+func NewMemberValidator(m model.Member) MemberValidator {
+	return MemberValidator{
+		Validator: validator.NewValidator(),
+		Model:     m,
+	}
+}
+
+func (v MemberValidator) ValidateForUpdate() error {
+	// Role
+	ok0 := v.ValidateRole()
+
+	if ok0 {
+		return nil
+	}
+
+	return errors.NewKind(errors.Invalid, "member has errors")
+}
+
+func (v MemberValidator) ValidateRole(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	if m.Role.Valid() {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.NotAllowedErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Role"] = append(v.Errors["Role"], msg)
+	return false
+}
+
+type (
+	InvitationValidator struct {
+		validator.Validator
+		Model model.Invitation
+	}
+)
+
+func NewInvitationValidator(m model.Invitation) InvitationValidator {
+	return InvitationValidator{
+		Validator: validator.NewValidator(),
+		Model:     m,
+	}
+}
+
+func (v InvitationValidator) ValidateForCreate() error {
+	// Email
+	ok0 := v.ValidateRequiredEmail()
+	ok1 := v.ValidateEmailFormat()
+	// Role
+	ok2 := v.ValidateRole()
+
+	if ok0 && ok1 && ok2 {
+		return nil
+	}
+
+	return errors.NewKind(errors.Invalid, "invitation has errors")
+}
+
+func (v InvitationValidator) ValidateRequiredEmail(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	ok = v.ValidateRequired(m.Email)
+	if ok {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.RequiredErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Email"] = append(v.Errors["Email"], msg)
+	return false
+}
+
+// ValidateEmailFormat checks the email address, an empty one is left to ValidateRequiredEmail.
+func (v InvitationValidator) ValidateEmailFormat(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	if m.Email == "" || v.ValidateEmail(m.Email) {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.NotEmailErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Email"] = append(v.Errors["Email"], msg)
+	return false
+}
+
+func (v InvitationValidator) ValidateRole(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	if m.Role.Valid() {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.NotAllowedErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Role"] = append(v.Errors["Role"], msg)
+	return false
+}
+
+type (
+	WebhookValidator struct {
+		validator.Validator
+		Model model.Webhook
+		// AllowPrivate accepts URLs of local and private hosts, only meant for development and tests.
+		AllowPrivate bool
+	}
+)
+
+func NewWebhookValidator(m model.Webhook) WebhookValidator {
+	return WebhookValidator{
+		Validator: validator.NewValidator(),
+		Model:     m,
+	}
+}
+
+func (v WebhookValidator) ValidateForCreate() error {
+	// URL
+	ok0 := v.ValidateRequiredURL()
+	ok1 := v.ValidateMaxLengthURL(2048)
+	ok2 := v.ValidateURLFormat()
+	// Events
+	ok3 := v.ValidateEvents()
+	// ListID
+	ok4 := v.ValidateListID()
+	// Secret
+	ok5 := v.ValidateMaxLengthSecret(128)
+
+	if ok0 && ok1 && ok2 && ok3 && ok4 && ok5 {
+		return nil
+	}
+
+	return errors.NewKind(errors.Invalid, "webhook has errors")
+}
+
+func (v WebhookValidator) ValidateRequiredURL(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	ok = v.ValidateRequired(m.URL)
+	if ok {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.RequiredErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["URL"] = append(v.Errors["URL"], msg)
+	return false
+}
+
+func (v WebhookValidator) ValidateMaxLengthURL(max int, errMsg ...string) (ok bool) {
+	m := v.Model
+
+	ok = v.ValidateMaxLength(m.URL, max)
+	if ok {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.MaxLengthErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["URL"] = append(v.Errors["URL"], msg)
+	return false
+}
+
+// ValidateURLFormat only allows absolute HTTP and HTTPS URLs, an empty one is left to ValidateRequiredURL.
+// Unless AllowPrivate is set, URLs of localhost and of loopback, private, link-local and other internal addresses
+// are rejected as well, so that webhooks cannot be used to reach the network of the service.
+func (v WebhookValidator) ValidateURLFormat(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	if m.URL == "" {
+		return true
+	}
+
+	u, err := url.Parse(m.URL)
+	if err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Hostname() != "" &&
+		(v.AllowPrivate || model.WebhookHostAllowed(u.Hostname())) {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.NotValidErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["URL"] = append(v.Errors["URL"], msg)
+	return false
+}
+
+// ValidateEvents requires at least one event type, all of them among the ones webhooks can subscribe to.
+func (v WebhookValidator) ValidateEvents(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	if len(m.Events) == 0 {
+		v.Errors["Events"] = append(v.Errors["Events"], validator.ValidatorMsg.RequiredErrMsg)
+		return false
+	}
+
+	for _, typ := range m.Events {
+		if typ.Webhook() {
+			continue
+		}
+
+		msg := validator.ValidatorMsg.NotAllowedErrMsg
+		if len(errMsg) > 0 {
+			msg = errMsg[0]
+		}
+
+		v.Errors["Events"] = append(v.Errors["Events"], msg)
+		return false
+	}
+
+	return true
+}
+
+// ValidateListID checks the list of the webhook if it has one, webhooks without list get the events of all lists.
+func (v WebhookValidator) ValidateListID(errMsg ...string) (ok bool) {
+	m := v.Model
+
+	if m.ListID.String() == "" || uuid.Validate(m.ListID.String()) {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.NotValidErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["ListID"] = append(v.Errors["ListID"], msg)
+	return false
+}
+
+func (v WebhookValidator) ValidateMaxLengthSecret(max int, errMsg ...string) (ok bool) {
+	m := v.Model
+
+	ok = v.ValidateMaxLength(m.Secret, max)
+	if ok {
+		return true
+	}
+
+	msg := validator.ValidatorMsg.MaxLengthErrMsg
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+
+	v.Errors["Secret"] = append(v.Errors["Secret"], msg)
+	return false
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/token"
+	t "github.com/vanillazen/stl/backend/internal/transport"
+)
+
+// MaxDeliveries is the number of entries of the delivery log of a webhook returned at most, latest first.
+const MaxDeliveries = 100
+
+type (
+	WebhookService interface {
+		sys.Core
+		CreateWebhook(ctx context.Context, req t.CreateWebhookReq) t.CreateWebhookRes
+		GetWebhooks(ctx context.Context, req t.GetWebhooksReq) t.GetWebhooksRes
+		GetWebhook(ctx context.Context, req t.GetWebhookReq) t.GetWebhookRes
+		DeleteWebhook(ctx context.Context, req t.DeleteWebhookReq) t.DeleteWebhookRes
+		GetDeliveries(ctx context.Context, req t.GetDeliveriesReq) t.GetDeliveriesRes
+		Redeliver(ctx context.Context, req t.RedeliverReq) t.RedeliverRes
+	}
+
+	// Webhook manages the webhooks of users, the events they get are queued by the list service.
+	Webhook struct {
+		*sys.SimpleCore
+		repo   port.WebhookRepo
+		policy *Policy
+	}
+)
+
+func NewWebhookService(rr port.WebhookRepo, policy *Policy, opts ...sys.Option) *Webhook {
+	return &Webhook{
+		SimpleCore: sys.NewCore("webhook-service", opts...),
+		repo:       rr,
+		policy:     policy,
+	}
+}
+
+// CreateWebhook subscribes a URL to the events of the lists of the user, or to those of one of them.
+// Webhooks of a single list can only be created by its editors.
+func (ws *Webhook) CreateWebhook(ctx context.Context, req t.CreateWebhookReq) (res t.CreateWebhookRes) {
+	// Transport to Model
+	webhook := req.ToWebhook()
+
+	// Validate model
+	v := NewWebhookValidator(webhook)
+	if cfg := ws.Cfg(); cfg != nil {
+		v.AllowPrivate = cfg.GetBool(config.Key.WebhooksAllowPrivate)
+	}
+
+	err := v.ValidateForCreate()
+	if err != nil {
+		return t.NewCreateWebhookRes(v.Errors, err, ws.Cfg())
+	}
+
+	_, err = ws.Policy().Authorize(ctx, WebhookManage, req.UserID, Target{})
+	if err == nil && webhook.ListID.String() != "" {
+		_, err = ws.Policy().Authorize(ctx, ListWebhook, req.UserID, Target{ListID: webhook.ListID.String()})
+	}
+	if err != nil {
+		err = errors.Wrap(err, "create webhook error")
+		return t.NewCreateWebhookRes(nil, err, ws.Cfg())
+	}
+
+	if webhook.Secret == "" {
+		webhook.Secret, err = token.NewWebhookSecret()
+		if err != nil {
+			err = errors.Wrap(err, "create webhook error")
+			return t.NewCreateWebhookRes(nil, err, ws.Cfg())
+		}
+	}
+
+	// Persist it
+	webhook, err = ws.Repo().CreateWebhook(ctx, webhook)
+	if err != nil {
+		err = errors.Wrap(err, "create webhook error")
+		return t.NewCreateWebhookRes(nil, err, ws.Cfg())
+	}
+
+	res = t.NewCreateWebhookRes(nil, nil, ws.Cfg())
+	res.FromWebhook(webhook)
+
+	return res
+}
+
+func (ws *Webhook) GetWebhooks(ctx context.Context, req t.GetWebhooksReq) (res t.GetWebhooksRes) {
+	_, err := ws.Policy().Authorize(ctx, WebhookManage, req.UserID, Target{})
+	if err != nil {
+		err = errors.Wrap(err, "get webhooks error")
+		return t.NewGetWebhooksRes(nil, err, ws.Cfg(), nil)
+	}
+
+	webhooks, err := ws.Repo().GetWebhooks(ctx, req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "get webhooks error")
+		return t.NewGetWebhooksRes(nil, err, ws.Cfg(), webhooks)
+	}
+
+	return t.NewGetWebhooksRes(nil, nil, ws.Cfg(), webhooks)
+}
+
+func (ws *Webhook) GetWebhook(ctx context.Context, req t.GetWebhookReq) (res t.GetWebhookRes) {
+	_, err := ws.Policy().Authorize(ctx, WebhookManage, req.UserID, Target{})
+	if err != nil {
+		err = errors.Wrap(err, "get webhook error")
+		return t.NewGetWebhookRes(nil, err, ws.Cfg(), model.Webhook{})
+	}
+
+	webhook, err := ws.Repo().GetWebhook(ctx, req.WebhookID, req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "get webhook error")
+		return t.NewGetWebhookRes(nil, err, ws.Cfg(), webhook)
+	}
+
+	return t.NewGetWebhookRes(nil, nil, ws.Cfg(), webhook)
+}
+
+// DeleteWebhook stops the deliveries to a webhook, pending ones are dropped along with its delivery log.
+func (ws *Webhook) DeleteWebhook(ctx context.Context, req t.DeleteWebhookReq) (res t.DeleteWebhookRes) {
+	_, err := ws.Policy().Authorize(ctx, WebhookManage, req.UserID, Target{})
+	if err != nil {
+		err = errors.Wrap(err, "delete webhook error")
+		return t.NewDeleteWebhookRes(nil, err, ws.Cfg())
+	}
+
+	err = ws.Repo().DeleteWebhook(ctx, req.WebhookID, req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "delete webhook error")
+		return t.NewDeleteWebhookRes(nil, err, ws.Cfg())
+	}
+
+	return t.NewDeleteWebhookRes(nil, nil, ws.Cfg())
+}
+
+// GetDeliveries returns the delivery log of a webhook, the last MaxDeliveries entries.
+func (ws *Webhook) GetDeliveries(ctx context.Context, req t.GetDeliveriesReq) (res t.GetDeliveriesRes) {
+	_, err := ws.Policy().Authorize(ctx, WebhookManage, req.UserID, Target{})
+	if err != nil {
+		err = errors.Wrap(err, "get deliveries error")
+		return t.NewGetDeliveriesRes(nil, err, ws.Cfg(), nil)
+	}
+
+	deliveries, err := ws.Repo().GetDeliveries(ctx, req.WebhookID, req.UserID, MaxDeliveries)
+	if err != nil {
+		err = errors.Wrap(err, "get deliveries error")
+		return t.NewGetDeliveriesRes(nil, err, ws.Cfg(), deliveries)
+	}
+
+	return t.NewGetDeliveriesRes(nil, nil, ws.Cfg(), deliveries)
+}
+
+// Redeliver queues the event of a delivery again, as a new delivery sent right away.
+func (ws *Webhook) Redeliver(ctx context.Context, req t.RedeliverReq) (res t.RedeliverRes) {
+	_, err := ws.Policy().Authorize(ctx, WebhookManage, req.UserID, Target{})
+	if err != nil {
+		err = errors.Wrap(err, "redeliver error")
+		return t.NewRedeliverRes(nil, err, ws.Cfg())
+	}
+
+	delivery, err := ws.Repo().Redeliver(ctx, req.DeliveryID, req.WebhookID, req.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "redeliver error")
+		return t.NewRedeliverRes(nil, err, ws.Cfg())
+	}
+
+	res = t.NewRedeliverRes(nil, nil, ws.Cfg())
+	res.FromDelivery(delivery)
+
+	return res
+}
+
+func (ws *Webhook) Repo() port.WebhookRepo {
+	return ws.repo
+}
+
+func (ws *Webhook) Policy() *Policy {
+	return ws.policy
+}
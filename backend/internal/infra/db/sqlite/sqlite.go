@@ -39,7 +39,8 @@ func (db *DB) Start(ctx context.Context) error {
 
 func (db *DB) Connect(ctx context.Context) error {
 	// TODO: Make journaling mode configurable (i.e.: "?_journal_mode=WAL")
-	sqlDB, err := sql.Open("sqlite3", db.Path())
+	// Foreign keys are enforced so that ON DELETE CASCADE clauses take effect.
+	sqlDB, err := sql.Open("sqlite3", db.Path()+"?_foreign_keys=on")
 	if err != nil {
 		msg := fmt.Sprintf("%s connection error", db.Name())
 		return errors.Wrap(err, msg)
@@ -51,11 +52,32 @@ func (db *DB) Connect(ctx context.Context) error {
 		return errors.Wrap(err, msg)
 	}
 
+	err = checkFTS5(sqlDB)
+	if err != nil {
+		return errors.Wrapf(err, "%s connection error", db.Name())
+	}
+
 	db.db = sqlDB
 	db.Log().Infof("%s database connected", db.Name())
 	return nil
 }
 
+// checkFTS5 fails if SQLite was built without full-text search, which the search migration requires.
+// go-sqlite3 only compiles it in with the sqlite_fts5 build tag.
+func checkFTS5(sqlDB *sql.DB) error {
+	var enabled bool
+	err := sqlDB.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&enabled)
+	if err != nil {
+		return errors.Wrap(err, "FTS5 check error")
+	}
+
+	if !enabled {
+		return errors.New("SQLite built without FTS5, build with the sqlite_fts5 tag (go build -tags sqlite_fts5)")
+	}
+
+	return nil
+}
+
 func (db *DB) DBConn(ctx context.Context) (*sql.DB, error) {
 	return db.db, nil
 }
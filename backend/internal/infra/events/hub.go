@@ -0,0 +1,174 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+)
+
+const (
+	defaultLogSize = 1000
+	// subscriptionBuffer is the number of events a subscriber can fall behind before it is dropped.
+	subscriptionBuffer = 64
+)
+
+type (
+	// Hub is an in-process EventHub, events are only delivered to subscribers of the same process.
+	// The last events are kept in a bounded log, so that subscribers can resume after a reconnection.
+	Hub struct {
+		*sys.SimpleCore
+		mu      sync.Mutex
+		seq     uint64
+		log     []model.Event
+		size    int
+		evicted uint64
+		subs    map[*subscription]struct{}
+		closed  bool
+	}
+
+	subscription struct {
+		hub    *Hub
+		listID string
+		userID string
+		events chan model.Event
+	}
+)
+
+func NewHub(opts ...sys.Option) *Hub {
+	h := &Hub{
+		SimpleCore: sys.NewCore("event-hub", opts...),
+		size:       defaultLogSize,
+		subs:       map[*subscription]struct{}{},
+	}
+
+	if cfg := h.Cfg(); cfg != nil {
+		if size := cfg.GetInt(config.Key.EventsLogSize); size > 0 {
+			h.size = size
+		}
+	}
+
+	return h
+}
+
+// Start blocks until the context is done, then it ends all subscriptions.
+func (h *Hub) Start(ctx context.Context) error {
+	<-ctx.Done()
+	return h.Stop(ctx)
+}
+
+// Stop ends all subscriptions, events published afterwards are only logged.
+func (h *Hub) Stop(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for sub := range h.subs {
+		h.drop(sub)
+	}
+
+	return nil
+}
+
+func (h *Hub) Publish(ctx context.Context, e model.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	e.ID = h.seq
+
+	if len(h.log) == h.size {
+		h.evicted = h.log[0].ID
+		h.log = append(h.log[:0], h.log[1:]...)
+	}
+	h.log = append(h.log, e)
+
+	for sub := range h.subs {
+		if sub.listID != e.ListID {
+			continue
+		}
+
+		select {
+		case sub.events <- e:
+		default:
+			h.Log().Infof("%s dropped a subscriber of list %s falling behind", h.Name(), sub.listID)
+			h.drop(sub)
+		}
+	}
+}
+
+// Subscribe registers the subscriber while holding the lock publishing does,
+// so that no event falls between the missed ones and those delivered to the subscription.
+func (h *Hub) Subscribe(listID, userID string, lastEventID uint64) (sub port.Subscription, missed []model.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := &subscription{
+		hub:    h,
+		listID: listID,
+		userID: userID,
+		events: make(chan model.Event, subscriptionBuffer),
+	}
+
+	if h.closed {
+		close(s.events)
+		return s, nil
+	}
+
+	h.subs[s] = struct{}{}
+
+	if lastEventID == 0 {
+		return s, nil
+	}
+
+	// IDs past the last one were given by a previous run of the process
+	if lastEventID < h.evicted || lastEventID > h.seq {
+		reset := model.Event{ID: h.seq, Type: model.ResetEvent, ListID: listID, At: time.Now().UTC()}
+		return s, []model.Event{reset}
+	}
+
+	for _, e := range h.log {
+		if e.ID > lastEventID && e.ListID == listID {
+			missed = append(missed, e)
+		}
+	}
+
+	return s, missed
+}
+
+// Unsubscribe ends the subscriptions of the user to the list, so that a removed member gets no further events.
+func (h *Hub) Unsubscribe(listID, userID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs {
+		if sub.listID == listID && sub.userID == userID {
+			h.drop(sub)
+		}
+	}
+}
+
+// drop ends a subscription, the lock must be held.
+func (h *Hub) drop(sub *subscription) {
+	if _, ok := h.subs[sub]; !ok {
+		return
+	}
+
+	delete(h.subs, sub)
+	close(sub.events)
+}
+
+func (s *subscription) Events() <-chan model.Event {
+	return s.events
+}
+
+func (s *subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.hub.drop(s)
+}
@@ -0,0 +1,154 @@
+package events_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/infra/events"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	"github.com/vanillazen/stl/backend/internal/sys/log"
+)
+
+const (
+	list1ID = "3b6f7a4e-0a8f-4d0a-9b0e-5f4e8b1a2c01"
+	list2ID = "3b6f7a4e-0a8f-4d0a-9b0e-5f4e8b1a2c02"
+	user1ID = "8a5b2c1d-4e6f-4a7b-9c8d-0e1f2a3b4c01"
+	user2ID = "8a5b2c1d-4e6f-4a7b-9c8d-0e1f2a3b4c02"
+)
+
+func newTestHub(logSize string) *events.Hub {
+	cfg := &config.Config{}
+	cfg.SetValues(map[string]string{config.Key.EventsLogSize: logSize})
+
+	return events.NewHub(sys.WithConfig(cfg), sys.WithLogger(log.NewLogger("error")))
+}
+
+func TestHubPublish(t *testing.T) {
+	h := newTestHub("10")
+	ctx := context.Background()
+
+	sub, missed := h.Subscribe(list1ID, user1ID, 0)
+	defer sub.Close()
+
+	if len(missed) != 0 {
+		t.Errorf("Missed: expected none without a last event, got %d", len(missed))
+	}
+
+	h.Publish(ctx, model.Event{Type: model.TaskCreatedEvent, ListID: list2ID})
+	h.Publish(ctx, model.Event{Type: model.TaskUpdatedEvent, ListID: list1ID})
+
+	e := <-sub.Events()
+	if e.ID != 2 || e.Type != model.TaskUpdatedEvent {
+		t.Errorf("Event: expected the second one, of the subscribed list, got %+v", e)
+	}
+
+	sub.Close()
+	if _, ok := <-sub.Events(); ok {
+		t.Errorf("Close: expected the events channel closed")
+	}
+}
+
+func TestHubUnsubscribe(t *testing.T) {
+	h := newTestHub("10")
+	ctx := context.Background()
+
+	removed, _ := h.Subscribe(list1ID, user2ID, 0)
+	other, _ := h.Subscribe(list2ID, user2ID, 0)
+	member, _ := h.Subscribe(list1ID, user1ID, 0)
+	defer other.Close()
+	defer member.Close()
+
+	h.Unsubscribe(list1ID, user2ID)
+	h.Publish(ctx, model.Event{Type: model.TaskUpdatedEvent, ListID: list1ID})
+
+	if _, ok := <-removed.Events(); ok {
+		t.Errorf("Removed member: expected the events channel closed")
+	}
+
+	if e := <-member.Events(); e.ID != 1 {
+		t.Errorf("Member: expected the event delivered, got %+v", e)
+	}
+
+	select {
+	case _, ok := <-other.Events():
+		t.Errorf("Other list: expected the subscription kept, got an event or closed (%t)", ok)
+	default:
+	}
+}
+
+func TestHubResume(t *testing.T) {
+	h := newTestHub("2")
+	ctx := context.Background()
+
+	// Only the last two events are kept
+	for _, listID := range []string{list1ID, list2ID, list1ID, list1ID} {
+		h.Publish(ctx, model.Event{Type: model.TaskUpdatedEvent, ListID: listID})
+	}
+
+	tests := []struct {
+		name        string
+		lastEventID uint64
+		expected    []uint64
+		reset       bool
+	}{
+		{name: "Kept", lastEventID: 2, expected: []uint64{3, 4}},
+		{name: "Up to date", lastEventID: 4, expected: nil},
+		{name: "Evicted", lastEventID: 1, expected: []uint64{4}, reset: true},
+		{name: "Previous run", lastEventID: 9, expected: []uint64{4}, reset: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sub, missed := h.Subscribe(list1ID, user1ID, test.lastEventID)
+			defer sub.Close()
+
+			if len(missed) != len(test.expected) {
+				t.Fatalf("Missed: expected %v, got %+v", test.expected, missed)
+			}
+
+			for i, e := range missed {
+				if e.ID != test.expected[i] || (e.Type == model.ResetEvent) != test.reset {
+					t.Errorf("Missed: expected %v (reset %t), got %+v", test.expected, test.reset, missed)
+				}
+			}
+		})
+	}
+}
+
+func TestHubDrop(t *testing.T) {
+	h := newTestHub("1000")
+	ctx := context.Background()
+
+	slow, _ := h.Subscribe(list1ID, user1ID, 0)
+	for i := 0; i < 100; i++ {
+		h.Publish(ctx, model.Event{Type: model.TaskUpdatedEvent, ListID: list1ID})
+	}
+
+	n := 0
+	for range slow.Events() {
+		n++
+	}
+
+	if n == 0 || n == 100 {
+		t.Errorf("Slow subscriber: expected it dropped once its buffer was full, got %d events", n)
+	}
+
+	// Stopping the hub ends all subscriptions
+	sub, _ := h.Subscribe(list1ID, user1ID, 0)
+
+	err := h.Stop(ctx)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	if _, ok := <-sub.Events(); ok {
+		t.Errorf("Stop: expected the events channel closed")
+	}
+
+	sub, _ = h.Subscribe(list1ID, user1ID, 0)
+	if _, ok := <-sub.Events(); ok {
+		t.Errorf("Stopped: expected new subscriptions closed")
+	}
+}
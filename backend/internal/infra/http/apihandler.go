@@ -1,11 +1,13 @@
 package http
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 
+	"github.com/vanillazen/stl/backend/internal/domain/port"
 	"github.com/vanillazen/stl/backend/internal/domain/service"
 	"github.com/vanillazen/stl/backend/internal/sys"
 	"github.com/vanillazen/stl/backend/internal/sys/errors"
@@ -16,54 +18,79 @@ type (
 	APIHTTPHandler interface {
 		sys.Core
 		Service() service.ListService
+		UserService() service.UserService
+		AuthService() service.AuthService
+		WebhookService() service.WebhookService
 	}
 
 	APIHandler struct {
 		*sys.SimpleCore
-		svc    service.ListService
-		apiDoc string
+		svc         service.ListService
+		userSvc     service.UserService
+		authSvc     service.AuthService
+		webhookSvc  service.WebhookService
+		idempotency idempotency
+		streams     eventStreams
+		realtime    *Realtime
+		apiDoc      string
 	}
 )
 
-func NewAPIHandler(svc service.ListService, apiDoc string, opts ...sys.Option) *APIHandler {
-	return &APIHandler{
+// NewAPIHandler returns the handler of the API routes.
+// Without an idempotency repo, Idempotency-Key headers are ignored.
+func NewAPIHandler(svc service.ListService, userSvc service.UserService, authSvc service.AuthService, webhookSvc service.WebhookService, idemRepo port.IdempotencyRepo, apiDoc string, opts ...sys.Option) *APIHandler {
+	h := &APIHandler{
 		SimpleCore: sys.NewCore("list-handler", opts...),
 		svc:        svc,
+		userSvc:    userSvc,
+		authSvc:    authSvc,
+		webhookSvc: webhookSvc,
 		apiDoc:     apiDoc,
 	}
-}
 
-func (h *APIHandler) handleList(w http.ResponseWriter, r *http.Request) {
-	res, ok := h.resource(r)
-	if !ok {
-		h.handleError(w, http.StatusBadRequest, NoResourceErr)
-	}
+	h.idempotency = newIdempotency(idemRepo, h.Cfg())
+	h.streams = newEventStreams(h.Cfg())
+	h.realtime = NewRealtime(opts...)
 
-	switch r.Method {
-	case http.MethodGet:
-		if res.IDLevel1() != "" {
-			h.GetList(w, r)
-			return
-		} else {
-			h.GetLists(w, r)
-			return
-		}
+	return h
+}
 
-	case http.MethodPost:
-		//h.CreateListList(w, r)
-		h.Log().Error("not implemented yet")
+// GetLists return user lists
+// @summary Get all lists
+// @description Gets a page of the lists the user is a member of
+// @id get-lists
+// @produce json
+// @Param limit query int false "Page size, 50 by default and 200 at most"
+// @Param page query int false "Page number, starting at 1"
+// @Param sort query string false "Comma separated fields, '-' prefixed for descending order: name, created_at, updated_at"
+// @Param role query string false "Only lists where the user has the role: owner, editor or viewer"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/lists [get]
+// @tags Lists
+func (h *APIHandler) GetLists(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
-	case http.MethodPut:
-		//h.UpdateList(w, r)
-		h.Log().Error("not implemented yet")
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
 
-	default:
-		h.handleError(w, http.StatusMethodNotAllowed, MethodNotAllowedErr)
+	req := transport.GetListsReq{
+		UserID: userID,
+		Query:  query(r, "role"),
 	}
-}
 
-func (h *APIHandler) GetLists(w http.ResponseWriter, r *http.Request) {
-	h.Log().Error("not implemented yet")
+	res := h.Service().GetLists(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "get lists error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handlePage(w, r, res, len(res.Lists), res.Page)
 }
 
 // CreateList creates a new list
@@ -73,23 +100,27 @@ func (h *APIHandler) GetLists(w http.ResponseWriter, r *http.Request) {
 // @accept json
 // @produce json
 // @Param list body transport.CreateListReq true "List name and description"
+// @Param Idempotency-Key header string false "Key making retries of the request replay its first response"
 // @Success 201 {object} APIResponse
-// @Success 400 {object} APIResponse
+// @Failure 400 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
 // @Router /api/v1/lists [post]
 // @tags Lists
 func (h *APIHandler) CreateList(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	defer h.closeBody(r.Body)
 
 	userID, err := h.User(r)
 	if err != nil {
-		h.handleError(w, http.StatusNotFound, errors.Wrap(err))
+		h.handleError(w, r, errors.Wrap(err))
 		return
 	}
 
 	var req transport.CreateListReq
 	err = json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		h.handleError(w, http.StatusBadRequest, errors.Wrap(err, "invalid request payload"))
+		h.handleError(w, r, invalidBody(err))
 		return
 	}
 
@@ -97,16 +128,176 @@ func (h *APIHandler) CreateList(w http.ResponseWriter, r *http.Request) {
 
 	res := h.Service().CreateList(ctx, req)
 	if err = res.Err(); err != nil {
-		err = errors.Wrap(err, "get list error")
-		h.handleError(w, http.StatusNotFound, err)
+		err = errors.Wrap(err, "create list error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	setETag(w, res.Version)
+	h.handleCreated(w, res)
+}
+
+// UpdateList updates a user list
+// @summary Update list by ID
+// @description Updates the name and description of a list
+// @id update-list
+// @accept json
+// @produce json
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Param If-Match header string true "ETag of the list version being updated, or * for any"
+// @Param list body transport.UpdateListReq true "List name and description"
+// @Success 200 {object} APIResponse
+// @Header 200 {string} ETag "Version of the updated list"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 428 {object} Problem
+// @Router /api/v1/lists/{listID} [put]
+// @tags Lists
+func (h *APIHandler) UpdateList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer h.closeBody(r.Body)
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	version, err := ifMatch(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	var req transport.UpdateListReq
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.handleError(w, r, invalidBody(err))
+		return
+	}
+
+	req.UserID = userID
+	req.ListID = PathParam(r, "listID")
+	req.Version = version
+
+	res := h.Service().UpdateList(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "update list error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	setETag(w, res.Version)
+	h.handleSuccess(w, res, 1, 1)
+}
+
+// PatchList partially updates a list
+// @summary Patch list by ID
+// @description Applies a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902) to the editable fields of a list.
+// @description The patched list is validated as in full updates.
+// @id patch-list
+// @accept application/merge-patch+json
+// @accept application/json-patch+json
+// @produce json
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Param If-Match header string true "ETag of the list version being patched, or * for any"
+// @Param patch body object true "Merge patch object or array of JSON Patch operations"
+// @Success 200 {object} APIResponse
+// @Header 200 {string} ETag "Version of the patched list"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 415 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 428 {object} Problem
+// @Router /api/v1/lists/{listID} [patch]
+// @tags Lists
+func (h *APIHandler) PatchList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer h.closeBody(r.Body)
+
+	w.Header().Set(AcceptPatchHeader, acceptPatch)
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
 		return
 	}
 
+	version, err := ifMatch(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	mediaType, body, err := patchBody(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.PatchListReq{
+		UserID:    userID,
+		ListID:    PathParam(r, "listID"),
+		MediaType: mediaType,
+		Patch:     body,
+		Version:   version,
+	}
+
+	res := h.Service().PatchList(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "patch list error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	setETag(w, res.Version)
 	h.handleSuccess(w, res, 1, 1)
+}
+
+// DeleteList deletes a user list
+// @summary Delete list by ID
+// @description Deletes a list and all its tasks
+// @id delete-list
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Param If-Match header string true "ETag of the list version being deleted, or * for any"
+// @Success 204
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 428 {object} Problem
+// @Router /api/v1/lists/{listID} [delete]
+// @tags Lists
+func (h *APIHandler) DeleteList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	version, err := ifMatch(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.DeleteListReq{
+		UserID:  userID,
+		ListID:  PathParam(r, "listID"),
+		Version: version,
+	}
+
+	res := h.Service().DeleteList(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "delete list error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
 
-	//h.Service().CreateList(ctx, list)
-	//
-	//h.handleSuccess(w, list, 1, 1)
+	h.handleNoContent(w)
 }
 
 // GetList return user list
@@ -114,64 +305,1332 @@ func (h *APIHandler) CreateList(w http.ResponseWriter, r *http.Request) {
 // @description Gets a list by its ID
 // @id get-list
 // @produce json
-// @Param id path string true "List ID formatted as an UUID string"
+// @Param listID path string true "List ID formatted as an UUID string"
 // @Success 200 {object} APIResponse
-// @Success 400 {object} APIResponse
-// @Success 404 {object} APIResponse
-// @Success 405 {object} APIResponse
-// @Router /api/v1/lists/{id} [get]
+// @Header 200 {string} ETag "Version of the list, changes of its tasks leave it unchanged"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 405 {object} Problem
+// @Router /api/v1/lists/{listID} [get]
 // @tags Lists
 func (h *APIHandler) GetList(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	userID, err := h.User(r)
 	if err != nil {
-		h.handleError(w, http.StatusNotFound, errors.Wrap(err))
-		return
-	}
-
-	resource, ok := h.resource(r)
-	if !ok {
-		h.handleError(w, http.StatusBadRequest, errors.Wrap(NoResourceErr, "get list error"))
+		h.handleError(w, r, errors.Wrap(err))
 		return
 	}
 
 	req := transport.GetListReq{
 		UserID: userID,
-		ListID: resource.IDLevel1(),
+		ListID: PathParam(r, "listID"),
 	}
 
 	res := h.Service().GetList(ctx, req)
 	if err = res.Err(); err != nil {
 		err = errors.Wrap(err, "get list error")
-		h.handleError(w, http.StatusNotFound, err)
+		h.handleServiceError(w, r, &res, err)
 		return
 	}
 
+	// The tasks sent along are versioned apart, so a list matching If-None-Match can not be answered with 304.
+	setETag(w, res.Version)
 	h.handleSuccess(w, res, 1, 1)
 }
 
-func (h *APIHandler) handleTask(w http.ResponseWriter, r *http.Request) {
-	h.Log().Error("not implemented yet")
+// GetTasks return list tasks
+// @summary Get list tasks
+// @description Gets a page of the tasks of a list
+// @id get-tasks
+// @produce json
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Param limit query int false "Page size, 50 by default and 200 at most"
+// @Param page query int false "Page number, starting at 1"
+// @Param sort query string false "Comma separated fields, '-' prefixed for descending order: position, name, priority, done, due_at, completed_at, created_at, updated_at"
+// @Param tag query string false "Only tasks with the tag"
+// @Param done query bool false "Only done or pending tasks"
+// @Param priority query int false "Only tasks with the priority, 0 to 3"
+// @Param due_before query string false "Only tasks due before the date or RFC 3339 time"
+// @Param due_after query string false "Only tasks due at or after the date or RFC 3339 time"
+// @Param If-None-Match header string false "ETag of the version of the tasks the client has"
+// @Success 200 {object} APIResponse
+// @Header 200 {string} ETag "Version of the tasks of the list, changed by any change to them"
+// @Success 304
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Router /api/v1/lists/{listID}/tasks [get]
+// @tags Tasks
+func (h *APIHandler) GetTasks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.GetTasksReq{
+		UserID: userID,
+		ListID: PathParam(r, "listID"),
+		Query:  query(r, "tag", "done", "priority", "due_before", "due_after"),
+	}
+
+	res := h.Service().GetTasks(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "get tasks error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	setETag(w, res.Version)
+	if notModified(r, res.Version) {
+		h.handleNotModified(w)
+		return
+	}
+
+	h.handlePage(w, r, res, len(res.Tasks), res.Page)
 }
 
-func (h *APIHandler) handleOpenAPIDocs(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/html")
-	_, _ = fmt.Fprint(w, h.apiDoc)
+// GetTask return a list task
+// @summary Get task by ID
+// @description Gets a task of a list by its ID
+// @id get-task
+// @produce json
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Param taskID path string true "Task ID formatted as an UUID string"
+// @Param If-None-Match header string false "ETag of the task version the client has"
+// @Success 200 {object} APIResponse
+// @Header 200 {string} ETag "Version of the task"
+// @Success 304
+// @Failure 404 {object} Problem
+// @Router /api/v1/lists/{listID}/tasks/{taskID} [get]
+// @tags Tasks
+func (h *APIHandler) GetTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.GetTaskReq{
+		UserID: userID,
+		ListID: PathParam(r, "listID"),
+		TaskID: PathParam(r, "taskID"),
+	}
+
+	res := h.Service().GetTask(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "get task error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	setETag(w, res.Version)
+	if notModified(r, res.Version) {
+		h.handleNotModified(w)
+		return
+	}
+
+	h.handleSuccess(w, res, 1, 1)
 }
 
-// Helpers
+// AddTask adds a task to a list
+// @summary Add a task to a list
+// @description Creates a new task in the list with the provided details
+// @id add-task
+// @accept json
+// @produce json
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Param task body transport.AddTaskReq true "Task details"
+// @Param Idempotency-Key header string false "Key making retries of the request replay its first response"
+// @Success 201 {object} APIResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Router /api/v1/lists/{listID}/tasks [post]
+// @tags Tasks
+func (h *APIHandler) AddTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer h.closeBody(r.Body)
 
-// closeBody close the body and log errors if happened.
-func (h *APIHandler) closeBody(body io.ReadCloser) {
-	if err := body.Close(); err != nil {
-		h.Log().Error(errors.Wrap(err, "failed to close body"))
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	var req transport.AddTaskReq
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.handleError(w, r, invalidBody(err))
+		return
 	}
+
+	req.UserID = userID
+	req.ListID = PathParam(r, "listID")
+
+	res := h.Service().AddTask(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "add task error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	setETag(w, res.Version)
+	h.handleCreated(w, res)
 }
 
-// Handler interface
+// AddTasks adds many tasks to a list at once
+// @summary Add tasks to a list
+// @description Creates all provided tasks in the list, none is created if any of them fails
+// @id add-tasks
+// @accept json
+// @produce json
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Param tasks body []transport.AddTaskReq true "Tasks details"
+// @Param Idempotency-Key header string false "Key making retries of the request replay its first response"
+// @Success 201 {object} APIResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Router /api/v1/lists/{listID}/tasks [post]
+// @tags Tasks
+func (h *APIHandler) AddTasks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer h.closeBody(r.Body)
 
-// Service returns a list svc implementation.
-func (h *APIHandler) Service() service.ListService {
-	return h.svc
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	var tasks []transport.AddTaskReq
+	err = json.NewDecoder(r.Body).Decode(&tasks)
+	if err != nil {
+		h.handleError(w, r, invalidBody(err))
+		return
+	}
+
+	req := transport.AddTasksReq{
+		UserID: userID,
+		ListID: PathParam(r, "listID"),
+		Tasks:  tasks,
+	}
+
+	res := h.Service().AddTasks(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "add tasks error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleSuccessWithStatus(w, http.StatusCreated, res, len(res.Tasks), 1)
+}
+
+// BatchTasks applies many operations to the tasks of a list at once
+// @summary Batch task operations
+// @description Creates, updates, deletes and moves tasks of the list in a single transaction.
+// @description Operations are all-or-nothing, a failed one fails the request pointing at it, unless BestEffort
+// @description is set, in which case each one succeeds or fails on its own. Results report the status of every
+// @description operation, as it would have been answered on its own.
+// @id batch-tasks
+// @accept json
+// @produce json
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Param batch body transport.BatchTasksReq true "Batch operations"
+// @Param Idempotency-Key header string false "Key making retries of the request replay its first response"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Router /api/v1/lists/{listID}/tasks:batch [post]
+// @tags Tasks
+func (h *APIHandler) BatchTasks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer h.closeBody(r.Body)
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	var req transport.BatchTasksReq
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.handleError(w, r, invalidBody(err))
+		return
+	}
+
+	req.UserID = userID
+	req.ListID = PathParam(r, "listID")
+
+	res := h.Service().BatchTasks(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "batch tasks error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	results := make([]TaskOpResult, len(res.Results))
+	for i := range res.Results {
+		results[i] = NewTaskOpResult(&res.Results[i])
+	}
+
+	h.handleSuccess(w, results, len(results), 1)
+}
+
+// UpdateTask updates a list task
+// @summary Update task by ID
+// @description Updates all the properties of a task
+// @id update-task
+// @accept json
+// @produce json
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Param taskID path string true "Task ID formatted as an UUID string"
+// @Param If-Match header string true "ETag of the task version being updated, or * for any"
+// @Param task body transport.UpdateTaskReq true "Task details"
+// @Success 200 {object} APIResponse
+// @Header 200 {string} ETag "Version of the updated task"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 428 {object} Problem
+// @Router /api/v1/lists/{listID}/tasks/{taskID} [put]
+// @tags Tasks
+func (h *APIHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer h.closeBody(r.Body)
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	version, err := ifMatch(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	var req transport.UpdateTaskReq
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.handleError(w, r, invalidBody(err))
+		return
+	}
+
+	req.UserID = userID
+	req.ListID = PathParam(r, "listID")
+	req.TaskID = PathParam(r, "taskID")
+	req.Version = version
+
+	res := h.Service().UpdateTask(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "update task error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	setETag(w, res.Version)
+
+	h.handleSuccess(w, res, 1, 1)
+}
+
+// PatchTask partially updates a task
+// @summary Patch task by ID
+// @description Applies a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902) to the editable fields of a task.
+// @description The patched task is validated as in full updates.
+// @id patch-task
+// @accept application/merge-patch+json
+// @accept application/json-patch+json
+// @produce json
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Param taskID path string true "Task ID formatted as an UUID string"
+// @Param If-Match header string true "ETag of the task version being patched, or * for any"
+// @Param patch body object true "Merge patch object or array of JSON Patch operations"
+// @Success 200 {object} APIResponse
+// @Header 200 {string} ETag "Version of the patched task"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 415 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 428 {object} Problem
+// @Router /api/v1/lists/{listID}/tasks/{taskID} [patch]
+// @tags Tasks
+func (h *APIHandler) PatchTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer h.closeBody(r.Body)
+
+	w.Header().Set(AcceptPatchHeader, acceptPatch)
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	version, err := ifMatch(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	mediaType, body, err := patchBody(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.PatchTaskReq{
+		UserID:    userID,
+		ListID:    PathParam(r, "listID"),
+		TaskID:    PathParam(r, "taskID"),
+		MediaType: mediaType,
+		Patch:     body,
+		Version:   version,
+	}
+
+	res := h.Service().PatchTask(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "patch task error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	setETag(w, res.Version)
+	h.handleSuccess(w, res, 1, 1)
+}
+
+// ToggleTask switches the completion state of a task
+// @summary Toggle task completion
+// @description Marks a pending task as done or a done task as pending
+// @id toggle-task
+// @produce json
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Param taskID path string true "Task ID formatted as an UUID string"
+// @Param If-Match header string true "ETag of the task version being toggled, or * for any"
+// @Success 200 {object} APIResponse
+// @Header 200 {string} ETag "Version of the toggled task"
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 428 {object} Problem
+// @Router /api/v1/lists/{listID}/tasks/{taskID}/toggle [post]
+// @tags Tasks
+func (h *APIHandler) ToggleTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	version, err := ifMatch(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.ToggleTaskReq{
+		UserID:  userID,
+		ListID:  PathParam(r, "listID"),
+		TaskID:  PathParam(r, "taskID"),
+		Version: version,
+	}
+
+	res := h.Service().ToggleTask(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "toggle task error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	setETag(w, res.Version)
+	h.handleSuccess(w, res, 1, 1)
+}
+
+// DeleteTask deletes a list task
+// @summary Delete task by ID
+// @description Deletes a task from a list
+// @id delete-task
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Param taskID path string true "Task ID formatted as an UUID string"
+// @Param If-Match header string true "ETag of the task version being deleted, or * for any"
+// @Success 204
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 428 {object} Problem
+// @Router /api/v1/lists/{listID}/tasks/{taskID} [delete]
+// @tags Tasks
+func (h *APIHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	version, err := ifMatch(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.DeleteTaskReq{
+		UserID:  userID,
+		ListID:  PathParam(r, "listID"),
+		TaskID:  PathParam(r, "taskID"),
+		Version: version,
+	}
+
+	res := h.Service().DeleteTask(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "delete task error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleNoContent(w)
+}
+
+// GetTags returns user tags
+// @summary Get all tags
+// @description Gets all tags of the user along with the number of tasks using each of them
+// @id get-tags
+// @produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/tags [get]
+// @tags Tags
+func (h *APIHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.GetTagsReq{
+		UserID: userID,
+	}
+
+	res := h.Service().GetTags(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "get tags error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleSuccess(w, res, len(res.Tags), 1)
+}
+
+// GetTag returns a user tag
+// @summary Get tag by ID
+// @description Gets a tag by its ID along with the number of tasks using it
+// @id get-tag
+// @produce json
+// @Param tagID path string true "Tag ID formatted as an UUID string"
+// @Success 200 {object} APIResponse
+// @Failure 404 {object} Problem
+// @Router /api/v1/tags/{tagID} [get]
+// @tags Tags
+func (h *APIHandler) GetTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.GetTagReq{
+		UserID: userID,
+		TagID:  PathParam(r, "tagID"),
+	}
+
+	res := h.Service().GetTag(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "get tag error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleSuccess(w, res, 1, 1)
+}
+
+// AttachTag tags a task
+// @summary Attach tag to task
+// @description Attaches a tag to a task by name, the tag is created if it does not exist yet
+// @id attach-tag
+// @accept json
+// @produce json
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Param taskID path string true "Task ID formatted as an UUID string"
+// @Param tag body transport.AttachTagReq true "Tag name"
+// @Success 201 {object} APIResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Router /api/v1/lists/{listID}/tasks/{taskID}/tags [post]
+// @tags Tags
+func (h *APIHandler) AttachTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer h.closeBody(r.Body)
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	var req transport.AttachTagReq
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.handleError(w, r, invalidBody(err))
+		return
+	}
+
+	req.UserID = userID
+	req.ListID = PathParam(r, "listID")
+	req.TaskID = PathParam(r, "taskID")
+
+	res := h.Service().AttachTag(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "attach tag error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleCreated(w, res)
+}
+
+// DetachTag removes a tag from a task
+// @summary Detach tag from task
+// @description Detaches a tag from a task, the tag itself is kept
+// @id detach-tag
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Param taskID path string true "Task ID formatted as an UUID string"
+// @Param tagID path string true "Tag ID formatted as an UUID string"
+// @Success 204
+// @Failure 404 {object} Problem
+// @Router /api/v1/lists/{listID}/tasks/{taskID}/tags/{tagID} [delete]
+// @tags Tags
+func (h *APIHandler) DetachTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.DetachTagReq{
+		UserID: userID,
+		ListID: PathParam(r, "listID"),
+		TaskID: PathParam(r, "taskID"),
+		TagID:  PathParam(r, "tagID"),
+	}
+
+	res := h.Service().DetachTag(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "detach tag error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleNoContent(w)
+}
+
+// RegisterUser creates a user account
+// @summary Register user
+// @description Creates a new user account, usernames and emails must be unique
+// @id register-user
+// @accept json
+// @produce json
+// @Param user body transport.RegisterUserReq true "User details"
+// @Success 201 {object} APIResponse
+// @Failure 400 {object} Problem
+// @Router /api/v1/users [post]
+// @tags Users
+func (h *APIHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer h.closeBody(r.Body)
+
+	var req transport.RegisterUserReq
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.handleError(w, r, invalidBody(err))
+		return
+	}
+
+	res := h.UserService().RegisterUser(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "register user error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleCreated(w, res)
+}
+
+// GetUser returns a user
+// @summary Get user by ID
+// @description Gets a user account by its ID, users can get their own account and admins any of them
+// @id get-user
+// @produce json
+// @Param userID path string true "User ID formatted as an UUID string"
+// @Success 200 {object} APIResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Router /api/v1/users/{userID} [get]
+// @tags Users
+func (h *APIHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.GetUserReq{
+		CallerID: userID,
+		UserID:   PathParam(r, "userID"),
+	}
+
+	res := h.UserService().GetUser(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "get user error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleSuccess(w, res, 1, 1)
+}
+
+// FindUser looks a user up
+// @summary Find user by username or email
+// @description Gets a user account by its username or, if not provided, by its email, only the user and admins find it
+// @id find-user
+// @produce json
+// @Param username query string false "Username"
+// @Param email query string false "Email"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 404 {object} Problem
+// @Router /api/v1/users [get]
+// @tags Users
+func (h *APIHandler) FindUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	query := r.URL.Query()
+
+	req := transport.FindUserReq{
+		CallerID: userID,
+		Username: query.Get("username"),
+		Email:    query.Get("email"),
+	}
+
+	res := h.UserService().FindUser(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "find user error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleSuccess(w, res, 1, 1)
+}
+
+// Login authenticates a user
+// @summary Login
+// @description Exchanges a username or email and a password for an access and a refresh token
+// @id login
+// @accept json
+// @produce json
+// @Param credentials body transport.LoginReq true "User credentials"
+// @Success 200 {object} APIResponse
+// @Failure 401 {object} Problem
+// @Router /api/v1/auth/login [post]
+// @tags Auth
+func (h *APIHandler) Login(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer h.closeBody(r.Body)
+
+	var req transport.LoginReq
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.handleError(w, r, invalidBody(err))
+		return
+	}
+
+	res := h.AuthService().Login(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "login error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleSuccess(w, res, 1, 1)
+}
+
+// RefreshToken renews the tokens of a session
+// @summary Refresh tokens
+// @description Exchanges a refresh token for a new access and refresh token, each refresh token can only be used once
+// @id refresh-token
+// @accept json
+// @produce json
+// @Param token body transport.RefreshTokenReq true "Refresh token"
+// @Success 200 {object} APIResponse
+// @Failure 401 {object} Problem
+// @Router /api/v1/auth/refresh [post]
+// @tags Auth
+func (h *APIHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer h.closeBody(r.Body)
+
+	var req transport.RefreshTokenReq
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.handleError(w, r, invalidBody(err))
+		return
+	}
+
+	res := h.AuthService().RefreshToken(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "refresh token error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleSuccess(w, res, 1, 1)
+}
+
+// Logout revokes the current session
+// @summary Logout
+// @description Revokes the session of the access token, its tokens can not be used or refreshed afterwards
+// @id logout
+// @produce json
+// @Success 204
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Router /api/v1/auth/logout [post]
+// @tags Auth
+func (h *APIHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	p, ok := h.principal(r)
+	if !ok {
+		h.handleError(w, r, NoUserErr)
+		return
+	}
+
+	if p.SessionID == "" {
+		h.handleError(w, r, SessionRequiredErr)
+		return
+	}
+
+	req := transport.LogoutReq{
+		UserID:    p.UserID,
+		SessionID: p.SessionID,
+	}
+
+	res := h.AuthService().Logout(ctx, req)
+	if err := res.Err(); err != nil {
+		err = errors.Wrap(err, "logout error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleNoContent(w)
+}
+
+// GetAPIKeys returns the user API keys
+// @summary Get API keys
+// @description Gets the API keys of the user, including revoked ones; the keys themselves are not returned
+// @id get-api-keys
+// @produce json
+// @Success 200 {object} APIResponse
+// @Failure 401 {object} Problem
+// @Router /api/v1/apikeys [get]
+// @tags APIKeys
+func (h *APIHandler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.GetAPIKeysReq{
+		UserID: userID,
+	}
+
+	res := h.AuthService().GetAPIKeys(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "get api keys error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleSuccess(w, res, len(res.APIKeys), 1)
+}
+
+// CreateAPIKey issues an API key
+// @summary Create API key
+// @description Issues a new API key for the user, scoped to "read" (default) or "read-write". The key is only returned in this response
+// @id create-api-key
+// @accept json
+// @produce json
+// @Param key body transport.CreateAPIKeyReq true "API key details"
+// @Success 201 {object} APIResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Router /api/v1/apikeys [post]
+// @tags APIKeys
+func (h *APIHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer h.closeBody(r.Body)
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	var req transport.CreateAPIKeyReq
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.handleError(w, r, invalidBody(err))
+		return
+	}
+
+	req.UserID = userID
+
+	res := h.AuthService().CreateAPIKey(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "create api key error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleCreated(w, res)
+}
+
+// RevokeAPIKey revokes an API key
+// @summary Revoke API key
+// @description Revokes an API key of the user, it can not be used afterwards
+// @id revoke-api-key
+// @produce json
+// @Param apiKeyID path string true "API key ID formatted as an UUID string"
+// @Success 204
+// @Failure 401 {object} Problem
+// @Failure 404 {object} Problem
+// @Router /api/v1/apikeys/{apiKeyID} [delete]
+// @tags APIKeys
+func (h *APIHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.RevokeAPIKeyReq{
+		UserID:   userID,
+		APIKeyID: PathParam(r, "apiKeyID"),
+	}
+
+	res := h.AuthService().RevokeAPIKey(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "revoke api key error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleNoContent(w)
+}
+
+// GetMembers returns list members
+// @summary Get list members
+// @description Gets the members of a list along with their roles
+// @id get-members
+// @produce json
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Success 200 {object} APIResponse
+// @Failure 404 {object} Problem
+// @Router /api/v1/lists/{listID}/members [get]
+// @tags Members
+func (h *APIHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.GetMembersReq{
+		UserID: userID,
+		ListID: PathParam(r, "listID"),
+	}
+
+	res := h.Service().GetMembers(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "get members error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleSuccess(w, res, len(res.Members), 1)
+}
+
+// InviteMember invites a user to a list
+// @summary Invite list member
+// @description Invites whoever owns the email to the list as "viewer" (default), "editor" or "owner". Only list owners can invite
+// @id invite-member
+// @accept json
+// @produce json
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Param invitation body transport.InviteMemberReq true "Invitation details"
+// @Success 201 {object} APIResponse
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Router /api/v1/lists/{listID}/members [post]
+// @tags Members
+func (h *APIHandler) InviteMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer h.closeBody(r.Body)
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	var req transport.InviteMemberReq
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.handleError(w, r, invalidBody(err))
+		return
+	}
+
+	req.UserID = userID
+	req.ListID = PathParam(r, "listID")
+
+	res := h.Service().InviteMember(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "invite member error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleCreated(w, res)
+}
+
+// UpdateMember changes the role of a list member
+// @summary Update list member
+// @description Changes the role of a member, only list owners can do it and a list always keeps at least one owner
+// @id update-member
+// @accept json
+// @produce json
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Param memberID path string true "Member user ID formatted as an UUID string"
+// @Param member body transport.UpdateMemberReq true "Member role"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Router /api/v1/lists/{listID}/members/{memberID} [put]
+// @tags Members
+func (h *APIHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer h.closeBody(r.Body)
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	var req transport.UpdateMemberReq
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.handleError(w, r, invalidBody(err))
+		return
+	}
+
+	req.UserID = userID
+	req.ListID = PathParam(r, "listID")
+	req.MemberID = PathParam(r, "memberID")
+
+	res := h.Service().UpdateMember(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "update member error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleSuccess(w, res, 1, 1)
+}
+
+// RemoveMember removes a member from a list
+// @summary Remove list member
+// @description Removes a member from a list. Owners can remove anyone and members can remove themselves, as long as the list keeps an owner
+// @id remove-member
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Param memberID path string true "Member user ID formatted as an UUID string"
+// @Success 204
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Router /api/v1/lists/{listID}/members/{memberID} [delete]
+// @tags Members
+func (h *APIHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.RemoveMemberReq{
+		UserID:   userID,
+		ListID:   PathParam(r, "listID"),
+		MemberID: PathParam(r, "memberID"),
+	}
+
+	res := h.Service().RemoveMember(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "remove member error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleNoContent(w)
+}
+
+// GetInvitations returns the user pending invitations
+// @summary Get invitations
+// @description Gets the pending list invitations sent to the email of the user
+// @id get-invitations
+// @produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/invitations [get]
+// @tags Members
+func (h *APIHandler) GetInvitations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.GetInvitationsReq{
+		UserID: userID,
+	}
+
+	res := h.Service().GetInvitations(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "get invitations error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleSuccess(w, res, len(res.Invitations), 1)
+}
+
+// AcceptInvitation accepts a list invitation
+// @summary Accept invitation
+// @description Makes the user a member of the list with the invited role, the invitation must be sent to the email of the user
+// @id accept-invitation
+// @produce json
+// @Param invitationID path string true "Invitation ID formatted as an UUID string"
+// @Success 200 {object} APIResponse
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Router /api/v1/invitations/{invitationID}/accept [post]
+// @tags Members
+func (h *APIHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.AcceptInvitationReq{
+		UserID:       userID,
+		InvitationID: PathParam(r, "invitationID"),
+	}
+
+	res := h.Service().AcceptInvitation(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "accept invitation error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleSuccess(w, res, 1, 1)
+}
+
+// DeclineInvitation declines a list invitation
+// @summary Decline invitation
+// @description Deletes an invitation, either by its recipient or by an owner of the list
+// @id decline-invitation
+// @Param invitationID path string true "Invitation ID formatted as an UUID string"
+// @Success 204
+// @Failure 404 {object} Problem
+// @Router /api/v1/invitations/{invitationID} [delete]
+// @tags Members
+func (h *APIHandler) DeclineInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.DeclineInvitationReq{
+		UserID:       userID,
+		InvitationID: PathParam(r, "invitationID"),
+	}
+
+	res := h.Service().DeclineInvitation(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "decline invitation error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleNoContent(w)
+}
+
+// Search looks up lists and tasks
+// @summary Search lists and tasks
+// @description Gets a page of the lists the user is a member of and their tasks matching all the words of the text, best matches first.
+// @description The last word also matches as a prefix. Matching words are enclosed in <mark> tags in names, snippets and tags.
+// @id search
+// @produce json
+// @Param q query string true "Text to look up in names, descriptions and tags"
+// @Param limit query int false "Page size, 50 by default and 200 at most"
+// @Param page query int false "Page number, starting at 1"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/search [get]
+// @tags Search
+func (h *APIHandler) Search(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.SearchReq{
+		UserID: userID,
+		Text:   r.URL.Query().Get(searchParam),
+		Query:  query(r),
+	}
+
+	res := h.Service().Search(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "search error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handlePage(w, r, res, len(res.Results), res.Page)
+}
+
+func (h *APIHandler) handleOpenAPIDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+	_, _ = fmt.Fprint(w, h.apiDoc)
+}
+
+// Helpers
+
+// isArrayBody reports whether the request body holds a JSON array.
+// The body is preserved so that it can still be decoded afterwards.
+func (h *APIHandler) isArrayBody(r *http.Request) bool {
+	br := bufio.NewReader(r.Body)
+	r.Body = readCloser{Reader: br, Closer: r.Body}
+
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return false
+		}
+
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+
+		_ = br.UnreadByte()
+		return b == '['
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// closeBody close the body and log errors if happened.
+func (h *APIHandler) closeBody(body io.ReadCloser) {
+	if err := body.Close(); err != nil {
+		h.Log().Error(errors.Wrap(err, "failed to close body"))
+	}
+}
+
+// Handler interface
+
+// Service returns a list svc implementation.
+func (h *APIHandler) Service() service.ListService {
+	return h.svc
+}
+
+// UserService returns a user svc implementation.
+func (h *APIHandler) UserService() service.UserService {
+	return h.userSvc
+}
+
+// AuthService returns an auth svc implementation.
+func (h *APIHandler) AuthService() service.AuthService {
+	return h.authSvc
+}
+
+func (h *APIHandler) WebhookService() service.WebhookService {
+	return h.webhookSvc
+}
+
+// Realtime returns the realtime connections opened through the handler.
+func (h *APIHandler) Realtime() *Realtime {
+	return h.realtime
 }
@@ -0,0 +1,44 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/transport"
+)
+
+type (
+	// TaskOpResult is the outcome of an operation of a task batch.
+	// Status is the one the operation would have been answered with on its own, Error describes a failed one.
+	TaskOpResult struct {
+		Op     string
+		Status int
+		Task   *transport.Task `json:",omitempty"`
+		Error  *Problem        `json:",omitempty"`
+	}
+)
+
+// taskOpStatus is the status of a successful operation by its kind.
+var taskOpStatus = map[model.TaskOpKind]int{
+	model.CreateTaskOp: http.StatusCreated,
+	model.UpdateTaskOp: http.StatusOK,
+	model.DeleteTaskOp: http.StatusNoContent,
+	model.MoveTaskOp:   http.StatusOK,
+}
+
+func NewTaskOpResult(res *transport.TaskOpRes) TaskOpResult {
+	if err := res.Err(); err != nil {
+		problem := serviceProblem(res, err)
+		return TaskOpResult{
+			Op:     res.Op,
+			Status: problem.Status,
+			Error:  &problem,
+		}
+	}
+
+	return TaskOpResult{
+		Op:     res.Op,
+		Status: taskOpStatus[model.TaskOpKind(res.Op)],
+		Task:   res.Task,
+	}
+}
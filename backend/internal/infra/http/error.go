@@ -3,14 +3,26 @@ package http
 import "github.com/vanillazen/stl/backend/internal/sys/errors"
 
 var (
-	MethodNotAllowedErr   = errors.New("method not allowed")
-	InvalidResourceErr    = errors.New("invalid resource")
-	NoUserErr             = errors.New("not a valid user in session")
-	NoResourceErr         = errors.New("no resource ID provided")
-	NoAssetReqErr         = errors.New("no asset request provided")
-	InvalidRequestErr     = errors.New("invalid request")
-	InvalidRequestDataErr = errors.New("invalid request data")
-	InvalidJSONBodyErr    = errors.New("invalid JSON body")
-	InvalidValueTypeErr   = errors.New("invalid value type")
-	ListNotFoundErr       = errors.New("list not found")
+	MethodNotAllowedErr            = errors.New("method not allowed")
+	InvalidResourceErr             = errors.New("invalid resource")
+	InvalidURLErr                  = errors.New("invalid URL")
+	NoUserErr                      = errors.New("not a valid user in session")
+	InvalidAuthHeaderErr           = errors.New("invalid authorization header")
+	InsufficientScopeErr           = errors.New("insufficient api key scope")
+	SessionRequiredErr             = errors.New("user session required")
+	NoAssetReqErr                  = errors.New("no asset request provided")
+	InvalidRequestErr              = errors.New("invalid request")
+	InvalidRequestDataErr          = errors.New("invalid request data")
+	InvalidJSONBodyErr             = errors.New("invalid JSON body")
+	InvalidValueTypeErr            = errors.New("invalid value type")
+	ListNotFoundErr                = errors.New("list not found")
+	IfMatchRequiredErr             = errors.New("If-Match header required")
+	ETagMismatchErr                = errors.New("entity tag does not match")
+	InvalidIdempotencyKeyErr       = errors.New("invalid Idempotency-Key header")
+	IdempotencyKeyReusedErr        = errors.New("Idempotency-Key already used with a different request")
+	IdempotentRequestInProgressErr = errors.New("a request with the same Idempotency-Key is in progress")
+	UnknownMsgTypeErr              = errors.New("unknown message type")
+	InvalidPresenceErr             = errors.New("invalid presence status")
+	NotSubscribedErr               = errors.New("not subscribed to list")
+	TooManySubscriptionsErr        = errors.New("too many subscriptions")
 )
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+)
+
+const (
+	ETagHeader        = "ETag"
+	IfMatchHeader     = "If-Match"
+	IfNoneMatchHeader = "If-None-Match"
+
+	weakETagPrefix = "W/"
+)
+
+// etag returns the entity tag of a version of a resource.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// setETag sets the entity tag of the resource in the response, versions below one are not sent.
+func setETag(w http.ResponseWriter, version int) {
+	if version < 1 {
+		return
+	}
+
+	w.Header().Set(ETagHeader, etag(version))
+}
+
+// ifMatch returns the version the If-Match header requires the resource to be at, zero if any will do ("*").
+// The header is required, updates without it could overwrite changes the client has not seen.
+// Weak or malformed entity tags never match, as strong comparison is used.
+func ifMatch(r *http.Request) (version int, err error) {
+	header := strings.TrimSpace(r.Header.Get(IfMatchHeader))
+	if header == "" {
+		return 0, IfMatchRequiredErr
+	}
+
+	if header == "*" {
+		return 0, nil
+	}
+
+	tags := strings.Split(header, ",")
+	if len(tags) > 1 {
+		return 0, errors.Wrap(InvalidRequestErr, "a single entity tag is supported in If-Match")
+	}
+
+	version, ok := parseETag(tags[0])
+	if !ok {
+		return 0, ETagMismatchErr
+	}
+
+	return version, nil
+}
+
+// notModified reports whether the If-None-Match header of the request matches the version of the resource.
+// Weak comparison is used, as for any conditional GET.
+func notModified(r *http.Request, version int) bool {
+	header := strings.TrimSpace(r.Header.Get(IfNoneMatchHeader))
+	if header == "" || version < 1 {
+		return false
+	}
+
+	if header == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		v, ok := parseETag(strings.TrimPrefix(strings.TrimSpace(tag), weakETagPrefix))
+		if ok && v == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseETag returns the version of a strong entity tag.
+func parseETag(tag string) (version int, ok bool) {
+	tag = strings.TrimSpace(tag)
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version < 1 {
+		return 0, false
+	}
+
+	return version, true
+}
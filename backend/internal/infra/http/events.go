@@ -0,0 +1,174 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/transport"
+)
+
+const (
+	EventStreamContentType = "text/event-stream"
+	LastEventIDHeader      = "Last-Event-ID"
+
+	defaultHeartbeat = 15 * time.Second
+)
+
+type (
+	// eventStreams ends the event streams being served when the server stops,
+	// as they would otherwise keep their connections busy and hold up its shutdown.
+	eventStreams struct {
+		heartbeat time.Duration
+		ctx       context.Context
+		stop      context.CancelFunc
+	}
+)
+
+func newEventStreams(cfg *config.Config) eventStreams {
+	streams := eventStreams{
+		heartbeat: defaultHeartbeat,
+	}
+
+	if cfg != nil {
+		if secs := cfg.GetInt(cfgKey.APIEventsHeartbeat); secs > 0 {
+			streams.heartbeat = time.Duration(secs) * time.Second
+		}
+	}
+
+	streams.ctx, streams.stop = context.WithCancel(context.Background())
+
+	return streams
+}
+
+// StreamEvents streams the changes of a list
+// @summary Stream list events
+// @description Streams the changes made to the list and its tasks as server-sent events, named after the change
+// @description and carrying it as JSON data. Clients resume after the last event they got with Last-Event-ID,
+// @description a reset event tells them that events were lost and the list must be read again.
+// @description Comments are sent as heartbeats while there are no changes.
+// @id stream-events
+// @produce text/event-stream
+// @Param listID path string true "List ID formatted as an UUID string"
+// @Param Last-Event-ID header string false "ID of the last event the client got"
+// @Success 200 {object} transport.Event
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Router /api/v1/lists/{listID}/events [get]
+// @tags Lists
+func (h *APIHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	lastEventID, err := lastEventID(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.SubscribeEventsReq{
+		UserID:      userID,
+		ListID:      PathParam(r, "listID"),
+		LastEventID: lastEventID,
+	}
+
+	res := h.Service().SubscribeEvents(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "stream events error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+	defer res.Close()
+
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", EventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	for _, e := range res.Missed {
+		err = writeEvent(w, transport.NewEvent(e))
+		if err != nil {
+			h.Log().Errorf("stream events error: %s", err)
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(h.streams.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		err = rc.Flush()
+		if err != nil {
+			h.Log().Errorf("stream events error: %s", err)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-h.streams.ctx.Done():
+			return
+
+		case e, ok := <-res.Events:
+			// The subscription ended, clients reconnect and resume from their last event
+			if !ok {
+				return
+			}
+
+			err = writeEvent(w, transport.NewEvent(e))
+
+		case <-heartbeat.C:
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		}
+
+		if err != nil {
+			h.Log().Errorf("stream events error: %s", err)
+			return
+		}
+	}
+}
+
+// StopStreams ends the event streams being served, new ones end as soon as they start.
+func (h *APIHandler) StopStreams() {
+	h.streams.stop()
+}
+
+// writeEvent writes the event in the text/event-stream format, its data is a single line of JSON.
+func writeEvent(w io.Writer, e transport.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
+
+// lastEventID returns the ID of the last event the client got, zero if none.
+func lastEventID(r *http.Request) (id uint64, err error) {
+	header := strings.TrimSpace(r.Header.Get(LastEventIDHeader))
+	if header == "" {
+		return 0, nil
+	}
+
+	id, err = strconv.ParseUint(header, 10, 64)
+	if err != nil {
+		return 0, errors.Wrap(InvalidRequestDataErr, "invalid Last-Event-ID header")
+	}
+
+	return id, nil
+}
@@ -0,0 +1,252 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks the responses replayed from a previous request made with the same key.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLen    = 255
+	defaultIdempotencyTTL   = 24 * time.Hour
+	defaultIdempotencyPurge = 10 * time.Minute
+)
+
+// idempotencyHeaders are the response headers stored along with the body, the others are set again on each request.
+var idempotencyHeaders = []string{"Content-Type", "Location", ETagHeader}
+
+type (
+	// idempotency records the responses to requests made with an Idempotency-Key.
+	idempotency struct {
+		repo  port.IdempotencyRepo
+		ttl   time.Duration
+		purge time.Duration
+	}
+
+	// responseRecorder keeps a copy of the response it writes so that it can be stored.
+	responseRecorder struct {
+		http.ResponseWriter
+		status int
+		body   bytes.Buffer
+	}
+)
+
+func newIdempotency(repo port.IdempotencyRepo, cfg *config.Config) idempotency {
+	idem := idempotency{
+		repo:  repo,
+		ttl:   defaultIdempotencyTTL,
+		purge: defaultIdempotencyPurge,
+	}
+
+	if cfg != nil {
+		if secs := cfg.GetInt(cfgKey.APIIdempotencyTTL); secs > 0 {
+			idem.ttl = time.Duration(secs) * time.Second
+		}
+
+		if secs := cfg.GetInt(cfgKey.APIIdempotencyPurge); secs > 0 {
+			idem.purge = time.Duration(secs) * time.Second
+		}
+	}
+
+	return idem
+}
+
+// Idempotent makes POST requests carrying an Idempotency-Key header safe to retry.
+// The response to the first request is stored per user, endpoint and key, and replayed to the retries.
+// A retry whose payload differs from the first request is rejected, as well as one arriving while the
+// first request is still being processed. Server errors are not stored, the request can be retried.
+// Requests without a user go through, keys are scoped to users.
+func (h *APIHandler) Idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if r.Method != http.MethodPost || key == "" || h.idempotency.repo == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !validIdempotencyKey(key) {
+			h.handleError(w, r, InvalidIdempotencyKeyErr)
+			return
+		}
+
+		userID, err := h.User(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			h.handleError(w, r, errors.Wrap(InvalidRequestErr, err.Error()))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		record := model.IdempotencyRecord{
+			UserID:      userID,
+			Method:      r.Method,
+			Path:        r.URL.Path,
+			Key:         key,
+			Fingerprint: fingerprint(r, body),
+			ExpiresAt:   time.Now().Add(h.idempotency.ttl),
+		}
+
+		repo := h.idempotency.repo
+
+		err = repo.CreateIdempotencyRecord(r.Context(), record)
+		if errors.Is(err, port.IdempotencyKeyUsedErr) {
+			h.replay(w, r, record)
+			return
+		}
+		if err != nil {
+			h.handleError(w, r, errors.Wrap(err, "idempotency error"))
+			return
+		}
+
+		// The key is released if the response is not stored, a panic included, so that the request can be retried.
+		// The request context may be done by then.
+		stored := false
+		defer func() {
+			if stored {
+				return
+			}
+
+			err := repo.DeleteIdempotencyRecord(context.Background(), record)
+			if err != nil {
+				h.Log().Errorf("idempotency key release error: %s", err)
+			}
+		}()
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		if rec.status >= http.StatusInternalServerError {
+			return
+		}
+
+		record.Status = rec.status
+		record.Header = map[string][]string{}
+		for _, name := range idempotencyHeaders {
+			if values := rec.Header().Values(name); len(values) > 0 {
+				record.Header[name] = values
+			}
+		}
+		record.Body = rec.body.Bytes()
+
+		err = repo.CompleteIdempotencyRecord(context.Background(), record)
+		if err != nil {
+			h.Log().Errorf("idempotency record error: %s", err)
+			return
+		}
+
+		stored = true
+	})
+}
+
+// replay writes the response stored for the key of the request, if it was made with the same payload.
+func (h *APIHandler) replay(w http.ResponseWriter, r *http.Request, record model.IdempotencyRecord) {
+	found, err := h.idempotency.repo.GetIdempotencyRecord(r.Context(), record.UserID, record.Method, record.Path, record.Key)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err, "idempotency error"))
+		return
+	}
+
+	if found.Fingerprint != record.Fingerprint {
+		h.handleError(w, r, IdempotencyKeyReusedErr)
+		return
+	}
+
+	if !found.Completed() {
+		h.handleError(w, r, IdempotentRequestInProgressErr)
+		return
+	}
+
+	for name, values := range found.Header {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(found.Status)
+
+	_, err = w.Write(found.Body)
+	if err != nil {
+		h.Log().Errorf("idempotency replay error: %s", err)
+	}
+}
+
+// PurgeIdempotencyRecords deletes the expired idempotency records periodically, until the context is done.
+func (h *APIHandler) PurgeIdempotencyRecords(ctx context.Context) error {
+	if h.idempotency.repo == nil {
+		return nil
+	}
+
+	ticker := time.NewTicker(h.idempotency.purge)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case now := <-ticker.C:
+			n, err := h.idempotency.repo.DeleteExpiredIdempotencyRecords(ctx, now)
+			if err != nil {
+				h.Log().Errorf("idempotency purge error: %s", err)
+				continue
+			}
+
+			if n > 0 {
+				h.Log().Debugf("%d expired idempotency records deleted", n)
+			}
+		}
+	}
+}
+
+// validIdempotencyKey allows printable ASCII keys of a bounded length, UUIDs are recommended to clients.
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLen {
+		return false
+	}
+
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fingerprint identifies the payload of a request, its query and body.
+func fingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.URL.RawQuery))
+	hash.Write([]byte{0})
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (rr *responseRecorder) WriteHeader(code int) {
+	rr.status = code
+	rr.ResponseWriter.WriteHeader(code)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
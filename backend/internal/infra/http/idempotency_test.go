@@ -0,0 +1,161 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/infra/db"
+	"github.com/vanillazen/stl/backend/internal/infra/http"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	"github.com/vanillazen/stl/backend/internal/sys/log"
+)
+
+const testUserID = "0792b97b-4f88-42a8-a035-1d0aad0ae7f8"
+
+// idempotencyRepo keeps records in memory, expiration is not checked.
+type idempotencyRepo struct {
+	*sys.SimpleCore
+	records map[string]model.IdempotencyRecord
+}
+
+func newIdempotencyRepo() *idempotencyRepo {
+	return &idempotencyRepo{
+		SimpleCore: sys.NewCore("test-idempotency-repo"),
+		records:    map[string]model.IdempotencyRecord{},
+	}
+}
+
+func (r *idempotencyRepo) DB(ctx context.Context) db.DB {
+	return nil
+}
+
+func (r *idempotencyRepo) CreateIdempotencyRecord(ctx context.Context, record model.IdempotencyRecord) error {
+	id := record.UserID + record.Method + record.Path + record.Key
+	if _, ok := r.records[id]; ok {
+		return port.IdempotencyKeyUsedErr
+	}
+
+	r.records[id] = record
+	return nil
+}
+
+func (r *idempotencyRepo) GetIdempotencyRecord(ctx context.Context, userID, method, path, key string) (model.IdempotencyRecord, error) {
+	record, ok := r.records[userID+method+path+key]
+	if !ok {
+		return record, port.NewIdempotencyNotFoundErr(key)
+	}
+
+	return record, nil
+}
+
+func (r *idempotencyRepo) CompleteIdempotencyRecord(ctx context.Context, record model.IdempotencyRecord) error {
+	r.records[record.UserID+record.Method+record.Path+record.Key] = record
+	return nil
+}
+
+func (r *idempotencyRepo) DeleteIdempotencyRecord(ctx context.Context, record model.IdempotencyRecord) error {
+	delete(r.records, record.UserID+record.Method+record.Path+record.Key)
+	return nil
+}
+
+func (r *idempotencyRepo) DeleteExpiredIdempotencyRecords(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestIdempotent(t *testing.T) {
+	repo := newIdempotencyRepo()
+	opts := []sys.Option{sys.WithConfig(&config.Config{}), sys.WithLogger(log.NewLogger("error"))}
+	h := http.NewAPIHandler(nil, nil, nil, nil, repo, "", opts...)
+
+	calls := 0
+	status := nethttp.StatusCreated
+	var retryWhileHandled func()
+	var handler nethttp.Handler
+	handler = h.Idempotent(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		calls++
+		if retry := retryWhileHandled; retry != nil {
+			retryWhileHandled = nil
+			retry()
+		}
+
+		w.Header().Set("Location", "/lists/1")
+		w.Header().Set(http.ETagHeader, `"1"`)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"call":` + strconv.Itoa(calls) + `}`))
+	}))
+
+	post := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(nethttp.MethodPost, "/lists", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(http.IdempotencyKeyHeader, key)
+		}
+		ctx := context.WithValue(req.Context(), http.UserCtxKey, http.Principal{UserID: testUserID})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req.WithContext(ctx))
+		return w
+	}
+
+	problemCode := func(w *httptest.ResponseRecorder) http.ProblemCode {
+		var problem http.Problem
+		_ = json.Unmarshal(w.Body.Bytes(), &problem)
+		return problem.Code
+	}
+
+	first := post("k1", `{"Name":"List"}`)
+	if first.Code != nethttp.StatusCreated || calls != 1 {
+		t.Fatalf("First: expected %d after 1 call, got %d after %d", nethttp.StatusCreated, first.Code, calls)
+	}
+
+	retry := post("k1", `{"Name":"List"}`)
+	if retry.Code != nethttp.StatusCreated || calls != 1 || retry.Body.String() != first.Body.String() {
+		t.Errorf("Retry: expected the first response replayed, got %d '%s' after %d calls", retry.Code, retry.Body.String(), calls)
+	}
+
+	if retry.Header().Get(http.IdempotentReplayedHeader) != "true" || retry.Header().Get("Location") != "/lists/1" || retry.Header().Get(http.ETagHeader) != `"1"` {
+		t.Errorf("Retry: expected replayed stored headers, got %v", retry.Header())
+	}
+
+	reused := post("k1", `{"Name":"Other"}`)
+	if reused.Code != nethttp.StatusUnprocessableEntity || problemCode(reused) != http.IdempotencyKeyReusedCode {
+		t.Errorf("Reused: expected %d %s, got %d '%s'", nethttp.StatusUnprocessableEntity, http.IdempotencyKeyReusedCode, reused.Code, reused.Body.String())
+	}
+
+	_ = post("", `{"Name":"List"}`)
+	_ = post("", `{"Name":"List"}`)
+	if calls != 3 {
+		t.Errorf("No key: expected every request handled, got %d calls", calls)
+	}
+
+	invalid := post("k\x01", `{}`)
+	if invalid.Code != nethttp.StatusBadRequest || calls != 3 {
+		t.Errorf("Invalid key: expected %d, got %d", nethttp.StatusBadRequest, invalid.Code)
+	}
+
+	// Server errors are not stored, the request can be retried
+	status = nethttp.StatusInternalServerError
+	_ = post("k2", `{}`)
+	status = nethttp.StatusCreated
+	retry = post("k2", `{}`)
+	if retry.Code != nethttp.StatusCreated || calls != 5 || retry.Header().Get(http.IdempotentReplayedHeader) != "" {
+		t.Errorf("Server error: expected the retry handled, got %d after %d calls", retry.Code, calls)
+	}
+
+	// A retry arriving while the first request is handled
+	var concurrent *httptest.ResponseRecorder
+	retryWhileHandled = func() { concurrent = post("k3", `{}`) }
+	_ = post("k3", `{}`)
+	if concurrent == nil || concurrent.Code != nethttp.StatusConflict || problemCode(concurrent) != http.RequestInProgressCode {
+		t.Errorf("In progress: expected %d %s, got %v", nethttp.StatusConflict, http.RequestInProgressCode, concurrent)
+	}
+}
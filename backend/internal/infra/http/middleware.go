@@ -3,13 +3,153 @@ package http
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
+	"runtime/debug"
 	"strings"
 	"time"
 
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
 	"github.com/vanillazen/stl/backend/internal/sys/log"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+	"github.com/vanillazen/stl/backend/internal/transport"
 )
 
+// Request ID
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	maxRequestIDLen = 128
+)
+
+// RequestID sets an ID to the request, in its context and in the X-Request-ID response header.
+// The ID received in the request header is kept if it is a safe value, otherwise a new one is generated.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(reqID) {
+			reqID = uuid.NewUUID().Val
+		}
+
+		w.Header().Set(RequestIDHeader, reqID)
+
+		ctx := context.WithValue(r.Context(), ReqCtxKey, reqID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ReqID returns the ID set to the request by RequestID.
+func ReqID(r *http.Request) string {
+	reqID, _ := r.Context().Value(ReqCtxKey).(string)
+	return reqID
+}
+
+// validRequestID only accepts IDs that are safe to log and echo back.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+
+	for _, c := range id {
+		ok := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_.:", c)
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Real IP
+
+// NewRealIPMiddleware replaces the request remote address by the client one when the request comes from a trusted proxy.
+// The client is the rightmost address in X-Forwarded-For that is not a trusted proxy, the ones on its left can be forged.
+// X-Real-IP is used if there is no X-Forwarded-For. Proxies are IPs or CIDR ranges, invalid ones are logged and ignored.
+func NewRealIPMiddleware(log log.Logger, proxies ...string) Middleware {
+	trusted := parseNets(log, proxies)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip := realIP(r, trusted); ip != "" {
+				r.RemoteAddr = ip
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func realIP(r *http.Request, trusted []*net.IPNet) string {
+	remote := remoteIP(r.RemoteAddr)
+	if remote == nil || !contains(trusted, remote) {
+		return ""
+	}
+
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				return ""
+			}
+
+			if !contains(trusted, ip) {
+				return ip.String()
+			}
+		}
+
+		return ""
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+
+	return ""
+}
+
+// remoteIP returns the IP of a remote address, with or without port.
+func remoteIP(addr string) net.IP {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	return net.ParseIP(host)
+}
+
+func parseNets(log log.Logger, cidrs []string) (nets []*net.IPNet) {
+	for _, c := range cidrs {
+		if !strings.Contains(c, "/") {
+			if ip := net.ParseIP(c); ip != nil && ip.To4() != nil {
+				c += "/32"
+			} else {
+				c += "/128"
+			}
+		}
+
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			log.Errorf("invalid trusted proxy '%s': %s", c, err)
+			continue
+		}
+
+		nets = append(nets, n)
+	}
+
+	return nets
+}
+
+func contains(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Request logger
 
 const (
@@ -30,55 +170,43 @@ func NewReqLogger(log log.Logger) *ReqLogger {
 	return &ReqLogger{log: log}
 }
 
-func NewReqLoggerMiddleware(log log.Logger) func(next http.Handler) http.Handler {
+// NewReqLoggerMiddleware writes an access log line for each request once it is served.
+func NewReqLoggerMiddleware(log log.Logger) Middleware {
 	return func(next http.Handler) http.Handler {
 		rl := NewReqLogger(log)
 
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ww := NewWrapResponseWriter(w)
-			ctx := r.Context()
 
-			t1 := time.Now()
 			defer func() {
-				elapsed := time.Since(t1)
-				bytes := ww.BytesWritten()
-				status := ww.Status()
-
-				entry := rl.NewLogEntry(ctx)
-				entry.Write(status, bytes, w.Header(), elapsed, nil)
+				entry := rl.NewLogEntry(r)
+				entry.Write(ww.Status(), ww.BytesWritten(), w.Header(), time.Since(ww.StartTime()), nil)
 			}()
 
-			next.ServeHTTP(ww, r.WithContext(ctx))
+			next.ServeHTTP(ww, r)
 		})
 	}
 }
 
-func (rl *ReqLogger) NewLogEntry(ctx context.Context) *LogEntry {
-	fields := map[string]string{}
-
-	reqID := ctx.Value(ReqCtxKey)
-	if reqID != nil {
-		fields["req-id"] = reqID.(string)
-	}
-
-	r := ctx.Value(http.Request{}).(*http.Request)
-
+func (rl *ReqLogger) NewLogEntry(r *http.Request) *LogEntry {
 	scheme := "http"
 	if r.TLS != nil {
 		scheme = "https"
 	}
 
-	fields["scheme"] = scheme
-	fields["proto"] = r.Proto
-	fields["method"] = r.Method
-	fields["addr"] = r.RemoteAddr
-	fields["agent"] = r.UserAgent()
-	fields["uri"] = fmt.Sprintf("%s://%s%s", scheme, r.Host, r.RequestURI)
-	fields["ts"] = time.Now().UTC().Format(tsFormat)
-
 	sb := strings.Builder{}
-	for k, v := range fields {
-		sb.WriteString(fmt.Sprintf("%s: %s, ", k, v))
+	fields := [][2]string{
+		{"ts", time.Now().UTC().Format(tsFormat)},
+		{"req-id", ReqID(r)},
+		{"addr", r.RemoteAddr},
+		{"method", r.Method},
+		{"uri", fmt.Sprintf("%s://%s%s", scheme, r.Host, r.RequestURI)},
+		{"proto", r.Proto},
+		{"agent", r.UserAgent()},
+	}
+
+	for _, f := range fields {
+		sb.WriteString(fmt.Sprintf("%s: %q, ", f[0], f[1]))
 	}
 
 	return &LogEntry{
@@ -102,20 +230,21 @@ func (le *LogEntry) Write(status, bytes int, header http.Header, elapsed time.Du
 	le.entry.WriteString(fmt.Sprintf("%s: %d, ", "status", status))
 	le.entry.WriteString(fmt.Sprintf("%s: %d, ", "bytes", bytes))
 	le.entry.WriteString(fmt.Sprintf("%s: %fms", "elapsed", float64(elapsed.Nanoseconds())/1000000.0))
-	le.Log().Debugf("%s", le.entry.String())
+	le.Log().Infof("%s", le.entry.String())
 }
 
 func (le *LogEntry) Panic(v interface{}, stack []byte) {
-	le.entry.WriteString(fmt.Sprintf("%s: %s, ", "stack", string(stack)))
 	le.entry.WriteString(fmt.Sprintf("%s: %s, ", "panic", fmt.Sprintf("%+v", v)))
-	le.Log().Debugf("%s", le.entry.String())
+	le.entry.WriteString(fmt.Sprintf("%s: %s", "stack", string(stack)))
+	le.Log().Errorf("%s", le.entry.String())
 }
 
 type WrapResponseWriter struct {
 	http.ResponseWriter
-	statusCode int
-	bytes      int
-	startTime  time.Time
+	statusCode  int
+	bytes       int
+	startTime   time.Time
+	wroteHeader bool
 }
 
 func NewWrapResponseWriter(w http.ResponseWriter) *WrapResponseWriter {
@@ -128,11 +257,15 @@ func NewWrapResponseWriter(w http.ResponseWriter) *WrapResponseWriter {
 }
 
 func (ww *WrapResponseWriter) WriteHeader(code int) {
-	ww.statusCode = code
+	if !ww.wroteHeader {
+		ww.statusCode = code
+		ww.wroteHeader = true
+	}
 	ww.ResponseWriter.WriteHeader(code)
 }
 
 func (ww *WrapResponseWriter) Write(b []byte) (int, error) {
+	ww.wroteHeader = true
 	bytesWritten, err := ww.ResponseWriter.Write(b)
 	ww.bytes += bytesWritten
 	return bytesWritten, err
@@ -150,13 +283,147 @@ func (ww *WrapResponseWriter) StartTime() time.Time {
 	return ww.startTime
 }
 
+// WroteHeader reports whether the response status was already sent.
+func (ww *WrapResponseWriter) WroteHeader() bool {
+	return ww.wroteHeader
+}
+
+// Unwrap returns the wrapped writer, so that http.ResponseController can flush streamed responses.
+func (ww *WrapResponseWriter) Unwrap() http.ResponseWriter {
+	return ww.ResponseWriter
+}
+
+// Recovery
+
+// Recover turns a handler panic into a 500 response and logs it along with its stack.
+// Panics aborting the response on purpose, http.ErrAbortHandler, are let through.
+func (h *APIHandler) Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww, ok := w.(*WrapResponseWriter)
+		if !ok {
+			ww = NewWrapResponseWriter(w)
+		}
+
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			h.Log().Errorf("panic serving %s %s (req-id: %s): %v\n%s", r.Method, r.URL.Path, ReqID(r), rec, debug.Stack())
+
+			// Nothing else can be sent once the response started
+			if ww.WroteHeader() {
+				return
+			}
+
+			h.handleError(ww, r, errors.Newf("panic: %v", rec))
+		}()
+
+		next.ServeHTTP(ww, r)
+	})
+}
+
+// Authentication
+
+const (
+	bearerScheme = "Bearer"
+	apiKeyScheme = "ApiKey"
+)
+
+// Authenticate verifies the credential of the request, if any, and sets the caller in its context.
+// Both access tokens, "Authorization: Bearer <token>", and API keys, "Authorization: ApiKey <key>",
+// are accepted. Requests without an Authorization header go through unauthenticated, handlers
+// requiring a user reject them; an invalid, expired or revoked credential is rejected right away,
+// as well as a read only API key used on a request that modifies resources.
+func (h *APIHandler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		scheme, credential, _ := strings.Cut(header, " ")
+		credential = strings.TrimSpace(credential)
+
+		var req transport.AuthenticateReq
+		switch {
+		case strings.EqualFold(scheme, bearerScheme):
+			req.AccessToken = credential
+		case strings.EqualFold(scheme, apiKeyScheme):
+			req.APIKey = credential
+		}
+
+		if credential == "" || (req.AccessToken == "" && req.APIKey == "") {
+			h.handleError(w, r, InvalidAuthHeaderErr)
+			return
+		}
+
+		res := h.AuthService().Authenticate(r.Context(), req)
+		if err := res.Err(); err != nil {
+			err = errors.Wrap(err, "authenticate error")
+			h.handleServiceError(w, r, &res, err)
+			return
+		}
+
+		p := Principal{
+			UserID:    res.UserID,
+			SessionID: res.SessionID,
+			APIKeyID:  res.APIKeyID,
+			Scope:     res.Scope,
+		}
+
+		if p.APIKeyID != "" && !p.allows(r.Method) {
+			h.handleError(w, r, InsufficientScopeErr)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), UserCtxKey, p)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequireSession rejects callers authenticated with an API key, the routes it wraps need a user session.
+func (h *APIHandler) RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := h.principal(r)
+		if ok && p.SessionID == "" {
+			h.handleError(w, r, SessionRequiredErr)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Path params
+
+// CheckIDs rejects requests whose path params are not valid IDs, all the API params are UUIDs.
+func (h *APIHandler) CheckIDs(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params, _ := r.Context().Value(ParamsCtxKey).(pathParams)
+		for _, value := range params {
+			if !uuid.Validate(value) {
+				h.handleError(w, r, InvalidURLErr)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 // Entity namespace related
 
+// ListContext sets the ID of the list addressed by the request path in its context.
 func ListContext(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		listID := "a294db43-ef93-4b5b-8a2e-009f5fc4a1ea" // WIP: Extract it from path
-
-		ctx := context.WithValue(r.Context(), ListCtxKey, listID)
+		ctx := context.WithValue(r.Context(), ListCtxKey, PathParam(r, "listID"))
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -0,0 +1,156 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vanillazen/stl/backend/internal/infra/http"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	"github.com/vanillazen/stl/backend/internal/sys/log"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		kept   bool
+	}{
+		{name: "Propagated", header: "req-123_abc.1", kept: true},
+		{name: "Generated", header: "", kept: false},
+		{name: "Unsafe", header: "id\" injected", kept: false},
+		{name: "Too long", header: strings.Repeat("a", 129), kept: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ctxID string
+			h := http.RequestID(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				ctxID = http.ReqID(r)
+			}))
+
+			req := httptest.NewRequest(nethttp.MethodGet, "/", nil)
+			req.Header.Set(http.RequestIDHeader, test.header)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			resID := w.Header().Get(http.RequestIDHeader)
+			if resID == "" || resID != ctxID {
+				t.Fatalf("ID: expected the same non empty ID in context and response, got '%s' and '%s'", ctxID, resID)
+			}
+
+			if (resID == test.header) != test.kept {
+				t.Errorf("ID: expected kept %v, got '%s'", test.kept, resID)
+			}
+		})
+	}
+}
+
+func TestRealIP(t *testing.T) {
+	mw := http.NewRealIPMiddleware(log.NewLogger("error"), "10.0.0.0/8", "192.168.1.1")
+
+	tests := []struct {
+		name     string
+		remote   string
+		xff      []string
+		realIP   string
+		expected string
+	}{
+		{
+			name:     "No proxy",
+			remote:   "203.0.113.7:4000",
+			xff:      []string{"1.2.3.4"},
+			expected: "203.0.113.7:4000",
+		},
+		{
+			name:     "Trusted proxy",
+			remote:   "10.1.2.3:4000",
+			xff:      []string{"1.2.3.4"},
+			expected: "1.2.3.4",
+		},
+		{
+			name:     "Forged hops are skipped",
+			remote:   "10.1.2.3:4000",
+			xff:      []string{"6.6.6.6, 1.2.3.4", "192.168.1.1"},
+			expected: "1.2.3.4",
+		},
+		{
+			name:     "Only proxies",
+			remote:   "192.168.1.1:4000",
+			xff:      []string{"10.0.0.2"},
+			expected: "192.168.1.1:4000",
+		},
+		{
+			name:     "Real IP header",
+			remote:   "10.1.2.3:4000",
+			realIP:   "1.2.3.4",
+			expected: "1.2.3.4",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var addr string
+			h := mw(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				addr = r.RemoteAddr
+			}))
+
+			req := httptest.NewRequest(nethttp.MethodGet, "/", nil)
+			req.RemoteAddr = test.remote
+			for _, v := range test.xff {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			if test.realIP != "" {
+				req.Header.Set("X-Real-IP", test.realIP)
+			}
+
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			if addr != test.expected {
+				t.Errorf("Address: expected '%s', got '%s'", test.expected, addr)
+			}
+		})
+	}
+}
+
+func TestRecover(t *testing.T) {
+	logger := log.NewLogger("info")
+	logs := &bytes.Buffer{}
+	logger.SetInfoOutput(logs)
+	logger.SetErrorOutput(logs)
+
+	opts := []sys.Option{sys.WithConfig(&config.Config{}), sys.WithLogger(logger)}
+	h := http.NewAPIHandler(nil, nil, nil, nil, nil, "", opts...)
+
+	mux := http.NewServeMux("test-mux", opts...)
+	mux.HandleFunc("/panic", func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		panic("boom")
+	})
+	mux.Use(http.RequestID, http.NewReqLoggerMiddleware(logger), h.Recover)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(nethttp.MethodGet, "/panic", nil))
+
+	if w.Code != nethttp.StatusInternalServerError {
+		t.Fatalf("Status: expected %d, got %d", nethttp.StatusInternalServerError, w.Code)
+	}
+
+	var problem http.Problem
+	err := json.Unmarshal(w.Body.Bytes(), &problem)
+	if err != nil || problem.Code != http.InternalCode || problem.Detail != "" {
+		t.Errorf("Body: expected an undisclosed internal error problem, got '%s' (%v)", w.Body.String(), err)
+	}
+
+	out := logs.String()
+	if !strings.Contains(out, "boom") || !strings.Contains(out, "goroutine") {
+		t.Errorf("Log: expected panic value and stack, got '%s'", out)
+	}
+
+	if !strings.Contains(out, "status: 500") || !strings.Contains(out, w.Header().Get(http.RequestIDHeader)) {
+		t.Errorf("Log: expected an access line with status and request ID, got '%s'", out)
+	}
+}
@@ -0,0 +1,198 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/patch"
+	"github.com/vanillazen/stl/backend/internal/sys/validator"
+	"github.com/vanillazen/stl/backend/internal/sys/websocket"
+)
+
+const (
+	ProblemContentType = "application/problem+json"
+
+	problemTypePrefix = "urn:stl:problem:"
+)
+
+// ProblemCode is a stable, machine-readable identifier of an error, clients branch on it instead of parsing messages.
+type ProblemCode string
+
+const (
+	InvalidRequestCode       ProblemCode = "invalid-request"
+	InvalidBodyCode          ProblemCode = "invalid-body"
+	InvalidURLCode           ProblemCode = "invalid-url"
+	ValidationFailedCode     ProblemCode = "validation-failed"
+	UnauthenticatedCode      ProblemCode = "unauthenticated"
+	ForbiddenCode            ProblemCode = "forbidden"
+	InsufficientScopeCode    ProblemCode = "insufficient-scope"
+	SessionRequiredCode      ProblemCode = "session-required"
+	NotFoundCode             ProblemCode = "not-found"
+	ConflictCode             ProblemCode = "conflict"
+	PreconditionFailedCode   ProblemCode = "precondition-failed"
+	PreconditionRequiredCode ProblemCode = "precondition-required"
+	InvalidPatchCode         ProblemCode = "invalid-patch"
+	UnsupportedMediaTypeCode ProblemCode = "unsupported-media-type"
+	IdempotencyKeyReusedCode ProblemCode = "idempotency-key-reused"
+	RequestInProgressCode    ProblemCode = "request-in-progress"
+	MethodNotAllowedCode     ProblemCode = "method-not-allowed"
+	InternalCode             ProblemCode = "internal-error"
+)
+
+type (
+	// Problem is an RFC 7807 error response.
+	// Code, Errors, RequestID and InternalErr are extension members.
+	Problem struct {
+		Type        string                `json:"type"`
+		Title       string                `json:"title"`
+		Status      int                   `json:"status"`
+		Detail      string                `json:"detail,omitempty"`
+		Instance    string                `json:"instance,omitempty"`
+		Code        ProblemCode           `json:"code"`
+		Errors      validator.ValErrorSet `json:"errors,omitempty"`
+		RequestID   string                `json:"requestId,omitempty"`
+		InternalErr string                `json:"internalError,omitempty"`
+	}
+
+	problemKind struct {
+		status int
+		code   ProblemCode
+	}
+
+	sentinelProblem struct {
+		err error
+		problemKind
+	}
+)
+
+// kindProblems maps the kinds of errors to the status and code of the response.
+var kindProblems = map[errors.Kind]problemKind{
+	errors.Invalid:         {status: http.StatusBadRequest, code: InvalidRequestCode},
+	errors.NotFound:        {status: http.StatusNotFound, code: NotFoundCode},
+	errors.Conflict:        {status: http.StatusConflict, code: ConflictCode},
+	errors.Unauthenticated: {status: http.StatusUnauthorized, code: UnauthenticatedCode},
+	errors.Forbidden:       {status: http.StatusForbidden, code: ForbiddenCode},
+}
+
+// sentinelProblems classifies the errors raised by the HTTP layer itself, they take precedence over kinds.
+var sentinelProblems = []sentinelProblem{
+	{err: InvalidRequestErr, problemKind: problemKind{status: http.StatusBadRequest, code: InvalidRequestCode}},
+	{err: InvalidRequestDataErr, problemKind: problemKind{status: http.StatusBadRequest, code: InvalidRequestCode}},
+	{err: InvalidValueTypeErr, problemKind: problemKind{status: http.StatusBadRequest, code: InvalidRequestCode}},
+	{err: NoAssetReqErr, problemKind: problemKind{status: http.StatusBadRequest, code: InvalidRequestCode}},
+	{err: InvalidJSONBodyErr, problemKind: problemKind{status: http.StatusBadRequest, code: InvalidBodyCode}},
+	{err: InvalidURLErr, problemKind: problemKind{status: http.StatusBadRequest, code: InvalidURLCode}},
+	{err: NoUserErr, problemKind: problemKind{status: http.StatusUnauthorized, code: UnauthenticatedCode}},
+	{err: InvalidAuthHeaderErr, problemKind: problemKind{status: http.StatusUnauthorized, code: UnauthenticatedCode}},
+	{err: InsufficientScopeErr, problemKind: problemKind{status: http.StatusForbidden, code: InsufficientScopeCode}},
+	{err: SessionRequiredErr, problemKind: problemKind{status: http.StatusForbidden, code: SessionRequiredCode}},
+	{err: InvalidResourceErr, problemKind: problemKind{status: http.StatusNotFound, code: NotFoundCode}},
+	{err: ListNotFoundErr, problemKind: problemKind{status: http.StatusNotFound, code: NotFoundCode}},
+	{err: MethodNotAllowedErr, problemKind: problemKind{status: http.StatusMethodNotAllowed, code: MethodNotAllowedCode}},
+	{err: IfMatchRequiredErr, problemKind: problemKind{status: http.StatusPreconditionRequired, code: PreconditionRequiredCode}},
+	{err: ETagMismatchErr, problemKind: problemKind{status: http.StatusPreconditionFailed, code: PreconditionFailedCode}},
+	{err: patch.UnsupportedTypeErr, problemKind: problemKind{status: http.StatusUnsupportedMediaType, code: UnsupportedMediaTypeCode}},
+	{err: InvalidIdempotencyKeyErr, problemKind: problemKind{status: http.StatusBadRequest, code: InvalidRequestCode}},
+	{err: IdempotencyKeyReusedErr, problemKind: problemKind{status: http.StatusUnprocessableEntity, code: IdempotencyKeyReusedCode}},
+	{err: IdempotentRequestInProgressErr, problemKind: problemKind{status: http.StatusConflict, code: RequestInProgressCode}},
+	{err: websocket.BadHandshakeErr, problemKind: problemKind{status: http.StatusBadRequest, code: InvalidRequestCode}},
+	{err: websocket.UnsupportedVersionErr, problemKind: problemKind{status: http.StatusUpgradeRequired, code: InvalidRequestCode}},
+	{err: UnknownMsgTypeErr, problemKind: problemKind{status: http.StatusBadRequest, code: InvalidRequestCode}},
+	{err: InvalidPresenceErr, problemKind: problemKind{status: http.StatusBadRequest, code: InvalidRequestCode}},
+	{err: NotSubscribedErr, problemKind: problemKind{status: http.StatusConflict, code: ConflictCode}},
+	{err: TooManySubscriptionsErr, problemKind: problemKind{status: http.StatusTooManyRequests, code: InvalidRequestCode}},
+}
+
+// NewProblem returns a problem with the type and title matching the status and code.
+func NewProblem(status int, code ProblemCode, detail string) Problem {
+	return Problem{
+		Type:   problemTypePrefix + string(code),
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// problemFor maps an error to the problem reported to the client.
+// This is the single place deciding the status of a failed request, errors of no known sentinel or kind
+// are internal ones and their details are not disclosed.
+func problemFor(err error) Problem {
+	for _, sp := range sentinelProblems {
+		if errors.Is(err, sp.err) {
+			return NewProblem(sp.status, sp.code, sentinelDetail(err, sp.err))
+		}
+	}
+
+	// Failed operations of a batch are reported as their own error, pointing at the operation.
+	var opErr port.TaskOpErr
+	if errors.As(err, &opErr) {
+		problem := problemFor(opErr.Err)
+		problem.Detail = fmt.Sprintf("operation %d (%s): %s", opErr.Index, opErr.Op, problem.Detail)
+		return problem
+	}
+
+	// Stale versions are conflicts, reported as failed preconditions of the conditional request.
+	var stale port.VersionErr
+	if errors.As(err, &stale) {
+		return NewProblem(http.StatusPreconditionFailed, PreconditionFailedCode, stale.Error())
+	}
+
+	// Patches that are well-formed JSON but can not be applied are unprocessable, not bad requests.
+	var invalidPatch patch.Error
+	if errors.As(err, &invalidPatch) {
+		return NewProblem(http.StatusUnprocessableEntity, InvalidPatchCode, invalidPatch.Error())
+	}
+
+	kind, ok := kindProblems[errors.KindOf(err)]
+	if !ok {
+		return NewProblem(http.StatusInternalServerError, InternalCode, "")
+	}
+
+	return NewProblem(kind.status, kind.code, problemDetail(err))
+}
+
+// sentinelDetail returns the message of the error wrapping the sentinel in the chain of err, so that the context
+// given where it was raised is kept, i.e.: "a single entity tag is supported in If-Match: invalid request".
+// Contexts added by the layers it went through afterwards are left out, as is the sentinel stack trace.
+func sentinelDetail(err, sentinel error) string {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if errors.Unwrap(err) == sentinel {
+			return errors.Message(err)
+		}
+	}
+
+	return errors.Message(sentinel)
+}
+
+// problemDetail returns the client facing message of an error, only domain errors addressed to the client have one.
+func problemDetail(err error) string {
+	var notFound port.NotFoundErr
+	if errors.As(err, &notFound) {
+		return notFound.Error()
+	}
+
+	var exists port.ExistsErr
+	if errors.As(err, &exists) {
+		return exists.Error()
+	}
+
+	var unauthenticated port.UnauthenticatedErr
+	if errors.As(err, &unauthenticated) {
+		return unauthenticated.Reason
+	}
+
+	var forbidden port.ForbiddenErr
+	if errors.As(err, &forbidden) {
+		return forbidden.Reason
+	}
+
+	return ""
+}
+
+// invalidBody reports a request body that could not be decoded, keeping the decoder error as context.
+func invalidBody(err error) error {
+	return errors.Wrap(InvalidJSONBodyErr, err.Error())
+}
@@ -0,0 +1,142 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vanillazen/stl/backend/internal/infra/http"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	"github.com/vanillazen/stl/backend/internal/sys/log"
+)
+
+func TestProblem(t *testing.T) {
+	opts := []sys.Option{sys.WithConfig(&config.Config{}), sys.WithLogger(log.NewLogger("error"))}
+	rt := http.NewAPIHandler(nil, nil, nil, nil, nil, "", opts...).Router()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		code   http.ProblemCode
+	}{
+		{
+			name:   "Unknown resource",
+			method: nethttp.MethodGet,
+			path:   "/unknown",
+			status: nethttp.StatusNotFound,
+			code:   http.NotFoundCode,
+		},
+		{
+			name:   "Method not allowed",
+			method: nethttp.MethodDelete,
+			path:   "/lists",
+			status: nethttp.StatusMethodNotAllowed,
+			code:   http.MethodNotAllowedCode,
+		},
+		{
+			name:   "Invalid ID",
+			method: nethttp.MethodGet,
+			path:   "/lists/not-an-id",
+			status: nethttp.StatusBadRequest,
+			code:   http.InvalidURLCode,
+		},
+		{
+			name:   "No user",
+			method: nethttp.MethodGet,
+			path:   "/lists?name=a",
+			status: nethttp.StatusUnauthorized,
+			code:   http.UnauthenticatedCode,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := serve(rt, test.method, test.path)
+
+			if w.Code != test.status {
+				t.Fatalf("Status: expected %d, got %d", test.status, w.Code)
+			}
+
+			if ct := w.Header().Get("Content-Type"); ct != http.ProblemContentType {
+				t.Errorf("Content-Type: expected '%s', got '%s'", http.ProblemContentType, ct)
+			}
+
+			var problem http.Problem
+			err := json.Unmarshal(w.Body.Bytes(), &problem)
+			if err != nil {
+				t.Fatalf("Body: %v", err)
+			}
+
+			expected := http.NewProblem(test.status, test.code, problem.Detail)
+			if problem.Type != expected.Type || problem.Title != expected.Title || problem.Status != test.status || problem.Code != test.code {
+				t.Errorf("Problem: expected %+v, got %+v", expected, problem)
+			}
+
+			if problem.Detail == "" || problem.Instance != test.path {
+				t.Errorf("Problem: expected detail and instance '%s', got %+v", test.path, problem)
+			}
+		})
+	}
+}
+
+func TestProblemDetail(t *testing.T) {
+	opts := []sys.Option{sys.WithConfig(&config.Config{}), sys.WithLogger(log.NewLogger("error"))}
+	rt := http.NewAPIHandler(nil, nil, nil, nil, nil, "", opts...).Router()
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		ifMatch string
+		status  int
+		detail  string
+	}{
+		{
+			name:    "Sentinel",
+			method:  nethttp.MethodPut,
+			path:    "/lists/cdc7a443-3c6a-431b-b45a-b14735953a19",
+			ifMatch: "",
+			status:  nethttp.StatusPreconditionRequired,
+			detail:  "If-Match header required",
+		},
+		{
+			name:    "Wrapped sentinel",
+			method:  nethttp.MethodPut,
+			path:    "/lists/cdc7a443-3c6a-431b-b45a-b14735953a19",
+			ifMatch: `"1", "2"`,
+			status:  nethttp.StatusBadRequest,
+			detail:  "a single entity tag is supported in If-Match: invalid request",
+		},
+		{
+			name:    "Toggle task",
+			method:  nethttp.MethodPost,
+			path:    "/lists/cdc7a443-3c6a-431b-b45a-b14735953a19/tasks/a8ff6ee5-f38b-4e3a-a9f7-e11e7a2b1b5e/toggle",
+			ifMatch: "",
+			status:  nethttp.StatusPreconditionRequired,
+			detail:  "If-Match header required",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader(`{}`))
+			req.Header.Set(http.IfMatchHeader, test.ifMatch)
+			ctx := context.WithValue(req.Context(), http.UserCtxKey, http.Principal{UserID: testUserID})
+
+			w := httptest.NewRecorder()
+			rt.ServeHTTP(w, req.WithContext(ctx))
+
+			var problem http.Problem
+			_ = json.Unmarshal(w.Body.Bytes(), &problem)
+			if w.Code != test.status || problem.Detail != test.detail {
+				t.Errorf("Problem: expected %d '%s', got %d '%s'", test.status, test.detail, w.Code, problem.Detail)
+			}
+		})
+	}
+}
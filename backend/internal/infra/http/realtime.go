@@ -0,0 +1,540 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+	"github.com/vanillazen/stl/backend/internal/sys/websocket"
+	"github.com/vanillazen/stl/backend/internal/transport"
+)
+
+// Realtime message types, see RealtimeMsg.
+const (
+	SubscribeMsg    = "subscribe"
+	UnsubscribeMsg  = "unsubscribe"
+	PresenceMsg     = "presence"
+	TypingMsg       = "typing"
+	SubscribedMsg   = "subscribed"
+	UnsubscribedMsg = "unsubscribed"
+	EventMsg        = "event"
+	ErrorMsg        = "error"
+)
+
+// Presence statuses, offline is only sent by the server when a user leaves a list.
+const (
+	OnlinePresence  = "online"
+	AwayPresence    = "away"
+	OfflinePresence = "offline"
+)
+
+const (
+	// realtimeSendBuffer is the number of messages a client can fall behind before it is disconnected.
+	realtimeSendBuffer   = 256
+	realtimeReadLimit    = 8 << 10
+	realtimeMaxSubs      = 100
+	realtimePingInterval = 25 * time.Second
+	realtimeIdleTimeout  = 2 * realtimePingInterval
+	realtimeWriteTimeout = 10 * time.Second
+	realtimeCloseTimeout = 5 * time.Second
+	// realtimeAuthCheck is how often connections check the session or API key they were opened with by default.
+	realtimeAuthCheck = time.Minute
+)
+
+type (
+	// RealtimeMsg is a message of the realtime protocol, sent as JSON text in both directions.
+	// Clients send subscribe, unsubscribe, presence and typing messages. The server acknowledges subscriptions,
+	// forwards the events of the subscribed lists, relays presence and typing of the other users of those lists
+	// and reports failed requests with an error message.
+	// A subscription dropped because the client fell behind is reported as unsubscribed, with a "dropped" status,
+	// clients subscribe again from their last event.
+	RealtimeMsg struct {
+		Type        string
+		ListID      string           `json:",omitempty"`
+		TaskID      string           `json:",omitempty"`
+		UserID      string           `json:",omitempty"`
+		Status      string           `json:",omitempty"`
+		LastEventID uint64           `json:",omitempty"`
+		Event       *transport.Event `json:",omitempty"`
+		Error       *Problem         `json:",omitempty"`
+	}
+
+	// Realtime keeps the open realtime connections, to relay signals between the subscribers of a list
+	// and to close them on shutdown, as the HTTP server lets go of hijacked connections.
+	Realtime struct {
+		*sys.SimpleCore
+		mu        sync.Mutex
+		conns     map[*realtimeConn]struct{}
+		wg        sync.WaitGroup
+		closed    bool
+		authCheck time.Duration
+	}
+
+	// realtimeConn is a realtime connection of a user.
+	// Outgoing messages are queued and written by a single goroutine, a full queue disconnects the client.
+	realtimeConn struct {
+		rt        *Realtime
+		ws        *websocket.Conn
+		userID    string
+		principal Principal
+		send      chan []byte
+		mu        sync.Mutex
+		subs      map[string]*transport.SubscribeEventsRes
+		done      chan struct{}
+		readDone  chan struct{}
+		once      sync.Once
+		closeCode int
+		closeText string
+	}
+)
+
+func NewRealtime(opts ...sys.Option) *Realtime {
+	rt := &Realtime{
+		SimpleCore: sys.NewCore("realtime", opts...),
+		conns:      map[*realtimeConn]struct{}{},
+		authCheck:  realtimeAuthCheck,
+	}
+
+	if cfg := rt.Cfg(); cfg != nil {
+		if secs := cfg.GetInt(cfgKey.APIRealtimeAuthCheck); secs > 0 {
+			rt.authCheck = time.Duration(secs) * time.Second
+		}
+	}
+
+	return rt
+}
+
+// Start blocks until the context is done, then it closes all connections.
+func (rt *Realtime) Start(ctx context.Context) error {
+	<-ctx.Done()
+	return rt.Stop(ctx)
+}
+
+// Stop closes all connections telling clients the server is going away, new ones are refused.
+// It waits for the closing handshakes for a few seconds at most.
+func (rt *Realtime) Stop(ctx context.Context) error {
+	rt.mu.Lock()
+	rt.closed = true
+	for c := range rt.conns {
+		c.close(websocket.CloseGoingAway, "server shutting down")
+	}
+	rt.mu.Unlock()
+
+	closed := make(chan struct{})
+	go func() {
+		rt.wg.Wait()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(realtimeCloseTimeout):
+		rt.Log().Errorf("%s stop: connections still open", rt.Name())
+	}
+
+	return nil
+}
+
+// open registers a connection of the caller, it returns nil once stopped.
+func (rt *Realtime) open(ws *websocket.Conn, p Principal) *realtimeConn {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if rt.closed {
+		return nil
+	}
+
+	c := &realtimeConn{
+		rt:        rt,
+		ws:        ws,
+		userID:    p.UserID,
+		principal: p,
+		send:      make(chan []byte, realtimeSendBuffer),
+		subs:      map[string]*transport.SubscribeEventsRes{},
+		done:      make(chan struct{}),
+		readDone:  make(chan struct{}),
+	}
+
+	rt.conns[c] = struct{}{}
+	rt.wg.Add(1)
+
+	return c
+}
+
+func (rt *Realtime) remove(c *realtimeConn) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if _, ok := rt.conns[c]; ok {
+		delete(rt.conns, c)
+		rt.wg.Done()
+	}
+}
+
+// relay sends the signal to the other connections subscribed to its list.
+func (rt *Realtime) relay(from *realtimeConn, msg RealtimeMsg) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	for c := range rt.conns {
+		if c != from && c.subscribed(msg.ListID) {
+			c.enqueue(msg)
+		}
+	}
+}
+
+// ServeRealtime upgrades the request to a realtime connection
+// @summary Realtime connection
+// @description Opens a WebSocket connection to follow several lists at once and exchange presence and typing
+// @description signals with their other users. Messages are JSON text frames, see RealtimeMsg.
+// @description The connection is closed with a policy violation once the session or API key it was opened with
+// @description expires or is revoked.
+// @id realtime
+// @Success 101
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Router /api/v1/realtime [get]
+// @tags Realtime
+func (h *APIHandler) ServeRealtime(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	p, _ := h.principal(r)
+
+	ws, err := websocket.Upgrade(w, r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err, "realtime error"))
+		return
+	}
+
+	ws.SetReadLimit(realtimeReadLimit)
+	ws.SetIdleTimeout(realtimeIdleTimeout)
+
+	c := h.realtime.open(ws, p)
+	if c == nil {
+		ws.WriteClose(websocket.CloseGoingAway, "server shutting down")
+		ws.Close()
+		return
+	}
+
+	written := make(chan struct{})
+	go func() {
+		c.writeLoop()
+		close(written)
+	}()
+
+	go h.checkRealtimeAuth(ctx, c)
+
+	err = h.readRealtime(ctx, c)
+	c.close(websocket.CloseNormal, "")
+	close(c.readDone)
+	<-written
+
+	c.leave()
+
+	var ce websocket.CloseError
+	if errors.As(err, &ce) && ce.Code != websocket.CloseNormal && ce.Code != websocket.CloseGoingAway {
+		h.Log().Infof("realtime connection of user %s closed: %s", userID, err)
+	}
+}
+
+// checkRealtimeAuth closes the connection once the session or API key it was opened with is no longer active,
+// the logout or the revocation of a key would not end it otherwise.
+func (h *APIHandler) checkRealtimeAuth(ctx context.Context, c *realtimeConn) {
+	ticker := time.NewTicker(h.realtime.authCheck)
+	defer ticker.Stop()
+
+	req := transport.CheckAuthReq{
+		UserID:    c.principal.UserID,
+		SessionID: c.principal.SessionID,
+		APIKeyID:  c.principal.APIKeyID,
+	}
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
+
+		res := h.AuthService().CheckAuth(ctx, req)
+		err := res.Err()
+		if errors.KindOf(err) == errors.Unauthenticated {
+			c.close(websocket.ClosePolicyViolation, "session expired or revoked")
+			return
+		}
+
+		// The connection is kept open while the check can not be made
+		if err != nil {
+			h.Log().Errorf("realtime auth check error: %s", err)
+		}
+	}
+}
+
+// readRealtime handles the messages of the client until the connection is closed.
+func (h *APIHandler) readRealtime(ctx context.Context, c *realtimeConn) error {
+	for {
+		op, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		if op != websocket.TextMessage {
+			c.close(websocket.CloseUnsupportedData, "text messages only")
+			continue
+		}
+
+		var msg RealtimeMsg
+		err = json.Unmarshal(data, &msg)
+		if err != nil {
+			c.fail(msg, invalidBody(err))
+			continue
+		}
+
+		err = h.handleRealtimeMsg(ctx, c, msg)
+		if err != nil {
+			c.fail(msg, err)
+		}
+	}
+}
+
+func (h *APIHandler) handleRealtimeMsg(ctx context.Context, c *realtimeConn, msg RealtimeMsg) error {
+	if msg.Type != SubscribeMsg && msg.Type != UnsubscribeMsg && msg.Type != PresenceMsg && msg.Type != TypingMsg {
+		return UnknownMsgTypeErr
+	}
+
+	if !uuid.Validate(msg.ListID) {
+		return errors.Wrap(InvalidRequestDataErr, "invalid list ID")
+	}
+
+	switch msg.Type {
+	case SubscribeMsg:
+		return h.subscribeRealtime(ctx, c, msg)
+
+	case UnsubscribeMsg:
+		c.enqueue(RealtimeMsg{Type: UnsubscribedMsg, ListID: msg.ListID})
+		if c.unsubscribe(msg.ListID, nil) {
+			h.realtime.relay(c, RealtimeMsg{Type: PresenceMsg, ListID: msg.ListID, UserID: c.userID, Status: OfflinePresence})
+		}
+		return nil
+
+	case PresenceMsg:
+		if msg.Status != OnlinePresence && msg.Status != AwayPresence {
+			return InvalidPresenceErr
+		}
+	}
+
+	// Signals are only relayed on the lists the connection follows, which the user is allowed to read
+	if !c.subscribed(msg.ListID) {
+		return NotSubscribedErr
+	}
+
+	h.realtime.relay(c, RealtimeMsg{
+		Type:   msg.Type,
+		ListID: msg.ListID,
+		TaskID: msg.TaskID,
+		UserID: c.userID,
+		Status: msg.Status,
+	})
+
+	return nil
+}
+
+// subscribeRealtime subscribes the connection to the events of a list, replacing its previous subscription if any.
+func (h *APIHandler) subscribeRealtime(ctx context.Context, c *realtimeConn, msg RealtimeMsg) error {
+	req := transport.SubscribeEventsReq{
+		UserID:      c.userID,
+		ListID:      msg.ListID,
+		LastEventID: msg.LastEventID,
+	}
+
+	res := h.Service().SubscribeEvents(ctx, req)
+	if err := res.Err(); err != nil {
+		err = errors.Wrap(err, "realtime subscribe error")
+		c.enqueue(RealtimeMsg{Type: ErrorMsg, ListID: msg.ListID, Error: problemRef(serviceProblem(&res, err))})
+		return nil
+	}
+
+	if !c.subscribe(msg.ListID, &res) {
+		res.Close()
+		return TooManySubscriptionsErr
+	}
+
+	c.enqueue(RealtimeMsg{Type: SubscribedMsg, ListID: msg.ListID})
+	go c.forward(msg.ListID, &res)
+
+	return nil
+}
+
+// writeLoop writes the queued messages and pings the client, until the connection is closed.
+func (c *realtimeConn) writeLoop() {
+	ping := time.NewTicker(realtimePingInterval)
+	defer ping.Stop()
+
+	for {
+		var err error
+
+		select {
+		case data := <-c.send:
+			c.ws.SetWriteDeadline(time.Now().Add(realtimeWriteTimeout))
+			err = c.ws.WriteMessage(websocket.TextMessage, data)
+
+		case <-ping.C:
+			c.ws.SetWriteDeadline(time.Now().Add(realtimeWriteTimeout))
+			err = c.ws.WriteControl(websocket.PingMessage, nil)
+
+		case <-c.done:
+			// Clients answer the close frame and their answer ends the read loop
+			err = c.ws.WriteClose(c.closeCode, c.closeText)
+			if err == nil {
+				select {
+				case <-c.readDone:
+				case <-time.After(realtimeCloseTimeout):
+				}
+			}
+
+			c.ws.Close()
+			return
+		}
+
+		// A failed write leaves the connection unusable, there is no closing handshake
+		if err != nil {
+			c.ws.Close()
+			c.close(websocket.CloseAbnormal, err.Error())
+			return
+		}
+	}
+}
+
+// forward queues the missed and live events of a subscription until it ends.
+func (c *realtimeConn) forward(listID string, res *transport.SubscribeEventsRes) {
+	for _, e := range res.Missed {
+		c.enqueue(eventMsg(e))
+	}
+
+	for {
+		select {
+		case <-c.done:
+			return
+
+		case e, ok := <-res.Events:
+			if ok {
+				c.enqueue(eventMsg(e))
+				continue
+			}
+
+			// Unless the client unsubscribed, the hub dropped the subscription
+			if c.unsubscribe(listID, res) {
+				c.enqueue(RealtimeMsg{Type: UnsubscribedMsg, ListID: listID, Status: "dropped"})
+			}
+			return
+		}
+	}
+}
+
+// enqueue queues a message to the client, a client that fell too far behind is disconnected.
+func (c *realtimeConn) enqueue(msg RealtimeMsg) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		c.rt.Log().Errorf("realtime message encoding error: %s", err)
+		return
+	}
+
+	select {
+	case <-c.done:
+	case c.send <- data:
+	default:
+		c.close(websocket.CloseTryAgainLater, "client too slow")
+	}
+}
+
+// fail reports the error of a client message.
+func (c *realtimeConn) fail(msg RealtimeMsg, err error) {
+	c.enqueue(RealtimeMsg{Type: ErrorMsg, ListID: msg.ListID, Error: problemRef(problemFor(err))})
+}
+
+// close starts closing the connection with the code, only the first call has effect.
+func (c *realtimeConn) close(code int, text string) {
+	c.once.Do(func() {
+		c.closeCode = code
+		c.closeText = text
+		close(c.done)
+	})
+}
+
+func (c *realtimeConn) subscribe(listID string, res *transport.SubscribeEventsRes) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prev, ok := c.subs[listID]
+	if !ok && len(c.subs) >= realtimeMaxSubs {
+		return false
+	}
+
+	c.subs[listID] = res
+	if ok {
+		prev.Close()
+	}
+
+	return true
+}
+
+// unsubscribe ends the subscription to the list, if res is not nil only if it is the current one.
+// It returns true if the subscription was ended by this call.
+func (c *realtimeConn) unsubscribe(listID string, res *transport.SubscribeEventsRes) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current, ok := c.subs[listID]
+	if !ok || (res != nil && current != res) {
+		return false
+	}
+
+	delete(c.subs, listID)
+	current.Close()
+
+	return true
+}
+
+func (c *realtimeConn) subscribed(listID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.subs[listID]
+	return ok
+}
+
+// leave ends the subscriptions of a closed connection, telling the other users of its lists.
+func (c *realtimeConn) leave() {
+	c.mu.Lock()
+	subs := c.subs
+	c.subs = map[string]*transport.SubscribeEventsRes{}
+	c.mu.Unlock()
+
+	c.rt.remove(c)
+
+	for listID, res := range subs {
+		res.Close()
+		c.rt.relay(c, RealtimeMsg{Type: PresenceMsg, ListID: listID, UserID: c.userID, Status: OfflinePresence})
+	}
+}
+
+func eventMsg(e model.Event) RealtimeMsg {
+	event := transport.NewEvent(e)
+	return RealtimeMsg{Type: EventMsg, ListID: e.ListID, Event: &event}
+}
+
+func problemRef(p Problem) *Problem {
+	return &p
+}
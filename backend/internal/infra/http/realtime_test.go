@@ -0,0 +1,491 @@
+package http_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/domain/service"
+	"github.com/vanillazen/stl/backend/internal/infra/http"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	"github.com/vanillazen/stl/backend/internal/sys/log"
+	"github.com/vanillazen/stl/backend/internal/sys/websocket"
+	"github.com/vanillazen/stl/backend/internal/transport"
+)
+
+const (
+	testUser2ID = "b1c20e60-ec1c-4fae-97b9-b4d0578b0123"
+	testUser3ID = "7d399e9e-9df0-4dcb-a733-3d4a8be80123"
+	testList1ID = "cdc7a443-3c6a-431b-b45a-b14735953a19"
+	testList2ID = "70a4a418-7b2b-4c2b-95b3-4e1656c81234"
+	// unreadableListID is a list no user can read.
+	unreadableListID = "00000000-0000-4000-8000-000000000000"
+
+	wsTestKey = "dGhlIHNhbXBsZSBub25jZQ=="
+)
+
+// realtimeService subscribes users to any list but the unreadable one. The events of the last subscription of a
+// user to a list are kept, to publish to it or drop it.
+type realtimeService struct {
+	service.ListService
+	mu     sync.Mutex
+	subs   map[string]chan model.Event
+	closed map[string]bool
+}
+
+func newRealtimeService() *realtimeService {
+	return &realtimeService{
+		subs:   map[string]chan model.Event{},
+		closed: map[string]bool{},
+	}
+}
+
+func (s *realtimeService) SubscribeEvents(ctx context.Context, req transport.SubscribeEventsReq) transport.SubscribeEventsRes {
+	if req.ListID == unreadableListID {
+		return transport.NewSubscribeEventsRes(nil, port.NewListNotFoundErr(req.ListID), nil)
+	}
+
+	key := req.UserID + "/" + req.ListID
+	events := make(chan model.Event)
+
+	s.mu.Lock()
+	s.subs[key] = events
+	s.closed[key] = false
+	s.mu.Unlock()
+
+	res := transport.NewSubscribeEventsRes(nil, nil, nil)
+	res.FromSubscription(events, func() {
+		s.mu.Lock()
+		s.closed[key] = true
+		s.mu.Unlock()
+	}, nil)
+
+	return res
+}
+
+func (s *realtimeService) events(userID, listID string) chan model.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.subs[userID+"/"+listID]
+}
+
+func (s *realtimeService) isClosed(userID, listID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closed[userID+"/"+listID]
+}
+
+// realtimeAuth reports the revoked sessions as no longer active.
+type realtimeAuth struct {
+	service.AuthService
+	mu      sync.Mutex
+	revoked map[string]bool
+}
+
+func (a *realtimeAuth) CheckAuth(ctx context.Context, req transport.CheckAuthReq) transport.CheckAuthRes {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.revoked[req.SessionID] {
+		return transport.NewCheckAuthRes(nil, port.NewUnauthenticatedErr("session expired or revoked"), nil)
+	}
+
+	return transport.NewCheckAuthRes(nil, nil, nil)
+}
+
+func (a *realtimeAuth) revoke(sessionID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.revoked[sessionID] = true
+}
+
+// realtimeClient is the client end of a realtime connection, written and read frame by frame.
+type realtimeClient struct {
+	t  *testing.T
+	nc net.Conn
+	br *bufio.Reader
+}
+
+func TestRealtimeSubscribe(t *testing.T) {
+	_, _, _, url := newRealtimeServer(t)
+	c := dialRealtime(t, url, testUserID, "s1")
+
+	c.send(http.RealtimeMsg{Type: http.SubscribeMsg, ListID: unreadableListID})
+	msg := c.expect(http.ErrorMsg)
+	if msg.ListID != unreadableListID || msg.Error == nil || msg.Error.Status != nethttp.StatusNotFound {
+		t.Errorf("Unreadable list: expected a not found error, got %+v", msg)
+	}
+
+	// No subscription was made, signals on the list are rejected
+	c.send(http.RealtimeMsg{Type: http.TypingMsg, ListID: unreadableListID})
+	msg = c.expect(http.ErrorMsg)
+	if msg.Error == nil || msg.Error.Status != nethttp.StatusConflict {
+		t.Errorf("Not subscribed: expected a conflict error, got %+v", msg)
+	}
+
+	c.send(http.RealtimeMsg{Type: http.SubscribeMsg, ListID: testList1ID})
+	msg = c.expect(http.SubscribedMsg)
+	if msg.ListID != testList1ID {
+		t.Errorf("Subscribe: expected list '%s', got '%s'", testList1ID, msg.ListID)
+	}
+
+	c.send(http.RealtimeMsg{Type: "shout", ListID: testList1ID})
+	msg = c.expect(http.ErrorMsg)
+	if msg.Error == nil || msg.Error.Status != nethttp.StatusBadRequest {
+		t.Errorf("Unknown type: expected a bad request error, got %+v", msg)
+	}
+}
+
+func TestRealtimeRelay(t *testing.T) {
+	_, svc, _, url := newRealtimeServer(t)
+	a := dialRealtime(t, url, testUserID, "s1")
+	b := dialRealtime(t, url, testUser2ID, "s2")
+	c := dialRealtime(t, url, testUser3ID, "s3")
+
+	a.subscribe(testList1ID)
+	b.subscribe(testList1ID)
+	c.subscribe(testList2ID)
+
+	a.send(http.RealtimeMsg{Type: http.PresenceMsg, ListID: testList1ID, Status: http.OnlinePresence})
+	msg := b.expect(http.PresenceMsg)
+	if msg.ListID != testList1ID || msg.UserID != testUserID || msg.Status != http.OnlinePresence {
+		t.Errorf("Presence: expected user 1 online in list 1, got %+v", msg)
+	}
+
+	a.send(http.RealtimeMsg{Type: http.TypingMsg, ListID: testList1ID, TaskID: "t1"})
+	msg = b.expect(http.TypingMsg)
+	if msg.ListID != testList1ID || msg.UserID != testUserID || msg.TaskID != "t1" {
+		t.Errorf("Typing: expected user 1 typing in task t1, got %+v", msg)
+	}
+
+	// The signals were relayed before b got them, neither the sender nor other lists subscribers got any
+	for _, client := range []*realtimeClient{a, c} {
+		client.send(http.RealtimeMsg{Type: http.UnsubscribeMsg, ListID: unreadableListID})
+		client.expect(http.UnsubscribedMsg)
+	}
+
+	a.send(http.RealtimeMsg{Type: http.PresenceMsg, ListID: testList1ID, Status: "busy"})
+	msg = a.expect(http.ErrorMsg)
+	if msg.Error == nil || msg.Error.Status != nethttp.StatusBadRequest {
+		t.Errorf("Invalid presence: expected a bad request error, got %+v", msg)
+	}
+
+	svc.events(testUser2ID, testList1ID) <- model.Event{ID: 7, Type: model.TaskCreatedEvent, ListID: testList1ID}
+	msg = b.expect(http.EventMsg)
+	if msg.ListID != testList1ID || msg.Event == nil || msg.Event.ID != 7 {
+		t.Errorf("Event: expected event 7 of list 1, got %+v", msg)
+	}
+}
+
+func TestRealtimeUnsubscribe(t *testing.T) {
+	_, svc, _, url := newRealtimeServer(t)
+	a := dialRealtime(t, url, testUserID, "s1")
+	b := dialRealtime(t, url, testUser2ID, "s2")
+
+	a.subscribe(testList1ID)
+	b.subscribe(testList1ID)
+
+	a.send(http.RealtimeMsg{Type: http.UnsubscribeMsg, ListID: testList1ID})
+	msg := a.expect(http.UnsubscribedMsg)
+	if msg.ListID != testList1ID || msg.Status != "" {
+		t.Errorf("Unsubscribe: expected list 1 without status, got %+v", msg)
+	}
+
+	msg = b.expect(http.PresenceMsg)
+	if msg.UserID != testUserID || msg.Status != http.OfflinePresence {
+		t.Errorf("Unsubscribe: expected user 1 offline, got %+v", msg)
+	}
+
+	if !svc.isClosed(testUserID, testList1ID) {
+		t.Errorf("Unsubscribe: expected the subscription closed")
+	}
+
+	// Subscriptions ended by the hub are reported as dropped
+	close(svc.events(testUser2ID, testList1ID))
+	msg = b.expect(http.UnsubscribedMsg)
+	if msg.ListID != testList1ID || msg.Status != "dropped" {
+		t.Errorf("Dropped: expected list 1 dropped, got %+v", msg)
+	}
+
+	b.send(http.RealtimeMsg{Type: http.TypingMsg, ListID: testList1ID})
+	msg = b.expect(http.ErrorMsg)
+	if msg.Error == nil || msg.Error.Status != nethttp.StatusConflict {
+		t.Errorf("Dropped: expected a conflict error, got %+v", msg)
+	}
+}
+
+func TestRealtimeMaxSubscriptions(t *testing.T) {
+	_, _, _, url := newRealtimeServer(t)
+	c := dialRealtime(t, url, testUserID, "s1")
+
+	// As many as allowed, see realtimeMaxSubs
+	for i := 1; i <= 100; i++ {
+		c.subscribe(fmt.Sprintf("00000000-0000-4000-8000-%012d", i))
+	}
+
+	c.send(http.RealtimeMsg{Type: http.SubscribeMsg, ListID: testList1ID})
+	msg := c.expect(http.ErrorMsg)
+	if msg.ListID != testList1ID || msg.Error == nil || msg.Error.Status != nethttp.StatusTooManyRequests {
+		t.Errorf("Too many: expected a too many requests error, got %+v", msg)
+	}
+
+	// Subscribing again replaces the subscription
+	c.subscribe(fmt.Sprintf("00000000-0000-4000-8000-%012d", 1))
+}
+
+func TestRealtimeSlowClient(t *testing.T) {
+	_, svc, _, url := newRealtimeServer(t)
+	c := dialRealtime(t, url, testUserID, "s1")
+	c.subscribe(testList1ID)
+
+	// Events are published while the client reads none, until the connection stops taking them
+	events := svc.events(testUserID, testList1ID)
+	big := model.Event{Type: model.TaskUpdatedEvent, ListID: testList1ID, Task: &model.Task{Description: strings.Repeat("x", 16<<10)}}
+
+	disconnected := false
+	for i := 0; i < 10000 && !disconnected; i++ {
+		select {
+		case events <- big:
+		case <-time.After(time.Second):
+			disconnected = true
+		}
+	}
+
+	if !disconnected {
+		t.Fatalf("Slow client: expected it disconnected")
+	}
+
+	c.expectClose(websocket.CloseTryAgainLater)
+}
+
+func TestRealtimeStop(t *testing.T) {
+	h, _, _, url := newRealtimeServer(t)
+	c := dialRealtime(t, url, testUserID, "s1")
+	c.subscribe(testList1ID)
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- h.Realtime().Stop(context.Background())
+	}()
+
+	c.expectClose(websocket.CloseGoingAway)
+
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("Stop: unexpected '%v'", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("Stop: expected it to return once the connection closed")
+	}
+
+	// Connections opened afterwards are closed right away
+	late := dialRealtime(t, url, testUserID, "s1")
+	late.expectClose(websocket.CloseGoingAway)
+}
+
+func TestRealtimeSessionRevoked(t *testing.T) {
+	_, _, auth, url := newRealtimeServer(t)
+	c := dialRealtime(t, url, testUserID, "s1")
+	other := dialRealtime(t, url, testUser2ID, "s2")
+	c.subscribe(testList1ID)
+	other.subscribe(testList1ID)
+
+	auth.revoke("s1")
+
+	// Checked every second
+	c.expectClose(websocket.ClosePolicyViolation)
+
+	msg := other.expect(http.PresenceMsg)
+	if msg.UserID != testUserID || msg.Status != http.OfflinePresence {
+		t.Errorf("Revoked: expected user 1 offline, got %+v", msg)
+	}
+
+	other.subscribe(testList2ID)
+}
+
+// newRealtimeServer serves realtime connections of the user and session set in the query of the request,
+// sessions are checked every second.
+func newRealtimeServer(t *testing.T) (*http.APIHandler, *realtimeService, *realtimeAuth, string) {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.SetValues(map[string]string{
+		config.Key.APIRealtimeAuthCheck: "1",
+	})
+
+	opts := []sys.Option{sys.WithConfig(cfg), sys.WithLogger(log.NewLogger("error"))}
+	svc := newRealtimeService()
+	auth := &realtimeAuth{revoked: map[string]bool{}}
+	h := http.NewAPIHandler(svc, nil, auth, nil, nil, "", opts...)
+
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		p := http.Principal{UserID: r.URL.Query().Get("user"), SessionID: r.URL.Query().Get("session")}
+		h.ServeRealtime(w, r.WithContext(context.WithValue(r.Context(), http.UserCtxKey, p)))
+	}))
+
+	// Clients are closed first, as cleanups run in reverse order
+	t.Cleanup(func() {
+		_ = h.Realtime().Stop(context.Background())
+		srv.Close()
+	})
+
+	return h, svc, auth, srv.URL
+}
+
+// dialRealtime opens a realtime connection of the user and completes the handshake.
+func dialRealtime(t *testing.T, url, userID, sessionID string) *realtimeClient {
+	t.Helper()
+
+	nc, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { _ = nc.Close() })
+
+	_, err = io.WriteString(nc, "GET /?user="+userID+"&session="+sessionID+" HTTP/1.1\r\nHost: test\r\n"+
+		"Connection: Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: "+wsTestKey+"\r\n\r\n")
+	if err != nil {
+		t.Fatalf("Handshake: %v", err)
+	}
+
+	br := bufio.NewReader(nc)
+	res, err := nethttp.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("Handshake: %v", err)
+	}
+
+	if res.StatusCode != nethttp.StatusSwitchingProtocols {
+		t.Fatalf("Handshake: expected switching protocols, got %d", res.StatusCode)
+	}
+
+	return &realtimeClient{t: t, nc: nc, br: br}
+}
+
+func (c *realtimeClient) subscribe(listID string) {
+	c.t.Helper()
+
+	c.send(http.RealtimeMsg{Type: http.SubscribeMsg, ListID: listID})
+	msg := c.expect(http.SubscribedMsg)
+	if msg.ListID != listID {
+		c.t.Fatalf("Subscribe: expected list '%s', got '%s'", listID, msg.ListID)
+	}
+}
+
+func (c *realtimeClient) send(msg http.RealtimeMsg) {
+	c.t.Helper()
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		c.t.Fatalf("Send: %v", err)
+	}
+
+	c.write(websocket.TextMessage, data)
+}
+
+// write writes a masked client frame, short enough to have its length in the header.
+func (c *realtimeClient) write(op websocket.Opcode, data []byte) {
+	c.t.Helper()
+
+	if len(data) > 125 {
+		c.t.Fatalf("Write frame: payload of %d bytes too long", len(data))
+	}
+
+	mask := []byte{1, 2, 3, 4}
+	frame := append([]byte{0x80 | byte(op), 0x80 | byte(len(data))}, mask...)
+	for i, b := range data {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	_, err := c.nc.Write(frame)
+	if err != nil {
+		c.t.Fatalf("Write frame: %v", err)
+	}
+}
+
+// readFrame reads a server frame, they are neither masked nor fragmented.
+func (c *realtimeClient) readFrame() (websocket.Opcode, []byte) {
+	c.t.Helper()
+
+	_ = c.nc.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	header := make([]byte, 2)
+	_, err := io.ReadFull(c.br, header)
+	if err != nil {
+		c.t.Fatalf("Read frame: %v", err)
+	}
+
+	n := uint64(header[1] & 0x7f)
+	switch n {
+	case 126:
+		ext := make([]byte, 2)
+		_, err = io.ReadFull(c.br, ext)
+		n = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		_, err = io.ReadFull(c.br, ext)
+		n = binary.BigEndian.Uint64(ext)
+	}
+	if err != nil {
+		c.t.Fatalf("Read frame: %v", err)
+	}
+
+	data := make([]byte, n)
+	_, err = io.ReadFull(c.br, data)
+	if err != nil {
+		c.t.Fatalf("Read frame: %v", err)
+	}
+
+	return websocket.Opcode(header[0] & 0x0f), data
+}
+
+// expect reads the next message, which must be of the type.
+func (c *realtimeClient) expect(msgType string) http.RealtimeMsg {
+	c.t.Helper()
+
+	op, data := c.readFrame()
+
+	var msg http.RealtimeMsg
+	err := json.Unmarshal(data, &msg)
+	if op != websocket.TextMessage || err != nil || msg.Type != msgType {
+		c.t.Fatalf("Message: expected %s, got op %d '%s'", msgType, op, data)
+	}
+
+	return msg
+}
+
+// expectClose skips the messages queued before the close frame, which must have the code, and answers it.
+func (c *realtimeClient) expectClose(code int) {
+	c.t.Helper()
+
+	for {
+		op, data := c.readFrame()
+		if op != websocket.CloseMessage {
+			continue
+		}
+
+		if len(data) < 2 || int(binary.BigEndian.Uint16(data)) != code {
+			c.t.Fatalf("Close: expected code %d, got '%x'", code, data)
+		}
+
+		c.write(websocket.CloseMessage, data[:2])
+		return
+	}
+}
@@ -1,17 +1,39 @@
 package http
 
 import (
+	"encoding/json"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/patch"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+	"github.com/vanillazen/stl/backend/internal/transport"
 )
 
 type ContextKey string
 
+// Context keys are typed so that they can not collide with values set by other packages.
 const (
-	ReqCtxKey  = "req"
-	UserCtxKey = "user"
-	ListCtxKey = "list"
+	ReqCtxKey  ContextKey = "req"
+	UserCtxKey ContextKey = "user"
+	ListCtxKey ContextKey = "list"
 )
 
+// Principal identifies the authenticated caller of a request.
+// Callers using an access token have a SessionID, those using an API key have an APIKeyID and a Scope.
+type Principal struct {
+	UserID    string
+	SessionID string
+	APIKeyID  string
+	Scope     string
+}
+
 type AssetRequest struct {
 	Type        string `json:"type"`
 	Action      string `json:"action"`
@@ -20,37 +42,31 @@ type AssetRequest struct {
 }
 
 const (
-	UserIDCtxKey   = "resource"
-	ResourceCtxKey = "resource"
-	AssetReqCtxKey = "assetreq"
+	AssetReqCtxKey ContextKey = "assetreq"
 )
 
-func (h *APIHandler) userID(r *http.Request) (userID string, ok bool) {
-	value := r.Context().Value(UserIDCtxKey)
-	if value == nil {
-		return userID, false
+// User returns the ID of the authenticated user set in the request context by Authenticate.
+func (h *APIHandler) User(r *http.Request) (userID string, err error) {
+	p, ok := h.principal(r)
+	if !ok || !uuid.Validate(p.UserID) {
+		return "", NoUserErr
 	}
 
-	userID, ok = value.(string)
-	if !ok {
-		return userID, false
-	}
-
-	return userID, true
+	return p.UserID, nil
 }
 
-func (h *APIHandler) resource(r *http.Request) (ri ResourceInfo, ok bool) {
-	value := r.Context().Value(ResourceCtxKey)
+func (h *APIHandler) principal(r *http.Request) (p Principal, ok bool) {
+	value := r.Context().Value(UserCtxKey)
 	if value == nil {
-		return ri, false
+		return p, false
 	}
 
-	ri, ok = value.(ResourceInfo)
+	p, ok = value.(Principal)
 	if !ok {
-		return ri, false
+		return p, false
 	}
 
-	return ri, true
+	return p, true
 }
 
 func (h *APIHandler) assetReq(r *http.Request) (req AssetRequest, ok bool) {
@@ -66,3 +82,92 @@ func (h *APIHandler) assetReq(r *http.Request) (req AssetRequest, ok bool) {
 
 	return req, true
 }
+
+// allows reports whether the principal scope permits a request with the given method.
+func (p Principal) allows(method string) bool {
+	if p.APIKeyID == "" {
+		return true
+	}
+
+	key := model.APIKey{Scope: p.Scope}
+	return key.Allows(!isSafeMethod(method))
+}
+
+// isSafeMethod reports whether the method does not modify resources.
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	return false
+}
+
+// AcceptPatchHeader lists the patch formats a resource accepts, it is sent in the responses to PATCH requests.
+const AcceptPatchHeader = "Accept-Patch"
+
+// acceptPatch is the value of the Accept-Patch header.
+var acceptPatch = strings.Join(patch.Types(), ", ")
+
+// patchBody returns the media type and document of a PATCH request.
+// The media type must be one of the supported patch formats and the document well-formed JSON.
+func patchBody(r *http.Request) (mediaType string, body []byte, err error) {
+	mediaType, _, err = mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || !patch.Supported(mediaType) {
+		return mediaType, nil, errors.Wrap(patch.UnsupportedTypeErr, r.Header.Get("Content-Type"))
+	}
+
+	body, err = io.ReadAll(r.Body)
+	if err != nil {
+		return mediaType, nil, invalidBody(err)
+	}
+
+	if !json.Valid(body) {
+		return mediaType, nil, errors.Wrap(InvalidJSONBodyErr, "malformed patch document")
+	}
+
+	return mediaType, body, nil
+}
+
+// Collection query parameters, other parameters are read as filters.
+const (
+	limitParam = "limit"
+	pageParam  = "page"
+	sortParam  = "sort"
+
+	searchParam = "q"
+)
+
+// query returns the collection parameters of the request, filters are taken from the given parameters only.
+func query(r *http.Request, filters ...string) transport.Query {
+	values := r.URL.Query()
+
+	q := transport.Query{
+		Limit:   values.Get(limitParam),
+		Page:    values.Get(pageParam),
+		Sort:    values.Get(sortParam),
+		Filters: map[string]string{},
+	}
+
+	for _, f := range filters {
+		if values.Has(f) {
+			q.Filters[f] = values.Get(f)
+		}
+	}
+
+	return q
+}
+
+// pageURL returns the URL of the request, as sent by the client, for another page of the collection.
+func pageURL(r *http.Request, page int) string {
+	u, err := url.ParseRequestURI(r.RequestURI)
+	if err != nil || r.RequestURI == "" {
+		u = &url.URL{Path: r.URL.Path, RawQuery: r.URL.RawQuery}
+	}
+
+	values := u.Query()
+	values.Set(pageParam, strconv.Itoa(page))
+	u.RawQuery = values.Encode()
+
+	return u.RequestURI()
+}
@@ -6,24 +6,90 @@ import (
 
 	"github.com/vanillazen/stl/backend/internal/sys/config"
 	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/validator"
+	"github.com/vanillazen/stl/backend/internal/transport"
 )
 
 type (
 	APIResponse struct {
 		Count  int         `json:"count,omitempty"`
 		Pages  int         `json:"pages,omitempty"`
+		Total  int         `json:"total,omitempty"`
+		Links  *Links      `json:"links,omitempty"`
 		Data   interface{} `json:"data,omitempty"`
 		Status `json:"error,omitempty"`
 	}
 
+	// Links to the pages next to the one of a collection response, empty at its ends.
+	Links struct {
+		Next string `json:"next,omitempty"`
+		Prev string `json:"prev,omitempty"`
+	}
+
 	Status struct {
 		OK          bool
 		Message     string `json:"message,omitempty"`
 		InternalErr string `json:"internalError,omitempty"`
 	}
+
+	// serviceRes is satisfied by transport responses returned from service.
+	serviceRes interface {
+		Msg() string
+		ValidationErrors() validator.ValErrorSet
+		Err() error
+	}
 )
 
 func (h *APIHandler) handleSuccess(w http.ResponseWriter, payload interface{}, count, pages int, msg ...string) {
+	h.handleSuccessWithStatus(w, http.StatusOK, payload, count, pages, msg...)
+}
+
+// handlePage writes a page of a collection, with its counts and the links to the next and previous ones.
+func (h *APIHandler) handlePage(w http.ResponseWriter, r *http.Request, payload interface{}, count int, page transport.Page) {
+	response := APIResponse{
+		Count: count,
+		Pages: page.Pages(),
+		Total: page.Total,
+		Data:  payload,
+		Status: Status{
+			OK: true,
+		},
+	}
+
+	var links Links
+	if page.Number < page.Pages() {
+		links.Next = pageURL(r, page.Number+1)
+	}
+
+	// Past the last page the previous link points to the last one
+	if prev := page.Number - 1; prev > 0 {
+		if prev > page.Pages() {
+			prev = page.Pages()
+		}
+		links.Prev = pageURL(r, prev)
+	}
+
+	if links != (Links{}) {
+		response.Links = &links
+	}
+
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+func (h *APIHandler) handleCreated(w http.ResponseWriter, payload interface{}, msg ...string) {
+	h.handleSuccessWithStatus(w, http.StatusCreated, payload, 1, 1, msg...)
+}
+
+func (h *APIHandler) handleNoContent(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleNotModified answers a conditional GET whose client already has the current version of the resource.
+func (h *APIHandler) handleNotModified(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNotModified)
+}
+
+func (h *APIHandler) handleSuccessWithStatus(w http.ResponseWriter, httpStatus int, payload interface{}, count, pages int, msg ...string) {
 	var m string
 	if len(msg) > 0 {
 		m = msg[0]
@@ -39,41 +105,59 @@ func (h *APIHandler) handleSuccess(w http.ResponseWriter, payload interface{}, c
 		},
 	}
 
-	w.WriteHeader(http.StatusOK)
+	h.writeJSON(w, httpStatus, response)
+}
+
+func (h *APIHandler) writeJSON(w http.ResponseWriter, httpStatus int, response APIResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
 	err := json.NewEncoder(w).Encode(response)
 	if err != nil {
 		h.Log().Error(errors.Wrap(err, "error encoding handler success"))
 	}
+}
 
-	return
+// handleError writes the problem describing err, see problemFor.
+func (h *APIHandler) handleError(w http.ResponseWriter, r *http.Request, handlerError error) {
+	h.writeProblem(w, r, problemFor(handlerError), handlerError)
 }
 
-func (h *APIHandler) handleError(w http.ResponseWriter, httpStatus int, handlerError error, message ...string) {
-	var msg string
-	if len(message) > 0 {
-		msg = message[0]
+// handleServiceError writes the problem describing a failed service response, including its validation errors.
+func (h *APIHandler) handleServiceError(w http.ResponseWriter, r *http.Request, res serviceRes, handlerError error) {
+	h.writeProblem(w, r, serviceProblem(res, handlerError), handlerError)
+}
+
+// serviceProblem maps a failed service response to the problem reported to the client, see problemFor.
+func serviceProblem(res serviceRes, err error) Problem {
+	valErrs := res.ValidationErrors()
+	if valErrs.IsEmpty() {
+		return problemFor(err)
 	}
 
-	var intErr string
-	if h.Cfg().GetBool(config.Key.APIErrorExposeInt) {
-		intErr = handlerError.Error()
+	problem := NewProblem(http.StatusBadRequest, ValidationFailedCode, res.Msg())
+	problem.Errors = valErrs
+
+	return problem
+}
+
+func (h *APIHandler) writeProblem(w http.ResponseWriter, r *http.Request, problem Problem, handlerError error) {
+	problem.Instance = r.RequestURI
+	if problem.Instance == "" {
+		problem.Instance = r.URL.RequestURI()
 	}
 
-	response := APIResponse{
-		Status: Status{
-			OK:          false,
-			Message:     msg,
-			InternalErr: intErr,
-		},
+	problem.RequestID = ReqID(r)
+
+	if h.Cfg().GetBool(config.Key.APIErrorExposeInt) {
+		problem.InternalErr = handlerError.Error()
 	}
 
-	h.Log().Errorf("handler error:\n%s", errors.Stacktrace(handlerError))
+	h.Log().Errorf("handler error (%s):\n%s", problem.Code, errors.Stacktrace(handlerError))
 
-	w.WriteHeader(httpStatus)
-	err := json.NewEncoder(w).Encode(response)
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	err := json.NewEncoder(w).Encode(problem)
 	if err != nil {
 		h.Log().Error(errors.Wrap(err, "error encoding handler error"))
 	}
-
-	return
 }
@@ -1,15 +1,54 @@
 package http
 
 import (
+	"context"
 	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/vanillazen/stl/backend/internal/sys"
 )
 
+const (
+	ParamsCtxKey ContextKey = "params"
+)
+
 type (
 	ServeMux struct {
 		sys.Core
 		*http.ServeMux
+		handler http.Handler
+	}
+
+	// Middleware wraps a handler to act before and after it.
+	Middleware func(next http.Handler) http.Handler
+
+	// Router matches requests by method and path.
+	// Pattern segments like "{listID}" match any non-empty segment and their values are read with PathParam,
+	// a trailing "*" segment matches the rest of the path. Routes are matched in the order they were added.
+	Router struct {
+		prefix      string
+		middlewares []Middleware
+		table       *routeTable
+	}
+
+	routeTable struct {
+		routes           []route
+		notFound         http.Handler
+		methodNotAllowed http.Handler
+	}
+
+	route struct {
+		method   string
+		segments []string
+		handler  http.Handler
+	}
+
+	pathParams map[string]string
+
+	// headResponseWriter discards the body GET handlers write when answering HEAD requests.
+	headResponseWriter struct {
+		http.ResponseWriter
 	}
 )
 
@@ -20,6 +59,263 @@ func NewServeMux(name string, opts ...sys.Option) *ServeMux {
 	}
 }
 
+// Use wraps the whole mux with middlewares, the first one being the outermost.
+func (sm *ServeMux) Use(middlewares ...Middleware) {
+	if sm.handler == nil {
+		sm.handler = sm.ServeMux
+	}
+
+	sm.handler = chain(sm.handler, middlewares)
+}
+
+func (sm *ServeMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if sm.handler == nil {
+		sm.ServeMux.ServeHTTP(w, r)
+		return
+	}
+
+	sm.handler.ServeHTTP(w, r)
+}
+
+// Mount serves the handler under the pattern path prefix.
+// The handler gets the request path relative to the prefix, i.e.: "/lists" for "/api/v1/lists".
 func (sm *ServeMux) Mount(pattern string, handler http.Handler) {
-	// TODO: Implement route-handler mount mechanism
+	pattern = strings.TrimSuffix(pattern, "/")
+	sm.Handle(pattern+"/", http.StripPrefix(pattern, handler))
+}
+
+func NewRouter() *Router {
+	return &Router{
+		table: &routeTable{
+			notFound:         http.HandlerFunc(http.NotFound),
+			methodNotAllowed: http.HandlerFunc(methodNotAllowed),
+		},
+	}
+}
+
+// NotFound sets the handler for requests not matching any route path.
+func (rt *Router) NotFound(h http.Handler) {
+	rt.table.notFound = h
+}
+
+// MethodNotAllowed sets the handler for requests matching a route path but none of its methods.
+// The Allow header is already set when it is called.
+func (rt *Router) MethodNotAllowed(h http.Handler) {
+	rt.table.methodNotAllowed = h
+}
+
+// Use appends middlewares to the router, they wrap the routes added afterwards.
+func (rt *Router) Use(middlewares ...Middleware) {
+	rt.middlewares = append(rt.middlewares, middlewares...)
+}
+
+// With returns a group of the router with additional middlewares.
+func (rt *Router) With(middlewares ...Middleware) *Router {
+	mws := make([]Middleware, 0, len(rt.middlewares)+len(middlewares))
+	mws = append(mws, rt.middlewares...)
+	mws = append(mws, middlewares...)
+
+	return &Router{
+		prefix:      rt.prefix,
+		middlewares: mws,
+		table:       rt.table,
+	}
+}
+
+// Group calls fn with a group of the router, middlewares it uses only wrap the routes of the group.
+func (rt *Router) Group(fn func(r *Router)) {
+	fn(rt.With())
+}
+
+// Route calls fn with a group of the router whose routes are under the prefix.
+func (rt *Router) Route(prefix string, fn func(r *Router)) {
+	g := rt.With()
+	g.prefix = rt.prefix + prefix
+	fn(g)
+}
+
+// Mount serves the handler, usually another router, under the prefix for any method.
+// The handler gets the request path relative to the prefix.
+func (rt *Router) Mount(prefix string, h http.Handler) {
+	rt.Handle("", prefix+"/*", stripSegments(len(split(rt.prefix+prefix)), h))
+}
+
+// Handle adds a route for the method and pattern, an empty method matches any of them.
+func (rt *Router) Handle(method, pattern string, h http.Handler) {
+	rt.table.routes = append(rt.table.routes, route{
+		method:   method,
+		segments: split(rt.prefix + pattern),
+		handler:  chain(h, rt.middlewares),
+	})
+}
+
+func (rt *Router) HandleFunc(method, pattern string, fn http.HandlerFunc) {
+	rt.Handle(method, pattern, fn)
+}
+
+func (rt *Router) Get(pattern string, fn http.HandlerFunc) {
+	rt.Handle(http.MethodGet, pattern, fn)
+}
+
+func (rt *Router) Post(pattern string, fn http.HandlerFunc) {
+	rt.Handle(http.MethodPost, pattern, fn)
+}
+
+func (rt *Router) Put(pattern string, fn http.HandlerFunc) {
+	rt.Handle(http.MethodPut, pattern, fn)
+}
+
+func (rt *Router) Patch(pattern string, fn http.HandlerFunc) {
+	rt.Handle(http.MethodPatch, pattern, fn)
+}
+
+func (rt *Router) Delete(pattern string, fn http.HandlerFunc) {
+	rt.Handle(http.MethodDelete, pattern, fn)
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := split(r.URL.Path)
+
+	var allowed []string
+	for _, rte := range rt.table.routes {
+		params, ok := rte.match(segments)
+		if !ok {
+			continue
+		}
+
+		if !rte.allows(r.Method) {
+			allowed = append(allowed, rte.method)
+			if rte.method == http.MethodGet {
+				allowed = append(allowed, http.MethodHead)
+			}
+			continue
+		}
+
+		if r.Method == http.MethodHead && rte.method == http.MethodGet {
+			w = headResponseWriter{w}
+		}
+
+		ctx := context.WithValue(r.Context(), ParamsCtxKey, params.merge(r))
+		rte.handler.ServeHTTP(w, r.WithContext(ctx))
+		return
+	}
+
+	if len(allowed) > 0 {
+		sort.Strings(allowed)
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		rt.table.methodNotAllowed.ServeHTTP(w, r)
+		return
+	}
+
+	rt.table.notFound.ServeHTTP(w, r)
+}
+
+// PathParam returns the value of a named segment of the route matching the request.
+func PathParam(r *http.Request, name string) string {
+	params, _ := r.Context().Value(ParamsCtxKey).(pathParams)
+	return params[name]
+}
+
+// allows returns true if the route handles the method, GET routes also answer HEAD requests.
+func (rte route) allows(method string) bool {
+	return rte.method == "" || rte.method == method || (method == http.MethodHead && rte.method == http.MethodGet)
+}
+
+func (rte route) match(segments []string) (params pathParams, ok bool) {
+	params = pathParams{}
+
+	for i, seg := range rte.segments {
+		if seg == "*" {
+			return params, true
+		}
+
+		if i >= len(segments) {
+			return nil, false
+		}
+
+		if name, ok := paramName(seg); ok {
+			if segments[i] == "" {
+				return nil, false
+			}
+			params[name] = segments[i]
+			continue
+		}
+
+		if seg != segments[i] {
+			return nil, false
+		}
+	}
+
+	return params, len(rte.segments) == len(segments)
+}
+
+// merge adds the params of the routers the request went through before, i.e.: when mounted under another one.
+func (params pathParams) merge(r *http.Request) pathParams {
+	prev, _ := r.Context().Value(ParamsCtxKey).(pathParams)
+	for name, value := range prev {
+		if _, ok := params[name]; !ok {
+			params[name] = value
+		}
+	}
+
+	return params
+}
+
+func paramName(seg string) (name string, ok bool) {
+	if len(seg) > 2 && strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+		return seg[1 : len(seg)-1], true
+	}
+
+	return "", false
+}
+
+// chain wraps the handler with the middlewares, the first one being the outermost.
+func chain(h http.Handler, middlewares []Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+
+	return h
+}
+
+// split returns the segments of a path, leading and trailing slashes are ignored.
+func split(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+
+	return strings.Split(path, "/")
+}
+
+// stripSegments removes the first n segments of the request path before calling the handler.
+// Unlike http.StripPrefix it works with prefixes holding params.
+func stripSegments(n int, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		segments := split(r.URL.Path)
+		if len(segments) > n {
+			segments = segments[n:]
+		} else {
+			segments = nil
+		}
+
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = "/" + strings.Join(segments, "/")
+		r2.URL.RawPath = ""
+
+		h.ServeHTTP(w, r2)
+	})
+}
+
+func (w headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// Unwrap returns the wrapped writer, so that http.ResponseController reaches it.
+func (w headResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 }
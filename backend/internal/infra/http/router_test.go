@@ -0,0 +1,227 @@
+package http_test
+
+import (
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vanillazen/stl/backend/internal/infra/http"
+)
+
+func TestRouter(t *testing.T) {
+	rt := http.NewRouter()
+
+	rt.Get("/lists", reply("get lists"))
+	rt.Route("/lists/{listID}", func(r *http.Router) {
+		r.Get("", reply("get list"))
+		r.Put("", reply("update list"))
+		r.Get("/tasks/{taskID}", reply("get task"))
+		r.Post("/tasks/{taskID}/toggle", reply("toggle task"))
+		r.Post("/tasks:batch", reply("batch tasks"))
+	})
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		status   int
+		expected string
+		allow    string
+	}{
+		{
+			name:     "Collection",
+			method:   nethttp.MethodGet,
+			path:     "/lists",
+			status:   nethttp.StatusOK,
+			expected: "get lists",
+		},
+		{
+			name:     "Trailing slash",
+			method:   nethttp.MethodGet,
+			path:     "/lists/",
+			status:   nethttp.StatusOK,
+			expected: "get lists",
+		},
+		{
+			name:     "Item",
+			method:   nethttp.MethodPut,
+			path:     "/lists/l1",
+			status:   nethttp.StatusOK,
+			expected: "update list l1",
+		},
+		{
+			name:     "Nested item",
+			method:   nethttp.MethodGet,
+			path:     "/lists/l1/tasks/t1",
+			status:   nethttp.StatusOK,
+			expected: "get task l1 t1",
+		},
+		{
+			name:     "Nested action",
+			method:   nethttp.MethodPost,
+			path:     "/lists/l1/tasks/t1/toggle",
+			status:   nethttp.StatusOK,
+			expected: "toggle task l1 t1",
+		},
+		{
+			name:     "Custom method",
+			method:   nethttp.MethodPost,
+			path:     "/lists/l1/tasks:batch",
+			status:   nethttp.StatusOK,
+			expected: "batch tasks l1",
+		},
+		{
+			name:   "Head of get route",
+			method: nethttp.MethodHead,
+			path:   "/lists/l1/tasks/t1",
+			status: nethttp.StatusOK,
+		},
+		{
+			name:   "Head of post route",
+			method: nethttp.MethodHead,
+			path:   "/lists/l1/tasks/t1/toggle",
+			status: nethttp.StatusMethodNotAllowed,
+			allow:  "POST",
+		},
+		{
+			name:   "Method not allowed",
+			method: nethttp.MethodDelete,
+			path:   "/lists/l1",
+			status: nethttp.StatusMethodNotAllowed,
+			allow:  "GET, HEAD, PUT",
+		},
+		{
+			name:   "Not found",
+			method: nethttp.MethodGet,
+			path:   "/lists/l1/tasks",
+			status: nethttp.StatusNotFound,
+		},
+		{
+			name:   "Empty param",
+			method: nethttp.MethodGet,
+			path:   "/lists//tasks/t1",
+			status: nethttp.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := serve(rt, test.method, test.path)
+
+			if w.Code != test.status {
+				t.Fatalf("Status: expected %d, got %d", test.status, w.Code)
+			}
+
+			// The body of the GET handler is discarded when it answers a HEAD request
+			if test.method == nethttp.MethodHead && test.status == nethttp.StatusOK && w.Body.Len() > 0 {
+				t.Errorf("Body: expected none, got '%s'", w.Body.String())
+			}
+
+			if test.expected != "" && w.Body.String() != test.expected {
+				t.Errorf("Body: expected '%s', got '%s'", test.expected, w.Body.String())
+			}
+
+			if allow := w.Header().Get("Allow"); allow != test.allow {
+				t.Errorf("Allow: expected '%s', got '%s'", test.allow, allow)
+			}
+		})
+	}
+}
+
+func TestRouterMiddleware(t *testing.T) {
+	rt := http.NewRouter()
+	rt.Use(header("outer"))
+
+	rt.Get("/public", reply("public"))
+	rt.Group(func(r *http.Router) {
+		r.Use(header("inner"))
+		r.Get("/private", reply("private"))
+	})
+	rt.With(header("inline")).Get("/inline", reply("inline"))
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{path: "/public", expected: []string{"outer"}},
+		{path: "/private", expected: []string{"outer", "inner"}},
+		{path: "/inline", expected: []string{"outer", "inline"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			w := serve(rt, nethttp.MethodGet, test.path)
+
+			if !equalSlices(w.Header().Values("X-Mw"), test.expected) {
+				t.Errorf("Middlewares: expected %v, got %v", test.expected, w.Header().Values("X-Mw"))
+			}
+		})
+	}
+}
+
+func TestMount(t *testing.T) {
+	api := http.NewRouter()
+	api.Get("/lists/{listID}", reply("get list"))
+
+	rt := http.NewRouter()
+	rt.Route("/users/{userID}", func(r *http.Router) {
+		r.Mount("/api", api)
+	})
+
+	w := serve(rt, nethttp.MethodGet, "/users/u1/api/lists/l1")
+	if w.Body.String() != "get list l1" {
+		t.Errorf("Router mount: expected 'get list l1', got '%s' (%d)", w.Body.String(), w.Code)
+	}
+
+	mux := http.NewServeMux("test-mux")
+	mux.Mount("/api/v1/", api)
+
+	w = serve(mux, nethttp.MethodGet, "/api/v1/lists/l1")
+	if w.Body.String() != "get list l1" {
+		t.Errorf("ServeMux mount: expected 'get list l1', got '%s' (%d)", w.Body.String(), w.Code)
+	}
+}
+
+// reply writes the message followed by the values of the path params present.
+func reply(msg string) nethttp.HandlerFunc {
+	return func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		body := msg
+		for _, name := range []string{"listID", "taskID"} {
+			if v := http.PathParam(r, name); v != "" {
+				body = fmt.Sprintf("%s %s", body, v)
+			}
+		}
+
+		_, _ = fmt.Fprint(w, body)
+	}
+}
+
+func header(name string) http.Middleware {
+	return func(next nethttp.Handler) nethttp.Handler {
+		return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			w.Header().Add("X-Mw", name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func serve(h nethttp.Handler, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func equalSlices(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"net/http"
+)
+
+// Router returns the routes of the API, relative to the path it is mounted on.
+func (h *APIHandler) Router() *Router {
+	rt := NewRouter()
+	rt.NotFound(http.HandlerFunc(h.handleNotFound))
+	rt.MethodNotAllowed(http.HandlerFunc(h.handleMethodNotAllowed))
+
+	// Auth routes handle credentials themselves, only logout needs a caller.
+	rt.Route("/auth", func(r *Router) {
+		r.Post("/login", h.Login)
+		r.Post("/refresh", h.RefreshToken)
+		r.With(h.Authenticate).Post("/logout", h.Logout)
+	})
+
+	rt.Group(func(r *Router) {
+		r.Use(h.Authenticate, h.CheckIDs, h.Idempotent)
+
+		r.Route("/lists", func(r *Router) {
+			r.Get("", h.GetLists)
+			r.Post("", h.CreateList)
+
+			r.Route("/{listID}", func(r *Router) {
+				r.Use(ListContext)
+
+				r.Get("", h.GetList)
+				r.Put("", h.UpdateList)
+				r.Patch("", h.PatchList)
+				r.Delete("", h.DeleteList)
+				r.Get("/events", h.StreamEvents)
+
+				r.Get("/tasks", h.GetTasks)
+				r.Post("/tasks", h.handleAddTask)
+				r.Post("/tasks:batch", h.BatchTasks)
+				r.Get("/tasks/{taskID}", h.GetTask)
+				r.Put("/tasks/{taskID}", h.UpdateTask)
+				r.Patch("/tasks/{taskID}", h.PatchTask)
+				r.Delete("/tasks/{taskID}", h.DeleteTask)
+				r.Post("/tasks/{taskID}/toggle", h.ToggleTask)
+				r.Post("/tasks/{taskID}/tags", h.AttachTag)
+				r.Delete("/tasks/{taskID}/tags/{tagID}", h.DetachTag)
+
+				r.Get("/members", h.GetMembers)
+				r.Post("/members", h.InviteMember)
+				r.Put("/members/{memberID}", h.UpdateMember)
+				r.Delete("/members/{memberID}", h.RemoveMember)
+			})
+		})
+
+		r.Get("/tags", h.GetTags)
+		r.Get("/tags/{tagID}", h.GetTag)
+
+		r.Get("/users", h.FindUser)
+		r.Post("/users", h.RegisterUser)
+		r.Get("/users/{userID}", h.GetUser)
+
+		r.Get("/search", h.Search)
+
+		r.Get("/realtime", h.ServeRealtime)
+
+		r.Get("/sync", h.PullChanges)
+		r.Post("/sync", h.PushChanges)
+
+		r.Route("/webhooks", func(r *Router) {
+			r.Get("", h.GetWebhooks)
+			r.Post("", h.CreateWebhook)
+			r.Get("/{webhookID}", h.GetWebhook)
+			r.Delete("/{webhookID}", h.DeleteWebhook)
+			r.Get("/{webhookID}/deliveries", h.GetDeliveries)
+			r.Post("/{webhookID}/deliveries/{deliveryID}/redeliver", h.Redeliver)
+		})
+
+		r.Get("/invitations", h.GetInvitations)
+		r.Delete("/invitations/{invitationID}", h.DeclineInvitation)
+		r.Post("/invitations/{invitationID}/accept", h.AcceptInvitation)
+
+		// API keys are managed from user sessions only, a key can not be used to issue or revoke others.
+		r.Route("/apikeys", func(r *Router) {
+			r.Use(h.RequireSession)
+
+			r.Get("", h.GetAPIKeys)
+			r.Post("", h.CreateAPIKey)
+			r.Delete("/{apiKeyID}", h.RevokeAPIKey)
+		})
+	})
+
+	return rt
+}
+
+// handleAddTask adds a single task or, if the body is an array, many of them.
+func (h *APIHandler) handleAddTask(w http.ResponseWriter, r *http.Request) {
+	if h.isArrayBody(r) {
+		h.AddTasks(w, r)
+		return
+	}
+
+	h.AddTask(w, r)
+}
+
+func (h *APIHandler) handleNotFound(w http.ResponseWriter, r *http.Request) {
+	h.handleError(w, r, InvalidResourceErr)
+}
+
+func (h *APIHandler) handleMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	h.handleError(w, r, MethodNotAllowedErr)
+}
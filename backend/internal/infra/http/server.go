@@ -8,6 +8,7 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/vanillazen/stl/backend/internal/domain/port"
 	"github.com/vanillazen/stl/backend/internal/domain/service"
 	"github.com/vanillazen/stl/backend/internal/sys"
 	"github.com/vanillazen/stl/backend/internal/sys/config"
@@ -19,8 +20,11 @@ type (
 		opts []sys.Option
 		http.Server
 		*ServeMux
-		apiV1 *APIHandler
-		svc   service.ListService
+		apiV1      *APIHandler
+		svc        service.ListService
+		userSvc    service.UserService
+		authSvc    service.AuthService
+		webhookSvc service.WebhookService
 	}
 )
 
@@ -34,27 +38,31 @@ var (
 	cfgKey = config.Key
 )
 
-func NewServer(svc service.ListService, apiDoc string, opts ...sys.Option) (server *Server) {
-	apiHandler := NewAPIHandler(svc, apiDoc, opts...)
+func NewServer(svc service.ListService, userSvc service.UserService, authSvc service.AuthService, webhookSvc service.WebhookService, idemRepo port.IdempotencyRepo, apiDoc string, opts ...sys.Option) (server *Server) {
+	apiHandler := NewAPIHandler(svc, userSvc, authSvc, webhookSvc, idemRepo, apiDoc, opts...)
 
 	return &Server{
-		Core:     sys.NewCore("api-server", opts...),
-		opts:     opts,
-		ServeMux: NewServeMux("api-router", opts...),
-		apiV1:    apiHandler,
-		svc:      svc,
+		Core:       sys.NewCore("api-server", opts...),
+		opts:       opts,
+		ServeMux:   NewServeMux("api-router", opts...),
+		apiV1:      apiHandler,
+		svc:        svc,
+		userSvc:    userSvc,
+		authSvc:    authSvc,
+		webhookSvc: webhookSvc,
 	}
 }
 
 func (srv *Server) Setup(ctx context.Context) error {
-	//reqLog := NewReqLoggerMiddleware(srv.Log())
+	srv.Mux().Use(
+		RequestID,
+		NewRealIPMiddleware(srv.Log(), srv.Cfg().GetStringSlice(cfgKey.APITrustedProxies)...),
+		NewReqLoggerMiddleware(srv.Log()),
+		srv.apiV1.Recover,
+	)
 
-	// TODO: Add middlewares for srv.router:
-	// RequestID, RealIP, Logging and Recover
-
-	// TODO: Setup Mux routes & handlers
 	srv.Mux().HandleFunc(apiV1Docs, srv.apiV1.handleOpenAPIDocs)
-	srv.Mux().HandleFunc(apiV1, srv.apiV1.handleV1)
+	srv.Mount(apiV1, srv.apiV1.Router())
 
 	return nil
 }
@@ -78,10 +86,17 @@ func (srv *Server) Start(ctx context.Context) error {
 		return nil
 	})
 
+	group.Go(func() error {
+		return srv.apiV1.PurgeIdempotencyRecords(errGrpCtx)
+	})
+
 	group.Go(func() error {
 		<-errGrpCtx.Done()
 		srv.Log().Errorf("%s shutdown", srv.Name())
 
+		// Shutdown waits for the requests being served, event streams would not end on their own
+		srv.apiV1.StopStreams()
+
 		ctx, cancel := context.WithTimeout(context.Background(), srv.ShutdownTimeout())
 		defer cancel()
 
@@ -95,6 +110,18 @@ func (srv *Server) Start(ctx context.Context) error {
 	return group.Wait()
 }
 
+// Stop ends the event streams being served.
+func (srv *Server) Stop(ctx context.Context) error {
+	srv.apiV1.StopStreams()
+	return srv.Core.Stop(ctx)
+}
+
+// Realtime returns the realtime connections of the API, they outlive the requests that opened them
+// and must be closed on their own on shutdown.
+func (srv *Server) Realtime() *Realtime {
+	return srv.apiV1.Realtime()
+}
+
 func (srv *Server) SetMux(sm *ServeMux) {
 	srv.ServeMux = sm
 }
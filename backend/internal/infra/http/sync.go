@@ -0,0 +1,140 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/transport"
+)
+
+const tokenParam = "token"
+
+type (
+	// MutationResult is the outcome of a pushed mutation.
+	// Status is the one the same change would have been answered with on its own, Error describes a failed one.
+	// Conflicting mutations have the current state of their item, if the user can still read it.
+	MutationResult struct {
+		Op     string
+		Kind   string
+		ID     string
+		Status int
+		List   *transport.List `json:",omitempty"`
+		Task   *transport.Task `json:",omitempty"`
+		Error  *Problem        `json:",omitempty"`
+	}
+)
+
+// mutationStatus is the status of a successful mutation by its op.
+var mutationStatus = map[model.MutationOp]int{
+	model.CreateMutation: http.StatusCreated,
+	model.UpdateMutation: http.StatusOK,
+	model.DeleteMutation: http.StatusNoContent,
+}
+
+func NewMutationResult(res *transport.MutationRes) MutationResult {
+	result := MutationResult{
+		Op:     res.Op,
+		Kind:   res.Kind,
+		ID:     res.ID,
+		Status: mutationStatus[model.MutationOp(res.Op)],
+		List:   res.List,
+		Task:   res.Task,
+	}
+
+	if err := res.Err(); err != nil {
+		problem := serviceProblem(res, err)
+		result.Status = problem.Status
+		result.Error = &problem
+	}
+
+	return result
+}
+
+// PullChanges returns the changes made since the last sync
+// @summary Pull changes
+// @description Gets the lists and tasks of the user created, updated or deleted after the change token, oldest first,
+// @description and the token to pull the next ones. Without token all current lists and tasks are returned.
+// @description Deleted items are tombstones, a deleted task is only removed from the list it was in.
+// @description More is set while changes are left, Reset when the token is no longer valid and clients must
+// @description drop their items before applying the changes.
+// @id pull-changes
+// @produce json
+// @Param token query string false "Change token of the last sync"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} Problem
+// @Router /api/v1/sync [get]
+// @tags Sync
+func (h *APIHandler) PullChanges(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.PullChangesReq{
+		UserID: userID,
+		Token:  r.URL.Query().Get(tokenParam),
+	}
+
+	res := h.Service().PullChanges(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "pull changes error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleSuccess(w, res, len(res.Changes), 1)
+}
+
+// PushChanges applies the mutations made while offline
+// @summary Push changes
+// @description Applies the creations, updates and deletions of lists and tasks queued by a client, in order,
+// @description each one on its own. Results report the status of every mutation, as it would have been answered
+// @description on its own. Mutations of items changed meanwhile fail with a conflict holding their current state,
+// @description deletions of items already gone succeed.
+// @id push-changes
+// @accept json
+// @produce json
+// @Param mutations body transport.PushChangesReq true "Mutations"
+// @Param Idempotency-Key header string false "Key making retries of the request replay its first response"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} Problem
+// @Router /api/v1/sync [post]
+// @tags Sync
+func (h *APIHandler) PushChanges(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer h.closeBody(r.Body)
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	var req transport.PushChangesReq
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.handleError(w, r, invalidBody(err))
+		return
+	}
+
+	req.UserID = userID
+
+	res := h.Service().PushChanges(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "push changes error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	results := make([]MutationResult, len(res.Results))
+	for i := range res.Results {
+		results[i] = NewMutationResult(&res.Results[i])
+	}
+
+	h.handleSuccess(w, results, len(results), 1)
+}
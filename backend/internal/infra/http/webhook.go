@@ -0,0 +1,233 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/transport"
+)
+
+// GetWebhooks returns the user webhooks
+// @summary Get webhooks
+// @description Gets the webhooks of the user; their secrets are not returned
+// @id get-webhooks
+// @produce json
+// @Success 200 {object} APIResponse
+// @Failure 401 {object} Problem
+// @Router /api/v1/webhooks [get]
+// @tags Webhooks
+func (h *APIHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.GetWebhooksReq{
+		UserID: userID,
+	}
+
+	res := h.WebhookService().GetWebhooks(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "get webhooks error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleSuccess(w, res, len(res.Webhooks), 1)
+}
+
+// CreateWebhook subscribes a URL to list events
+// @summary Create webhook
+// @description Subscribes a URL to events of the lists of the user, or of a single list if ListID is set, which requires
+// @description the editor role. Events are: list.updated, task.created, task.updated, task.completed, task.deleted and
+// @description task.moved. An event has the list or task as it was when queued, which may include later changes, so
+// @description that a task.completed event can have a task already undone. Deliveries are POSTed with a
+// @description Webhook-Signature header, "sha256=" followed by the hex encoded
+// @description HMAC-SHA256 of the Webhook-Timestamp header, a dot and the body, keyed with the secret. A secret is
+// @description generated if none is given, it is only returned in this response. Failed deliveries are retried with
+// @description exponential backoff
+// @id create-webhook
+// @accept json
+// @produce json
+// @Param webhook body transport.CreateWebhookReq true "Webhook details"
+// @Success 201 {object} APIResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Router /api/v1/webhooks [post]
+// @tags Webhooks
+func (h *APIHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer h.closeBody(r.Body)
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	var req transport.CreateWebhookReq
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.handleError(w, r, invalidBody(err))
+		return
+	}
+
+	req.UserID = userID
+
+	res := h.WebhookService().CreateWebhook(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "create webhook error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleCreated(w, res)
+}
+
+// GetWebhook returns a user webhook
+// @summary Get webhook
+// @description Gets a webhook of the user, without its secret
+// @id get-webhook
+// @produce json
+// @Param webhookID path string true "Webhook ID formatted as an UUID string"
+// @Success 200 {object} APIResponse
+// @Failure 401 {object} Problem
+// @Failure 404 {object} Problem
+// @Router /api/v1/webhooks/{webhookID} [get]
+// @tags Webhooks
+func (h *APIHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.GetWebhookReq{
+		UserID:    userID,
+		WebhookID: PathParam(r, "webhookID"),
+	}
+
+	res := h.WebhookService().GetWebhook(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "get webhook error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleSuccess(w, res, 1, 1)
+}
+
+// DeleteWebhook deletes a user webhook
+// @summary Delete webhook
+// @description Deletes a webhook of the user along with its delivery log, pending deliveries are not sent
+// @id delete-webhook
+// @produce json
+// @Param webhookID path string true "Webhook ID formatted as an UUID string"
+// @Success 204
+// @Failure 401 {object} Problem
+// @Failure 404 {object} Problem
+// @Router /api/v1/webhooks/{webhookID} [delete]
+// @tags Webhooks
+func (h *APIHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.DeleteWebhookReq{
+		UserID:    userID,
+		WebhookID: PathParam(r, "webhookID"),
+	}
+
+	res := h.WebhookService().DeleteWebhook(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "delete webhook error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleNoContent(w)
+}
+
+// GetDeliveries returns the delivery log of a webhook
+// @summary Get webhook deliveries
+// @description Gets the last deliveries of a webhook of the user, latest first, with their payload, status, attempts
+// @description and the outcome of the last one
+// @id get-webhook-deliveries
+// @produce json
+// @Param webhookID path string true "Webhook ID formatted as an UUID string"
+// @Success 200 {object} APIResponse
+// @Failure 401 {object} Problem
+// @Failure 404 {object} Problem
+// @Router /api/v1/webhooks/{webhookID}/deliveries [get]
+// @tags Webhooks
+func (h *APIHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.GetDeliveriesReq{
+		UserID:    userID,
+		WebhookID: PathParam(r, "webhookID"),
+	}
+
+	res := h.WebhookService().GetDeliveries(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "get deliveries error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleSuccess(w, res, len(res.Deliveries), 1)
+}
+
+// Redeliver sends the event of a delivery again
+// @summary Redeliver webhook event
+// @description Queues the event of a delivery of a webhook of the user again, as a new delivery sent right away
+// @id redeliver-webhook-event
+// @produce json
+// @Param webhookID path string true "Webhook ID formatted as an UUID string"
+// @Param deliveryID path string true "Delivery ID formatted as an UUID string"
+// @Success 202 {object} APIResponse
+// @Failure 401 {object} Problem
+// @Failure 404 {object} Problem
+// @Router /api/v1/webhooks/{webhookID}/deliveries/{deliveryID}/redeliver [post]
+// @tags Webhooks
+func (h *APIHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := h.User(r)
+	if err != nil {
+		h.handleError(w, r, errors.Wrap(err))
+		return
+	}
+
+	req := transport.RedeliverReq{
+		UserID:     userID,
+		WebhookID:  PathParam(r, "webhookID"),
+		DeliveryID: PathParam(r, "deliveryID"),
+	}
+
+	res := h.WebhookService().Redeliver(ctx, req)
+	if err = res.Err(); err != nil {
+		err = errors.Wrap(err, "redeliver error")
+		h.handleServiceError(w, r, &res, err)
+		return
+	}
+
+	h.handleSuccessWithStatus(w, http.StatusAccepted, res, 1, 1)
+}
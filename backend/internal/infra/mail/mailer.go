@@ -0,0 +1,104 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+)
+
+type (
+	// SMTPMailer sends emails through the SMTP server set in config.
+	SMTPMailer struct {
+		*sys.SimpleCore
+	}
+
+	// LogMailer only logs the emails, it is used when no SMTP server is configured.
+	LogMailer struct {
+		*sys.SimpleCore
+	}
+)
+
+// NewMailer returns an SMTPMailer if an SMTP host is configured and a LogMailer otherwise.
+func NewMailer(opts ...sys.Option) port.Mailer {
+	core := sys.NewCore("mailer", opts...)
+
+	if cfg := core.Cfg(); cfg != nil && cfg.GetString(config.Key.MailSMTPHost) != "" {
+		return &SMTPMailer{SimpleCore: core}
+	}
+
+	return &LogMailer{SimpleCore: core}
+}
+
+func (m *SMTPMailer) SendMail(ctx context.Context, e port.Email) error {
+	cfg := m.Cfg()
+	host := cfg.GetString(config.Key.MailSMTPHost)
+	addr := net.JoinHostPort(host, strconv.Itoa(cfg.GetInt(config.Key.MailSMTPPort)))
+
+	if e.From == "" {
+		e.From = port.EmailAddress(cfg.GetString(config.Key.MailFrom))
+	}
+
+	var auth smtp.Auth
+	if user := cfg.GetString(config.Key.MailSMTPUser); user != "" {
+		auth = smtp.PlainAuth("", user, cfg.GetString(config.Key.MailSMTPPass), host)
+	}
+
+	var rcpts []string
+	for _, list := range [][]port.EmailAddress{e.To, e.CC, e.BC} {
+		for _, a := range list {
+			rcpts = append(rcpts, string(a))
+		}
+	}
+
+	err := smtp.SendMail(addr, auth, string(e.From), rcpts, message(e))
+	if err != nil {
+		return errors.Wrap(err, "send mail error")
+	}
+
+	return nil
+}
+
+func (m *LogMailer) SendMail(ctx context.Context, e port.Email) error {
+	m.Log().Infof("%s: mail to %s: %s\n%s", m.Name(), join(e.To), e.Subject, e.Body)
+	return nil
+}
+
+// message formats the email as a plain text message, blind copies are not included in its headers.
+func message(e port.Email) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "From: %s\r\n", headerValue(string(e.From)))
+	fmt.Fprintf(&b, "To: %s\r\n", headerValue(join(e.To)))
+	if len(e.CC) > 0 {
+		fmt.Fprintf(&b, "Cc: %s\r\n", headerValue(join(e.CC)))
+	}
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerValue(e.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.Write(e.Body)
+
+	return b.Bytes()
+}
+
+// headerValue removes line breaks so that user provided values, i.e.: list names, can not add headers.
+func headerValue(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+func join(addresses []port.EmailAddress) string {
+	ss := make([]string, 0, len(addresses))
+	for _, a := range addresses {
+		ss = append(ss, string(a))
+	}
+
+	return strings.Join(ss, ", ")
+}
@@ -0,0 +1,275 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+)
+
+const (
+	defaultPoll        = time.Second
+	defaultBackoff     = 5 * time.Second
+	defaultMaxAttempts = 10
+	defaultRetention   = 24 * time.Hour
+	defaultPurge       = 10 * time.Minute
+	// maxBackoff caps the wait before an event is delivered again, it doubles after every failed attempt until then.
+	maxBackoff = time.Hour
+	// batchSize is the number of events read from the outbox at once.
+	batchSize = 100
+)
+
+type (
+	// Bus is the in-process domain event bus. Its dispatcher reads the events recorded in the outbox, delivers them
+	// to the subscribers of their type in the order they were recorded and marks them done once all of them handled
+	// them. Subscribers that failed to handle an event get it again later, with exponential backoff, so delivery is
+	// at least once. The later events of its list wait for it, so that each list gets its events in order, until
+	// the event fails the maximum number of attempts. It is then kept as failed and no longer delivered.
+	Bus struct {
+		*sys.SimpleCore
+		repo        port.OutboxRepo
+		poll        time.Duration
+		backoff     time.Duration
+		maxAttempts int
+		retention   time.Duration
+		purge       time.Duration
+		mu          sync.RWMutex
+		subs        []subscriber
+	}
+
+	subscriber struct {
+		name    string
+		handler port.DomainEventHandler
+		// types the subscriber handles, all of them if nil.
+		types map[model.DomainEventType]bool
+	}
+)
+
+func NewBus(repo port.OutboxRepo, opts ...sys.Option) *Bus {
+	b := &Bus{
+		SimpleCore:  sys.NewCore("event-bus", opts...),
+		repo:        repo,
+		poll:        defaultPoll,
+		backoff:     defaultBackoff,
+		maxAttempts: defaultMaxAttempts,
+		retention:   defaultRetention,
+		purge:       defaultPurge,
+	}
+
+	if cfg := b.Cfg(); cfg != nil {
+		if secs := cfg.GetInt(config.Key.OutboxPoll); secs > 0 {
+			b.poll = time.Duration(secs) * time.Second
+		}
+
+		if secs := cfg.GetInt(config.Key.OutboxBackoff); secs > 0 {
+			b.backoff = time.Duration(secs) * time.Second
+		}
+
+		if n := cfg.GetInt(config.Key.OutboxMaxAttempts); n > 0 {
+			b.maxAttempts = n
+		}
+
+		if secs := cfg.GetInt(config.Key.OutboxRetention); secs > 0 {
+			b.retention = time.Duration(secs) * time.Second
+		}
+
+		if secs := cfg.GetInt(config.Key.OutboxPurge); secs > 0 {
+			b.purge = time.Duration(secs) * time.Second
+		}
+	}
+
+	return b
+}
+
+// Subscribe the handler to the domain events of the given types, or to all of them if none are given.
+// Subscribers are expected to be added before the bus starts, events done before are not delivered to them.
+// The name records which subscribers handled an event, it must be unique and kept across restarts.
+func (b *Bus) Subscribe(name string, handler port.DomainEventHandler, types ...model.DomainEventType) {
+	sub := subscriber{name: name, handler: handler}
+
+	if len(types) > 0 {
+		sub.types = make(map[model.DomainEventType]bool, len(types))
+		for _, typ := range types {
+			sub.types[typ] = true
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subs = append(b.subs, sub)
+}
+
+// Start runs the dispatcher, it delivers the pending events every poll interval until the context is done.
+// A full batch is followed by the next one right away.
+func (b *Bus) Start(ctx context.Context) error {
+	ticker := time.NewTicker(b.poll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-ticker.C:
+			for {
+				n, err := b.Dispatch(ctx, time.Now())
+				if err != nil {
+					b.Log().Errorf("%s error: %s", b.Name(), err)
+				}
+
+				if err != nil || n < batchSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// Purge deletes the events done longer than the retention period ago every purge interval, until the context is
+// done. Failed events are kept.
+func (b *Bus) Purge(ctx context.Context) error {
+	ticker := time.NewTicker(b.purge)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case now := <-ticker.C:
+			n, err := b.repo.DeleteDoneEvents(ctx, now.Add(-b.retention))
+			if err != nil {
+				b.Log().Errorf("%s purge error: %s", b.Name(), err)
+				continue
+			}
+
+			if n > 0 {
+				b.Log().Debugf("%d done events deleted", n)
+			}
+		}
+	}
+}
+
+// Dispatch delivers the pending events due at the given time and records the outcome, returning how many were read.
+// Once an event of a list is left pending, the later ones of the list read along with it wait for its next attempt.
+func (b *Bus) Dispatch(ctx context.Context, now time.Time) (n int, err error) {
+	events, err := b.repo.GetPendingEvents(ctx, now, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := map[string]bool{}
+	for _, e := range events {
+		// Events left pending are delivered again once the dispatcher starts again.
+		if ctx.Err() != nil {
+			break
+		}
+
+		if pending[e.ListID] {
+			continue
+		}
+
+		e = b.deliver(ctx, e, now)
+		if !e.Done() && !e.Failed() {
+			pending[e.ListID] = true
+		}
+
+		err = b.repo.UpdateEvent(ctx, e)
+		if err != nil {
+			b.Log().Errorf("%s cannot record event %d: %s", b.Name(), e.ID, err)
+			pending[e.ListID] = true
+		}
+	}
+
+	return len(events), nil
+}
+
+// deliver hands the event to the subscribers of its type that did not handle it yet and returns it updated with
+// the outcome.
+func (b *Bus) deliver(ctx context.Context, e model.DomainEvent, now time.Time) model.DomainEvent {
+	e.Attempts++
+
+	var errs []string
+	for _, sub := range b.subscribers(e.Type) {
+		if e.HandledBy(sub.name) {
+			continue
+		}
+
+		err := sub.handle(ctx, e)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", sub.name, err))
+			continue
+		}
+
+		e.Handled = append(e.Handled, sub.name)
+	}
+
+	if len(errs) == 0 {
+		e.DoneAt = now.UTC()
+		e.NextAttemptAt = time.Time{}
+		e.Error = ""
+		return e
+	}
+
+	e.Error = strings.Join(errs, "; ")
+
+	if e.Attempts >= b.maxAttempts {
+		e.FailedAt = now.UTC()
+		e.NextAttemptAt = time.Time{}
+
+		b.Log().Errorf("%s gave up event %d (%s) after %d attempts: %s", b.Name(), e.ID, e.Type, e.Attempts, e.Error)
+		return e
+	}
+
+	e.NextAttemptAt = now.UTC().Add(b.retryAfter(e.Attempts))
+
+	b.Log().Errorf("%s cannot deliver event %d (%s), attempt %d: %s", b.Name(), e.ID, e.Type, e.Attempts, e.Error)
+
+	return e
+}
+
+// subscribers returns the subscribers of the event type.
+func (b *Bus) subscribers(typ model.DomainEventType) (subs []subscriber) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subs {
+		if sub.types == nil || sub.types[typ] {
+			subs = append(subs, sub)
+		}
+	}
+
+	return subs
+}
+
+// retryAfter returns the wait before delivering again an event that failed the given number of times.
+func (b *Bus) retryAfter(attempts int) time.Duration {
+	wait := b.backoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+
+	if wait > maxBackoff {
+		return maxBackoff
+	}
+
+	return wait
+}
+
+// handle calls the handler of the subscriber, a panic is a failure to handle the event.
+func (s subscriber) handle(ctx context.Context, e model.DomainEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return s.handler(ctx, e)
+}
@@ -0,0 +1,225 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/infra/outbox"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	"github.com/vanillazen/stl/backend/internal/sys/log"
+)
+
+const (
+	list1ID = "3b6f7a4e-0a8f-4d0a-9b0e-5f4e8b1a2c01"
+	list2ID = "3b6f7a4e-0a8f-4d0a-9b0e-5f4e8b1a2c02"
+)
+
+// store is an in-memory outbox, only the methods used by the bus are implemented.
+type store struct {
+	port.OutboxRepo
+	events map[uint64]model.DomainEvent
+}
+
+func newStore(types ...model.DomainEventType) *store {
+	s := &store{events: map[uint64]model.DomainEvent{}}
+	for _, typ := range types {
+		s.add(typ, list1ID)
+	}
+
+	return s
+}
+
+// add records an event of the list, returning its ID.
+func (s *store) add(typ model.DomainEventType, listID string) uint64 {
+	id := uint64(len(s.events) + 1)
+	s.events[id] = model.DomainEvent{ID: id, Type: typ, ListID: listID}
+	return id
+}
+
+// GetPendingEvents leaves out the events of a list after one that is not due, as the repo does.
+func (s *store) GetPendingEvents(ctx context.Context, now time.Time, limit int) (events []model.DomainEvent, err error) {
+	var pending []model.DomainEvent
+	for _, e := range s.events {
+		if !e.Done() && !e.Failed() {
+			pending = append(pending, e)
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ID < pending[j].ID })
+
+	waiting := map[string]bool{}
+	for _, e := range pending {
+		if e.NextAttemptAt.After(now) {
+			waiting[e.ListID] = true
+		}
+
+		if !waiting[e.ListID] && len(events) < limit {
+			events = append(events, e)
+		}
+	}
+
+	return events, nil
+}
+
+func (s *store) UpdateEvent(ctx context.Context, e model.DomainEvent) error {
+	s.events[e.ID] = e
+	return nil
+}
+
+func newTestBus(s *store) *outbox.Bus {
+	cfg := &config.Config{}
+	cfg.SetValues(map[string]string{
+		config.Key.OutboxBackoff:     "60",
+		config.Key.OutboxMaxAttempts: "3",
+	})
+
+	return outbox.NewBus(s, sys.WithConfig(cfg), sys.WithLogger(log.NewLogger("error")))
+}
+
+func TestBusDispatch(t *testing.T) {
+	s := newStore(model.ListCreated, model.TaskCreated, model.TaskCompleted, model.ListShared)
+	b := newTestBus(s)
+
+	var all, tasks []uint64
+	b.Subscribe("all", func(ctx context.Context, e model.DomainEvent) error {
+		all = append(all, e.ID)
+		return nil
+	})
+	b.Subscribe("tasks", func(ctx context.Context, e model.DomainEvent) error {
+		tasks = append(tasks, e.ID)
+		return nil
+	}, model.TaskCreated, model.TaskCompleted)
+
+	n, err := b.Dispatch(context.Background(), time.Now())
+	if err != nil || n != 4 {
+		t.Fatalf("Dispatch: expected 4 events, got %d (%v)", n, err)
+	}
+
+	if len(all) != 4 || all[0] != 1 || all[3] != 4 {
+		t.Errorf("All: expected events 1 to 4 in order, got %v", all)
+	}
+
+	if len(tasks) != 2 || tasks[0] != 2 || tasks[1] != 3 {
+		t.Errorf("Tasks: expected events 2 and 3, got %v", tasks)
+	}
+
+	for id, e := range s.events {
+		if !e.Done() || e.Attempts != 1 {
+			t.Errorf("Event %d: expected done on first attempt, got %+v", id, e)
+		}
+	}
+}
+
+func TestBusRetry(t *testing.T) {
+	s := newStore(model.TaskCompleted)
+	b := newTestBus(s)
+	ctx := context.Background()
+
+	var got int
+	b.Subscribe("counter", func(ctx context.Context, e model.DomainEvent) error {
+		got++
+		return nil
+	})
+
+	fail := true
+	b.Subscribe("flaky", func(ctx context.Context, e model.DomainEvent) error {
+		if fail {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+
+	b.Subscribe("panics", func(ctx context.Context, e model.DomainEvent) error {
+		if fail {
+			panic("boom")
+		}
+		return nil
+	})
+
+	now := time.Now()
+	_, err := b.Dispatch(ctx, now)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	e := s.events[1]
+	if e.Done() || e.Attempts != 1 || e.Error != "flaky: unavailable; panics: panic: boom" || !e.HandledBy("counter") {
+		t.Errorf("Failed: expected pending with the errors of both subscribers, got %+v", e)
+	}
+
+	if wait := e.NextAttemptAt.Sub(now); wait != time.Minute {
+		t.Errorf("Backoff: expected next attempt in %s, got %s", time.Minute, wait)
+	}
+
+	n, err := b.Dispatch(ctx, now)
+	if err != nil || n != 0 {
+		t.Errorf("Not due: expected no events, got %d (%v)", n, err)
+	}
+
+	// Delivered again only to the subscribers that failed
+	fail = false
+	n, err = b.Dispatch(ctx, now.Add(time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("Due: expected 1 event, got %d (%v)", n, err)
+	}
+
+	e = s.events[1]
+	if !e.Done() || e.Attempts != 2 || e.Error != "" || got != 1 || len(e.Handled) != 3 {
+		t.Errorf("Retried: expected done on second attempt, handled once by each, got %+v (%d)", e, got)
+	}
+}
+
+func TestBusOrder(t *testing.T) {
+	s := &store{events: map[uint64]model.DomainEvent{}}
+	first := s.add(model.TaskCreated, list1ID)
+	s.add(model.TaskCreated, list2ID)
+	later := s.add(model.TaskUpdated, list1ID)
+	b := newTestBus(s)
+	ctx := context.Background()
+
+	var got []uint64
+	b.Subscribe("poisoned", func(ctx context.Context, e model.DomainEvent) error {
+		if e.ID == first {
+			return errors.New("poison")
+		}
+
+		got = append(got, e.ID)
+		return nil
+	})
+
+	// The later event of the list waits for the failed one, other lists go on
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		_, err := b.Dispatch(ctx, now)
+		if err != nil {
+			t.Fatalf("Error: unexpected '%v'", err)
+		}
+
+		if s.events[later].Attempts != 0 || len(got) != 1 || got[0] != 2 {
+			t.Fatalf("Attempt %d: expected only the event of the other list delivered, got %v", i+1, got)
+		}
+
+		now = now.Add(time.Hour)
+	}
+
+	// Given up after the last attempt, the later event is delivered right after it
+	_, err := b.Dispatch(ctx, now)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	e := s.events[first]
+	if !e.Failed() || e.Done() || e.Attempts != 3 || e.Error != "poisoned: poison" || !e.NextAttemptAt.IsZero() {
+		t.Errorf("Failed: expected given up after 3 attempts, got %+v", e)
+	}
+
+	if !s.events[later].Done() || len(got) != 2 || got[1] != later {
+		t.Errorf("Later: expected delivered once the failed event was given up, got %v", got)
+	}
+}
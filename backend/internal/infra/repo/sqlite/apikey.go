@@ -0,0 +1,183 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/infra/db"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+)
+
+const (
+	apiKeyColumns = `id, user_id, name, prefix, hash, scope, last_used_at, revoked_at, created_at, updated_at`
+
+	// apiKeyTouchInterval limits how often the last used time of a key is written.
+	apiKeyTouchInterval = time.Minute
+)
+
+func (r *UserRepo) CreateAPIKey(ctx context.Context, m model.APIKey) (key model.APIKey, err error) {
+	err = m.GenID()
+	if err != nil {
+		return m, errors.Wrap(err, "create api key repo error")
+	}
+
+	dbase := r.DB(ctx).DB()
+
+	now := time.Now().UTC()
+	m.Audit = model.NewAudit(now, now)
+
+	query := `
+		INSERT INTO api_keys (id, user_id, name, prefix, hash, scope, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err = dbase.ExecContext(ctx, query,
+		m.ID.String(),
+		m.UserID.String(),
+		m.Name,
+		m.Prefix,
+		m.Hash,
+		m.Scope,
+		m.CreatedAt,
+		m.UpdatedAt,
+	)
+	if err != nil {
+		return m, errors.Wrap(err, "create api key repo error")
+	}
+
+	return m, nil
+}
+
+func (r *UserRepo) GetAPIKeys(ctx context.Context, userID string) (keys []model.APIKey, err error) {
+	ok := uuid.Validate(userID)
+	if !ok {
+		return keys, InvalidResourceIDErr
+	}
+
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := dbase.QueryContext(ctx, query, userID)
+	if err != nil {
+		return keys, errors.Wrap(err, "get api keys repo error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return keys, errors.Wrap(err, "get api keys repo error")
+		}
+
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return keys, errors.Wrap(err, "get api keys repo error")
+	}
+
+	return keys, nil
+}
+
+func (r *UserRepo) GetAPIKeyByHash(ctx context.Context, hash string) (key model.APIKey, err error) {
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE hash = $1
+	`
+
+	key, err = scanAPIKey(dbase.QueryRowContext(ctx, query, hash))
+	if err == sql.ErrNoRows {
+		return key, errors.Wrap(port.APIKeyNotFoundErr, "get api key repo error")
+	}
+	if err != nil {
+		return key, errors.Wrap(err, "get api key repo error")
+	}
+
+	return key, nil
+}
+
+func (r *UserRepo) RevokeAPIKey(ctx context.Context, keyID, userID string) error {
+	ok := uuid.Validate(keyID)
+	if !ok {
+		return InvalidResourceIDErr
+	}
+
+	dbase := r.DB(ctx).DB()
+
+	now := time.Now().UTC()
+
+	query := `
+		UPDATE api_keys
+		SET revoked_at = COALESCE(revoked_at, $1), updated_at = $1
+		WHERE id = $2 AND user_id = $3
+	`
+
+	res, err := dbase.ExecContext(ctx, query, now, keyID, userID)
+	if err != nil {
+		return errors.Wrap(err, "revoke api key repo error")
+	}
+
+	err = checkAffected(res, port.NewAPIKeyNotFoundErr(keyID))
+	if err != nil {
+		return errors.Wrap(err, "revoke api key repo error")
+	}
+
+	return nil
+}
+
+// TouchAPIKey updates the last used time of the key.
+// Keys used in quick succession are only written once per apiKeyTouchInterval.
+func (r *UserRepo) TouchAPIKey(ctx context.Context, keyID string, usedAt time.Time) error {
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		UPDATE api_keys
+		SET last_used_at = $1
+		WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $3)
+	`
+
+	_, err := dbase.ExecContext(ctx, query, usedAt, keyID, usedAt.Add(-apiKeyTouchInterval))
+	if err != nil {
+		return errors.Wrap(err, "touch api key repo error")
+	}
+
+	return nil
+}
+
+func scanAPIKey(s scanner) (key model.APIKey, err error) {
+	var lastUsedAt, revokedAt db.NullTime
+
+	err = s.Scan(
+		&key.ID.UUID,
+		&key.UserID.UUID,
+		&key.Name,
+		&key.Prefix,
+		&key.Hash,
+		&key.Scope,
+		&lastUsedAt,
+		&revokedAt,
+		&key.CreatedAt,
+		&key.UpdatedAt,
+	)
+	if err != nil {
+		return key, err
+	}
+
+	key.LastUsedAt = lastUsedAt.Time
+	key.RevokedAt = revokedAt.Time
+
+	return key, nil
+}
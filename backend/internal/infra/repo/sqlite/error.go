@@ -1,9 +1,18 @@
 package sqlite
 
-import "github.com/vanillazen/stl/backend/internal/sys/errors"
+import (
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+)
 
 var (
 	NoConnectionError    = errors.New("no connection error")
 	InvalidResourceIDErr = errors.New("invalid resource ID")
-	UserNotFoundErr      = errors.New("user not found")
 )
+
+// isPrimaryKeyErr returns true if err is the violation of a primary key, a row with the same ID exists.
+func isPrimaryKeyErr(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+}
@@ -0,0 +1,182 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/infra/db"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+)
+
+type IdempotencyRepo struct {
+	*sys.SimpleCore
+	db db.DB
+}
+
+func NewIdempotencyRepo(db db.DB, opts ...sys.Option) *IdempotencyRepo {
+	return &IdempotencyRepo{
+		SimpleCore: sys.NewCore("idempotency-repo", opts...),
+		db:         db,
+	}
+}
+
+func (r *IdempotencyRepo) DB(ctx context.Context) db.DB {
+	return r.db
+}
+
+func (r *IdempotencyRepo) Start(ctx context.Context) error {
+	r.Log().Infof("%s started", r.Name())
+	return nil
+}
+
+// CreateIdempotencyRecord reserves the key for a request in progress.
+// An expired record with the same key, not purged yet, is replaced.
+func (r *IdempotencyRepo) CreateIdempotencyRecord(ctx context.Context, m model.IdempotencyRecord) error {
+	dbase := r.DB(ctx).DB()
+
+	m.CreatedAt = time.Now().UTC()
+
+	query := `
+		INSERT INTO idempotency_keys (user_id, method, path, key, fingerprint, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, method, path, key) DO UPDATE SET
+			fingerprint = excluded.fingerprint,
+			status = 0,
+			header = NULL,
+			body = NULL,
+			created_at = excluded.created_at,
+			expires_at = excluded.expires_at
+		WHERE idempotency_keys.expires_at <= excluded.created_at
+	`
+
+	res, err := dbase.ExecContext(ctx, query,
+		m.UserID,
+		m.Method,
+		m.Path,
+		m.Key,
+		m.Fingerprint,
+		m.CreatedAt,
+		m.ExpiresAt.UTC(),
+	)
+	if err != nil {
+		return errors.Wrap(err, "create idempotency record repo error")
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "create idempotency record repo error")
+	}
+
+	if n == 0 {
+		return errors.Wrap(port.IdempotencyKeyUsedErr, "create idempotency record repo error")
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepo) GetIdempotencyRecord(ctx context.Context, userID, method, path, key string) (record model.IdempotencyRecord, err error) {
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		SELECT user_id, method, path, key, fingerprint, status, header, body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND method = $2 AND path = $3 AND key = $4
+	`
+
+	var header sql.NullString
+
+	err = dbase.QueryRowContext(ctx, query, userID, method, path, key).Scan(
+		&record.UserID,
+		&record.Method,
+		&record.Path,
+		&record.Key,
+		&record.Fingerprint,
+		&record.Status,
+		&header,
+		&record.Body,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if err == sql.ErrNoRows {
+		return record, errors.Wrap(port.NewIdempotencyNotFoundErr(key), "get idempotency record repo error")
+	}
+	if err != nil {
+		return record, errors.Wrap(err, "get idempotency record repo error")
+	}
+
+	if header.Valid {
+		err = json.Unmarshal([]byte(header.String), &record.Header)
+		if err != nil {
+			return record, errors.Wrap(err, "get idempotency record repo error")
+		}
+	}
+
+	return record, nil
+}
+
+func (r *IdempotencyRepo) CompleteIdempotencyRecord(ctx context.Context, m model.IdempotencyRecord) error {
+	dbase := r.DB(ctx).DB()
+
+	header, err := json.Marshal(m.Header)
+	if err != nil {
+		return errors.Wrap(err, "complete idempotency record repo error")
+	}
+
+	query := `
+		UPDATE idempotency_keys SET status = $1, header = $2, body = $3
+		WHERE user_id = $4 AND method = $5 AND path = $6 AND key = $7
+	`
+
+	res, err := dbase.ExecContext(ctx, query, m.Status, string(header), m.Body, m.UserID, m.Method, m.Path, m.Key)
+	if err != nil {
+		return errors.Wrap(err, "complete idempotency record repo error")
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "complete idempotency record repo error")
+	}
+
+	if n == 0 {
+		return errors.Wrap(port.NewIdempotencyNotFoundErr(m.Key), "complete idempotency record repo error")
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepo) DeleteIdempotencyRecord(ctx context.Context, m model.IdempotencyRecord) error {
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE user_id = $1 AND method = $2 AND path = $3 AND key = $4
+	`
+
+	_, err := dbase.ExecContext(ctx, query, m.UserID, m.Method, m.Path, m.Key)
+	if err != nil {
+		return errors.Wrap(err, "delete idempotency record repo error")
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepo) DeleteExpiredIdempotencyRecords(ctx context.Context, now time.Time) (int64, error) {
+	dbase := r.DB(ctx).DB()
+
+	res, err := dbase.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now.UTC())
+	if err != nil {
+		return 0, errors.Wrap(err, "delete expired idempotency records repo error")
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "delete expired idempotency records repo error")
+	}
+
+	return n, nil
+}
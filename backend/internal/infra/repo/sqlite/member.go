@@ -0,0 +1,362 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+)
+
+const (
+	// memberColumns lists the columns read by scanMember, in order.
+	memberColumns = `m.list_id, u.id, u.username, u.name, u.email, m.role, m.created_at, m.updated_at`
+
+	// invitationColumns lists the columns read by scanInvitation, in order.
+	invitationColumns = `i.id, i.list_id, l.name, i.email, i.role, i.invited_by, i.expires_at, i.created_at, i.updated_at`
+)
+
+func (r *ListRepo) GetMember(ctx context.Context, listID, userID string) (member model.Member, err error) {
+	member, err = r.getMember(ctx, r.DB(ctx).DB(), listID, userID)
+	if errors.Is(err, port.MemberNotFoundErr) {
+		// Users that are not members can not tell the list exists.
+		return member, errors.Wrap(port.NewListNotFoundErr(listID), "get member repo error")
+	}
+	if err != nil {
+		return member, errors.Wrap(err, "get member repo error")
+	}
+
+	return member, nil
+}
+
+func (r *ListRepo) getMember(ctx context.Context, q queryer, listID, userID string) (member model.Member, err error) {
+	query := `
+		SELECT ` + memberColumns + `
+		FROM list_members m
+		INNER JOIN users u ON m.user_id = u.id
+		WHERE m.list_id = $1 AND m.user_id = $2
+	`
+
+	member, err = scanMember(q.QueryRowContext(ctx, query, listID, userID))
+	if err == sql.ErrNoRows {
+		return member, port.NewMemberNotFoundErr(userID)
+	}
+
+	return member, err
+}
+
+func (r *ListRepo) GetMembers(ctx context.Context, listID string) (members []model.Member, err error) {
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		SELECT ` + memberColumns + `
+		FROM list_members m
+		INNER JOIN users u ON m.user_id = u.id
+		WHERE m.list_id = $1
+		ORDER BY m.created_at, u.username
+	`
+
+	rows, err := dbase.QueryContext(ctx, query, listID)
+	if err != nil {
+		return members, errors.Wrap(err, "get members repo error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		member, err := scanMember(rows)
+		if err != nil {
+			return members, errors.Wrap(err, "get members repo error")
+		}
+
+		members = append(members, member)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return members, errors.Wrap(err, "get members repo error")
+	}
+
+	return members, nil
+}
+
+// UpdateMember changes the role of a member as the user, the last owner of the list cannot be demoted.
+func (r *ListRepo) UpdateMember(ctx context.Context, m model.Member, userID string) (member model.Member, err error) {
+	listID, memberID := m.ListID.String(), m.User.ID.String()
+
+	err = r.inUserTx(ctx, userID, func(tx *sql.Tx) error {
+		if m.Role != model.OwnerRole {
+			err := checkNotLastOwner(ctx, tx, listID, memberID)
+			if err != nil {
+				return err
+			}
+		}
+
+		query := `
+			UPDATE list_members
+			SET role = $1, updated_at = $2
+			WHERE list_id = $3 AND user_id = $4
+		`
+
+		res, err := tx.ExecContext(ctx, query, m.Role, time.Now().UTC(), listID, memberID)
+		if err != nil {
+			return err
+		}
+
+		err = checkAffected(res, port.NewMemberNotFoundErr(memberID))
+		if err != nil {
+			return err
+		}
+
+		member, err = r.getMember(ctx, tx, listID, memberID)
+		return err
+	})
+	if err != nil {
+		return m, errors.Wrap(err, "update member repo error")
+	}
+
+	return member, nil
+}
+
+// DeleteMember removes a member from the list as the user, the last owner of the list cannot be removed.
+func (r *ListRepo) DeleteMember(ctx context.Context, listID, memberID, userID string) error {
+	err := r.inUserTx(ctx, userID, func(tx *sql.Tx) error {
+		err := checkNotLastOwner(ctx, tx, listID, memberID)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			DELETE FROM list_members
+			WHERE list_id = $1 AND user_id = $2
+		`
+
+		res, err := tx.ExecContext(ctx, query, listID, memberID)
+		if err != nil {
+			return err
+		}
+
+		return checkAffected(res, port.NewMemberNotFoundErr(memberID))
+	})
+	if err != nil {
+		return errors.Wrap(err, "delete member repo error")
+	}
+
+	return nil
+}
+
+// checkNotLastOwner returns a ForbiddenErr if the user is the only owner of the list. It is run in the transaction
+// that demotes or removes the user, transactions are serializable so owners changing each other at the same time
+// cannot leave the list without one.
+func checkNotLastOwner(ctx context.Context, q queryer, listID, userID string) error {
+	query := `
+		SELECT COUNT(*), COALESCE(SUM(user_id = $1), 0)
+		FROM list_members
+		WHERE list_id = $2 AND role = $3
+	`
+
+	var owners, isOwner int
+	err := q.QueryRowContext(ctx, query, userID, listID, model.OwnerRole).Scan(&owners, &isOwner)
+	if err != nil {
+		return err
+	}
+
+	if isOwner > 0 && owners == 1 {
+		return port.NewForbiddenErr("a list must keep at least one owner")
+	}
+
+	return nil
+}
+
+func (r *ListRepo) CreateInvitation(ctx context.Context, m model.Invitation) (invitation model.Invitation, err error) {
+	err = m.GenID()
+	if err != nil {
+		return m, errors.Wrap(err, "create invitation repo error")
+	}
+
+	dbase := r.DB(ctx).DB()
+
+	now := time.Now().UTC()
+	m.Audit = model.NewAudit(now, now)
+
+	query := `
+		INSERT INTO list_invitations (id, list_id, email, role, invited_by, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err = dbase.ExecContext(ctx, query,
+		m.ID.String(),
+		m.ListID.String(),
+		m.Email,
+		m.Role,
+		m.InvitedBy.String(),
+		m.ExpiresAt,
+		m.CreatedAt,
+		m.UpdatedAt,
+	)
+	if err != nil {
+		return m, errors.Wrap(err, "create invitation repo error")
+	}
+
+	return m, nil
+}
+
+func (r *ListRepo) GetInvitation(ctx context.Context, invitationID string) (invitation model.Invitation, err error) {
+	ok := uuid.Validate(invitationID)
+	if !ok {
+		return invitation, InvalidResourceIDErr
+	}
+
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM list_invitations i
+		INNER JOIN lists l ON i.list_id = l.id
+		WHERE i.id = $1
+	`
+
+	invitation, err = scanInvitation(dbase.QueryRowContext(ctx, query, invitationID))
+	if err == sql.ErrNoRows {
+		return invitation, errors.Wrap(port.NewInvitationNotFoundErr(invitationID), "get invitation repo error")
+	}
+	if err != nil {
+		return invitation, errors.Wrap(err, "get invitation repo error")
+	}
+
+	return invitation, nil
+}
+
+func (r *ListRepo) GetInvitations(ctx context.Context, email string) (invitations []model.Invitation, err error) {
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM list_invitations i
+		INNER JOIN lists l ON i.list_id = l.id
+		WHERE i.email = $1
+		ORDER BY i.created_at, i.id
+	`
+
+	rows, err := dbase.QueryContext(ctx, query, email)
+	if err != nil {
+		return invitations, errors.Wrap(err, "get invitations repo error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return invitations, errors.Wrap(err, "get invitations repo error")
+		}
+
+		invitations = append(invitations, invitation)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return invitations, errors.Wrap(err, "get invitations repo error")
+	}
+
+	return invitations, nil
+}
+
+// AcceptInvitation adds the user to the list, or changes its role if already a member, and deletes the invitation.
+func (r *ListRepo) AcceptInvitation(ctx context.Context, invitation model.Invitation, userID string) (member model.Member, err error) {
+	listID := invitation.ListID.String()
+
+	err = r.inUserTx(ctx, userID, func(tx *sql.Tx) error {
+		query := `
+			DELETE FROM list_invitations
+			WHERE id = $1
+		`
+
+		res, err := tx.ExecContext(ctx, query, invitation.ID.String())
+		if err != nil {
+			return err
+		}
+
+		// Checked so that an invitation can only be accepted once
+		err = checkAffected(res, port.NewInvitationNotFoundErr(invitation.ID.String()))
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+
+		query = `
+			INSERT INTO list_members (list_id, user_id, role, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $4)
+			ON CONFLICT (list_id, user_id) DO UPDATE SET role = excluded.role, updated_at = excluded.updated_at
+		`
+
+		_, err = tx.ExecContext(ctx, query, listID, userID, invitation.Role, now)
+		if err != nil {
+			return err
+		}
+
+		member, err = r.getMember(ctx, tx, listID, userID)
+		return err
+	})
+	if err != nil {
+		return member, errors.Wrap(err, "accept invitation repo error")
+	}
+
+	return member, nil
+}
+
+func (r *ListRepo) DeleteInvitation(ctx context.Context, invitationID string) error {
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		DELETE FROM list_invitations
+		WHERE id = $1
+	`
+
+	res, err := dbase.ExecContext(ctx, query, invitationID)
+	if err != nil {
+		return errors.Wrap(err, "delete invitation repo error")
+	}
+
+	err = checkAffected(res, port.NewInvitationNotFoundErr(invitationID))
+	if err != nil {
+		return errors.Wrap(err, "delete invitation repo error")
+	}
+
+	return nil
+}
+
+// scanMember reads a member from a row selected using memberColumns.
+func scanMember(s scanner) (member model.Member, err error) {
+	err = s.Scan(
+		&member.ListID.UUID,
+		&member.User.ID.UUID,
+		&member.User.Username,
+		&member.User.Name,
+		&member.User.Email,
+		&member.Role,
+		&member.CreatedAt,
+		&member.UpdatedAt,
+	)
+
+	return member, err
+}
+
+// scanInvitation reads an invitation from a row selected using invitationColumns.
+func scanInvitation(s scanner) (invitation model.Invitation, err error) {
+	err = s.Scan(
+		&invitation.ID.UUID,
+		&invitation.ListID.UUID,
+		&invitation.ListName,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.InvitedBy.UUID,
+		&invitation.ExpiresAt,
+		&invitation.CreatedAt,
+		&invitation.UpdatedAt,
+	)
+
+	return invitation, err
+}
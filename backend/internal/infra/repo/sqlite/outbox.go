@@ -0,0 +1,175 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/infra/db"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+)
+
+// OutboxRepo reads the domain events recorded in the outbox. They are written by triggers of the lists, tasks and
+// list_members tables, so every statement of the other repos that changes them records its events in its own
+// transaction. The list repo sets the user that made the change in that same transaction, see inUserTx.
+type OutboxRepo struct {
+	*sys.SimpleCore
+	db db.DB
+}
+
+func NewOutboxRepo(db db.DB, opts ...sys.Option) *OutboxRepo {
+	return &OutboxRepo{
+		SimpleCore: sys.NewCore("outbox-repo", opts...),
+		db:         db,
+	}
+}
+
+func (r *OutboxRepo) DB(ctx context.Context) db.DB {
+	return r.db
+}
+
+func (r *OutboxRepo) Start(ctx context.Context) error {
+	r.Log().Infof("%s started", r.Name())
+	return nil
+}
+
+func (r *OutboxRepo) GetPendingEvents(ctx context.Context, now time.Time, limit int) (events []model.DomainEvent, err error) {
+	dbase := r.DB(ctx).DB()
+
+	// An event is left out if it or an earlier one of its list is pending and not due, so that the events of a
+	// list are delivered in order. Failed events no longer hold the later ones back.
+	query := `
+		SELECT o.id, o.type, o.list_id, o.item_id, o.user_id, o.payload, o.attempts, o.next_attempt_at, o.error,
+		       (SELECT json_group_array(h.subscriber) FROM outbox_handled h WHERE h.event_id = o.id),
+		       o.created_at, o.done_at, o.failed_at
+		FROM outbox o
+		WHERE o.done_at IS NULL AND o.failed_at IS NULL
+		  AND NOT EXISTS (
+		      SELECT 1 FROM outbox w
+		      WHERE w.list_id = o.list_id AND w.id <= o.id
+		        AND w.done_at IS NULL AND w.failed_at IS NULL AND w.next_attempt_at > $1
+		  )
+		ORDER BY o.id
+		LIMIT $2
+	`
+
+	rows, err := dbase.QueryContext(ctx, query, now.UTC(), limit)
+	if err != nil {
+		return events, errors.Wrap(err, "get pending events repo error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e model.DomainEvent
+		var payload, handled string
+		var nextAttemptAt, doneAt, failedAt db.NullTime
+
+		err := rows.Scan(
+			&e.ID,
+			&e.Type,
+			&e.ListID,
+			&e.ItemID,
+			&e.UserID,
+			&payload,
+			&e.Attempts,
+			&nextAttemptAt,
+			&e.Error,
+			&handled,
+			&e.CreatedAt,
+			&doneAt,
+			&failedAt,
+		)
+		if err != nil {
+			return events, errors.Wrap(err, "get pending events repo error")
+		}
+
+		err = json.Unmarshal([]byte(handled), &e.Handled)
+		if err != nil {
+			return events, errors.Wrap(err, "get pending events repo error")
+		}
+
+		e.Payload = []byte(payload)
+		e.NextAttemptAt = nextAttemptAt.Time
+		e.DoneAt = doneAt.Time
+		e.FailedAt = failedAt.Time
+
+		events = append(events, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return events, errors.Wrap(err, "get pending events repo error")
+	}
+
+	return events, nil
+}
+
+func (r *OutboxRepo) UpdateEvent(ctx context.Context, e model.DomainEvent) error {
+	handled, err := json.Marshal(append([]string{}, e.Handled...))
+	if err != nil {
+		return errors.Wrap(err, "update event repo error")
+	}
+
+	err = inTx(ctx, r.DB(ctx).DB(), func(tx *sql.Tx) error {
+		query := `
+			UPDATE outbox
+			SET attempts = $1, next_attempt_at = $2, error = $3, done_at = $4, failed_at = $5
+			WHERE id = $6
+		`
+
+		res, err := tx.ExecContext(ctx, query,
+			e.Attempts,
+			toNullTime(e.NextAttemptAt),
+			e.Error,
+			toNullTime(e.DoneAt),
+			toNullTime(e.FailedAt),
+			e.ID,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = checkAffected(res, port.NewEventNotFoundErr(strconv.FormatUint(e.ID, 10)))
+		if err != nil {
+			return err
+		}
+
+		query = `
+			INSERT OR IGNORE INTO outbox_handled (event_id, subscriber, handled_at)
+			SELECT $1, value, $2 FROM json_each($3)
+		`
+
+		_, err = tx.ExecContext(ctx, query, e.ID, time.Now().UTC(), string(handled))
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "update event repo error")
+	}
+
+	return nil
+}
+
+// DeleteDoneEvents deletes the events done before the given time along with their handled subscribers.
+// Failed events are kept to be looked into.
+func (r *OutboxRepo) DeleteDoneEvents(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM outbox
+		WHERE done_at IS NOT NULL AND done_at < $1
+	`
+
+	res, err := r.DB(ctx).DB().ExecContext(ctx, query, before.UTC())
+	if err != nil {
+		return 0, errors.Wrap(err, "delete done events repo error")
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "delete done events repo error")
+	}
+
+	return n, nil
+}
@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+)
+
+type (
+	// sqlQuery collects the conditions of a query and their arguments as numbered parameters.
+	sqlQuery struct {
+		conds []string
+		args  []any
+	}
+
+	// sortColumn is the expression a sort field orders by.
+	// Nullable columns sort NULL values last in both directions.
+	sortColumn struct {
+		expr     string
+		nullable bool
+	}
+)
+
+var (
+	listSortColumns = map[string]sortColumn{
+		"name":       {expr: "l.name COLLATE NOCASE"},
+		"created_at": {expr: "l.created_at"},
+		"updated_at": {expr: "l.updated_at"},
+	}
+
+	taskSortColumns = map[string]sortColumn{
+		"position":     {expr: "t.position"},
+		"name":         {expr: "t.name COLLATE NOCASE"},
+		"priority":     {expr: "t.priority"},
+		"done":         {expr: "t.done"},
+		"due_at":       {expr: "t.due_at", nullable: true},
+		"completed_at": {expr: "t.completed_at", nullable: true},
+		"created_at":   {expr: "t.created_at"},
+		"updated_at":   {expr: "t.updated_at"},
+	}
+)
+
+func newSQLQuery(args ...any) *sqlQuery {
+	return &sqlQuery{args: args}
+}
+
+// arg adds an argument and returns its parameter, i.e.: "$3".
+func (q *sqlQuery) arg(value any) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+// where adds a condition, conditions are joined with AND.
+func (q *sqlQuery) where(cond string) {
+	q.conds = append(q.conds, cond)
+}
+
+// and returns the conditions to append to a WHERE clause, prefixed with AND.
+func (q *sqlQuery) and() string {
+	if len(q.conds) == 0 {
+		return ""
+	}
+
+	return " AND " + strings.Join(q.conds, " AND ")
+}
+
+// limit returns the LIMIT and OFFSET clauses of the page.
+func (q *sqlQuery) limit(page model.Page) string {
+	if page.Limit <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(" LIMIT %s OFFSET %s", q.arg(page.Limit), q.arg(page.Offset))
+}
+
+// orderBy returns the ORDER BY clause of the sort fields, or of the default ones if none.
+// The tiebreaker keeps the order stable across pages.
+func orderBy(sort []model.Sort, columns map[string]sortColumn, defaults []model.Sort, tiebreaker string) (string, error) {
+	if len(sort) == 0 {
+		sort = defaults
+	}
+
+	terms := make([]string, 0, len(sort)+1)
+	for _, s := range sort {
+		col, ok := columns[s.Field]
+		if !ok {
+			return "", errors.NewKind(errors.Invalid, fmt.Sprintf("unknown sort field '%s'", s.Field))
+		}
+
+		dir := "ASC"
+		if s.Desc {
+			dir = "DESC"
+		}
+
+		if col.nullable {
+			terms = append(terms, col.expr+" IS NULL")
+		}
+
+		terms = append(terms, col.expr+" "+dir)
+	}
+
+	terms = append(terms, tiebreaker)
+
+	return " ORDER BY " + strings.Join(terms, ", "), nil
+}
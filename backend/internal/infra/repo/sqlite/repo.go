@@ -2,8 +2,11 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
 	"github.com/vanillazen/stl/backend/internal/infra/db"
 	"github.com/vanillazen/stl/backend/internal/sys"
 	"github.com/vanillazen/stl/backend/internal/sys/errors"
@@ -48,90 +51,264 @@ func (r *ListRepo) CreateList(ctx context.Context, m model.List) (updated model.
 		return m, errors.Wrap(err, "create list repo error")
 	}
 
-	return updated, nil
+	now := time.Now().UTC()
+	m.Audit = model.NewAudit(now, now)
+	m.Version = 1
+	m.Role = model.OwnerRole
+
+	// The creator becomes the first owner member of the list.
+	err = r.inUserTx(ctx, m.Owner.ID.String(), func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO lists (id, name, description, owner_id, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`
+
+		_, err := tx.ExecContext(ctx, query,
+			m.ID.String(),
+			m.Name,
+			m.Description,
+			m.Owner.ID.String(),
+			m.CreatedAt,
+			m.UpdatedAt,
+		)
+		if isPrimaryKeyErr(err) {
+			return port.NewListExistsErr(m.ID.String())
+		}
+		if err != nil {
+			return err
+		}
+
+		query = `
+			INSERT INTO list_members (list_id, user_id, role, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $4)
+		`
+
+		_, err = tx.ExecContext(ctx, query, m.ID.String(), m.Owner.ID.String(), m.Role, now)
+		return err
+	})
+	if err != nil {
+		return m, errors.Wrap(err, "create list repo error")
+	}
+
+	return m, nil
 }
 
-func (r *ListRepo) GetList(ctx context.Context, userID, listID string, preload ...bool) (list model.List, err error) {
+func (r *ListRepo) GetLists(ctx context.Context, userID string, q model.ListQuery) (lists []model.List, total int, err error) {
 	dbase := r.DB(ctx).DB()
 
-	query := `
-		SELECT l.id, l.name, l.description, l.created_at, l.updated_at, 
-		       t.id, t.list_id, t.name, t.description, t.category, t.tags, t.location, t.created_at, t.updated_at
+	sq := newSQLQuery(userID)
+	if q.Role != "" {
+		sq.where("m.role = " + sq.arg(q.Role))
+	}
+
+	from := `
 		FROM lists l
-		INNER JOIN users u ON l.owner_id = u.id
-		LEFT JOIN tasks t ON l.id = t.list_id
-		WHERE u.id = $1
-		ORDER BY l.id, t.id
-	`
+		INNER JOIN list_members m ON m.list_id = l.id
+		WHERE m.user_id = $1` + sq.and()
 
-	rows, err := dbase.Query(query, userID)
+	err = dbase.QueryRowContext(ctx, `SELECT COUNT(*)`+from, sq.args...).Scan(&total)
 	if err != nil {
-		return list, err
+		return lists, total, errors.Wrap(err, "get lists repo error")
 	}
-	defer rows.Close()
 
-	var currentListID model.ID
-	var currentTaskID model.ID
+	order, err := orderBy(q.Sort, listSortColumns, []model.Sort{{Field: "created_at"}}, "l.id")
+	if err != nil {
+		return lists, total, errors.Wrap(err, "get lists repo error")
+	}
+
+	query := `
+		SELECT l.id, l.name, l.description, l.owner_id, m.role, l.created_at, l.updated_at, l.version` + from + order + sq.limit(q.Page)
+
+	rows, err := dbase.QueryContext(ctx, query, sq.args...)
+	if err != nil {
+		return lists, total, errors.Wrap(err, "get lists repo error")
+	}
+	defer rows.Close()
 
 	for rows.Next() {
-		var task model.Task
+		var list model.List
 
 		err := rows.Scan(
 			&list.ID.UUID,
 			&list.Name,
 			&list.Description,
+			&list.Owner.ID.UUID,
+			&list.Role,
 			&list.CreatedAt,
 			&list.UpdatedAt,
-			&task.ID.UUID,
-			&task.ListID.UUID,
-			&task.Name,
-			&task.Description,
-			&task.Category,
-			&task.Tags,
-			&task.Location,
-			&task.CreatedAt,
-			&task.UpdatedAt,
+			&list.Version,
 		)
 		if err != nil {
-			return list, err
+			return lists, total, errors.Wrap(err, "get lists repo error")
 		}
 
-		if currentListID.String() != list.ID.String() {
-			currentListID = list.ID
-			list.Tasks = []model.Task{task}
-		} else {
-			if currentTaskID.String() != task.ID.String() {
-				currentTaskID = task.ID
-				list.Tasks = append(list.Tasks, task)
-			}
-		}
+		lists = append(lists, list)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return lists, total, errors.Wrap(err, "get lists repo error")
+	}
+
+	return lists, total, nil
+}
+
+func (r *ListRepo) GetList(ctx context.Context, userID, listID string, preload ...bool) (list model.List, err error) {
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		SELECT l.id, l.name, l.description, l.owner_id, m.role, l.created_at, l.updated_at, l.version, l.tasks_version
+		FROM lists l
+		INNER JOIN list_members m ON m.list_id = l.id
+		WHERE l.id = $1 AND m.user_id = $2
+	`
+
+	row := dbase.QueryRowContext(ctx, query, listID, userID)
+
+	err = row.Scan(
+		&list.ID.UUID,
+		&list.Name,
+		&list.Description,
+		&list.Owner.ID.UUID,
+		&list.Role,
+		&list.CreatedAt,
+		&list.UpdatedAt,
+		&list.Version,
+		&list.TasksVersion,
+	)
+	if err == sql.ErrNoRows {
+		return list, errors.Wrap(port.NewListNotFoundErr(listID), "get list repo error")
+	}
+	if err != nil {
+		return list, errors.Wrap(err, "get list repo error")
+	}
+
+	if len(preload) == 0 || !preload[0] {
+		return list, nil
+	}
+
+	list.Tasks, err = r.listTasks(ctx, dbase, listID)
+	if err != nil {
+		return list, errors.Wrap(err, "get list repo error")
 	}
 
 	return list, nil
 }
 
-func (r *ListRepo) GetUser(ctx context.Context, userID string) (user model.User, err error) {
-	// WIP: Mock implementation
-	ref := "e1263c73-521b-41b5-96e5-58c3f71e65a1\""
+func (r *ListRepo) UpdateList(ctx context.Context, m model.List, userID string) (updated model.List, err error) {
+	m.UpdatedAt = time.Now().UTC()
+
+	query := `
+		UPDATE lists
+		SET name = $1, description = $2, updated_at = $3, version = version + 1
+		WHERE id = $4 AND id IN (SELECT list_id FROM list_members WHERE user_id = $5)
+		  AND ($6 = 0 OR version = $6)
+		RETURNING owner_id, created_at, version
+	`
+
+	err = r.inUserTx(ctx, userID, func(tx *sql.Tx) error {
+		row := tx.QueryRowContext(ctx, query,
+			m.Name,
+			m.Description,
+			m.UpdatedAt,
+			m.ID.String(),
+			userID,
+			m.Version,
+		)
+
+		err := row.Scan(&m.Owner.ID.UUID, &m.CreatedAt, &m.Version)
+		if err == sql.ErrNoRows {
+			return listVersionErr(ctx, tx, m.ID.String(), userID)
+		}
+
+		return err
+	})
+	if err != nil {
+		return m, errors.Wrap(err, "update list repo error")
+	}
+
+	return m, nil
+}
+
+func (r *ListRepo) DeleteList(ctx context.Context, listID, userID string, version int) error {
+	query := `
+		DELETE FROM lists
+		WHERE id = $1 AND id IN (SELECT list_id FROM list_members WHERE user_id = $2)
+		  AND ($3 = 0 OR version = $3)
+	`
+
+	err := r.inUserTx(ctx, userID, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, query, listID, userID, version)
+		if err != nil {
+			return err
+		}
+
+		n, err := res.RowsAffected()
+		if err == nil && n == 0 {
+			err = listVersionErr(ctx, tx, listID, userID)
+		}
+
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "delete list repo error")
+	}
+
+	return nil
+}
 
+func (r *ListRepo) GetUser(ctx context.Context, userID string) (user model.User, err error) {
 	ok := uuid.Validate(userID)
 	if !ok {
 		return user, InvalidResourceIDErr
 	}
 
-	uid, err := uuid.Parse(ref)
+	user, err = selectUser(ctx, r.DB(ctx).DB(), "id = $1", userID)
 	if err != nil {
-		return user, err
+		return user, errors.Wrap(err, "get user repo error")
 	}
 
-	if userID == ref {
-		return model.User{
-			ID:       model.NewID(uid),
-			Username: "johndoe",
-			Name:     "John Doe",
-			Email:    "john.doe@localhost.com",
-		}, nil
+	return user, nil
+}
+
+// listVersionErr tells apart why a list change conditioned to its version affected no rows.
+// It returns ListNotFoundErr if the list is not visible to the user, a VersionErr otherwise.
+func listVersionErr(ctx context.Context, q queryer, listID, userID string) error {
+	query := `
+		SELECT version
+		FROM lists
+		WHERE id = $1 AND id IN (SELECT list_id FROM list_members WHERE user_id = $2)
+	`
+
+	return versionErr(q.QueryRowContext(ctx, query, listID, userID), port.NewListNotFoundErr(listID))
+}
+
+// versionErr returns notFoundErr if the row holding the current version of a resource does not exist,
+// a VersionErr for the resource otherwise.
+func versionErr(row *sql.Row, notFoundErr port.NotFoundErr) error {
+	var current int
+
+	err := row.Scan(&current)
+	if err == sql.ErrNoRows {
+		return notFoundErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return port.NewVersionErr(notFoundErr.Resource, notFoundErr.ID, current)
+}
+
+// checkAffected returns notFoundErr if no rows were affected by the statement.
+func checkAffected(res sql.Result, notFoundErr error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
 	}
 
-	return model.User{}, UserNotFoundErr
+	if n == 0 {
+		return notFoundErr
+	}
+
+	return nil
 }
@@ -0,0 +1,1764 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/infra/db/sqlite"
+	repo "github.com/vanillazen/stl/backend/internal/infra/repo/sqlite"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	l "github.com/vanillazen/stl/backend/internal/sys/log"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+)
+
+const (
+	assetsDir = "../../../../assets"
+	user1ID   = "0792b97b-4f88-42a8-a035-1d0aad0ae7f8"
+	user2ID   = "b1c20e60-ec1c-4fae-97b9-b4d0578b0123"
+	list1ID   = "cdc7a443-3c6a-431b-b45a-b14735953a19"
+	list3ID   = "dd7edff4-9b0d-4e92-80e2-1db98b4b0123"
+	task1ID   = "c0d1dbdb-b65e-4c4d-8f92-7b3ed6250123"
+	task3ID   = "d6ff256b-9f79-42be-99ea-835c586e0123"
+	noneID    = "00000000-0000-4000-8000-000000000000"
+)
+
+// newTestRepo returns a list repo backed by a migrated and seeded in-memory database.
+func newTestRepo(t *testing.T) *repo.ListRepo {
+	t.Helper()
+
+	db, opts := newTestDB(t)
+	return repo.NewListRepo(db, opts...)
+}
+
+// newTestDB returns a migrated and seeded in-memory database.
+func newTestDB(t *testing.T) (*sqlite.DB, []sys.Option) {
+	t.Helper()
+
+	db, opts := openTestDB(t)
+	execAssetFiles(t, db, assetFiles(t, "migrations"), upStmts)
+	execAssets(t, db, "seeding", seedStmts)
+
+	return db, opts
+}
+
+// openTestDB returns an empty in-memory database.
+func openTestDB(t *testing.T) (*sqlite.DB, []sys.Option) {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.SetValues(map[string]string{
+		config.Key.SQLiteFilePath: ":memory:",
+	})
+
+	opts := []sys.Option{
+		sys.WithConfig(cfg),
+		sys.WithLogger(l.NewLogger("error")),
+	}
+
+	db := sqlite.NewDB(opts...)
+	err := db.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+
+	// Every connection to :memory: opens a new empty database.
+	db.DB().SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.DB().Close() })
+
+	return db, opts
+}
+
+// upStmts returns the statements of the UP section of a migration.
+func upStmts(content string) string {
+	up := strings.Split(content, "--DOWN")[0]
+	return strings.TrimPrefix(up, "--UP\n")
+}
+
+func seedStmts(content string) string {
+	return strings.ReplaceAll(content, "--SEED", "")
+}
+
+func execAssets(t *testing.T, db *sqlite.DB, dir string, stmts func(content string) string) {
+	t.Helper()
+
+	execAssetFiles(t, db, assetFiles(t, dir), stmts)
+}
+
+// assetFiles returns the SQL files of an assets directory, in the order they are applied.
+func assetFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(assetsDir, dir, "sqlite", "*.sql"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Test setup error: no %s found", dir)
+	}
+
+	return files
+}
+
+func execAssetFiles(t *testing.T, db *sqlite.DB, files []string, stmts func(content string) string) {
+	t.Helper()
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Test setup error: %s", err)
+		}
+
+		_, err = db.DB().Exec(stmts(string(content)))
+		if err != nil {
+			t.Fatalf("Test setup error: %s: %s", file, err)
+		}
+	}
+}
+
+func TestGetList(t *testing.T) {
+	tests := []struct {
+		name          string
+		userID        string
+		listID        string
+		preload       []bool
+		expectedName  string
+		expectedTasks []string
+		expectedErr   error
+	}{
+		{
+			name:          "Owned list with tasks",
+			userID:        user1ID,
+			listID:        list1ID,
+			preload:       []bool{true},
+			expectedName:  "List 1",
+			expectedTasks: []string{task1ID},
+		},
+		{
+			name:          "Owned list without tasks",
+			userID:        user1ID,
+			listID:        list1ID,
+			preload:       []bool{false},
+			expectedName:  "List 1",
+			expectedTasks: nil,
+		},
+		{
+			name:          "Owned list with default preload",
+			userID:        user1ID,
+			listID:        list1ID,
+			expectedName:  "List 1",
+			expectedTasks: nil,
+		},
+		{
+			name:          "Only tasks of the requested list",
+			userID:        user2ID,
+			listID:        list3ID,
+			preload:       []bool{true},
+			expectedName:  "List 3",
+			expectedTasks: []string{task3ID},
+		},
+		{
+			name:        "List of another user",
+			userID:      user2ID,
+			listID:      list1ID,
+			preload:     []bool{true},
+			expectedErr: port.NewListNotFoundErr(list1ID),
+		},
+		{
+			name:        "Unknown list",
+			userID:      user1ID,
+			listID:      noneID,
+			preload:     []bool{true},
+			expectedErr: port.NewListNotFoundErr(noneID),
+		},
+	}
+
+	r := newTestRepo(t)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			list, err := r.GetList(context.Background(), test.userID, test.listID, test.preload...)
+
+			// Verify the error
+			if test.expectedErr != nil {
+				var notFound port.NotFoundErr
+				if !errors.As(err, &notFound) || !errors.Is(err, test.expectedErr) {
+					t.Fatalf("Error: expected '%v', got '%v'", test.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Error: unexpected '%v'", err)
+			}
+
+			// Verify the list
+			if list.ID.String() != test.listID || list.Name != test.expectedName {
+				t.Errorf("List: expected %s (%s), got %s (%s)", test.listID, test.expectedName, list.ID.String(), list.Name)
+			}
+
+			if list.Owner.ID.String() != test.userID {
+				t.Errorf("Owner: expected %s, got %s", test.userID, list.Owner.ID.String())
+			}
+
+			// Verify the tasks
+			var taskIDs []string
+			for _, task := range list.Tasks {
+				taskIDs = append(taskIDs, task.ID.String())
+			}
+
+			if !equalSlices(taskIDs, test.expectedTasks) {
+				t.Errorf("Tasks: expected %v, got %v", test.expectedTasks, taskIDs)
+			}
+		})
+	}
+}
+
+func TestGetListAfterAddTask(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+
+	task, err := r.AddTask(ctx, list1ID, model.Task{Name: "Task 6"}, user1ID)
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+
+	list, err := r.GetList(ctx, user1ID, list1ID, true)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	expected := []string{task1ID, task.ID.String()}
+	var taskIDs []string
+	for _, t := range list.Tasks {
+		taskIDs = append(taskIDs, t.ID.String())
+	}
+
+	if !equalSlices(taskIDs, expected) {
+		t.Errorf("Tasks: expected %v, got %v", expected, taskIDs)
+	}
+}
+
+func TestGetTasksQuery(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+
+	due := time.Date(2030, 1, 10, 12, 0, 0, 0, time.UTC)
+	_, err := r.AddTasks(ctx, list1ID, []model.Task{
+		{Name: "b task", Priority: model.PriorityHigh, DueAt: due},
+		{Name: "A task", Priority: model.PriorityLow, DueAt: due.AddDate(0, 0, 5)},
+		{Name: "c task", Priority: model.PriorityHigh},
+	}, user1ID)
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+
+	done, high := true, model.PriorityHigh
+
+	tests := []struct {
+		name     string
+		query    model.TaskQuery
+		expected []string
+		total    int
+	}{
+		{
+			name:     "Default order",
+			query:    model.TaskQuery{},
+			expected: []string{"Task 1", "b task", "A task", "c task"},
+			total:    4,
+		},
+		{
+			name:     "Sorted page",
+			query:    model.TaskQuery{Page: model.Page{Limit: 2, Offset: 1}, Sort: []model.Sort{{Field: "name"}}},
+			expected: []string{"b task", "c task"},
+			total:    4,
+		},
+		{
+			name:     "Descending with tiebreak",
+			query:    model.TaskQuery{Sort: []model.Sort{{Field: "priority", Desc: true}, {Field: "name", Desc: true}}},
+			expected: []string{"c task", "b task", "A task", "Task 1"},
+			total:    4,
+		},
+		{
+			name:     "Nulls last",
+			query:    model.TaskQuery{Sort: []model.Sort{{Field: "due_at", Desc: true}, {Field: "name"}}},
+			expected: []string{"A task", "b task", "c task", "Task 1"},
+			total:    4,
+		},
+		{
+			name:     "Tag",
+			query:    model.TaskQuery{Tag: "Tag 1"},
+			expected: []string{"Task 1"},
+			total:    1,
+		},
+		{
+			name:     "Priority and due date",
+			query:    model.TaskQuery{Priority: &high, DueBefore: due.AddDate(0, 0, 1)},
+			expected: []string{"b task"},
+			total:    1,
+		},
+		{
+			name:     "Due after",
+			query:    model.TaskQuery{DueAfter: due.AddDate(0, 0, 1)},
+			expected: []string{"A task"},
+			total:    1,
+		},
+		{
+			name:     "Done",
+			query:    model.TaskQuery{Done: &done},
+			expected: nil,
+			total:    0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tasks, total, err := r.GetTasks(ctx, list1ID, user1ID, test.query)
+			if err != nil {
+				t.Fatalf("Error: unexpected '%v'", err)
+			}
+
+			var names []string
+			for _, task := range tasks {
+				names = append(names, task.Name)
+			}
+
+			if !equalSlices(names, test.expected) || total != test.total {
+				t.Errorf("Tasks: expected %v of %d, got %v of %d", test.expected, test.total, names, total)
+			}
+		})
+	}
+
+	_, _, err = r.GetTasks(ctx, list1ID, user2ID, model.TaskQuery{})
+	if !errors.Is(err, port.ListNotFoundErr) {
+		t.Errorf("Error: expected '%v', got '%v'", port.ListNotFoundErr, err)
+	}
+}
+
+func TestGetListsQuery(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+
+	lists, total, err := r.GetLists(ctx, user2ID, model.ListQuery{
+		Page: model.Page{Limit: 2},
+		Sort: []model.Sort{{Field: "name", Desc: true}},
+	})
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	var names []string
+	for _, list := range lists {
+		names = append(names, list.Name)
+	}
+
+	expected := []string{"List 4", "List 3"}
+	if !equalSlices(names, expected) || total != 3 {
+		t.Errorf("Lists: expected %v of 3, got %v of %d", expected, names, total)
+	}
+
+	lists, total, err = r.GetLists(ctx, user2ID, model.ListQuery{Role: model.ViewerRole})
+	if err != nil || len(lists) != 0 || total != 0 {
+		t.Errorf("Role: expected no lists, got %d of %d (%v)", len(lists), total, err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+
+	task, err := r.AddTask(ctx, list1ID, model.Task{Name: "Buy groceries", Description: "Milk, bread and café"}, user1ID)
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+
+	_, err = r.AddTask(ctx, list1ID, model.Task{Name: "Call mom", Description: "About the groceries"}, user1ID)
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+
+	_, err = r.AttachTag(ctx, list1ID, task.ID.String(), "errands", user1ID)
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+
+	tests := []struct {
+		name     string
+		userID   string
+		terms    []string
+		expected []string
+	}{
+		{
+			name:     "Name ranks first",
+			userID:   user1ID,
+			terms:    []string{"groceries"},
+			expected: []string{"Buy <mark>groceries</mark>", "Call mom"},
+		},
+		{
+			name:     "Prefix and diacritics",
+			userID:   user1ID,
+			terms:    []string{"milk", "cafe"},
+			expected: []string{"Buy groceries"},
+		},
+		{
+			name:     "Tags",
+			userID:   user1ID,
+			terms:    []string{"errand"},
+			expected: []string{"Buy groceries"},
+		},
+		{
+			name:     "Lists",
+			userID:   user1ID,
+			terms:    []string{"list"},
+			expected: []string{"<mark>List</mark> 1"},
+		},
+		{
+			name:     "Query syntax is not interpreted",
+			userID:   user1ID,
+			terms:    []string{`"groceries`, "OR", "NEAR("},
+			expected: nil,
+		},
+		{
+			name:     "Other users lists are not searched",
+			userID:   user2ID,
+			terms:    []string{"groceries"},
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, total, err := r.Search(ctx, test.userID, model.SearchQuery{Terms: test.terms})
+			if err != nil {
+				t.Fatalf("Error: unexpected '%v'", err)
+			}
+
+			var names []string
+			for _, res := range results {
+				names = append(names, res.Name)
+			}
+
+			if !equalSlices(names, test.expected) || total != len(test.expected) {
+				t.Errorf("Results: expected %v, got %v of %d", test.expected, names, total)
+			}
+		})
+	}
+}
+
+func equalSlices(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestToggleTask(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+
+	task, err := r.ToggleTask(ctx, list1ID, task1ID, user1ID, 0)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	if !task.Done || task.CompletedAt.IsZero() {
+		t.Errorf("Toggle on: expected done with completion time, got %v (%v)", task.Done, task.CompletedAt)
+	}
+
+	task, err = r.ToggleTask(ctx, list1ID, task1ID, user1ID, 0)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	if task.Done || !task.CompletedAt.IsZero() {
+		t.Errorf("Toggle off: expected pending without completion time, got %v (%v)", task.Done, task.CompletedAt)
+	}
+
+	_, err = r.ToggleTask(ctx, list1ID, task1ID, user1ID, task.Version-1)
+	if !errors.Is(err, port.VersionMismatchErr) {
+		t.Errorf("Stale version: expected '%v', got '%v'", port.VersionMismatchErr, err)
+	}
+
+	task, err = r.ToggleTask(ctx, list1ID, task1ID, user1ID, task.Version)
+	if err != nil || !task.Done {
+		t.Errorf("Current version: expected done, got %v (%v)", task.Done, err)
+	}
+
+	_, err = r.ToggleTask(ctx, list1ID, task1ID, user2ID, 0)
+	if !errors.Is(err, port.TaskNotFoundErr) {
+		t.Errorf("Error: expected '%v', got '%v'", port.TaskNotFoundErr, err)
+	}
+}
+
+func TestVersions(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+
+	list, err := r.GetList(ctx, user1ID, list1ID)
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+
+	task, err := r.GetTask(ctx, list1ID, task1ID, user1ID)
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+
+	stale, tasksVersion := list.Version, list.TasksVersion
+
+	list.Name = "List 1 renamed"
+	list, err = r.UpdateList(ctx, list, user1ID)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	if list.Version != stale+1 {
+		t.Errorf("Update list: expected version %d, got %d", stale+1, list.Version)
+	}
+
+	list.Version = stale
+	_, err = r.UpdateList(ctx, list, user1ID)
+	if !errors.Is(err, port.VersionMismatchErr) || errors.KindOf(err) != errors.Conflict {
+		t.Errorf("Stale list: expected '%v', got '%v'", port.VersionMismatchErr, err)
+	}
+
+	_, err = r.UpdateList(ctx, list, user2ID)
+	if !errors.Is(err, port.ListNotFoundErr) {
+		t.Errorf("Not a member: expected '%v', got '%v'", port.ListNotFoundErr, err)
+	}
+
+	task, err = r.UpdateTask(ctx, task, user1ID)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	_, err = r.AttachTag(ctx, list1ID, task1ID, "Tag 3", user1ID)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	err = r.DeleteTask(ctx, list1ID, task1ID, user1ID, task.Version)
+	if !errors.Is(err, port.VersionMismatchErr) {
+		t.Errorf("Task labels: expected '%v', got '%v'", port.VersionMismatchErr, err)
+	}
+
+	err = r.DeleteTask(ctx, list1ID, task1ID, user1ID, task.Version+1)
+	if err != nil {
+		t.Errorf("Delete task: unexpected '%v'", err)
+	}
+
+	// Changes of the tasks are a new version of the collection, the list keeps its own
+	current, err := r.GetList(ctx, user1ID, list1ID)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	if current.Version != stale+1 {
+		t.Errorf("List tasks: expected list version %d, got %d", stale+1, current.Version)
+	}
+
+	// Updated, labelled and deleted
+	if current.TasksVersion != tasksVersion+3 {
+		t.Errorf("List tasks: expected tasks version %d, got %d", tasksVersion+3, current.TasksVersion)
+	}
+
+	err = r.DeleteList(ctx, list1ID, user1ID, current.Version)
+	if err != nil {
+		t.Errorf("Delete list: unexpected '%v'", err)
+	}
+
+	err = r.DeleteList(ctx, list1ID, user1ID, 0)
+	if !errors.Is(err, port.ListNotFoundErr) {
+		t.Errorf("Deleted list: expected '%v', got '%v'", port.ListNotFoundErr, err)
+	}
+}
+
+func TestBatchTasks(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+
+	other, err := r.CreateList(ctx, model.List{Name: "List 1b", Owner: model.User{ID: model.NewID(uuid.MustParse(user1ID))}})
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+	otherID := other.ID.String()
+
+	taskID := func(id string) model.Task {
+		return model.Task{ID: model.NewID(uuid.MustParse(id))}
+	}
+
+	// A failed operation undoes the whole atomic batch
+	_, err = r.BatchTasks(ctx, list1ID, []model.TaskOp{
+		{Kind: model.CreateTaskOp, Task: model.Task{Name: "Undone"}},
+		{Kind: model.DeleteTaskOp, Task: taskID(noneID)},
+	}, user1ID, true)
+
+	var opErr port.TaskOpErr
+	if !errors.As(err, &opErr) || opErr.Index != 1 || !errors.Is(err, port.TaskNotFoundErr) || errors.KindOf(err) != errors.NotFound {
+		t.Errorf("Atomic: expected a not found error of operation 1, got '%v'", err)
+	}
+
+	tasks, _, _ := r.GetTasks(ctx, list1ID, user1ID, model.TaskQuery{})
+	if len(tasks) != 1 {
+		t.Errorf("Atomic: expected no task created, got %d tasks", len(tasks))
+	}
+
+	update := taskID(task1ID)
+	update.Name = "Task 1 batched"
+	update.Tags = []string{"Tag 1"}
+	results, err := r.BatchTasks(ctx, list1ID, []model.TaskOp{
+		{Kind: model.CreateTaskOp, Task: model.Task{Name: "Created", Tags: []string{"Tag 9"}}},
+		{Kind: model.UpdateTaskOp, Task: update},
+		{Kind: model.MoveTaskOp, Task: taskID(task1ID), ToListID: otherID},
+	}, user1ID, true)
+	if err != nil || len(results) != 3 {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	created := results[0].Task
+	if created.ID.String() == "" || created.Name != "Created" || !equalSlices(created.Tags, []string{"Tag 9"}) {
+		t.Errorf("Create: expected the created task with its tags, got %+v", created)
+	}
+
+	moved := results[2].Task
+	if moved.ListID.String() != otherID || moved.Name != "Task 1 batched" || moved.Position != 1 || moved.Version != 3 || !equalSlices(moved.Tags, []string{"Tag 1"}) {
+		t.Errorf("Move: expected the updated task at the end of the other list, got %+v", moved)
+	}
+
+	// Operations of a best effort batch fail on their own
+	results, err = r.BatchTasks(ctx, list1ID, []model.TaskOp{
+		{Kind: model.DeleteTaskOp, Task: created},
+		{Kind: model.UpdateTaskOp, Task: model.Task{ID: created.ID, Name: "Deleted"}},
+		{Kind: model.MoveTaskOp, Task: taskID(task1ID), ToListID: list1ID},
+		{Kind: model.MoveTaskOp, Task: moved, ToListID: list3ID},
+	}, user1ID, false)
+	if err != nil || len(results) != 4 {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	expected := []error{nil, port.TaskNotFoundErr, port.TaskNotFoundErr, port.ListNotFoundErr}
+	for i, want := range expected {
+		if (want == nil && results[i].Err != nil) || (want != nil && !errors.Is(results[i].Err, want)) {
+			t.Errorf("Best effort %d: expected '%v', got '%v'", i, want, results[i].Err)
+		}
+	}
+
+	tasks, _, _ = r.GetTasks(ctx, list1ID, user1ID, model.TaskQuery{})
+	if len(tasks) != 0 {
+		t.Errorf("Best effort: expected the list emptied, got %d tasks", len(tasks))
+	}
+}
+
+func TestTags(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+
+	// Names with commas are kept as a single tag.
+	task, err := r.AddTask(ctx, list1ID, model.Task{Name: "Task 6", Tags: model.StringSlice{"Tag 1", "a, b"}}, user1ID)
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+
+	expected := []string{"Tag 1", "a, b"}
+	if !equalSlices(task.Tags, expected) {
+		t.Errorf("Task tags: expected %v, got %v", expected, []string(task.Tags))
+	}
+
+	tag, err := r.AttachTag(ctx, list1ID, task1ID, "a, b", user1ID)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	if tag.Usage != 2 {
+		t.Errorf("Usage: expected 2, got %d", tag.Usage)
+	}
+
+	tags, err := r.GetTags(ctx, user1ID)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	usage := map[string]int{}
+	for _, tg := range tags {
+		usage[tg.Name] = tg.Usage
+	}
+
+	expectedUsage := map[string]int{"Tag 1": 2, "Tag 2": 1, "a, b": 2}
+	for name, count := range expectedUsage {
+		if usage[name] != count {
+			t.Errorf("Usage of %s: expected %d, got %d", name, count, usage[name])
+		}
+	}
+
+	err = r.DetachTag(ctx, list1ID, task1ID, tag.ID.String(), user1ID)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	err = r.DetachTag(ctx, list1ID, task1ID, tag.ID.String(), user1ID)
+	if !errors.Is(err, port.TagNotFoundErr) {
+		t.Errorf("Error: expected '%v', got '%v'", port.TagNotFoundErr, err)
+	}
+
+	// Tags of other users are not visible.
+	_, err = r.GetTag(ctx, tag.ID.String(), user2ID)
+	if !errors.Is(err, port.TagNotFoundErr) {
+		t.Errorf("Error: expected '%v', got '%v'", port.TagNotFoundErr, err)
+	}
+}
+
+func TestSharedTags(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+
+	addMember(t, r, list1ID, "user2@example.com", user1ID, user2ID)
+	addMember(t, r, list3ID, "user1@example.com", user2ID, user1ID)
+
+	// Editors label the tasks of a shared list with the labels of its owner
+	tag, err := r.AttachTag(ctx, list1ID, task1ID, "Shared", user2ID)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	if tag.OwnerID.String() != user1ID {
+		t.Errorf("Owner: expected %s, got %s", user1ID, tag.OwnerID.String())
+	}
+
+	if usage := tagUsage(t, r, user2ID); usage["Shared"] != 0 {
+		t.Errorf("Editor tags: expected no 'Shared' tag, got %v", usage)
+	}
+
+	// A task moved to a list of another owner gets the labels of the new owner with the same names
+	_, err = r.BatchTasks(ctx, list1ID, []model.TaskOp{
+		{Kind: model.MoveTaskOp, Task: model.Task{ID: model.NewID(uuid.MustParse(task1ID))}, ToListID: list3ID},
+	}, user1ID, true)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	task, err := r.GetTask(ctx, list3ID, task1ID, user1ID)
+	expected := []string{"Shared", "Tag 1", "Tag 2"}
+	if err != nil || !equalSlices(task.Tags, expected) || !equalSlices(task.Category, []string{"Category 1"}) {
+		t.Errorf("Moved task: expected tags %v, got %+v (%v)", expected, task, err)
+	}
+
+	usage := tagUsage(t, r, user1ID)
+	if usage["Shared"] != 0 || usage["Tag 1"] != 0 {
+		t.Errorf("Previous owner tags: expected unused, got %v", usage)
+	}
+
+	usage = tagUsage(t, r, user2ID)
+	if usage["Shared"] != 1 || usage["Tag 1"] != 1 || usage["Tag 2"] != 2 {
+		t.Errorf("New owner tags: expected the ones of the moved task, got %v", usage)
+	}
+}
+
+// addMember adds the user with the email to the list as an editor, invited by the owner.
+func addMember(t *testing.T, r *repo.ListRepo, listID, email, ownerID, userID string) {
+	t.Helper()
+
+	invitation, err := r.CreateInvitation(context.Background(), model.Invitation{
+		ListID:    model.NewID(uuid.MustParse(listID)),
+		Email:     email,
+		Role:      model.EditorRole,
+		InvitedBy: model.NewID(uuid.MustParse(ownerID)),
+		ExpiresAt: time.Now().UTC().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+
+	_, err = r.AcceptInvitation(context.Background(), invitation, userID)
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+}
+
+// tagUsage returns the number of tasks of every tag of the user.
+func tagUsage(t *testing.T, r *repo.ListRepo, userID string) map[string]int {
+	t.Helper()
+
+	tags, err := r.GetTags(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	usage := map[string]int{}
+	for _, tg := range tags {
+		usage[tg.Name] = tg.Usage
+	}
+
+	return usage
+}
+
+// legacyTasks are the tasks seeded before labels had their own tables, as comma-joined values,
+// along with one with blank, duplicated and padded values.
+const legacyTasks = `
+INSERT INTO tasks (id, list_id, name, description, category, tags, location, created_at, updated_at)
+VALUES ('c0d1dbdb-b65e-4c4d-8f92-7b3ed6250123', 'cdc7a443-3c6a-431b-b45a-b14735953a19', 'Task 1', 'Task 1 Description', 'Category 1', 'Tag 1,Tag 2', 'Location 1', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+       ('4b87ef82-d16b-4b5d-b1a0-3a8366120123', '70a4a418-7b2b-4c2b-95b3-4e1656c81234', 'Task 2', 'Task 2 Description', 'Category 2', 'Tag 3,Tag 4', 'Location 2,Location 3', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+       ('d6ff256b-9f79-42be-99ea-835c586e0123', 'dd7edff4-9b0d-4e92-80e2-1db98b4b0123', 'Task 3', 'Task 3 Description', 'Category 1,Category 3', 'Tag 2,Tag 5', 'Location 1,Location 4', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+       ('e8b13d39-894e-487b-9f9e-19a5c2490123', 'd9d5d7d0-dc10-48a9-a580-7d4293f11234', 'Task 4', 'Task 4 Description', 'Category 4', 'Tag 6,Tag 7', 'Location 5', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+       ('af6c62da-54ad-414f-9462-c5229a5b0123', 'ddc09e08-286b-47a7-bcd0-8b8d3bdc0123', 'Task 5', 'Task 5 Description', 'Category 2,Category 3', 'Tag 4,Tag 8', 'Location 6,Location 7', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+       ('5e0c7a51-27a4-4f6e-8d43-3f4b1a6c0123', 'cdc7a443-3c6a-431b-b45a-b14735953a19', 'Task 6', 'Task 6 Description', '  ', ' Tag 1 , ,Tag 1,,Tag 9 ', NULL, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+`
+
+func TestTagsMigration(t *testing.T) {
+	db, opts := openTestDB(t)
+	r := repo.NewListRepo(db, opts...)
+	ctx := context.Background()
+
+	migrations := assetFiles(t, "migrations")
+	i := 0
+	for i < len(migrations) && filepath.Base(migrations[i]) < "00000005" {
+		i++
+	}
+
+	// Comma-joined values are split by the tags migration
+	execAssetFiles(t, db, migrations[:i], upStmts)
+	execAssetFiles(t, db, assetFiles(t, "seeding")[:2], seedStmts)
+	_, err := db.DB().Exec(legacyTasks)
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+	execAssetFiles(t, db, migrations[i:], upStmts)
+
+	tests := []struct {
+		listID, taskID, userID   string
+		category, tags, location []string
+	}{
+		{list1ID, task1ID, user1ID, []string{"Category 1"}, []string{"Tag 1", "Tag 2"}, []string{"Location 1"}},
+		{list3ID, task3ID, user2ID, []string{"Category 1", "Category 3"}, []string{"Tag 2", "Tag 5"}, []string{"Location 1", "Location 4"}},
+		{list1ID, "5e0c7a51-27a4-4f6e-8d43-3f4b1a6c0123", user1ID, []string{}, []string{"Tag 1", "Tag 9"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		task, err := r.GetTask(ctx, tt.listID, tt.taskID, tt.userID)
+		if err != nil {
+			t.Fatalf("Error: unexpected '%v'", err)
+		}
+
+		if !equalSlices(task.Category, tt.category) || !equalSlices(task.Tags, tt.tags) || !equalSlices(task.Location, tt.location) {
+			t.Errorf("Task %s: expected %v %v %v, got %v %v %v", task.Name, tt.category, tt.tags, tt.location,
+				[]string(task.Category), []string(task.Tags), []string(task.Location))
+		}
+	}
+
+	// Values are shared by the tasks of the lists of an owner, not by other users
+	tags, err := r.GetTags(ctx, user1ID)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	usage := map[string]int{}
+	for _, tg := range tags {
+		usage[tg.Name] = tg.Usage
+	}
+
+	expectedUsage := map[string]int{"Tag 1": 2, "Tag 2": 1, "Tag 9": 1}
+	if len(usage) != len(expectedUsage) {
+		t.Errorf("Tags: expected %v, got %v", expectedUsage, usage)
+	}
+
+	for name, count := range expectedUsage {
+		if usage[name] != count {
+			t.Errorf("Usage of %s: expected %d, got %d", name, count, usage[name])
+		}
+	}
+
+	var categories int
+	err = db.DB().QueryRow(`SELECT COUNT(*) FROM categories WHERE owner_id = $1`, user2ID).Scan(&categories)
+	if err != nil || categories != 4 {
+		t.Errorf("Categories: expected 4 of user 2, got %d (%v)", categories, err)
+	}
+}
+
+func TestUserRepo(t *testing.T) {
+	db, opts := newTestDB(t)
+	r := repo.NewUserRepo(db, opts...)
+	ctx := context.Background()
+
+	user, err := r.CreateUser(ctx, model.User{Username: "jdoe", Name: "John", Email: "jdoe@example.com", Password: "hash"})
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	found, err := r.GetUserByUsername(ctx, "JDoe")
+	if err != nil || found.ID.String() != user.ID.String() {
+		t.Errorf("By username: expected %s, got %s (%v)", user.ID.String(), found.ID.String(), err)
+	}
+
+	if found.Role != model.UserSystemRole {
+		t.Errorf("Role: expected '%s', got '%s'", model.UserSystemRole, found.Role)
+	}
+
+	found, err = r.GetUserByEmail(ctx, "JDOE@example.com")
+	if err != nil || found.Password != "hash" {
+		t.Errorf("By email: expected %s with its hash, got %s (%v)", user.ID.String(), found.ID.String(), err)
+	}
+
+	_, err = r.CreateUser(ctx, model.User{Username: "JDOE", Name: "Other", Email: "other@example.com", Password: "hash"})
+	if err == nil {
+		t.Errorf("Duplicated username: expected error, got none")
+	}
+
+	_, err = r.GetUser(ctx, noneID)
+	if !errors.Is(err, port.UserNotFoundErr) {
+		t.Errorf("Error: expected '%v', got '%v'", port.UserNotFoundErr, err)
+	}
+}
+
+func TestSessionRepo(t *testing.T) {
+	db, opts := newTestDB(t)
+	r := repo.NewUserRepo(db, opts...)
+	ctx := context.Background()
+
+	user, err := r.CreateUser(ctx, model.User{Username: "jdoe", Name: "John", Email: "jdoe@example.com", Password: "hash"})
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	expiresAt := time.Now().UTC().Add(time.Hour)
+	session, err := r.CreateSession(ctx, model.Session{UserID: user.ID, RefreshID: "r1", ExpiresAt: expiresAt})
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+	sessionID := session.ID.String()
+
+	err = r.RotateSession(ctx, sessionID, "r1", "r2", expiresAt)
+	if err != nil {
+		t.Errorf("Rotate: unexpected '%v'", err)
+	}
+
+	err = r.RotateSession(ctx, sessionID, "r1", "r3", expiresAt)
+	if !errors.Is(err, port.SessionNotFoundErr) {
+		t.Errorf("Rotate reused: expected '%v', got '%v'", port.SessionNotFoundErr, err)
+	}
+
+	err = r.RevokeSession(ctx, sessionID, noneID)
+	if !errors.Is(err, port.SessionNotFoundErr) {
+		t.Errorf("Revoke not owned: expected '%v', got '%v'", port.SessionNotFoundErr, err)
+	}
+
+	err = r.RevokeSession(ctx, sessionID, user.ID.String())
+	if err != nil {
+		t.Errorf("Revoke: unexpected '%v'", err)
+	}
+
+	found, err := r.GetSession(ctx, sessionID)
+	if err != nil || found.RefreshID != "r2" || found.Active(time.Now()) {
+		t.Errorf("Get: expected revoked session with refresh r2, got %+v (%v)", found, err)
+	}
+
+	err = r.RotateSession(ctx, sessionID, "r2", "r3", expiresAt)
+	if !errors.Is(err, port.SessionNotFoundErr) {
+		t.Errorf("Rotate revoked: expected '%v', got '%v'", port.SessionNotFoundErr, err)
+	}
+}
+
+func TestAPIKeyRepo(t *testing.T) {
+	db, opts := newTestDB(t)
+	r := repo.NewUserRepo(db, opts...)
+	ctx := context.Background()
+
+	key, err := r.CreateAPIKey(ctx, model.APIKey{UserID: model.NewID(uuid.MustParse(user1ID)), Name: "batch", Prefix: "stl_abc", Hash: "h1", Scope: model.ReadScope})
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+	keyID := key.ID.String()
+
+	usedAt := time.Now().UTC().Truncate(time.Second)
+	for _, at := range []time.Time{usedAt, usedAt.Add(time.Second)} {
+		err = r.TouchAPIKey(ctx, keyID, at)
+		if err != nil {
+			t.Errorf("Touch: unexpected '%v'", err)
+		}
+	}
+
+	found, err := r.GetAPIKeyByHash(ctx, "h1")
+	if err != nil || found.ID.String() != keyID || !found.LastUsedAt.Equal(usedAt) {
+		t.Errorf("By hash: expected %s used at %s, got %+v (%v)", keyID, usedAt, found, err)
+	}
+
+	err = r.RevokeAPIKey(ctx, keyID, noneID)
+	if !errors.Is(err, port.APIKeyNotFoundErr) {
+		t.Errorf("Revoke not owned: expected '%v', got '%v'", port.APIKeyNotFoundErr, err)
+	}
+
+	err = r.RevokeAPIKey(ctx, keyID, user1ID)
+	if err != nil {
+		t.Errorf("Revoke: unexpected '%v'", err)
+	}
+
+	keys, err := r.GetAPIKeys(ctx, user1ID)
+	if err != nil || len(keys) != 1 || keys[0].Active() {
+		t.Errorf("Get: expected 1 revoked key, got %+v (%v)", keys, err)
+	}
+
+	_, err = r.GetAPIKeyByHash(ctx, "none")
+	if !errors.Is(err, port.APIKeyNotFoundErr) {
+		t.Errorf("Error: expected '%v', got '%v'", port.APIKeyNotFoundErr, err)
+	}
+}
+
+func TestIdempotencyRepo(t *testing.T) {
+	db, opts := newTestDB(t)
+	r := repo.NewIdempotencyRepo(db, opts...)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	record := model.IdempotencyRecord{UserID: user1ID, Method: "POST", Path: "/api/v1/lists", Key: "k1", Fingerprint: "f1", ExpiresAt: now.Add(time.Hour)}
+
+	err := r.CreateIdempotencyRecord(ctx, record)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	err = r.CreateIdempotencyRecord(ctx, record)
+	if !errors.Is(err, port.IdempotencyKeyUsedErr) || errors.KindOf(err) != errors.Conflict {
+		t.Errorf("Used key: expected '%v', got '%v'", port.IdempotencyKeyUsedErr, err)
+	}
+
+	// Keys are scoped to the user and endpoint
+	other := record
+	other.Path = "/api/v1/lists/" + list1ID + "/tasks"
+	err = r.CreateIdempotencyRecord(ctx, other)
+	if err != nil {
+		t.Errorf("Other endpoint: unexpected '%v'", err)
+	}
+
+	record.Status = 201
+	record.Header = map[string][]string{"Location": {"/api/v1/lists/1"}}
+	record.Body = []byte(`{"ID":"1"}`)
+	err = r.CompleteIdempotencyRecord(ctx, record)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	found, err := r.GetIdempotencyRecord(ctx, user1ID, "POST", "/api/v1/lists", "k1")
+	if err != nil || !found.Completed() || found.Fingerprint != "f1" || string(found.Body) != string(record.Body) || found.Header["Location"][0] != "/api/v1/lists/1" {
+		t.Errorf("Get: expected %+v, got %+v (%v)", record, found, err)
+	}
+
+	_, err = r.GetIdempotencyRecord(ctx, user2ID, "POST", "/api/v1/lists", "k1")
+	if !errors.Is(err, port.IdempotencyNotFoundErr) {
+		t.Errorf("Other user: expected '%v', got '%v'", port.IdempotencyNotFoundErr, err)
+	}
+
+	// An expired record not purged yet is replaced
+	expired := model.IdempotencyRecord{UserID: user1ID, Method: "POST", Path: "/api/v1/lists", Key: "k2", Fingerprint: "f1", ExpiresAt: now.Add(-time.Second)}
+	err = r.CreateIdempotencyRecord(ctx, expired)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	expired.Fingerprint = "f2"
+	expired.ExpiresAt = now.Add(time.Hour)
+	err = r.CreateIdempotencyRecord(ctx, expired)
+	if err != nil {
+		t.Errorf("Expired key: unexpected '%v'", err)
+	}
+
+	found, err = r.GetIdempotencyRecord(ctx, user1ID, "POST", "/api/v1/lists", "k2")
+	if err != nil || found.Fingerprint != "f2" || found.Completed() {
+		t.Errorf("Replaced: expected a new record in progress, got %+v (%v)", found, err)
+	}
+
+	n, err := r.DeleteExpiredIdempotencyRecords(ctx, now.Add(2*time.Hour))
+	if err != nil || n != 3 {
+		t.Errorf("Purge: expected 3 records deleted, got %d (%v)", n, err)
+	}
+
+	err = r.CreateIdempotencyRecord(ctx, record)
+	if err != nil {
+		t.Errorf("Purged key: unexpected '%v'", err)
+	}
+
+	err = r.DeleteIdempotencyRecord(ctx, record)
+	if err != nil {
+		t.Errorf("Delete: unexpected '%v'", err)
+	}
+
+	_, err = r.GetIdempotencyRecord(ctx, user1ID, "POST", "/api/v1/lists", "k1")
+	if !errors.Is(err, port.IdempotencyNotFoundErr) {
+		t.Errorf("Deleted: expected '%v', got '%v'", port.IdempotencyNotFoundErr, err)
+	}
+}
+
+func TestMembers(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+
+	_, err := r.GetList(ctx, user2ID, list1ID)
+	if !errors.Is(err, port.ListNotFoundErr) {
+		t.Fatalf("Non member: expected '%v', got '%v'", port.ListNotFoundErr, err)
+	}
+
+	invitation, err := r.CreateInvitation(ctx, model.Invitation{
+		ListID:    model.NewID(uuid.MustParse(list1ID)),
+		Email:     "USER2@example.com",
+		Role:      model.EditorRole,
+		InvitedBy: model.NewID(uuid.MustParse(user1ID)),
+		ExpiresAt: time.Now().UTC().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	invitations, err := r.GetInvitations(ctx, "user2@example.com")
+	if err != nil || len(invitations) != 1 || invitations[0].ListName != "List 1" {
+		t.Errorf("Invitations: expected 1 for List 1, got %+v (%v)", invitations, err)
+	}
+
+	member, err := r.AcceptInvitation(ctx, invitation, user2ID)
+	if err != nil || member.Role != model.EditorRole {
+		t.Fatalf("Accept: expected editor, got %+v (%v)", member, err)
+	}
+
+	// An invitation can only be accepted once.
+	_, err = r.AcceptInvitation(ctx, invitation, user2ID)
+	if !errors.Is(err, port.InvitationNotFoundErr) {
+		t.Errorf("Accept again: expected '%v', got '%v'", port.InvitationNotFoundErr, err)
+	}
+
+	list, err := r.GetList(ctx, user2ID, list1ID)
+	if err != nil || list.Role != model.EditorRole {
+		t.Errorf("Member list: expected editor role, got '%s' (%v)", list.Role, err)
+	}
+
+	_, err = r.AddTask(ctx, list1ID, model.Task{Name: "Shared task"}, user2ID)
+	if err != nil {
+		t.Errorf("Member add task: unexpected '%v'", err)
+	}
+
+	members, err := r.GetMembers(ctx, list1ID)
+	if err != nil || len(members) != 2 {
+		t.Errorf("Members: expected 2, got %+v (%v)", members, err)
+	}
+
+	// The last owner can neither be demoted nor removed
+	owner := model.Member{ListID: model.NewID(uuid.MustParse(list1ID)), User: model.User{ID: model.NewID(uuid.MustParse(user1ID))}}
+
+	owner.Role = model.EditorRole
+	_, err = r.UpdateMember(ctx, owner, user1ID)
+	if errors.KindOf(err) != errors.Forbidden {
+		t.Errorf("Demote last owner: expected forbidden, got '%v'", err)
+	}
+
+	err = r.DeleteMember(ctx, list1ID, user1ID, user1ID)
+	if errors.KindOf(err) != errors.Forbidden {
+		t.Errorf("Delete last owner: expected forbidden, got '%v'", err)
+	}
+
+	// Once there is another one it can
+	promoted := owner
+	promoted.User.ID = model.NewID(uuid.MustParse(user2ID))
+	promoted.Role = model.OwnerRole
+	_, err = r.UpdateMember(ctx, promoted, user1ID)
+	if err != nil {
+		t.Fatalf("Promote: unexpected '%v'", err)
+	}
+
+	member, err = r.UpdateMember(ctx, owner, user1ID)
+	if err != nil || member.Role != model.EditorRole {
+		t.Errorf("Demote owner: expected editor, got %+v (%v)", member, err)
+	}
+
+	err = r.DeleteMember(ctx, list1ID, user1ID, user1ID)
+	if err != nil {
+		t.Errorf("Delete owner: unexpected '%v'", err)
+	}
+
+	err = r.DeleteMember(ctx, list1ID, user2ID, user2ID)
+	if errors.KindOf(err) != errors.Forbidden {
+		t.Errorf("Delete new last owner: expected forbidden, got '%v'", err)
+	}
+
+	_, err = r.UpdateMember(ctx, model.Member{ListID: owner.ListID, User: model.User{ID: model.NewID(uuid.MustParse(user1ID))}, Role: model.ViewerRole}, user2ID)
+	if !errors.Is(err, port.MemberNotFoundErr) {
+		t.Errorf("Update removed member: expected '%v', got '%v'", port.MemberNotFoundErr, err)
+	}
+
+	_, err = r.GetMember(ctx, list1ID, user1ID)
+	if !errors.Is(err, port.ListNotFoundErr) {
+		t.Errorf("Removed member: expected '%v', got '%v'", port.ListNotFoundErr, err)
+	}
+}
+
+func TestChanges(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+
+	// A first sync has the current lists and tasks of the user
+	first, err := r.GetChanges(ctx, user1ID, 0, 100)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	if len(first.Changes) != 2 || first.More || first.Reset || changeAt(first, model.ListChange, list1ID, list1ID) < 0 || changeAt(first, model.TaskChange, task1ID, list1ID) < 0 {
+		t.Fatalf("First sync: expected list 1 and its task, got %+v", first)
+	}
+
+	other, err := r.CreateList(ctx, model.List{Name: "List 1b", Owner: model.User{ID: model.NewID(uuid.MustParse(user1ID))}})
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+	otherID := other.ID.String()
+
+	created, err := r.AddTask(ctx, list1ID, model.Task{Name: "Created"}, user1ID)
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+	createdID := created.ID.String()
+
+	_, err = r.BatchTasks(ctx, list1ID, []model.TaskOp{
+		{Kind: model.DeleteTaskOp, Task: created},
+		{Kind: model.MoveTaskOp, Task: model.Task{ID: model.NewID(uuid.MustParse(task1ID))}, ToListID: otherID},
+	}, user1ID, true)
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+
+	// Only the last change of an item is kept, moved tasks leave a tombstone before they show up in their new list
+	changes, err := r.GetChanges(ctx, user1ID, first.Seq, 100)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	left, moved := changeAt(changes, model.TaskChange, task1ID, list1ID), changeAt(changes, model.TaskChange, task1ID, otherID)
+	if left < 0 || moved < left || !changes.Changes[left].Deleted || changes.Changes[moved].Task == nil || changes.Changes[moved].Task.ListID.String() != otherID {
+		t.Errorf("Moved: expected a tombstone then the task in the other list, got %+v", changes.Changes)
+	}
+
+	deleted := changeAt(changes, model.TaskChange, createdID, list1ID)
+	if deleted < 0 || !changes.Changes[deleted].Deleted || changes.Changes[deleted].Task != nil {
+		t.Errorf("Deleted: expected the tombstone of the created task, got %+v", changes.Changes)
+	}
+
+	list := changeAt(changes, model.ListChange, otherID, otherID)
+	if list < 0 || changes.Changes[list].List == nil || changes.Changes[list].List.Role != model.OwnerRole {
+		t.Errorf("Created list: expected it with the user role, got %+v", changes.Changes)
+	}
+
+	// Changes of its tasks leave list 1 out
+	if changes.Seq <= first.Seq || len(changes.Changes) != 4 {
+		t.Errorf("Changes: expected 4 after the first sync, got %d up to %d", len(changes.Changes), changes.Seq)
+	}
+
+	// Pages
+	page, err := r.GetChanges(ctx, user1ID, first.Seq, 2)
+	if err != nil || !page.More || len(page.Changes) != 2 || page.Seq != page.Changes[1].Seq {
+		t.Errorf("Page: expected the first 2 changes and more, got %+v (%v)", page, err)
+	}
+
+	// A new member gets the whole list, a removed one its deletion
+	synced, err := r.GetChanges(ctx, user2ID, 0, 100)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	invitation, err := r.CreateInvitation(ctx, model.Invitation{
+		ListID:    model.NewID(uuid.MustParse(otherID)),
+		Email:     "user2@example.com",
+		Role:      model.ViewerRole,
+		InvitedBy: model.NewID(uuid.MustParse(user1ID)),
+		ExpiresAt: time.Now().UTC().Add(time.Hour),
+	})
+	if err == nil {
+		_, err = r.AcceptInvitation(ctx, invitation, user2ID)
+	}
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+
+	joined, err := r.GetChanges(ctx, user2ID, synced.Seq, 100)
+	if err != nil || len(joined.Changes) != 2 || changeAt(joined, model.ListChange, otherID, otherID) < 0 || changeAt(joined, model.TaskChange, task1ID, otherID) < 0 {
+		t.Errorf("Joined: expected the list and its task, got %+v (%v)", joined, err)
+	}
+
+	err = r.DeleteMember(ctx, otherID, user2ID, user1ID)
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+
+	removed, err := r.GetChanges(ctx, user2ID, joined.Seq, 100)
+	if err != nil || len(removed.Changes) != 1 || !removed.Changes[0].Deleted || removed.Changes[0].Kind != model.ListChange || removed.Changes[0].ItemID != otherID {
+		t.Errorf("Removed: expected the list deleted, got %+v (%v)", removed, err)
+	}
+
+	// Sequence numbers unknown to the database start over
+	reset, err := r.GetChanges(ctx, user1ID, changes.Seq+1000, 100)
+	if err != nil || !reset.Reset || len(reset.Changes) != 3 {
+		t.Errorf("Reset: expected the current lists and tasks, got %+v (%v)", reset, err)
+	}
+
+	// Clients creating items offline give them their IDs
+	_, err = r.AddTask(ctx, otherID, model.Task{ID: model.NewID(uuid.MustParse(task1ID)), Name: "Again"}, user1ID)
+	if !errors.Is(err, port.TaskExistsErr) || errors.KindOf(err) != errors.Conflict {
+		t.Errorf("Existing task: expected '%v', got '%v'", port.TaskExistsErr, err)
+	}
+
+	_, err = r.CreateList(ctx, model.List{ID: other.ID, Name: "Again", Owner: other.Owner})
+	if !errors.Is(err, port.ListExistsErr) {
+		t.Errorf("Existing list: expected '%v', got '%v'", port.ListExistsErr, err)
+	}
+}
+
+// changeAt returns the index of the change of the item in the list, -1 if there is none.
+func changeAt(changes model.ChangeSet, kind model.ChangeKind, itemID, listID string) int {
+	for i, c := range changes.Changes {
+		if c.Kind == kind && c.ItemID == itemID && c.ListID == listID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func TestWebhookRepo(t *testing.T) {
+	db, opts := newTestDB(t)
+	r := repo.NewWebhookRepo(db, opts...)
+	ctx := context.Background()
+
+	user1, user2 := model.NewID(uuid.MustParse(user1ID)), model.NewID(uuid.MustParse(user2ID))
+	webhooks := []model.Webhook{
+		{UserID: user1, URL: "https://example.com/all", Events: []model.EventType{model.TaskCreatedEvent}},
+		{UserID: user1, ListID: model.NewID(uuid.MustParse(list1ID)), URL: "https://example.com/list1", Events: []model.EventType{model.TaskCreatedEvent, model.TaskUpdatedEvent}},
+		{UserID: user2, URL: "https://example.com/user2", Events: []model.EventType{model.TaskCreatedEvent}},
+	}
+
+	for i, webhook := range webhooks {
+		created, err := r.CreateWebhook(ctx, webhook)
+		if err != nil {
+			t.Fatalf("Error: unexpected '%v'", err)
+		}
+		webhooks[i] = created
+	}
+	allID, list1HookID := webhooks[0].ID.String(), webhooks[1].ID.String()
+
+	found, err := r.GetWebhook(ctx, list1HookID, user1ID)
+	if err != nil || found.ListID.String() != list1ID || len(found.Events) != 2 || found.Events[1] != model.TaskUpdatedEvent {
+		t.Errorf("Get: expected %+v, got %+v (%v)", webhooks[1], found, err)
+	}
+
+	_, err = r.GetWebhook(ctx, list1HookID, user2ID)
+	if !errors.Is(err, port.WebhookNotFoundErr) {
+		t.Errorf("Not owned: expected '%v', got '%v'", port.WebhookNotFoundErr, err)
+	}
+
+	found2, err := r.GetWebhooks(ctx, user1ID)
+	if err != nil || len(found2) != 2 {
+		t.Errorf("Get all: expected 2 webhooks, got %+v (%v)", found2, err)
+	}
+
+	// Webhooks only get the events of the lists their user is a member of
+	tests := []struct {
+		listID string
+		typ    model.EventType
+		want   int
+	}{
+		{listID: list1ID, typ: model.TaskCreatedEvent, want: 2},
+		{listID: list1ID, typ: model.TaskUpdatedEvent, want: 1},
+		{listID: list1ID, typ: model.TaskDeletedEvent, want: 0},
+		{listID: list3ID, typ: model.TaskCreatedEvent, want: 1},
+	}
+
+	for _, tt := range tests {
+		n, err := r.EnqueueDeliveries(ctx, tt.listID, tt.typ, []byte(`{"Type":"`+string(tt.typ)+`"}`))
+		if err != nil || n != tt.want {
+			t.Errorf("Enqueue %s %s: expected %d deliveries, got %d (%v)", tt.listID, tt.typ, tt.want, n, err)
+		}
+	}
+
+	now := time.Now().UTC().Add(time.Second)
+	claimed, err := r.ClaimDeliveries(ctx, now, time.Minute, 10)
+	if err != nil || len(claimed) != 4 {
+		t.Fatalf("Claim: expected 4 deliveries, got %d (%v)", len(claimed), err)
+	}
+
+	for _, delivery := range claimed {
+		if delivery.Webhook.ID.String() != delivery.WebhookID.String() || delivery.Webhook.URL == "" {
+			t.Errorf("Claim: expected the webhook of %s, got %+v", delivery.WebhookID.String(), delivery.Webhook)
+		}
+	}
+
+	// Claimed deliveries are kept from other workers until the lease ends
+	again, err := r.ClaimDeliveries(ctx, now, time.Minute, 10)
+	if err != nil || len(again) != 0 {
+		t.Errorf("Leased: expected no deliveries, got %d (%v)", len(again), err)
+	}
+
+	var sent model.WebhookDelivery
+	for _, delivery := range claimed {
+		if delivery.WebhookID.String() == allID {
+			sent = delivery
+		}
+	}
+
+	sent.Status = model.SucceededDelivery
+	sent.Attempts = 1
+	sent.LastAttemptAt = now
+	sent.NextAttemptAt = time.Time{}
+	sent.ResponseStatus = 204
+	err = r.UpdateDelivery(ctx, sent)
+	if err != nil {
+		t.Fatalf("Update: unexpected '%v'", err)
+	}
+
+	again, err = r.ClaimDeliveries(ctx, now.Add(2*time.Minute), time.Minute, 10)
+	if err != nil || len(again) != 3 {
+		t.Errorf("Lease ended: expected 3 deliveries, got %d (%v)", len(again), err)
+	}
+
+	log, err := r.GetDeliveries(ctx, allID, user1ID, 10)
+	if err != nil || len(log) != 1 || !log[0].Done() || log[0].ResponseStatus != 204 || string(log[0].Payload) != string(sent.Payload) {
+		t.Errorf("Deliveries: expected %+v, got %+v (%v)", sent, log, err)
+	}
+
+	_, err = r.GetDeliveries(ctx, allID, user2ID, 10)
+	if !errors.Is(err, port.WebhookNotFoundErr) {
+		t.Errorf("Deliveries not owned: expected '%v', got '%v'", port.WebhookNotFoundErr, err)
+	}
+
+	_, err = r.Redeliver(ctx, sent.ID.String(), allID, user2ID)
+	if !errors.Is(err, port.DeliveryNotFoundErr) {
+		t.Errorf("Redeliver not owned: expected '%v', got '%v'", port.DeliveryNotFoundErr, err)
+	}
+
+	redelivery, err := r.Redeliver(ctx, sent.ID.String(), allID, user1ID)
+	if err != nil || redelivery.ID.String() == sent.ID.String() || redelivery.Done() || string(redelivery.Payload) != string(sent.Payload) {
+		t.Errorf("Redeliver: expected a new pending delivery of %+v, got %+v (%v)", sent, redelivery, err)
+	}
+
+	log, err = r.GetDeliveries(ctx, allID, user1ID, 10)
+	if err != nil || len(log) != 2 {
+		t.Errorf("Redelivered: expected 2 deliveries, got %d (%v)", len(log), err)
+	}
+
+	err = r.DeleteWebhook(ctx, allID, user2ID)
+	if !errors.Is(err, port.WebhookNotFoundErr) {
+		t.Errorf("Delete not owned: expected '%v', got '%v'", port.WebhookNotFoundErr, err)
+	}
+
+	err = r.DeleteWebhook(ctx, allID, user1ID)
+	if err != nil {
+		t.Errorf("Delete: unexpected '%v'", err)
+	}
+
+	_, err = r.GetWebhook(ctx, allID, user1ID)
+	if !errors.Is(err, port.WebhookNotFoundErr) {
+		t.Errorf("Deleted: expected '%v', got '%v'", port.WebhookNotFoundErr, err)
+	}
+}
+
+func TestOutboxRepo(t *testing.T) {
+	db, opts := newTestDB(t)
+	lists := repo.NewListRepo(db, opts...)
+	r := repo.NewOutboxRepo(db, opts...)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Add(time.Second)
+
+	// Seeding records events as well
+	seeded, err := r.GetPendingEvents(ctx, now, 1000)
+	if err != nil || len(seeded) == 0 || seeded[0].Type != model.ListCreated {
+		t.Fatalf("Seeded: expected list creations first, got %d events (%v)", len(seeded), err)
+	}
+
+	for _, e := range seeded {
+		e.Attempts = 1
+		e.DoneAt = now
+		err = r.UpdateEvent(ctx, e)
+		if err != nil {
+			t.Fatalf("Error: unexpected '%v'", err)
+		}
+	}
+
+	// A transaction rolled back leaves no events
+	_, err = lists.BatchTasks(ctx, list1ID, []model.TaskOp{
+		{Kind: model.CreateTaskOp, Task: model.Task{Name: "Undone"}},
+		{Kind: model.DeleteTaskOp, Task: model.Task{ID: model.NewID(uuid.MustParse(noneID))}},
+	}, user1ID, true)
+	if err == nil {
+		t.Fatalf("Atomic: expected an error")
+	}
+
+	list, err := lists.CreateList(ctx, model.List{Name: "Outbox", Owner: model.User{ID: model.NewID(uuid.MustParse(user1ID))}})
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+	listID := list.ID.String()
+
+	tasks, err := lists.AddTasks(ctx, listID, []model.Task{{Name: "Kept"}, {Name: "Deleted"}}, user1ID)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	_, err = lists.ToggleTask(ctx, listID, tasks[0].ID.String(), user1ID, 0)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	err = lists.DeleteTask(ctx, listID, tasks[1].ID.String(), user1ID, 0)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	err = lists.DeleteList(ctx, listID, user1ID, 0)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	events, err := r.GetPendingEvents(ctx, now, 100)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	want := []model.DomainEventType{
+		model.ListCreated,
+		model.TaskCreated,
+		model.TaskCreated,
+		model.TaskUpdated,
+		model.TaskCompleted,
+		model.TaskDeleted,
+		model.TaskDeleted,
+		model.ListDeleted,
+	}
+
+	if len(events) != len(want) {
+		t.Fatalf("Events: expected %d, got %+v", len(want), events)
+	}
+
+	for i, e := range events {
+		if e.Type != want[i] || e.ListID != listID || e.UserID != user1ID || e.Done() || (i > 0 && e.ID <= events[i-1].ID) {
+			t.Errorf("Event %d: expected %s of list %s by %s in order, got %+v", i, want[i], listID, user1ID, e)
+		}
+	}
+
+	if events[4].ItemID != tasks[0].ID.String() || !strings.Contains(string(events[4].Payload), `"name":"Kept"`) {
+		t.Errorf("Completed: expected task %s in the payload, got %+v", tasks[0].ID.String(), events[4])
+	}
+
+	other, err := lists.AddTask(ctx, list1ID, model.Task{Name: "Other list"}, user1ID)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	// Retried events are not due until their next attempt, the later ones of their list wait for them
+	retried := events[0]
+	retried.Attempts = 1
+	retried.Error = "failed"
+	retried.Handled = []string{"hub"}
+	retried.NextAttemptAt = now.Add(time.Minute)
+	err = r.UpdateEvent(ctx, retried)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	events, err = r.GetPendingEvents(ctx, now, 100)
+	if err != nil || len(events) != 1 || events[0].ItemID != other.ID.String() {
+		t.Errorf("Retried: expected only the event of the other list due, got %+v (%v)", events, err)
+	}
+
+	events, err = r.GetPendingEvents(ctx, now.Add(time.Hour), 1)
+	if err != nil || len(events) != 1 || events[0].ID != retried.ID || events[0].Attempts != 1 || events[0].Error != "failed" ||
+		len(events[0].Handled) != 1 || !events[0].HandledBy("hub") {
+		t.Errorf("Due: expected %+v, got %+v (%v)", retried, events, err)
+	}
+
+	// Failed events are no longer delivered nor hold back the others
+	retried.FailedAt = now
+	retried.NextAttemptAt = time.Time{}
+	err = r.UpdateEvent(ctx, retried)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	events, err = r.GetPendingEvents(ctx, now, 100)
+	if err != nil || len(events) != len(want) || events[0].ID == retried.ID {
+		t.Fatalf("Failed: expected %d events due, got %d (%v)", len(want), len(events), err)
+	}
+
+	// Done events are purged, failed ones are kept
+	for _, e := range events {
+		e.Attempts = 1
+		e.Handled = []string{"hub"}
+		e.DoneAt = now
+		err = r.UpdateEvent(ctx, e)
+		if err != nil {
+			t.Fatalf("Error: unexpected '%v'", err)
+		}
+	}
+
+	n, err := r.DeleteDoneEvents(ctx, now)
+	if err != nil || n != 0 {
+		t.Errorf("Purge: expected none done before %s, got %d (%v)", now, n, err)
+	}
+
+	n, err = r.DeleteDoneEvents(ctx, now.Add(time.Second))
+	if err != nil || n != int64(len(seeded)+len(want)) {
+		t.Errorf("Purge: expected %d events deleted, got %d (%v)", len(seeded)+len(want), n, err)
+	}
+
+	var kept, handled int
+	err = db.DB().QueryRow(`SELECT COUNT(*), (SELECT COUNT(*) FROM outbox_handled) FROM outbox`).Scan(&kept, &handled)
+	if err != nil || kept != 1 || handled != 1 {
+		t.Errorf("Purge: expected the failed event kept, got %d events, %d handled (%v)", kept, handled, err)
+	}
+
+	err = r.UpdateEvent(ctx, model.DomainEvent{ID: 1 << 40})
+	if !errors.Is(err, port.EventNotFoundErr) {
+		t.Errorf("Error: expected '%v', got '%v'", port.EventNotFoundErr, err)
+	}
+}
+
+// TestOutboxUsers checks that every event written by the list repo has the user that made the change.
+func TestOutboxUsers(t *testing.T) {
+	db, opts := newTestDB(t)
+	r := repo.NewListRepo(db, opts...)
+	ctx := context.Background()
+
+	list, err := r.CreateList(ctx, model.List{Name: "Other", Owner: model.User{ID: model.NewID(uuid.MustParse(user2ID))}})
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+	otherID := list.ID.String()
+
+	var last uint64
+	err = db.DB().QueryRow(`SELECT COALESCE(MAX(id), 0) FROM outbox`).Scan(&last)
+	if err != nil {
+		t.Fatalf("Test setup error: %s", err)
+	}
+
+	member := model.Member{ListID: model.NewID(uuid.MustParse(list1ID)), User: model.User{ID: model.NewID(uuid.MustParse(user2ID))}}
+
+	steps := []struct {
+		name   string
+		userID string
+		change func(userID string) error
+		want   []model.DomainEventType
+	}{
+		{
+			name:   "Join",
+			userID: user2ID,
+			change: func(userID string) error {
+				addMember(t, r, list1ID, "user2@example.com", user1ID, userID)
+				return nil
+			},
+			want: []model.DomainEventType{model.ListShared},
+		},
+		{
+			name:   "Update list",
+			userID: user2ID,
+			change: func(userID string) error {
+				_, err := r.UpdateList(ctx, model.List{ID: member.ListID, Name: "Renamed"}, userID)
+				return err
+			},
+			want: []model.DomainEventType{model.ListUpdated},
+		},
+		{
+			name:   "Toggle task",
+			userID: user2ID,
+			change: func(userID string) error {
+				_, err := r.ToggleTask(ctx, list1ID, task1ID, userID, 0)
+				return err
+			},
+			want: []model.DomainEventType{model.TaskUpdated, model.TaskCompleted},
+		},
+		{
+			name:   "Attach tag",
+			userID: user2ID,
+			change: func(userID string) error {
+				_, err := r.AttachTag(ctx, list1ID, task1ID, "Outbox", userID)
+				return err
+			},
+			want: []model.DomainEventType{model.TaskUpdated},
+		},
+		{
+			name:   "Move task",
+			userID: user2ID,
+			change: func(userID string) error {
+				_, err := r.BatchTasks(ctx, list1ID, []model.TaskOp{
+					{Kind: model.MoveTaskOp, Task: model.Task{ID: model.NewID(uuid.MustParse(task1ID))}, ToListID: otherID},
+				}, userID, true)
+				return err
+			},
+			want: []model.DomainEventType{model.TaskMoved},
+		},
+		{
+			name:   "Update member",
+			userID: user1ID,
+			change: func(userID string) error {
+				m := member
+				m.Role = model.ViewerRole
+				_, err := r.UpdateMember(ctx, m, userID)
+				return err
+			},
+		},
+		{
+			name:   "Delete member",
+			userID: user1ID,
+			change: func(userID string) error {
+				return r.DeleteMember(ctx, list1ID, user2ID, userID)
+			},
+			want: []model.DomainEventType{model.ListUnshared},
+		},
+		{
+			name:   "Delete list",
+			userID: user2ID,
+			change: func(userID string) error {
+				return r.DeleteList(ctx, otherID, userID, 0)
+			},
+			want: []model.DomainEventType{model.TaskDeleted, model.ListDeleted},
+		},
+	}
+
+	for _, step := range steps {
+		err := step.change(step.userID)
+		if err != nil {
+			t.Fatalf("%s: unexpected '%v'", step.name, err)
+		}
+
+		rows, err := db.DB().Query(`SELECT id, type, user_id FROM outbox WHERE id > $1 ORDER BY id`, last)
+		if err != nil {
+			t.Fatalf("%s: unexpected '%v'", step.name, err)
+		}
+
+		var got []model.DomainEventType
+		for rows.Next() {
+			var typ model.DomainEventType
+			var userID string
+			err = rows.Scan(&last, &typ, &userID)
+			if err != nil {
+				t.Fatalf("%s: unexpected '%v'", step.name, err)
+			}
+
+			if userID != step.userID {
+				t.Errorf("%s: expected %s event by %s, got '%s'", step.name, typ, step.userID, userID)
+			}
+
+			got = append(got, typ)
+		}
+
+		err = rows.Close()
+		if err != nil {
+			t.Fatalf("%s: unexpected '%v'", step.name, err)
+		}
+
+		if fmt.Sprint(got) != fmt.Sprint(step.want) {
+			t.Errorf("%s: expected events %v, got %v", step.name, step.want, got)
+		}
+	}
+}
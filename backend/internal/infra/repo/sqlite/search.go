@@ -0,0 +1,116 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+)
+
+const (
+	// HighlightStart and HighlightEnd enclose the matching terms of search results.
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+
+	snippetEllipsis = "…"
+	snippetTokens   = 16
+)
+
+// Search uses the full-text index kept in sync by the triggers of the search migration.
+// Only lists the user is a member of, and their tasks, are matched. Name matches rank above tags and descriptions.
+func (r *ListRepo) Search(ctx context.Context, userID string, q model.SearchQuery) (results []model.SearchResult, total int, err error) {
+	dbase := r.DB(ctx).DB()
+
+	match := matchExpr(q.Terms)
+	if match == "" {
+		return results, 0, nil
+	}
+
+	// Parameters are numbered in order of appearance, the highlight ones come first in the select.
+	count := newSQLQuery()
+	err = dbase.QueryRowContext(ctx, `SELECT COUNT(*)`+searchFrom(count, match, userID), count.args...).Scan(&total)
+	if err != nil {
+		return results, total, errors.Wrap(err, "search repo error")
+	}
+
+	sq := newSQLQuery()
+	start, end := sq.arg(HighlightStart), sq.arg(HighlightEnd)
+
+	query := fmt.Sprintf(`
+		SELECT d.kind, d.item_id, d.list_id, l.name,
+			highlight(search_fts, 0, %[1]s, %[2]s),
+			snippet(search_fts, 1, %[1]s, %[2]s, %[3]s, %[4]s),
+			highlight(search_fts, 2, %[1]s, %[2]s),
+			bm25(search_fts, 10.0, 1.0, 5.0) AS rank`,
+		start, end, sq.arg(snippetEllipsis), sq.arg(snippetTokens))
+
+	query += searchFrom(sq, match, userID) + `
+		ORDER BY rank, d.rowid` + sq.limit(q.Page)
+
+	rows, err := dbase.QueryContext(ctx, query, sq.args...)
+	if err != nil {
+		return results, total, errors.Wrap(err, "search repo error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var res model.SearchResult
+
+		err := rows.Scan(
+			&res.Kind,
+			&res.ItemID.UUID,
+			&res.ListID.UUID,
+			&res.ListName,
+			&res.Name,
+			&res.Snippet,
+			&res.Tags,
+			&res.Rank,
+		)
+		if err != nil {
+			return results, total, errors.Wrap(err, "search repo error")
+		}
+
+		results = append(results, res)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return results, total, errors.Wrap(err, "search repo error")
+	}
+
+	return results, total, nil
+}
+
+// searchFrom returns the FROM and WHERE clauses matching the documents of the lists the user is a member of.
+func searchFrom(sq *sqlQuery, match, userID string) string {
+	return `
+		FROM search_fts
+		INNER JOIN search_docs d ON d.rowid = search_fts.rowid
+		INNER JOIN lists l ON l.id = d.list_id
+		WHERE search_fts MATCH ` + sq.arg(match) + `
+		AND d.list_id IN (SELECT list_id FROM list_members WHERE user_id = ` + sq.arg(userID) + `)`
+}
+
+// matchExpr returns an FTS5 query matching all the terms, the last one as a prefix too.
+// Terms are quoted so that the query syntax can not be used, nor broken, by the user input.
+func matchExpr(terms []string) string {
+	var phrases []string
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		phrases = append(phrases, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+	}
+
+	if len(phrases) == 0 {
+		return ""
+	}
+
+	phrases[len(phrases)-1] += "*"
+
+	return strings.Join(phrases, " ")
+}
@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/infra/db"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+)
+
+func (r *UserRepo) CreateSession(ctx context.Context, m model.Session) (session model.Session, err error) {
+	err = m.GenID()
+	if err != nil {
+		return m, errors.Wrap(err, "create session repo error")
+	}
+
+	dbase := r.DB(ctx).DB()
+
+	now := time.Now().UTC()
+	m.Audit = model.NewAudit(now, now)
+
+	query := `
+		INSERT INTO sessions (id, user_id, refresh_id, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err = dbase.ExecContext(ctx, query,
+		m.ID.String(),
+		m.UserID.String(),
+		m.RefreshID,
+		m.ExpiresAt,
+		m.CreatedAt,
+		m.UpdatedAt,
+	)
+	if err != nil {
+		return m, errors.Wrap(err, "create session repo error")
+	}
+
+	return m, nil
+}
+
+func (r *UserRepo) GetSession(ctx context.Context, sessionID string) (session model.Session, err error) {
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		SELECT id, user_id, refresh_id, expires_at, revoked_at, created_at, updated_at
+		FROM sessions
+		WHERE id = $1
+	`
+
+	var revokedAt db.NullTime
+
+	err = dbase.QueryRowContext(ctx, query, sessionID).Scan(
+		&session.ID.UUID,
+		&session.UserID.UUID,
+		&session.RefreshID,
+		&session.ExpiresAt,
+		&revokedAt,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return session, errors.Wrap(port.NewSessionNotFoundErr(sessionID), "get session repo error")
+	}
+	if err != nil {
+		return session, errors.Wrap(err, "get session repo error")
+	}
+
+	session.RevokedAt = revokedAt.Time
+
+	return session, nil
+}
+
+func (r *UserRepo) RotateSession(ctx context.Context, sessionID, refreshID, newRefreshID string, expiresAt time.Time) error {
+	dbase := r.DB(ctx).DB()
+
+	now := time.Now().UTC()
+
+	query := `
+		UPDATE sessions
+		SET refresh_id = $1, expires_at = $2, updated_at = $3
+		WHERE id = $4 AND refresh_id = $5 AND revoked_at IS NULL AND expires_at > $3
+	`
+
+	res, err := dbase.ExecContext(ctx, query, newRefreshID, expiresAt, now, sessionID, refreshID)
+	if err != nil {
+		return errors.Wrap(err, "rotate session repo error")
+	}
+
+	err = checkAffected(res, port.NewSessionNotFoundErr(sessionID))
+	if err != nil {
+		return errors.Wrap(err, "rotate session repo error")
+	}
+
+	return nil
+}
+
+func (r *UserRepo) RevokeSession(ctx context.Context, sessionID, userID string) error {
+	dbase := r.DB(ctx).DB()
+
+	now := time.Now().UTC()
+
+	query := `
+		UPDATE sessions
+		SET revoked_at = COALESCE(revoked_at, $1), updated_at = $1
+		WHERE id = $2 AND user_id = $3
+	`
+
+	res, err := dbase.ExecContext(ctx, query, now, sessionID, userID)
+	if err != nil {
+		return errors.Wrap(err, "revoke session repo error")
+	}
+
+	err = checkAffected(res, port.NewSessionNotFoundErr(sessionID))
+	if err != nil {
+		return errors.Wrap(err, "revoke session repo error")
+	}
+
+	return nil
+}
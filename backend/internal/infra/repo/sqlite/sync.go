@@ -0,0 +1,229 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+)
+
+// changeRow is a row of the change log, member rows are the memberships of the user the changes are read for.
+type changeRow struct {
+	seq     uint64
+	kind    string
+	itemID  string
+	listID  string
+	deleted bool
+}
+
+const memberChange = "member"
+
+// GetChanges reads the change log kept by the triggers of the changes migration, in a single transaction so that
+// the items read match the log. Lists the user is no longer a member of are reported deleted, their items are not,
+// and a first sync, from zero, has no tombstones.
+func (r *ListRepo) GetChanges(ctx context.Context, userID string, since uint64, limit int) (changes model.ChangeSet, err error) {
+	err = r.inTx(ctx, func(tx *sql.Tx) error {
+		var last uint64
+		err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(seq), 0) FROM changes`).Scan(&last)
+		if err != nil {
+			return err
+		}
+
+		// Sequence numbers never go back, a later one is from another database
+		if since > last {
+			since = 0
+			changes.Reset = true
+		}
+
+		rows, err := r.changeRows(ctx, tx, userID, since, limit+1)
+		if err != nil {
+			return err
+		}
+
+		if len(rows) > limit {
+			rows = rows[:limit]
+			changes.More = true
+		}
+
+		changes.Seq = since
+		if len(rows) > 0 {
+			changes.Seq = rows[len(rows)-1].seq
+		}
+
+		changes.Changes, err = r.loadChanges(ctx, tx, userID, rows)
+		return err
+	})
+	if err != nil {
+		return changes, errors.Wrap(err, "get changes repo error")
+	}
+
+	return changes, nil
+}
+
+func (r *ListRepo) changeRows(ctx context.Context, q queryer, userID string, since uint64, limit int) (rows []changeRow, err error) {
+	query := `
+		SELECT c.seq, c.kind, c.item_id, c.list_id, c.deleted
+		FROM changes c
+		WHERE c.seq > $1
+		  AND ((c.kind IN ('list', 'task') AND c.list_id IN (SELECT list_id FROM list_members WHERE user_id = $2))
+		    OR (c.kind = 'member' AND c.item_id = $2 AND c.deleted = 1))
+		  AND ($1 > 0 OR c.deleted = 0)
+		ORDER BY c.seq
+		LIMIT $3
+	`
+
+	rs, err := q.QueryContext(ctx, query, since, userID, limit)
+	if err != nil {
+		return rows, err
+	}
+	defer rs.Close()
+
+	for rs.Next() {
+		var row changeRow
+
+		err := rs.Scan(&row.seq, &row.kind, &row.itemID, &row.listID, &row.deleted)
+		if err != nil {
+			return rows, err
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, rs.Err()
+}
+
+// loadChanges returns the changes of the rows with the current lists and tasks they are about.
+// A membership the user lost is the deletion of its list.
+func (r *ListRepo) loadChanges(ctx context.Context, q queryer, userID string, rows []changeRow) (changes []model.Change, err error) {
+	var listIDs, taskIDs []string
+	for _, row := range rows {
+		if row.deleted {
+			continue
+		}
+
+		switch model.ChangeKind(row.kind) {
+		case model.ListChange:
+			listIDs = append(listIDs, row.itemID)
+		case model.TaskChange:
+			taskIDs = append(taskIDs, row.itemID)
+		}
+	}
+
+	lists, err := r.listsByID(ctx, q, userID, listIDs)
+	if err != nil {
+		return changes, err
+	}
+
+	tasks, err := r.tasksByID(ctx, q, taskIDs)
+	if err != nil {
+		return changes, err
+	}
+
+	for _, row := range rows {
+		change := model.Change{
+			Seq:     row.seq,
+			Kind:    model.ChangeKind(row.kind),
+			ItemID:  row.itemID,
+			ListID:  row.listID,
+			Deleted: row.deleted,
+		}
+
+		if row.kind == memberChange {
+			change.Kind = model.ListChange
+			change.ItemID = row.listID
+		}
+
+		if !change.Deleted {
+			switch change.Kind {
+			case model.ListChange:
+				change.List = lists[row.itemID]
+			case model.TaskChange:
+				change.Task = tasks[row.itemID]
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// listsByID returns the lists with the IDs as read by the user, by ID.
+func (r *ListRepo) listsByID(ctx context.Context, q queryer, userID string, ids []string) (lists map[string]*model.List, err error) {
+	lists = map[string]*model.List{}
+	if len(ids) == 0 {
+		return lists, nil
+	}
+
+	listIDs, err := json.Marshal(ids)
+	if err != nil {
+		return lists, err
+	}
+
+	query := `
+		SELECT l.id, l.name, l.description, l.owner_id, m.role, l.created_at, l.updated_at, l.version
+		FROM lists l
+		INNER JOIN list_members m ON m.list_id = l.id
+		WHERE m.user_id = $1 AND l.id IN (SELECT value FROM json_each($2))
+	`
+
+	rows, err := q.QueryContext(ctx, query, userID, string(listIDs))
+	if err != nil {
+		return lists, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var list model.List
+
+		err := rows.Scan(
+			&list.ID.UUID,
+			&list.Name,
+			&list.Description,
+			&list.Owner.ID.UUID,
+			&list.Role,
+			&list.CreatedAt,
+			&list.UpdatedAt,
+			&list.Version,
+		)
+		if err != nil {
+			return lists, err
+		}
+
+		lists[list.ID.String()] = &list
+	}
+
+	return lists, rows.Err()
+}
+
+// tasksByID returns the tasks with the IDs, with their labels, by ID.
+func (r *ListRepo) tasksByID(ctx context.Context, q queryer, ids []string) (tasks map[string]*model.Task, err error) {
+	tasks = map[string]*model.Task{}
+	if len(ids) == 0 {
+		return tasks, nil
+	}
+
+	taskIDs, err := json.Marshal(ids)
+	if err != nil {
+		return tasks, err
+	}
+
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks t
+		WHERE t.id IN (SELECT value FROM json_each($1))
+	`
+
+	found, err := r.queryTasks(ctx, q, query, string(taskIDs))
+	if err != nil {
+		return tasks, err
+	}
+
+	for i := range found {
+		tasks[found[i].ID.String()] = &found[i]
+	}
+
+	return tasks, nil
+}
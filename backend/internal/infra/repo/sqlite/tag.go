@@ -0,0 +1,368 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+)
+
+const (
+	// tagColumns lists the columns read by scanTag, in order.
+	// Queries using it must group by g.id.
+	tagColumns = `g.id, g.owner_id, g.name, g.description, COUNT(tt.task_id), g.created_at, g.updated_at`
+)
+
+type (
+	// label describes how a kind of task label is stored: a table of named
+	// entities owned by a user and a join table that links them to tasks.
+	// Labels of a task belong to the owner of its list. Members of a shared list label its tasks with the
+	// labels of the owner, which only the owner gets as tags, and a task moved to a list of another owner
+	// is labeled with the ones of the new owner.
+	label struct {
+		table     string
+		joinTable string
+		column    string
+		field     func(task *model.Task) *model.StringSlice
+	}
+)
+
+var (
+	categoryLabel = label{
+		table:     "categories",
+		joinTable: "task_categories",
+		column:    "category_id",
+		field:     func(task *model.Task) *model.StringSlice { return &task.Category },
+	}
+
+	tagLabel = label{
+		table:     "tags",
+		joinTable: "task_tags",
+		column:    "tag_id",
+		field:     func(task *model.Task) *model.StringSlice { return &task.Tags },
+	}
+
+	locationLabel = label{
+		table:     "locations",
+		joinTable: "task_locations",
+		column:    "location_id",
+		field:     func(task *model.Task) *model.StringSlice { return &task.Location },
+	}
+
+	labels = []label{categoryLabel, tagLabel, locationLabel}
+)
+
+func (r *ListRepo) GetTags(ctx context.Context, userID string) (tags []model.Tag, err error) {
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		SELECT ` + tagColumns + `
+		FROM tags g
+		LEFT JOIN task_tags tt ON tt.tag_id = g.id
+		WHERE g.owner_id = $1
+		GROUP BY g.id
+		ORDER BY g.name
+	`
+
+	rows, err := dbase.QueryContext(ctx, query, userID)
+	if err != nil {
+		return tags, errors.Wrap(err, "get tags repo error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return tags, errors.Wrap(err, "get tags repo error")
+		}
+
+		tags = append(tags, tag)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return tags, errors.Wrap(err, "get tags repo error")
+	}
+
+	return tags, nil
+}
+
+func (r *ListRepo) GetTag(ctx context.Context, tagID, userID string) (tag model.Tag, err error) {
+	tag, err = r.getTag(ctx, r.DB(ctx).DB(), tagID, userID)
+	if err != nil {
+		return tag, errors.Wrap(err, "get tag repo error")
+	}
+
+	return tag, nil
+}
+
+func (r *ListRepo) getTag(ctx context.Context, q queryer, tagID, userID string) (tag model.Tag, err error) {
+	query := `
+		SELECT ` + tagColumns + `
+		FROM tags g
+		LEFT JOIN task_tags tt ON tt.tag_id = g.id
+		WHERE g.id = $1 AND g.owner_id = $2
+		GROUP BY g.id
+	`
+
+	tag, err = scanTag(q.QueryRowContext(ctx, query, tagID, userID))
+	if err == sql.ErrNoRows {
+		return tag, port.NewTagNotFoundErr(tagID)
+	}
+
+	return tag, err
+}
+
+func (r *ListRepo) AttachTag(ctx context.Context, listID, taskID, name, userID string) (tag model.Tag, err error) {
+	err = r.inUserTx(ctx, userID, func(tx *sql.Tx) error {
+		ownerID, err := r.checkTaskMember(ctx, tx, listID, taskID, userID)
+		if err != nil {
+			return err
+		}
+
+		tagID, err := r.attachLabel(ctx, tx, tagLabel, taskID, name, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+
+		err = r.touchTask(ctx, tx, taskID)
+		if err != nil {
+			return err
+		}
+
+		// Labels belong to the list owner, who may not be the user on shared lists
+		tag, err = r.getTag(ctx, tx, tagID, ownerID)
+		return err
+	})
+	if err != nil {
+		return tag, errors.Wrap(err, "attach tag repo error")
+	}
+
+	return tag, nil
+}
+
+func (r *ListRepo) DetachTag(ctx context.Context, listID, taskID, tagID, userID string) error {
+	query := `
+		DELETE FROM task_tags
+		WHERE task_id = $1 AND tag_id = $2
+		  AND task_id IN (SELECT t.id
+		                  FROM tasks t
+		                  INNER JOIN list_members m ON m.list_id = t.list_id
+		                  WHERE t.list_id = $3 AND m.user_id = $4)
+	`
+
+	err := r.inUserTx(ctx, userID, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, query, taskID, tagID, listID, userID)
+		if err != nil {
+			return err
+		}
+
+		err = checkAffected(res, port.NewTagNotFoundErr(tagID))
+		if err != nil {
+			return err
+		}
+
+		return r.touchTask(ctx, tx, taskID)
+	})
+	if err != nil {
+		return errors.Wrap(err, "detach tag repo error")
+	}
+
+	return nil
+}
+
+// touchTask records a change of the labels of a task as a new version of it.
+func (r *ListRepo) touchTask(ctx context.Context, q queryer, taskID string) error {
+	query := `UPDATE tasks SET version = version + 1 WHERE id = $1`
+
+	_, err := q.ExecContext(ctx, query, taskID)
+	return err
+}
+
+// checkTaskMember returns TaskNotFoundErr unless the task exists in the list and the user is one of its members.
+// It returns the ID of the list owner.
+func (r *ListRepo) checkTaskMember(ctx context.Context, q queryer, listID, taskID, userID string) (ownerID string, err error) {
+	query := `
+		SELECT l.owner_id
+		FROM tasks t
+		INNER JOIN lists l ON t.list_id = l.id
+		INNER JOIN list_members m ON m.list_id = l.id
+		WHERE t.id = $1 AND t.list_id = $2 AND m.user_id = $3
+	`
+
+	err = q.QueryRowContext(ctx, query, taskID, listID, userID).Scan(&ownerID)
+	if err == sql.ErrNoRows {
+		return ownerID, port.NewTaskNotFoundErr(taskID)
+	}
+
+	return ownerID, err
+}
+
+// saveLabels replaces the categories, tags and locations of a task with the ones it holds.
+func (r *ListRepo) saveLabels(ctx context.Context, q queryer, task model.Task, now time.Time) error {
+	taskID := task.ID.String()
+
+	for _, lb := range labels {
+		query := `DELETE FROM ` + lb.joinTable + ` WHERE task_id = $1`
+
+		_, err := q.ExecContext(ctx, query, taskID)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range *lb.field(&task) {
+			if strings.TrimSpace(name) == "" {
+				continue
+			}
+
+			_, err = r.attachLabel(ctx, q, lb, taskID, name, now)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// attachLabel links a label to a task, creating it for the list owner if it does not exist yet.
+// It returns the ID of the label.
+func (r *ListRepo) attachLabel(ctx context.Context, q queryer, lb label, taskID, name string, now time.Time) (labelID string, err error) {
+	name = strings.TrimSpace(name)
+
+	query := `
+		INSERT INTO ` + lb.table + ` (id, owner_id, name, created_at, updated_at)
+		SELECT $1, l.owner_id, $2, $3, $3
+		FROM tasks t
+		INNER JOIN lists l ON t.list_id = l.id
+		WHERE t.id = $4
+		ON CONFLICT (owner_id, name) DO NOTHING
+	`
+
+	_, err = q.ExecContext(ctx, query, uuid.NewUUID().Val, name, now, taskID)
+	if err != nil {
+		return labelID, err
+	}
+
+	query = `
+		SELECT e.id
+		FROM ` + lb.table + ` e
+		INNER JOIN lists l ON e.owner_id = l.owner_id
+		INNER JOIN tasks t ON t.list_id = l.id
+		WHERE t.id = $1 AND e.name = $2
+	`
+
+	err = q.QueryRowContext(ctx, query, taskID, name).Scan(&labelID)
+	if err != nil {
+		return labelID, err
+	}
+
+	query = `
+		INSERT INTO ` + lb.joinTable + ` (task_id, ` + lb.column + `)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+
+	_, err = q.ExecContext(ctx, query, taskID, labelID)
+	if err != nil {
+		return labelID, err
+	}
+
+	return labelID, nil
+}
+
+// relabelTask links the task to the labels of the owner of its list with the names of the ones it has,
+// so that a task moved to a list of another owner does not keep labels of the previous one.
+func (r *ListRepo) relabelTask(ctx context.Context, q queryer, task model.Task, now time.Time) error {
+	tasks := []model.Task{task}
+
+	err := r.loadLabels(ctx, q, tasks)
+	if err != nil {
+		return err
+	}
+
+	return r.saveLabels(ctx, q, tasks[0], now)
+}
+
+// loadLabels sets the categories, tags and locations of the tasks.
+func (r *ListRepo) loadLabels(ctx context.Context, q queryer, tasks []model.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	index := make(map[string]int, len(tasks))
+	ids := make([]string, 0, len(tasks))
+	for i := range tasks {
+		id := tasks[i].ID.String()
+		index[id] = i
+		ids = append(ids, id)
+
+		for _, lb := range labels {
+			*lb.field(&tasks[i]) = model.StringSlice{}
+		}
+	}
+
+	taskIDs, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+
+	for _, lb := range labels {
+		err = r.loadLabel(ctx, q, lb, string(taskIDs), tasks, index)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ListRepo) loadLabel(ctx context.Context, q queryer, lb label, taskIDs string, tasks []model.Task, index map[string]int) error {
+	query := `
+		SELECT j.task_id, e.name
+		FROM ` + lb.joinTable + ` j
+		INNER JOIN ` + lb.table + ` e ON j.` + lb.column + ` = e.id
+		WHERE j.task_id IN (SELECT value FROM json_each($1))
+		ORDER BY e.name
+	`
+
+	rows, err := q.QueryContext(ctx, query, taskIDs)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID, name string
+		err = rows.Scan(&taskID, &name)
+		if err != nil {
+			return err
+		}
+
+		field := lb.field(&tasks[index[taskID]])
+		*field = append(*field, name)
+	}
+
+	return rows.Err()
+}
+
+// scanTag reads a tag from a row selected using tagColumns.
+func scanTag(s scanner) (tag model.Tag, err error) {
+	err = s.Scan(
+		&tag.ID.UUID,
+		&tag.OwnerID.UUID,
+		&tag.Name,
+		&tag.Description,
+		&tag.Usage,
+		&tag.CreatedAt,
+		&tag.UpdatedAt,
+	)
+
+	return tag, err
+}
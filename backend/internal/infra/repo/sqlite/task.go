@@ -0,0 +1,634 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/infra/db"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+)
+
+const (
+	// taskColumns lists the columns read by scanTask, in order.
+	taskColumns = `t.id, t.list_id, t.name, t.description,
+		t.done, t.completed_at, t.due_at, t.priority, t.position, t.created_at, t.updated_at, t.version`
+
+	// taskReturning lists the same columns as taskColumns for RETURNING clauses,
+	// which do not accept table aliases.
+	taskReturning = `id, list_id, name, description,
+		done, completed_at, due_at, priority, position, created_at, updated_at, version`
+)
+
+type (
+	// queryer is satisfied by both *sql.DB and *sql.Tx.
+	queryer interface {
+		ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+		QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+		QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	}
+
+	// scanner is satisfied by both *sql.Row and *sql.Rows.
+	scanner interface {
+		Scan(dest ...any) error
+	}
+)
+
+func (r *ListRepo) AddTask(ctx context.Context, listID string, m model.Task, userID string) (task model.Task, err error) {
+	tasks, err := r.addTasks(ctx, listID, []model.Task{m}, userID)
+	if err != nil {
+		return task, errors.Wrap(err, "add task repo error")
+	}
+
+	return tasks[0], nil
+}
+
+func (r *ListRepo) AddTasks(ctx context.Context, listID string, mm []model.Task, userID string) (tasks []model.Task, err error) {
+	tasks, err = r.addTasks(ctx, listID, mm, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "add tasks repo error")
+	}
+
+	return tasks, nil
+}
+
+// addTasks adds all tasks in a single transaction along with their labels.
+func (r *ListRepo) addTasks(ctx context.Context, listID string, mm []model.Task, userID string) (tasks []model.Task, err error) {
+	err = r.inUserTx(ctx, userID, func(tx *sql.Tx) error {
+		for _, m := range mm {
+			task, err := r.addTask(ctx, tx, listID, m, userID)
+			if err != nil {
+				return err
+			}
+
+			tasks = append(tasks, task)
+		}
+
+		return r.loadLabels(ctx, tx, tasks)
+	})
+
+	return tasks, err
+}
+
+func (r *ListRepo) addTask(ctx context.Context, q queryer, listID string, m model.Task, userID string) (model.Task, error) {
+	err := m.GenID()
+	if err != nil {
+		return m, err
+	}
+
+	now := time.Now().UTC()
+	m.ListID.UUID.Val = listID
+	m.Audit = model.NewAudit(now, now)
+	m.Version = 1
+
+	if m.Done {
+		m.CompletedAt = now
+	}
+
+	// Insert only succeeds if the list exists and the user is one of its members.
+	// Tasks without an explicit position are appended at the end of the list.
+	query := `
+		INSERT INTO tasks (id, list_id, name, description,
+		                   done, completed_at, due_at, priority, position, created_at, updated_at)
+		SELECT $1, l.id, $2, $3, $4, $5, $6, $7,
+		       CASE WHEN $8 > 0 THEN $8
+		            ELSE (SELECT COALESCE(MAX(position), 0) + 1 FROM tasks WHERE list_id = l.id) END,
+		       $9, $10
+		FROM lists l
+		WHERE l.id = $11 AND l.id IN (SELECT list_id FROM list_members WHERE user_id = $12)
+		RETURNING position
+	`
+
+	row := q.QueryRowContext(ctx, query,
+		m.ID.String(),
+		m.Name,
+		m.Description,
+		m.Done,
+		toNullTime(m.CompletedAt),
+		toNullTime(m.DueAt),
+		m.Priority,
+		m.Position,
+		m.CreatedAt,
+		m.UpdatedAt,
+		listID,
+		userID,
+	)
+
+	err = row.Scan(&m.Position)
+	if err == sql.ErrNoRows {
+		return m, port.NewListNotFoundErr(listID)
+	}
+	if isPrimaryKeyErr(err) {
+		return m, port.NewTaskExistsErr(m.ID.String())
+	}
+	if err != nil {
+		return m, err
+	}
+
+	err = r.saveLabels(ctx, q, m, now)
+	if err != nil {
+		return m, err
+	}
+
+	return m, nil
+}
+
+func (r *ListRepo) GetTasks(ctx context.Context, listID, userID string, q model.TaskQuery) (tasks []model.Task, total int, err error) {
+	dbase := r.DB(ctx).DB()
+
+	err = r.checkListMember(ctx, dbase, listID, userID)
+	if err != nil {
+		return tasks, total, errors.Wrap(err, "get tasks repo error")
+	}
+
+	sq := newSQLQuery(listID)
+	if q.Tag != "" {
+		sq.where(`EXISTS (
+			SELECT 1 FROM task_tags tt
+			INNER JOIN tags g ON g.id = tt.tag_id
+			WHERE tt.task_id = t.id AND g.name = ` + sq.arg(q.Tag) + `)`)
+	}
+	if q.Done != nil {
+		sq.where("t.done = " + sq.arg(*q.Done))
+	}
+	if q.Priority != nil {
+		sq.where("t.priority = " + sq.arg(*q.Priority))
+	}
+	if !q.DueBefore.IsZero() {
+		sq.where("julianday(t.due_at) < julianday(" + sq.arg(q.DueBefore.UTC().Format(time.RFC3339)) + ")")
+	}
+	if !q.DueAfter.IsZero() {
+		sq.where("julianday(t.due_at) >= julianday(" + sq.arg(q.DueAfter.UTC().Format(time.RFC3339)) + ")")
+	}
+
+	from := `
+		FROM tasks t
+		WHERE t.list_id = $1` + sq.and()
+
+	err = dbase.QueryRowContext(ctx, `SELECT COUNT(*)`+from, sq.args...).Scan(&total)
+	if err != nil {
+		return tasks, total, errors.Wrap(err, "get tasks repo error")
+	}
+
+	order, err := orderBy(q.Sort, taskSortColumns, []model.Sort{{Field: "position"}, {Field: "created_at"}}, "t.id")
+	if err != nil {
+		return tasks, total, errors.Wrap(err, "get tasks repo error")
+	}
+
+	query := `
+		SELECT ` + taskColumns + from + order + sq.limit(q.Page)
+
+	tasks, err = r.queryTasks(ctx, dbase, query, sq.args...)
+	if err != nil {
+		return tasks, total, errors.Wrap(err, "get tasks repo error")
+	}
+
+	return tasks, total, nil
+}
+
+// listTasks returns all tasks of a list without checking its membership.
+func (r *ListRepo) listTasks(ctx context.Context, q queryer, listID string) (tasks []model.Task, err error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks t
+		WHERE t.list_id = $1
+		ORDER BY t.position, t.created_at, t.id
+	`
+
+	return r.queryTasks(ctx, q, query, listID)
+}
+
+// queryTasks returns the tasks selected by a query using taskColumns, with their labels.
+func (r *ListRepo) queryTasks(ctx context.Context, q queryer, query string, args ...any) (tasks []model.Task, err error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return tasks, err
+	}
+
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			_ = rows.Close()
+			return tasks, err
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	// Rows are released before labels are queried.
+	err = rows.Close()
+	if err == nil {
+		err = rows.Err()
+	}
+	if err != nil {
+		return tasks, err
+	}
+
+	return tasks, r.loadLabels(ctx, q, tasks)
+}
+
+func (r *ListRepo) GetTask(ctx context.Context, listID, taskID, userID string) (task model.Task, err error) {
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks t
+		INNER JOIN list_members m ON m.list_id = t.list_id
+		WHERE t.id = $1 AND t.list_id = $2 AND m.user_id = $3
+	`
+
+	row := dbase.QueryRowContext(ctx, query, taskID, listID, userID)
+
+	task, err = scanTask(row)
+	if err == sql.ErrNoRows {
+		return task, errors.Wrap(port.NewTaskNotFoundErr(taskID), "get task repo error")
+	}
+	if err != nil {
+		return task, errors.Wrap(err, "get task repo error")
+	}
+
+	task, err = r.withLabels(ctx, dbase, task)
+	if err != nil {
+		return task, errors.Wrap(err, "get task repo error")
+	}
+
+	return task, nil
+}
+
+func (r *ListRepo) UpdateTask(ctx context.Context, m model.Task, userID string) (updated model.Task, err error) {
+	err = r.inUserTx(ctx, userID, func(tx *sql.Tx) error {
+		updated, err = r.updateTask(ctx, tx, m, userID, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+
+		updated, err = r.withLabels(ctx, tx, updated)
+		return err
+	})
+	if err != nil {
+		return m, errors.Wrap(err, "update task repo error")
+	}
+
+	return updated, nil
+}
+
+// updateTask updates the task and saves its labels, which are not loaded back.
+func (r *ListRepo) updateTask(ctx context.Context, q queryer, m model.Task, userID string, now time.Time) (updated model.Task, err error) {
+	// Completion time is kept when an already done task is updated again.
+	// Position is only changed when a new one is provided.
+	query := `
+		UPDATE tasks
+		SET name = $1, description = $2,
+		    done = $3,
+		    completed_at = CASE WHEN $3 THEN COALESCE(completed_at, $4) END,
+		    due_at = $5, priority = $6,
+		    position = CASE WHEN $7 > 0 THEN $7 ELSE position END,
+		    updated_at = $4, version = version + 1
+		WHERE id = $8 AND list_id = $9
+		  AND list_id IN (SELECT list_id FROM list_members WHERE user_id = $10)
+		  AND ($11 = 0 OR version = $11)
+		RETURNING ` + taskReturning + `
+	`
+
+	row := q.QueryRowContext(ctx, query,
+		m.Name,
+		m.Description,
+		m.Done,
+		now,
+		toNullTime(m.DueAt),
+		m.Priority,
+		m.Position,
+		m.ID.String(),
+		m.ListID.String(),
+		userID,
+		m.Version,
+	)
+
+	updated, err = scanTask(row)
+	if err == sql.ErrNoRows {
+		return updated, taskVersionErr(ctx, q, m.ListID.String(), m.ID.String(), userID)
+	}
+	if err != nil {
+		return updated, err
+	}
+
+	updated.Category, updated.Tags, updated.Location = m.Category, m.Tags, m.Location
+
+	err = r.saveLabels(ctx, q, updated, now)
+	if err != nil {
+		return updated, err
+	}
+
+	return updated, nil
+}
+
+func (r *ListRepo) ToggleTask(ctx context.Context, listID, taskID, userID string, version int) (task model.Task, err error) {
+	now := time.Now().UTC()
+
+	query := `
+		UPDATE tasks
+		SET done = NOT done,
+		    completed_at = CASE WHEN done THEN NULL ELSE $1 END,
+		    updated_at = $1, version = version + 1
+		WHERE id = $2 AND list_id = $3
+		  AND list_id IN (SELECT list_id FROM list_members WHERE user_id = $4)
+		  AND ($5 = 0 OR version = $5)
+		RETURNING ` + taskReturning + `
+	`
+
+	err = r.inUserTx(ctx, userID, func(tx *sql.Tx) error {
+		row := tx.QueryRowContext(ctx, query, now, taskID, listID, userID, version)
+
+		task, err = scanTask(row)
+		if err == sql.ErrNoRows {
+			return taskVersionErr(ctx, tx, listID, taskID, userID)
+		}
+		if err != nil {
+			return err
+		}
+
+		task, err = r.withLabels(ctx, tx, task)
+		return err
+	})
+	if err != nil {
+		return task, errors.Wrap(err, "toggle task repo error")
+	}
+
+	return task, nil
+}
+
+func (r *ListRepo) DeleteTask(ctx context.Context, listID, taskID, userID string, version int) error {
+	err := r.inUserTx(ctx, userID, func(tx *sql.Tx) error {
+		return r.deleteTask(ctx, tx, listID, taskID, userID, version)
+	})
+	if err != nil {
+		return errors.Wrap(err, "delete task repo error")
+	}
+
+	return nil
+}
+
+func (r *ListRepo) deleteTask(ctx context.Context, q queryer, listID, taskID, userID string, version int) error {
+	query := `
+		DELETE FROM tasks
+		WHERE id = $1 AND list_id = $2
+		  AND list_id IN (SELECT list_id FROM list_members WHERE user_id = $3)
+		  AND ($4 = 0 OR version = $4)
+	`
+
+	res, err := q.ExecContext(ctx, query, taskID, listID, userID, version)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return taskVersionErr(ctx, q, listID, taskID, userID)
+	}
+
+	return nil
+}
+
+// moveTask moves the task to another list the user is a member of, at the task position or else at its end.
+func (r *ListRepo) moveTask(ctx context.Context, q queryer, m model.Task, toListID, userID string, now time.Time) (moved model.Task, err error) {
+	err = r.checkListMember(ctx, q, toListID, userID)
+	if err != nil {
+		return moved, err
+	}
+
+	query := `
+		UPDATE tasks
+		SET list_id = $1,
+		    position = CASE WHEN $2 > 0 THEN $2
+		                    ELSE (SELECT COALESCE(MAX(position), 0) + 1 FROM tasks WHERE list_id = $1) END,
+		    updated_at = $3, version = version + 1
+		WHERE id = $4 AND list_id = $5
+		  AND list_id IN (SELECT list_id FROM list_members WHERE user_id = $6)
+		  AND ($7 = 0 OR version = $7)
+		RETURNING ` + taskReturning + `
+	`
+
+	row := q.QueryRowContext(ctx, query,
+		toListID,
+		m.Position,
+		now,
+		m.ID.String(),
+		m.ListID.String(),
+		userID,
+		m.Version,
+	)
+
+	moved, err = scanTask(row)
+	if err == sql.ErrNoRows {
+		return moved, taskVersionErr(ctx, q, m.ListID.String(), m.ID.String(), userID)
+	}
+	if err != nil {
+		return moved, err
+	}
+
+	err = r.relabelTask(ctx, q, moved, now)
+	return moved, err
+}
+
+// BatchTasks runs every operation in a savepoint of the batch transaction, so that a failed one
+// can be undone on its own when the batch is not atomic.
+func (r *ListRepo) BatchTasks(ctx context.Context, listID string, ops []model.TaskOp, userID string, atomic bool) (results []model.TaskOpResult, err error) {
+	results = make([]model.TaskOpResult, len(ops))
+	now := time.Now().UTC()
+
+	err = r.inUserTx(ctx, userID, func(tx *sql.Tx) error {
+		var tasks []model.Task
+		var indexes []int
+
+		for i, op := range ops {
+			_, err := tx.ExecContext(ctx, `SAVEPOINT task_op`)
+			if err != nil {
+				return err
+			}
+
+			task, err := r.taskOp(ctx, tx, listID, op, userID, now)
+			if err != nil && atomic {
+				return port.NewTaskOpErr(i, string(op.Kind), err)
+			}
+
+			if err != nil {
+				results[i].Err = err
+				_, err = tx.ExecContext(ctx, `ROLLBACK TO task_op`)
+				if err != nil {
+					return err
+				}
+			}
+
+			_, err = tx.ExecContext(ctx, `RELEASE task_op`)
+			if err != nil {
+				return err
+			}
+
+			if results[i].Err == nil && op.Kind != model.DeleteTaskOp {
+				tasks = append(tasks, task)
+				indexes = append(indexes, i)
+			}
+		}
+
+		err := r.loadLabels(ctx, tx, tasks)
+		if err != nil {
+			return err
+		}
+
+		for j, i := range indexes {
+			results[i].Task = tasks[j]
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "batch tasks repo error")
+	}
+
+	return results, nil
+}
+
+// taskOp applies an operation of a batch to a task of the list.
+func (r *ListRepo) taskOp(ctx context.Context, q queryer, listID string, op model.TaskOp, userID string, now time.Time) (model.Task, error) {
+	task := op.Task
+	task.ListID.UUID.Val = listID
+
+	switch op.Kind {
+	case model.CreateTaskOp:
+		return r.addTask(ctx, q, listID, task, userID)
+	case model.UpdateTaskOp:
+		return r.updateTask(ctx, q, task, userID, now)
+	case model.DeleteTaskOp:
+		return task, r.deleteTask(ctx, q, listID, task.ID.String(), userID, task.Version)
+	case model.MoveTaskOp:
+		return r.moveTask(ctx, q, task, op.ToListID, userID, now)
+	default:
+		return task, errors.NewKind(errors.Invalid, fmt.Sprintf("unknown task operation '%s'", op.Kind))
+	}
+}
+
+// taskVersionErr tells apart why a task change conditioned to its version affected no rows.
+// It returns TaskNotFoundErr if the task is not visible to the user, a VersionErr otherwise.
+func taskVersionErr(ctx context.Context, q queryer, listID, taskID, userID string) error {
+	query := `
+		SELECT version
+		FROM tasks
+		WHERE id = $1 AND list_id = $2
+		  AND list_id IN (SELECT list_id FROM list_members WHERE user_id = $3)
+	`
+
+	return versionErr(q.QueryRowContext(ctx, query, taskID, listID, userID), port.NewTaskNotFoundErr(taskID))
+}
+
+// checkListMember returns ListNotFoundErr unless the list exists and the user is one of its members.
+func (r *ListRepo) checkListMember(ctx context.Context, q queryer, listID, userID string) error {
+	query := `
+		SELECT 1
+		FROM list_members
+		WHERE list_id = $1 AND user_id = $2
+	`
+
+	var found int
+	err := q.QueryRowContext(ctx, query, listID, userID).Scan(&found)
+	if err == sql.ErrNoRows {
+		return port.NewListNotFoundErr(listID)
+	}
+
+	return err
+}
+
+// withLabels returns the task with its categories, tags and locations loaded.
+func (r *ListRepo) withLabels(ctx context.Context, q queryer, task model.Task) (model.Task, error) {
+	tasks := []model.Task{task}
+
+	err := r.loadLabels(ctx, q, tasks)
+	if err != nil {
+		return task, err
+	}
+
+	return tasks[0], nil
+}
+
+// inTx runs fn in a transaction that is rolled back if fn fails.
+func (r *ListRepo) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return inTx(ctx, r.DB(ctx).DB(), fn)
+}
+
+// inUserTx runs fn in a transaction like inTx, the events its changes record in the outbox are set the user that made
+// them. Writers are serialized, so the events recorded after the last one seen at the start are the ones of fn.
+func (r *ListRepo) inUserTx(ctx context.Context, userID string, fn func(tx *sql.Tx) error) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		var last uint64
+		err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM outbox`).Scan(&last)
+		if err != nil {
+			return err
+		}
+
+		err = fn(tx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE outbox SET user_id = $1 WHERE id > $2`, userID, last)
+		return err
+	})
+}
+
+// inTx runs fn in a transaction of the database that is rolled back if fn fails.
+func inTx(ctx context.Context, dbase *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := dbase.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = fn(tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// scanTask reads a task from a row selected using taskColumns.
+func scanTask(s scanner) (task model.Task, err error) {
+	var completedAt, dueAt db.NullTime
+
+	err = s.Scan(
+		&task.ID.UUID,
+		&task.ListID.UUID,
+		&task.Name,
+		&task.Description,
+		&task.Done,
+		&completedAt,
+		&dueAt,
+		&task.Priority,
+		&task.Position,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+		&task.Version,
+	)
+	if err != nil {
+		return task, err
+	}
+
+	task.CompletedAt = completedAt.Time
+	task.DueAt = dueAt.Time
+
+	return task, nil
+}
+
+// toNullTime maps the zero time to NULL.
+func toNullTime(t time.Time) db.NullTime {
+	return db.NullTime{
+		Time:  t,
+		Valid: !t.IsZero(),
+	}
+}
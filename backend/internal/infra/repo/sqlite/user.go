@@ -0,0 +1,130 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/infra/db"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+)
+
+type UserRepo struct {
+	*sys.SimpleCore
+	db db.DB
+}
+
+func NewUserRepo(db db.DB, opts ...sys.Option) *UserRepo {
+	return &UserRepo{
+		SimpleCore: sys.NewCore("user-repo", opts...),
+		db:         db,
+	}
+}
+
+func (r *UserRepo) DB(ctx context.Context) db.DB {
+	return r.db
+}
+
+func (r *UserRepo) Start(ctx context.Context) error {
+	r.Log().Infof("%s started", r.Name())
+	return nil
+}
+
+func (r *UserRepo) CreateUser(ctx context.Context, m model.User) (user model.User, err error) {
+	err = m.GenID()
+	if err != nil {
+		return m, errors.Wrap(err, "create user repo error")
+	}
+
+	dbase := r.DB(ctx).DB()
+
+	if m.Role == "" {
+		m.Role = model.UserSystemRole
+	}
+
+	now := time.Now().UTC()
+	m.Audit = model.NewAudit(now, now)
+
+	query := `
+		INSERT INTO users (id, username, name, email, password, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err = dbase.ExecContext(ctx, query,
+		m.ID.String(),
+		m.Username,
+		m.Name,
+		m.Email,
+		m.Password,
+		m.Role,
+		m.CreatedAt,
+		m.UpdatedAt,
+	)
+	if err != nil {
+		return m, errors.Wrap(err, "create user repo error")
+	}
+
+	return m, nil
+}
+
+func (r *UserRepo) GetUser(ctx context.Context, userID string) (user model.User, err error) {
+	ok := uuid.Validate(userID)
+	if !ok {
+		return user, InvalidResourceIDErr
+	}
+
+	user, err = selectUser(ctx, r.DB(ctx).DB(), "id = $1", userID)
+	if err != nil {
+		return user, errors.Wrap(err, "get user repo error")
+	}
+
+	return user, nil
+}
+
+func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (user model.User, err error) {
+	user, err = selectUser(ctx, r.DB(ctx).DB(), "username = $1 COLLATE NOCASE", username)
+	if err != nil {
+		return user, errors.Wrap(err, "get user by username repo error")
+	}
+
+	return user, nil
+}
+
+func (r *UserRepo) GetUserByEmail(ctx context.Context, email string) (user model.User, err error) {
+	user, err = selectUser(ctx, r.DB(ctx).DB(), "email = $1 COLLATE NOCASE", email)
+	if err != nil {
+		return user, errors.Wrap(err, "get user by email repo error")
+	}
+
+	return user, nil
+}
+
+// selectUser returns the user matching cond, a constant condition on the $1 value.
+// Usernames and emails are matched ignoring case, as their unique indexes do.
+func selectUser(ctx context.Context, q queryer, cond, value string) (user model.User, err error) {
+	query := `
+		SELECT id, username, name, email, password, role, created_at, updated_at
+		FROM users
+		WHERE ` + cond + `
+	`
+
+	err = q.QueryRowContext(ctx, query, value).Scan(
+		&user.ID.UUID,
+		&user.Username,
+		&user.Name,
+		&user.Email,
+		&user.Password,
+		&user.Role,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return user, port.NewUserNotFoundErr(value)
+	}
+
+	return user, err
+}
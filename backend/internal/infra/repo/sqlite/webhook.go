@@ -0,0 +1,533 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/infra/db"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+)
+
+const (
+	webhookColumns = `w.id, w.user_id, w.list_id, w.url, w.events, w.secret, w.created_at, w.updated_at`
+
+	deliveryColumns = `d.id, d.webhook_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at,
+		d.last_attempt_at, d.response_status, d.error, d.created_at, d.updated_at`
+)
+
+type WebhookRepo struct {
+	*sys.SimpleCore
+	db db.DB
+}
+
+func NewWebhookRepo(db db.DB, opts ...sys.Option) *WebhookRepo {
+	return &WebhookRepo{
+		SimpleCore: sys.NewCore("webhook-repo", opts...),
+		db:         db,
+	}
+}
+
+func (r *WebhookRepo) DB(ctx context.Context) db.DB {
+	return r.db
+}
+
+func (r *WebhookRepo) Start(ctx context.Context) error {
+	r.Log().Infof("%s started", r.Name())
+	return nil
+}
+
+func (r *WebhookRepo) CreateWebhook(ctx context.Context, m model.Webhook) (webhook model.Webhook, err error) {
+	err = m.GenID()
+	if err != nil {
+		return m, errors.Wrap(err, "create webhook repo error")
+	}
+
+	events, err := json.Marshal(m.Events)
+	if err != nil {
+		return m, errors.Wrap(err, "create webhook repo error")
+	}
+
+	dbase := r.DB(ctx).DB()
+
+	now := time.Now().UTC()
+	m.Audit = model.NewAudit(now, now)
+
+	query := `
+		INSERT INTO webhooks (id, user_id, list_id, url, events, secret, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err = dbase.ExecContext(ctx, query,
+		m.ID.String(),
+		m.UserID.String(),
+		toNullString(m.ListID.String()),
+		m.URL,
+		string(events),
+		m.Secret,
+		m.CreatedAt,
+		m.UpdatedAt,
+	)
+	if err != nil {
+		return m, errors.Wrap(err, "create webhook repo error")
+	}
+
+	return m, nil
+}
+
+func (r *WebhookRepo) GetWebhooks(ctx context.Context, userID string) (webhooks []model.Webhook, err error) {
+	ok := uuid.Validate(userID)
+	if !ok {
+		return webhooks, InvalidResourceIDErr
+	}
+
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks w
+		WHERE w.user_id = $1
+		ORDER BY w.created_at, w.id
+	`
+
+	rows, err := dbase.QueryContext(ctx, query, userID)
+	if err != nil {
+		return webhooks, errors.Wrap(err, "get webhooks repo error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return webhooks, errors.Wrap(err, "get webhooks repo error")
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err = rows.Err(); err != nil {
+		return webhooks, errors.Wrap(err, "get webhooks repo error")
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepo) GetWebhook(ctx context.Context, webhookID, userID string) (webhook model.Webhook, err error) {
+	ok := uuid.Validate(webhookID)
+	if !ok {
+		return webhook, InvalidResourceIDErr
+	}
+
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks w
+		WHERE w.id = $1 AND w.user_id = $2
+	`
+
+	webhook, err = scanWebhook(dbase.QueryRowContext(ctx, query, webhookID, userID))
+	if err == sql.ErrNoRows {
+		return webhook, errors.Wrap(port.NewWebhookNotFoundErr(webhookID), "get webhook repo error")
+	}
+	if err != nil {
+		return webhook, errors.Wrap(err, "get webhook repo error")
+	}
+
+	return webhook, nil
+}
+
+func (r *WebhookRepo) DeleteWebhook(ctx context.Context, webhookID, userID string) error {
+	ok := uuid.Validate(webhookID)
+	if !ok {
+		return InvalidResourceIDErr
+	}
+
+	dbase := r.DB(ctx).DB()
+
+	res, err := dbase.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1 AND user_id = $2`, webhookID, userID)
+	if err != nil {
+		return errors.Wrap(err, "delete webhook repo error")
+	}
+
+	err = checkAffected(res, port.NewWebhookNotFoundErr(webhookID))
+	if err != nil {
+		return errors.Wrap(err, "delete webhook repo error")
+	}
+
+	return nil
+}
+
+// EnqueueDeliveries matches the webhooks of the list and those of its members for all their lists.
+// Webhooks of users who are no longer members of the list do not get its events.
+func (r *WebhookRepo) EnqueueDeliveries(ctx context.Context, listID string, typ model.EventType, payload []byte) (n int, err error) {
+	now := time.Now().UTC()
+
+	err = inTx(ctx, r.DB(ctx).DB(), func(tx *sql.Tx) error {
+		query := `
+			SELECT w.id
+			FROM webhooks w
+			INNER JOIN list_members m ON m.user_id = w.user_id AND m.list_id = $1
+			WHERE (w.list_id IS NULL OR w.list_id = $1)
+			  AND EXISTS (SELECT 1 FROM json_each(w.events) WHERE value = $2)
+		`
+
+		rows, err := tx.QueryContext(ctx, query, listID, string(typ))
+		if err != nil {
+			return err
+		}
+
+		var webhookIDs []string
+		for rows.Next() {
+			var webhookID string
+
+			err := rows.Scan(&webhookID)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+
+			webhookIDs = append(webhookIDs, webhookID)
+		}
+
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		for _, webhookID := range webhookIDs {
+			_, err := insertDelivery(ctx, tx, webhookID, typ, payload, now)
+			if err != nil {
+				return err
+			}
+		}
+
+		n = len(webhookIDs)
+		return nil
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "enqueue deliveries repo error")
+	}
+
+	return n, nil
+}
+
+func (r *WebhookRepo) GetDeliveries(ctx context.Context, webhookID, userID string, limit int) (deliveries []model.WebhookDelivery, err error) {
+	_, err = r.GetWebhook(ctx, webhookID, userID)
+	if err != nil {
+		return deliveries, errors.Wrap(err, "get deliveries repo error")
+	}
+
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.webhook_id = $1
+		ORDER BY d.created_at DESC, d.id
+		LIMIT $2
+	`
+
+	rows, err := dbase.QueryContext(ctx, query, webhookID, limit)
+	if err != nil {
+		return deliveries, errors.Wrap(err, "get deliveries repo error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return deliveries, errors.Wrap(err, "get deliveries repo error")
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		return deliveries, errors.Wrap(err, "get deliveries repo error")
+	}
+
+	return deliveries, nil
+}
+
+// Redeliver enqueues the event again as a new delivery, the log of the previous one is kept as it was.
+func (r *WebhookRepo) Redeliver(ctx context.Context, deliveryID, webhookID, userID string) (delivery model.WebhookDelivery, err error) {
+	ok := uuid.Validate(deliveryID) && uuid.Validate(webhookID)
+	if !ok {
+		return delivery, InvalidResourceIDErr
+	}
+
+	now := time.Now().UTC()
+
+	err = inTx(ctx, r.DB(ctx).DB(), func(tx *sql.Tx) error {
+		query := `
+			SELECT ` + deliveryColumns + `
+			FROM webhook_deliveries d
+			INNER JOIN webhooks w ON w.id = d.webhook_id
+			WHERE d.id = $1 AND d.webhook_id = $2 AND w.user_id = $3
+		`
+
+		found, err := scanDelivery(tx.QueryRowContext(ctx, query, deliveryID, webhookID, userID))
+		if err == sql.ErrNoRows {
+			return port.NewDeliveryNotFoundErr(deliveryID)
+		}
+		if err != nil {
+			return err
+		}
+
+		delivery, err = insertDelivery(ctx, tx, webhookID, found.EventType, found.Payload, now)
+		return err
+	})
+	if err != nil {
+		return delivery, errors.Wrap(err, "redeliver repo error")
+	}
+
+	return delivery, nil
+}
+
+// ClaimDeliveries moves the next attempt of the due deliveries to the end of the lease in a single statement,
+// so that concurrent workers never claim the same ones. Deliveries whose worker stops before updating them
+// are claimed again when the lease ends.
+func (r *WebhookRepo) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) (deliveries []model.WebhookDelivery, err error) {
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $2
+			ORDER BY next_attempt_at, created_at
+			LIMIT $3
+		)
+		RETURNING id
+	`
+
+	rows, err := dbase.QueryContext(ctx, query, now.UTC().Add(lease), now.UTC(), limit)
+	if err != nil {
+		return deliveries, errors.Wrap(err, "claim deliveries repo error")
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+
+		err := rows.Scan(&id)
+		if err != nil {
+			return deliveries, errors.Wrap(err, "claim deliveries repo error")
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return deliveries, errors.Wrap(err, "claim deliveries repo error")
+	}
+
+	if len(ids) == 0 {
+		return deliveries, nil
+	}
+
+	deliveries, err = r.deliveriesByID(ctx, ids)
+	if err != nil {
+		return deliveries, errors.Wrap(err, "claim deliveries repo error")
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepo) UpdateDelivery(ctx context.Context, m model.WebhookDelivery) error {
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_attempt_at = $4,
+		    response_status = $5, error = $6, updated_at = $7
+		WHERE id = $8
+	`
+
+	res, err := dbase.ExecContext(ctx, query,
+		m.Status,
+		m.Attempts,
+		toNullTime(m.NextAttemptAt),
+		toNullTime(m.LastAttemptAt),
+		m.ResponseStatus,
+		m.Error,
+		time.Now().UTC(),
+		m.ID.String(),
+	)
+	if err != nil {
+		return errors.Wrap(err, "update delivery repo error")
+	}
+
+	err = checkAffected(res, port.NewDeliveryNotFoundErr(m.ID.String()))
+	if err != nil {
+		return errors.Wrap(err, "update delivery repo error")
+	}
+
+	return nil
+}
+
+// deliveriesByID returns the deliveries with the IDs along with their webhooks.
+func (r *WebhookRepo) deliveriesByID(ctx context.Context, ids []string) (deliveries []model.WebhookDelivery, err error) {
+	deliveryIDs, err := json.Marshal(ids)
+	if err != nil {
+		return deliveries, err
+	}
+
+	dbase := r.DB(ctx).DB()
+
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.id IN (SELECT value FROM json_each($1))
+		ORDER BY d.next_attempt_at, d.created_at
+	`
+
+	rows, err := dbase.QueryContext(ctx, query, string(deliveryIDs))
+	if err != nil {
+		return deliveries, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return deliveries, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		return deliveries, err
+	}
+
+	query = `
+		SELECT ` + webhookColumns + `
+		FROM webhooks w
+		WHERE w.id IN (SELECT d.webhook_id FROM webhook_deliveries d WHERE d.id IN (SELECT value FROM json_each($1)))
+	`
+
+	rows, err = dbase.QueryContext(ctx, query, string(deliveryIDs))
+	if err != nil {
+		return deliveries, err
+	}
+	defer rows.Close()
+
+	webhooks := map[string]model.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return deliveries, err
+		}
+
+		webhooks[webhook.ID.String()] = webhook
+	}
+
+	for i := range deliveries {
+		deliveries[i].Webhook = webhooks[deliveries[i].WebhookID.String()]
+	}
+
+	return deliveries, rows.Err()
+}
+
+// insertDelivery enqueues a pending delivery of the event to the webhook, due right away.
+func insertDelivery(ctx context.Context, q queryer, webhookID string, typ model.EventType, payload []byte, now time.Time) (m model.WebhookDelivery, err error) {
+	m = model.WebhookDelivery{
+		WebhookID:     model.NewID(uuid.UUID{Val: webhookID}),
+		EventType:     typ,
+		Payload:       payload,
+		Status:        model.PendingDelivery,
+		NextAttemptAt: now,
+		Audit:         model.NewAudit(now, now),
+	}
+
+	err = m.GenID()
+	if err != nil {
+		return m, err
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (id, webhook_id, event_type, payload, status, next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err = q.ExecContext(ctx, query,
+		m.ID.String(),
+		webhookID,
+		m.EventType,
+		m.Payload,
+		m.Status,
+		m.NextAttemptAt,
+		m.CreatedAt,
+		m.UpdatedAt,
+	)
+
+	return m, err
+}
+
+func scanWebhook(s scanner) (webhook model.Webhook, err error) {
+	var events string
+
+	err = s.Scan(
+		&webhook.ID.UUID,
+		&webhook.UserID.UUID,
+		&webhook.ListID.UUID,
+		&webhook.URL,
+		&events,
+		&webhook.Secret,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		return webhook, err
+	}
+
+	err = json.Unmarshal([]byte(events), &webhook.Events)
+	if err != nil {
+		return webhook, err
+	}
+
+	return webhook, nil
+}
+
+func scanDelivery(s scanner) (delivery model.WebhookDelivery, err error) {
+	var nextAttemptAt, lastAttemptAt db.NullTime
+
+	err = s.Scan(
+		&delivery.ID.UUID,
+		&delivery.WebhookID.UUID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&nextAttemptAt,
+		&lastAttemptAt,
+		&delivery.ResponseStatus,
+		&delivery.Error,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+	if err != nil {
+		return delivery, err
+	}
+
+	delivery.NextAttemptAt = nextAttemptAt.Time
+	delivery.LastAttemptAt = lastAttemptAt.Time
+
+	return delivery, nil
+}
+
+func toNullString(s string) sql.NullString {
+	return sql.NullString{
+		String: s,
+		Valid:  s != "",
+	}
+}
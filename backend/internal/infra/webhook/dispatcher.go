@@ -0,0 +1,283 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+)
+
+const (
+	// SignatureHeader holds the signature of a delivery, as returned by Sign.
+	SignatureHeader = "Webhook-Signature"
+	// TimestampHeader holds the Unix time a delivery was sent at, it is part of the signed content.
+	TimestampHeader = "Webhook-Timestamp"
+	// EventHeader holds the event type of a delivery.
+	EventHeader = "Webhook-Event"
+	// DeliveryHeader holds the ID of a delivery, redeliveries have a new one.
+	DeliveryHeader = "Webhook-Delivery"
+
+	userAgent = "stl-webhooks"
+
+	defaultWorkers     = 2
+	defaultPoll        = time.Second
+	defaultTimeout     = 10 * time.Second
+	defaultBackoff     = 30 * time.Second
+	defaultMaxAttempts = 8
+	// maxBackoff caps the wait between attempts, it doubles after every failed one until then.
+	maxBackoff = 6 * time.Hour
+	// claimSize is the number of deliveries a worker claims at once.
+	claimSize = 10
+	// maxResponseSize is the part of a response body read before closing it, so that connections can be reused.
+	maxResponseSize = 64 << 10
+)
+
+type (
+	// Dispatcher sends the deliveries queued in the webhook repo, signing them with the secret of their webhook.
+	// Failed attempts are retried with exponential backoff, until the maximum number of attempts.
+	// The queue is persistent, deliveries left pending when the process stops are sent once it starts again.
+	Dispatcher struct {
+		*sys.SimpleCore
+		repo        port.WebhookRepo
+		client      *http.Client
+		workers     int
+		poll        time.Duration
+		timeout     time.Duration
+		backoff     time.Duration
+		maxAttempts int
+		// allowPrivate lets deliveries reach local and private addresses, only meant for development and tests.
+		allowPrivate bool
+	}
+)
+
+func NewDispatcher(repo port.WebhookRepo, opts ...sys.Option) *Dispatcher {
+	d := &Dispatcher{
+		SimpleCore:  sys.NewCore("webhook-dispatcher", opts...),
+		repo:        repo,
+		workers:     defaultWorkers,
+		poll:        defaultPoll,
+		timeout:     defaultTimeout,
+		backoff:     defaultBackoff,
+		maxAttempts: defaultMaxAttempts,
+	}
+
+	if cfg := d.Cfg(); cfg != nil {
+		if n := cfg.GetInt(config.Key.WebhooksWorkers); n > 0 {
+			d.workers = n
+		}
+
+		if secs := cfg.GetInt(config.Key.WebhooksPoll); secs > 0 {
+			d.poll = time.Duration(secs) * time.Second
+		}
+
+		if secs := cfg.GetInt(config.Key.WebhooksTimeout); secs > 0 {
+			d.timeout = time.Duration(secs) * time.Second
+		}
+
+		if secs := cfg.GetInt(config.Key.WebhooksBackoff); secs > 0 {
+			d.backoff = time.Duration(secs) * time.Second
+		}
+
+		if n := cfg.GetInt(config.Key.WebhooksMaxAttempts); n > 0 {
+			d.maxAttempts = n
+		}
+
+		d.allowPrivate = cfg.GetBool(config.Key.WebhooksAllowPrivate)
+	}
+
+	// Addresses are checked once resolved, right before connecting, so that a name resolving to a public address
+	// when the webhook was created cannot be rebound to an internal one. Proxies are not used, they would be the
+	// ones checked.
+	dialer := &net.Dialer{Timeout: d.timeout, Control: d.checkAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	// Redirects are not followed, receivers are expected at the URL they registered.
+	d.client = &http.Client{
+		Transport: transport,
+		Timeout:   d.timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	return d
+}
+
+// Workers returns the delivery workers, to be run as supervisor tasks.
+func (d *Dispatcher) Workers() []sys.Task {
+	workers := make([]sys.Task, d.workers)
+	for i := range workers {
+		workers[i] = d.work
+	}
+
+	return workers
+}
+
+// work sends the due deliveries every poll interval until the context is done.
+// A worker that claimed a full batch goes on with the next one right away.
+func (d *Dispatcher) work(ctx context.Context) error {
+	ticker := time.NewTicker(d.poll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-ticker.C:
+			for {
+				n, err := d.Dispatch(ctx, time.Now())
+				if err != nil {
+					d.Log().Errorf("%s error: %s", d.Name(), err)
+				}
+
+				if err != nil || n < claimSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// Dispatch claims the deliveries due at the given time and sends them, returning how many were claimed.
+func (d *Dispatcher) Dispatch(ctx context.Context, now time.Time) (n int, err error) {
+	deliveries, err := d.repo.ClaimDeliveries(ctx, now, d.lease(), claimSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		delivery = d.deliver(ctx, delivery)
+
+		err = d.repo.UpdateDelivery(ctx, delivery)
+		if err != nil {
+			d.Log().Errorf("%s cannot record delivery %s: %s", d.Name(), delivery.ID.String(), err)
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// deliver makes an attempt to send the delivery and returns it updated with its outcome.
+func (d *Dispatcher) deliver(ctx context.Context, m model.WebhookDelivery) model.WebhookDelivery {
+	now := time.Now().UTC()
+
+	m.Attempts++
+	m.LastAttemptAt = now
+
+	var err error
+	m.ResponseStatus, err = d.send(ctx, m, now)
+	if err == nil {
+		m.Status = model.SucceededDelivery
+		m.NextAttemptAt = time.Time{}
+		m.Error = ""
+		return m
+	}
+
+	m.Error = err.Error()
+
+	if m.Attempts >= d.maxAttempts {
+		m.Status = model.FailedDelivery
+		m.NextAttemptAt = time.Time{}
+		return m
+	}
+
+	m.NextAttemptAt = now.Add(d.retryAfter(m.Attempts))
+	return m
+}
+
+// send posts the payload of the delivery to its webhook, only 2xx responses are successful.
+func (d *Dispatcher) send(ctx context.Context, m model.WebhookDelivery, now time.Time) (status int, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.Webhook.URL, bytes.NewReader(m.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(DeliveryHeader, m.ID.String())
+	req.Header.Set(EventHeader, string(m.EventType))
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(m.Webhook.Secret, timestamp, m.Payload))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxResponseSize))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected response status %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+// checkAddress rejects connections to addresses webhooks cannot be delivered to, unless private ones are allowed.
+func (d *Dispatcher) checkAddress(network, address string, c syscall.RawConn) error {
+	if d.allowPrivate {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !model.WebhookIPAllowed(ip) {
+		return fmt.Errorf("address %s not allowed", host)
+	}
+
+	return nil
+}
+
+// retryAfter returns the wait before the next attempt of a delivery that failed the given number of times.
+func (d *Dispatcher) retryAfter(attempts int) time.Duration {
+	wait := d.backoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+
+	if wait > maxBackoff {
+		return maxBackoff
+	}
+
+	return wait
+}
+
+// lease is how long claimed deliveries are kept from other workers, enough to send all of them.
+func (d *Dispatcher) lease() time.Duration {
+	return claimSize*d.timeout + d.poll
+}
+
+// Sign returns the signature of a payload sent at the timestamp, the hex encoded HMAC-SHA256 of
+// "timestamp.payload" keyed with the webhook secret, prefixed with "sha256=".
+// Receivers compute it again to check the delivery and can reject old timestamps to prevent replays.
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,195 @@
+package webhook_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/domain/port"
+	"github.com/vanillazen/stl/backend/internal/infra/webhook"
+	"github.com/vanillazen/stl/backend/internal/sys"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	"github.com/vanillazen/stl/backend/internal/sys/log"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+)
+
+const (
+	webhookID  = "5a1e2b7c-3d4f-4a6b-8c9d-0e1f2a3b4c01"
+	deliveryID = "5a1e2b7c-3d4f-4a6b-8c9d-0e1f2a3b4c02"
+	secret     = "whsec_test"
+	payload    = `{"Type":"task.created"}`
+)
+
+// queue is an in-memory delivery queue, only the methods used by the dispatcher are implemented.
+type queue struct {
+	port.WebhookRepo
+	deliveries map[string]model.WebhookDelivery
+}
+
+func newQueue(url string) *queue {
+	delivery := model.WebhookDelivery{
+		ID:            model.NewID(uuid.UUID{Val: deliveryID}),
+		WebhookID:     model.NewID(uuid.UUID{Val: webhookID}),
+		EventType:     model.TaskCreatedEvent,
+		Payload:       []byte(payload),
+		Status:        model.PendingDelivery,
+		NextAttemptAt: time.Now().UTC(),
+		Webhook: model.Webhook{
+			ID:     model.NewID(uuid.UUID{Val: webhookID}),
+			URL:    url,
+			Secret: secret,
+		},
+	}
+
+	return &queue{deliveries: map[string]model.WebhookDelivery{deliveryID: delivery}}
+}
+
+func (q *queue) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) (deliveries []model.WebhookDelivery, err error) {
+	for id, d := range q.deliveries {
+		if d.Done() || d.NextAttemptAt.After(now) || len(deliveries) == limit {
+			continue
+		}
+
+		d.NextAttemptAt = now.Add(lease)
+		q.deliveries[id] = d
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, nil
+}
+
+func (q *queue) UpdateDelivery(ctx context.Context, m model.WebhookDelivery) error {
+	q.deliveries[m.ID.String()] = m
+	return nil
+}
+
+// newTestDispatcher returns a dispatcher allowed to deliver to the local test receivers.
+func newTestDispatcher(q *queue, maxAttempts string) *webhook.Dispatcher {
+	cfg := &config.Config{}
+	cfg.SetValues(map[string]string{
+		config.Key.WebhooksBackoff:      "60",
+		config.Key.WebhooksMaxAttempts:  maxAttempts,
+		config.Key.WebhooksAllowPrivate: "true",
+	})
+
+	return webhook.NewDispatcher(q, sys.WithConfig(cfg), sys.WithLogger(log.NewLogger("error")))
+}
+
+func TestSign(t *testing.T) {
+	want := "sha256=afb472621231dd2e5f955c7b5d49b321b98c241df8333836387bf34d76f8aafa"
+
+	got := webhook.Sign(secret, "1700000000", []byte(payload))
+	if got != want {
+		t.Errorf("Sign: expected '%s', got '%s'", want, got)
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		signature := webhook.Sign(secret, r.Header.Get(webhook.TimestampHeader), body)
+		if r.Header.Get(webhook.SignatureHeader) != signature || string(body) != payload {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		received <- r
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	q := newQueue(receiver.URL)
+	d := newTestDispatcher(q, "3")
+
+	n, err := d.Dispatch(context.Background(), time.Now())
+	if err != nil || n != 1 {
+		t.Fatalf("Dispatch: expected 1 delivery, got %d (%v)", n, err)
+	}
+
+	r := <-received
+	if r.Header.Get(webhook.DeliveryHeader) != deliveryID || r.Header.Get(webhook.EventHeader) != string(model.TaskCreatedEvent) {
+		t.Errorf("Headers: expected delivery %s of %s, got %v", deliveryID, model.TaskCreatedEvent, r.Header)
+	}
+
+	delivery := q.deliveries[deliveryID]
+	if delivery.Status != model.SucceededDelivery || delivery.Attempts != 1 || delivery.ResponseStatus != http.StatusNoContent || delivery.Error != "" {
+		t.Errorf("Delivery: expected succeeded on first attempt, got %+v", delivery)
+	}
+}
+
+func TestDispatchRetry(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	q := newQueue(receiver.URL)
+	d := newTestDispatcher(q, "2")
+	ctx := context.Background()
+
+	_, err := d.Dispatch(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	delivery := q.deliveries[deliveryID]
+	if delivery.Done() || delivery.ResponseStatus != http.StatusServiceUnavailable || delivery.Error == "" {
+		t.Errorf("Failed attempt: expected pending with the response status, got %+v", delivery)
+	}
+
+	wait := delivery.NextAttemptAt.Sub(delivery.LastAttemptAt)
+	if wait != time.Minute {
+		t.Errorf("Backoff: expected next attempt in %s, got %s", time.Minute, wait)
+	}
+
+	// Not due before the backoff
+	n, err := d.Dispatch(ctx, time.Now())
+	if err != nil || n != 0 {
+		t.Errorf("Not due: expected no delivery, got %d (%v)", n, err)
+	}
+
+	n, err = d.Dispatch(ctx, time.Now().Add(time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("Due: expected 1 delivery, got %d (%v)", n, err)
+	}
+
+	delivery = q.deliveries[deliveryID]
+	if delivery.Status != model.FailedDelivery || delivery.Attempts != 2 || !delivery.NextAttemptAt.IsZero() {
+		t.Errorf("Last attempt: expected failed after 2 attempts, got %+v", delivery)
+	}
+}
+
+func TestDispatchPrivate(t *testing.T) {
+	var called bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	// Names are checked once resolved, as they could be rebound to an internal address after validation
+	target := strings.Replace(receiver.URL, "127.0.0.1", "localhost", 1)
+
+	for _, url := range []string{receiver.URL, target} {
+		q := newQueue(url)
+		cfg := &config.Config{}
+		d := webhook.NewDispatcher(q, sys.WithConfig(cfg), sys.WithLogger(log.NewLogger("error")))
+
+		_, err := d.Dispatch(context.Background(), time.Now())
+		if err != nil {
+			t.Fatalf("Error: unexpected '%v'", err)
+		}
+
+		delivery := q.deliveries[deliveryID]
+		if called || delivery.Done() || delivery.ResponseStatus != 0 || !strings.Contains(delivery.Error, "not allowed") {
+			t.Errorf("Delivery to %s: expected rejected before connecting, got %+v", url, delivery)
+		}
+	}
+}
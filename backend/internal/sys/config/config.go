@@ -320,8 +320,17 @@ func (cfg *Config) GetIntSlice(key string) []int {
 }
 
 // GetStringSlice returns the value associated with the key as a slice of strings.
+// The value is a comma separated list, surrounding spaces and empty items are dropped.
 func (cfg *Config) GetStringSlice(key string) []string {
-	panic("not implemented yet")
+	var vals []string
+	for _, v := range strings.Split(cfg.GetString(key), ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			vals = append(vals, v)
+		}
+	}
+
+	return vals
 }
 
 // GetStringMap returns the value associated with the key as a map of interfaces.
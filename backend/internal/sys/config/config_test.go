@@ -117,3 +117,22 @@ func TestLoadEnvVars(t *testing.T) {
 		t.Error("error processing config environment variables")
 	}
 }
+
+func TestGetStringSlice(t *testing.T) {
+	// Start a config handler.
+	cfg := config.Config{}
+	cfg.SetValues(map[string]string{
+		"proxies": " 10.0.0.1, ,192.168.0.0/16,",
+		"empty":   "",
+	})
+
+	// Assert result.
+	proxies := cfg.GetStringSlice("proxies")
+	if len(proxies) != 2 || proxies[0] != "10.0.0.1" || proxies[1] != "192.168.0.0/16" {
+		t.Errorf("error processing string slice: %v", proxies)
+	}
+
+	if empty := cfg.GetStringSlice("empty"); len(empty) != 0 {
+		t.Errorf("error processing empty string slice: %v", empty)
+	}
+}
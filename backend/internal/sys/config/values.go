@@ -10,6 +10,53 @@ func newCfgKeyReg() *cfgKeyReg {
 		APIServerPort:     "http.api.server.port",
 		APIServerTimeout:  "http.api.server.shutdown.timeout.secs",
 		APIErrorExposeInt: "api.errors.expose.internal",
+		APITrustedProxies: "http.api.trusted.proxies",
+
+		// Idempotency
+
+		APIIdempotencyTTL:   "http.api.idempotency.ttl.secs",
+		APIIdempotencyPurge: "http.api.idempotency.purge.interval.secs",
+
+		// Events
+
+		APIEventsHeartbeat: "http.api.events.heartbeat.secs",
+		EventsLogSize:      "events.log.size",
+
+		// Realtime
+
+		APIRealtimeAuthCheck: "http.api.realtime.auth.check.secs",
+
+		// Webhooks
+
+		WebhooksWorkers:     "webhooks.workers",
+		WebhooksPoll:        "webhooks.poll.interval.secs",
+		WebhooksTimeout:     "webhooks.timeout.secs",
+		WebhooksBackoff:     "webhooks.backoff.secs",
+		WebhooksMaxAttempts: "webhooks.max.attempts",
+		// Local and private hosts are rejected unless allowed, only meant for development and tests.
+		WebhooksAllowPrivate: "webhooks.allow.private",
+
+		// Outbox
+
+		OutboxPoll:        "outbox.poll.interval.secs",
+		OutboxBackoff:     "outbox.backoff.secs",
+		OutboxMaxAttempts: "outbox.max.attempts",
+		OutboxRetention:   "outbox.retention.secs",
+		OutboxPurge:       "outbox.purge.interval.secs",
+
+		// Auth
+
+		AuthTokenKey:   "auth.token.key",
+		AuthAccessTTL:  "auth.token.access.ttl.secs",
+		AuthRefreshTTL: "auth.token.refresh.ttl.secs",
+
+		// Mail
+
+		MailFrom:     "mail.from",
+		MailSMTPHost: "mail.smtp.host",
+		MailSMTPPort: "mail.smtp.port",
+		MailSMTPUser: "mail.smtp.user",
+		MailSMTPPass: "mail.smtp.pass",
 
 		// Postgres
 
@@ -39,6 +86,52 @@ type cfgKeyReg struct {
 	APIServerPort     string
 	APIServerTimeout  string
 	APIErrorExposeInt string
+	APITrustedProxies string
+
+	// Idempotency
+
+	APIIdempotencyTTL   string
+	APIIdempotencyPurge string
+
+	// Events
+
+	APIEventsHeartbeat string
+	EventsLogSize      string
+
+	// Realtime
+
+	APIRealtimeAuthCheck string
+
+	// Webhooks
+
+	WebhooksWorkers      string
+	WebhooksPoll         string
+	WebhooksTimeout      string
+	WebhooksBackoff      string
+	WebhooksMaxAttempts  string
+	WebhooksAllowPrivate string
+
+	// Outbox
+
+	OutboxPoll        string
+	OutboxBackoff     string
+	OutboxMaxAttempts string
+	OutboxRetention   string
+	OutboxPurge       string
+
+	// Auth
+
+	AuthTokenKey   string
+	AuthAccessTTL  string
+	AuthRefreshTTL string
+
+	// Mail
+
+	MailFrom     string
+	MailSMTPHost string
+	MailSMTPPort string
+	MailSMTPUser string
+	MailSMTPPass string
 
 	// Postgres
 
@@ -8,7 +8,8 @@ import (
 )
 
 const (
-	stackSize   = 10
+	stackSize = 10
+	// skipNTraces leaves out runtime.Callers, extractStacktrace, newError and the exported function calling it.
 	skipNTraces = 4
 )
 
@@ -20,15 +21,63 @@ var (
 	}
 )
 
+// Kind classifies an error regardless of the layer it went through, i.e.: to decide the status of a response.
+// It is kept by Wrap and read with KindOf, a Kind is also an error so that errors.Is(err, NotFound) works.
+type Kind uint8
+
+const (
+	Other Kind = iota
+	Invalid
+	NotFound
+	Conflict
+	Unauthenticated
+	Forbidden
+	Internal
+)
+
+var kindNames = map[Kind]string{
+	Other:           "other",
+	Invalid:         "invalid",
+	NotFound:        "not found",
+	Conflict:        "conflict",
+	Unauthenticated: "unauthenticated",
+	Forbidden:       "forbidden",
+	Internal:        "internal",
+}
+
+func (k Kind) String() string {
+	name, ok := kindNames[k]
+	if !ok {
+		return fmt.Sprintf("kind(%d)", k)
+	}
+
+	return name
+}
+
+func (k Kind) Error() string {
+	return k.String()
+}
+
+// kinded is implemented by errors declaring their kind, like Error or the domain errors.
+type kinded interface {
+	Kind() Kind
+}
+
 func New(msg string) Error {
 	err := errors.New(msg)
-	return Wrap(err, "")
+	return newError(err, "", Other)
 }
 
 func Newf(format string, ctxValues ...interface{}) Error {
 	msg := fmt.Sprintf(format, ctxValues...)
 	err := errors.New(msg)
-	return Wrap(err, "")
+	return newError(err, "", Other)
+}
+
+// NewKind returns a new error of the given kind.
+func NewKind(kind Kind, msg string) Error {
+	err := errors.New(msg)
+	return newError(err, "", kind)
 }
 
 func Wrap(err error, context ...string) Error {
@@ -37,33 +86,125 @@ func Wrap(err error, context ...string) Error {
 		ctx = context[0]
 	}
 
-	stackTrace := extractStacktrace()
-	return Error{
-		err:        err,
-		context:    ctx,
-		stackTrace: stackTrace,
-	}
+	return newError(err, ctx, Other)
 }
 
 func Wrapf(err error, format string, ctxValues ...interface{}) error {
 	context := fmt.Sprintf(format, ctxValues...)
-	return Wrap(err, context)
+	return newError(err, context, Other)
+}
+
+// WrapKind wraps err setting its kind, it overrides the one of the wrapped error, if any.
+func WrapKind(err error, kind Kind, context ...string) Error {
+	var ctx string
+	if len(context) > 0 {
+		ctx = context[0]
+	}
+
+	return newError(err, ctx, kind)
+}
+
+// newError captures the stack trace unless the wrapped error already has one,
+// so that an error wrapped at every layer carries a single trace: the one of the place it was raised.
+func newError(err error, context string, kind Kind) Error {
+	var stackTrace string
+	if stack(err) == "" {
+		stackTrace = extractStacktrace()
+	}
+
+	return Error{
+		err:        err,
+		context:    context,
+		stackTrace: stackTrace,
+		kind:       kind,
+	}
 }
 
 type Error struct {
 	err        error
 	context    string
 	stackTrace string
+	kind       Kind
 }
 
+// Error returns the messages of the error chain followed by its stack trace.
 func (err Error) Error() string {
-	return fmt.Sprintf("%s: %v\n%s", err.context, err.err, err.stackTrace)
+	trace := stack(err)
+	if trace == "" {
+		return err.message()
+	}
+
+	return fmt.Sprintf("%s\n%s", err.message(), trace)
 }
 
 func (err Error) Unwrap() error {
 	return err.err
 }
 
+// Kind returns the kind set on this error, KindOf looks through the whole chain.
+func (err Error) Kind() Kind {
+	return err.kind
+}
+
+// Is reports whether the error matches target, being target either the same error or a Kind.
+func (err Error) Is(target error) bool {
+	switch t := target.(type) {
+	case Kind:
+		return KindOf(err) == t
+	case Error:
+		return err.err != nil && err.err == t.err && err.context == t.context
+	}
+
+	return false
+}
+
+// As sets target if it is an *Error pointer, the value receiver otherwise only matches Error targets.
+func (err Error) As(target any) bool {
+	t, ok := target.(**Error)
+	if !ok {
+		return false
+	}
+
+	e := err
+	*t = &e
+	return true
+}
+
+// message returns the context and message of the chain without stack traces.
+func (err Error) message() string {
+	var msg string
+	switch e := err.err.(type) {
+	case nil:
+	case Error:
+		msg = e.message()
+	default:
+		msg = e.Error()
+	}
+
+	switch {
+	case err.context == "":
+		return msg
+	case msg == "":
+		return err.context
+	}
+
+	return fmt.Sprintf("%s: %s", err.context, msg)
+}
+
+// stack returns the first stack trace found in the error chain.
+func stack(err error) string {
+	for err != nil {
+		e, ok := err.(Error)
+		if ok && e.stackTrace != "" {
+			return e.stackTrace
+		}
+
+		err = errors.Unwrap(err)
+	}
+
+	return ""
+}
+
 func extractStacktrace() string {
 	stack := make([]uintptr, stackSize)
 	length := runtime.Callers(skipNTraces, stack)
@@ -72,15 +213,63 @@ func extractStacktrace() string {
 
 	var builder strings.Builder
 	for frame, more := frames.Next(); more; frame, more = frames.Next() {
+		// Package level sentinel errors get the trace of the place they are wrapped instead
+		if builder.Len() == 0 && strings.HasSuffix(frame.Function, ".init") {
+			return ""
+		}
+
 		fmt.Fprintf(&builder, "\tat %s:%d: %s\n", frame.File, frame.Line, frame.Function)
 	}
 
 	return builder.String()
 }
 
+// KindOf returns the kind of the first error of the chain declaring one, or Other.
+func KindOf(err error) Kind {
+	for err != nil {
+		k, ok := err.(kinded)
+		if ok && k.Kind() != Other {
+			return k.Kind()
+		}
+
+		err = errors.Unwrap(err)
+	}
+
+	return Other
+}
+
+// Is reports whether any error in err's chain matches target.
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As finds the first error in err's chain that matches target.
+func As(err error, target any) bool {
+	return errors.As(err, target)
+}
+
+// Unwrap returns the result of calling the Unwrap method on err, if any.
+func Unwrap(err error) error {
+	return errors.Unwrap(err)
+}
+
+// Message returns the messages of the error chain without its stack trace.
+func Message(err error) string {
+	e, ok := err.(Error)
+	if !ok {
+		return err.Error()
+	}
+
+	return e.message()
+}
+
+// Stacktrace returns the error message followed by the stack trace of its chain, if any.
 func Stacktrace(err error) string {
-	if wrappedErr, ok := err.(*Error); ok {
-		return wrappedErr.Error()
+	msg := err.Error()
+	trace := stack(err)
+	if trace == "" || strings.Contains(msg, trace) {
+		return msg
 	}
-	return err.Error()
+
+	return fmt.Sprintf("%s\n%s", msg, trace)
 }
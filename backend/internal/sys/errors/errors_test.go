@@ -0,0 +1,111 @@
+package errors_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+)
+
+var sentinelErr = errors.New("sentinel")
+
+type domainErr struct{}
+
+func (e domainErr) Error() string {
+	return "domain error"
+}
+
+func (e domainErr) Kind() errors.Kind {
+	return errors.NotFound
+}
+
+func TestKind(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected errors.Kind
+	}{
+		{
+			name:     "No kind",
+			err:      errors.Wrap(errors.New("plain")),
+			expected: errors.Other,
+		},
+		{
+			name:     "Wrapped",
+			err:      errors.Wrap(errors.Wrap(errors.NewKind(errors.Invalid, "bad input"), "service"), "handler"),
+			expected: errors.Invalid,
+		},
+		{
+			name:     "Overridden",
+			err:      errors.WrapKind(errors.NewKind(errors.Invalid, "bad input"), errors.Conflict, "service"),
+			expected: errors.Conflict,
+		},
+		{
+			name:     "Declared by a wrapped error",
+			err:      errors.Wrap(fmt.Errorf("repo: %w", domainErr{}), "service"),
+			expected: errors.NotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if kind := errors.KindOf(test.err); kind != test.expected {
+				t.Errorf("KindOf: expected '%s', got '%s'", test.expected, kind)
+			}
+
+			if test.expected != errors.Other && !errors.Is(test.err, test.expected) {
+				t.Errorf("Is: expected error to match kind '%s'", test.expected)
+			}
+		})
+	}
+}
+
+func TestIsAs(t *testing.T) {
+	err := errors.Wrap(errors.Wrap(sentinelErr, "repo"), "service")
+
+	if !errors.Is(err, sentinelErr) {
+		t.Error("Is: expected wrapped sentinel to match")
+	}
+
+	if errors.Is(err, errors.New("sentinel")) {
+		t.Error("Is: expected a different error with the same message not to match")
+	}
+
+	var e *errors.Error
+	if !errors.As(err, &e) || e.Kind() != errors.Other {
+		t.Error("As: expected error to be found as *Error")
+	}
+
+	var de domainErr
+	if !errors.As(errors.Wrap(domainErr{}, "service"), &de) {
+		t.Error("As: expected wrapped domain error to be found")
+	}
+}
+
+func TestStacktrace(t *testing.T) {
+	if strings.Contains(sentinelErr.Error(), "\tat ") {
+		t.Errorf("Sentinel: expected no trace captured at package init, got '%s'", sentinelErr.Error())
+	}
+
+	err := errors.Wrap(errors.Wrap(errors.Wrap(sentinelErr, "repo"), "service"), "handler")
+	msg := err.Error()
+
+	if !strings.HasPrefix(msg, "handler: service: repo: sentinel\n") {
+		t.Errorf("Message: expected the chain of contexts first, got '%s'", msg)
+	}
+
+	if n := strings.Count(msg, "errors_test.TestStacktrace"); n != 1 {
+		t.Errorf("Trace: expected a single trace, got %d frames of the test in '%s'", n, msg)
+	}
+
+	wrapped := fmt.Errorf("outer: %w", err)
+	if st := errors.Stacktrace(wrapped); strings.Count(st, "errors_test.TestStacktrace") != 1 {
+		t.Errorf("Stacktrace: expected the trace of the wrapped error once, got '%s'", st)
+	}
+
+	plain := fmt.Errorf("outer: %w", domainErr{})
+	if st := errors.Stacktrace(plain); st != plain.Error() {
+		t.Errorf("Stacktrace: expected just the message without trace, got '%s'", st)
+	}
+}
@@ -0,0 +1,103 @@
+// Package password hashes and verifies user passwords using PBKDF2 with HMAC-SHA256.
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+)
+
+const (
+	scheme     = "pbkdf2-sha256"
+	iterations = 310000
+	saltLen    = 16
+	keyLen     = 32
+)
+
+var (
+	InvalidHashErr = errors.New("invalid password hash")
+)
+
+var encoding = base64.RawStdEncoding
+
+// Hash returns an encoded salted hash of the password in the form
+// "pbkdf2-sha256$<iterations>$<salt>$<key>".
+func Hash(password string) (string, error) {
+	salt := make([]byte, saltLen)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", errors.Wrap(err, "password hash error")
+	}
+
+	key := pbkdf2([]byte(password), salt, iterations, keyLen)
+
+	return fmt.Sprintf("%s$%d$%s$%s", scheme, iterations, encoding.EncodeToString(salt), encoding.EncodeToString(key)), nil
+}
+
+// Verify reports whether password matches the encoded hash.
+func Verify(hash, password string) (ok bool, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != scheme {
+		return false, InvalidHashErr
+	}
+
+	iter, err := strconv.Atoi(parts[1])
+	if err != nil || iter < 1 {
+		return false, InvalidHashErr
+	}
+
+	salt, err := encoding.DecodeString(parts[2])
+	if err != nil {
+		return false, InvalidHashErr
+	}
+
+	key, err := encoding.DecodeString(parts[3])
+	if err != nil || len(key) == 0 {
+		return false, InvalidHashErr
+	}
+
+	other := pbkdf2([]byte(password), salt, iter, len(key))
+
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// pbkdf2 derives a key as described in RFC 8018, section 5.2.
+func pbkdf2(password, salt []byte, iter, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	blocks := (keyLen + hashLen - 1) / hashLen
+
+	var buf [4]byte
+	dk := make([]byte, 0, blocks*hashLen)
+	u := make([]byte, hashLen)
+
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(buf[:], uint32(block))
+		prf.Write(buf[:])
+		dk = prf.Sum(dk)
+		t := dk[len(dk)-hashLen:]
+		copy(u, t)
+
+		for n := 2; n <= iter; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = u[:0]
+			u = prf.Sum(u)
+			for i := range u {
+				t[i] ^= u[i]
+			}
+		}
+	}
+
+	return dk[:keyLen]
+}
@@ -0,0 +1,67 @@
+package password
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestPBKDF2(t *testing.T) {
+	// Test vectors from RFC 7914, section 11.
+	tests := []struct {
+		password string
+		salt     string
+		iter     int
+		keyLen   int
+		expected string
+	}{
+		{
+			password: "passwd",
+			salt:     "salt",
+			iter:     1,
+			keyLen:   64,
+			expected: "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783",
+		},
+		{
+			password: "Password",
+			salt:     "NaCl",
+			iter:     80000,
+			keyLen:   64,
+			expected: "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d",
+		},
+	}
+
+	for _, test := range tests {
+		key := pbkdf2([]byte(test.password), []byte(test.salt), test.iter, test.keyLen)
+
+		if hex.EncodeToString(key) != test.expected {
+			t.Errorf("Key for %s: expected %s, got %x", test.password, test.expected, key)
+		}
+	}
+}
+
+func TestHashAndVerify(t *testing.T) {
+	hash, err := Hash("secret password")
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	other, _ := Hash("secret password")
+	if hash == other {
+		t.Errorf("Hash: expected different salts, got the same hash twice")
+	}
+
+	ok, err := Verify(hash, "secret password")
+	if err != nil || !ok {
+		t.Errorf("Verify: expected match, got %v (%v)", ok, err)
+	}
+
+	ok, err = Verify(hash, "wrong password")
+	if err != nil || ok {
+		t.Errorf("Verify: expected mismatch, got %v (%v)", ok, err)
+	}
+
+	_, err = Verify("password1", "password1")
+	if err != InvalidHashErr {
+		t.Errorf("Error: expected '%v', got '%v'", InvalidHashErr, err)
+	}
+}
@@ -0,0 +1,496 @@
+// Package patch applies JSON Merge Patch (RFC 7396) and JSON Patch (RFC 6902) documents to values
+// through their JSON representation.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+)
+
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+var (
+	UnsupportedTypeErr = errors.NewKind(errors.Invalid, "unsupported patch media type")
+)
+
+type (
+	// Error reports a patch that can not be applied, Index and Op identify the failed operation of a JSON Patch.
+	Error struct {
+		Index  int
+		Op     string
+		Path   string
+		Reason string
+	}
+
+	// operation is a JSON Patch operation, Value is nil if the member is missing.
+	operation struct {
+		Op    string          `json:"op"`
+		Path  *string         `json:"path"`
+		From  *string         `json:"from"`
+		Value json.RawMessage `json:"value"`
+	}
+)
+
+// Types returns the media types of the supported patch formats, as listed in Accept-Patch headers.
+func Types() []string {
+	return []string{MergePatchType, JSONPatchType}
+}
+
+// Supported reports whether the media type is one of a supported patch format.
+func Supported(mediaType string) bool {
+	return mediaType == MergePatchType || mediaType == JSONPatchType
+}
+
+// Apply returns doc with the patch of the media type applied.
+// The patched document must still decode into T, unknown members are reported as errors.
+func Apply[T any](doc T, mediaType string, patch []byte) (patched T, err error) {
+	if !Supported(mediaType) {
+		return patched, errors.Wrap(UnsupportedTypeErr, mediaType)
+	}
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return patched, errors.Wrap(err, "patch error")
+	}
+
+	target, err := decode(raw)
+	if err != nil {
+		return patched, errors.Wrap(err, "patch error")
+	}
+
+	if mediaType == MergePatchType {
+		target, err = applyMerge(target, patch)
+	} else {
+		target, err = applyJSON(target, patch)
+	}
+	if err != nil {
+		return patched, err
+	}
+
+	raw, err = json.Marshal(target)
+	if err != nil {
+		return patched, errors.Wrap(err, "patch error")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+
+	err = dec.Decode(&patched)
+	if err != nil {
+		return patched, Error{Index: -1, Reason: fmt.Sprintf("patched document is not valid: %s", strings.TrimPrefix(err.Error(), "json: "))}
+	}
+
+	return patched, nil
+}
+
+func (e Error) Error() string {
+	if e.Op == "" {
+		return fmt.Sprintf("invalid patch: %s", e.Reason)
+	}
+
+	return fmt.Sprintf("invalid patch operation %d (%s %s): %s", e.Index, e.Op, e.Path, e.Reason)
+}
+
+func (e Error) Kind() errors.Kind {
+	return errors.Invalid
+}
+
+// applyMerge merges the patch into the target as described by RFC 7396, null members remove the target ones.
+func applyMerge(target any, patch []byte) (any, error) {
+	p, err := decode(patch)
+	if err != nil {
+		return nil, Error{Index: -1, Reason: fmt.Sprintf("malformed merge patch: %s", err)}
+	}
+
+	return merge(target, p), nil
+}
+
+func merge(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+
+		t[k] = merge(t[k], v)
+	}
+
+	return t
+}
+
+// applyJSON applies the operations of a JSON Patch in order, as described by RFC 6902.
+// Operations are atomic as a whole, the target is not returned if any of them fails.
+func applyJSON(target any, patch []byte) (any, error) {
+	var ops []operation
+
+	dec := json.NewDecoder(bytes.NewReader(patch))
+	dec.UseNumber()
+
+	err := dec.Decode(&ops)
+	if err != nil {
+		return nil, Error{Index: -1, Reason: fmt.Sprintf("malformed JSON patch, an array of operations is expected: %s", err)}
+	}
+
+	for i, op := range ops {
+		target, err = op.apply(target)
+		if err != nil {
+			e := Error{Index: i, Op: op.Op, Reason: err.Error()}
+			if op.Path != nil {
+				e.Path = *op.Path
+			}
+
+			return nil, e
+		}
+	}
+
+	return target, nil
+}
+
+func (op operation) apply(doc any) (any, error) {
+	if op.Path == nil {
+		return nil, fmt.Errorf("missing path")
+	}
+
+	path, err := pointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("missing value")
+		}
+
+		value, err := decode(op.Value)
+		if err != nil {
+			return nil, fmt.Errorf("malformed value: %s", err)
+		}
+
+		switch op.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			doc, err = remove(doc, path)
+			if err != nil {
+				return nil, err
+			}
+
+			return add(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+
+			if !equal(current, value) {
+				return nil, fmt.Errorf("value does not match")
+			}
+
+			return doc, nil
+		}
+
+	case "remove":
+		return remove(doc, path)
+
+	case "move", "copy":
+		if op.From == nil {
+			return nil, fmt.Errorf("missing from")
+		}
+
+		from, err := pointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		if op.Op == "copy" {
+			return add(doc, path, clone(value))
+		}
+
+		if len(path) > len(from) && isPrefix(from, path) {
+			return nil, fmt.Errorf("a value can not be moved into one of its children")
+		}
+
+		doc, err = remove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		return add(doc, path, value)
+
+	default:
+		return nil, fmt.Errorf("unknown operation")
+	}
+}
+
+// pointer parses a JSON Pointer (RFC 6901) into its reference tokens, the root is an empty slice.
+func pointer(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf("path '%s' is not a JSON pointer", s)
+	}
+
+	tokens := strings.Split(s[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+func get(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch c := doc.(type) {
+		case map[string]any:
+			v, ok := c[token]
+			if !ok {
+				return nil, fmt.Errorf("member '%s' not found", token)
+			}
+			doc = v
+
+		case []any:
+			i, err := index(token, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = c[i]
+
+		default:
+			return nil, fmt.Errorf("'%s' is not in an object or array", token)
+		}
+	}
+
+	return doc, nil
+}
+
+// add returns the document with the value added at the path.
+// Object members are set, array elements are inserted before the index or appended for "-".
+func add(doc any, path []string, value any) (any, error) {
+	return update(doc, path, func(parent any, token string) (any, error) {
+		switch c := parent.(type) {
+		case map[string]any:
+			c[token] = value
+			return c, nil
+
+		case []any:
+			if token == "-" {
+				return append(c, value), nil
+			}
+
+			i, err := index(token, len(c))
+			if err != nil {
+				return nil, err
+			}
+
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+			c[i] = value
+
+			return c, nil
+
+		default:
+			return nil, fmt.Errorf("'%s' can not be added to a value that is not an object or array", token)
+		}
+	}, value)
+}
+
+// remove returns the document without the value at the path, which must exist.
+func remove(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	return update(doc, path, func(parent any, token string) (any, error) {
+		switch c := parent.(type) {
+		case map[string]any:
+			if _, ok := c[token]; !ok {
+				return nil, fmt.Errorf("member '%s' not found", token)
+			}
+
+			delete(c, token)
+			return c, nil
+
+		case []any:
+			i, err := index(token, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+
+			return append(c[:i:i], c[i+1:]...), nil
+
+		default:
+			return nil, fmt.Errorf("'%s' is not in an object or array", token)
+		}
+	}, nil)
+}
+
+// update applies fn to the parent of the path and stores the container it returns back in the document.
+// root is the new document when the path is the root.
+func update(doc any, path []string, fn func(parent any, token string) (any, error), root any) (any, error) {
+	if len(path) == 0 {
+		return root, nil
+	}
+
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	child, err := get(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+
+	child, err = update(child, path[1:], fn, root)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c := doc.(type) {
+	case map[string]any:
+		c[path[0]] = child
+	case []any:
+		i, _ := index(path[0], len(c)-1)
+		c[i] = child
+	}
+
+	return doc, nil
+}
+
+// index parses an array index token, it must not be greater than max.
+// Leading zeros are not allowed.
+func index(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("'%s' is not an array index", token)
+	}
+
+	if i > max {
+		return 0, fmt.Errorf("index %d out of bounds", i)
+	}
+
+	return i, nil
+}
+
+func isPrefix(prefix, path []string) bool {
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// equal compares JSON values, numbers are equal if their values are.
+func equal(a, b any) bool {
+	switch x := a.(type) {
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+
+		for k, v := range x {
+			w, ok := y[k]
+			if !ok || !equal(v, w) {
+				return false
+			}
+		}
+
+		return true
+
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+
+		return true
+
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+
+		fx, errx := x.Float64()
+		fy, erry := y.Float64()
+
+		return errx == nil && erry == nil && fx == fy
+
+	default:
+		return a == b
+	}
+}
+
+func clone(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(x))
+		for k, v := range x {
+			c[k] = clone(v)
+		}
+
+		return c
+
+	case []any:
+		c := make([]any, len(x))
+		for i, v := range x {
+			c[i] = clone(v)
+		}
+
+		return c
+
+	default:
+		return v
+	}
+}
+
+// decode reads a JSON value keeping numbers as they were written.
+func decode(raw []byte) (v any, err error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	err = dec.Decode(&v)
+	if err != nil {
+		return nil, err
+	}
+
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+
+	return v, nil
+}
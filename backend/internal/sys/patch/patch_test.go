@@ -0,0 +1,158 @@
+package patch_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/patch"
+)
+
+type doc struct {
+	Name  string
+	Tags  []string
+	Due   *string
+	Count int
+	Meta  map[string]any `json:",omitempty"`
+}
+
+func TestApply(t *testing.T) {
+	due := "2024-01-01"
+	original := doc{Name: "Task", Tags: []string{"a", "b"}, Due: &due, Count: 1}
+
+	tests := []struct {
+		name      string
+		mediaType string
+		patch     string
+		expected  string
+		errIndex  int
+	}{
+		{
+			name:      "Merge sets and removes members",
+			mediaType: patch.MergePatchType,
+			patch:     `{"Name":"Renamed","Due":null}`,
+			expected:  `{"Name":"Renamed","Tags":["a","b"],"Due":null,"Count":1}`,
+		},
+		{
+			name:      "Merge replaces arrays and merges objects",
+			mediaType: patch.MergePatchType,
+			patch:     `{"Tags":["c"],"Meta":{"x":{"y":1}}}`,
+			expected:  `{"Name":"Task","Tags":["c"],"Due":"2024-01-01","Count":1,"Meta":{"x":{"y":1}}}`,
+		},
+		{
+			name:      "Merge unknown member",
+			mediaType: patch.MergePatchType,
+			patch:     `{"Nme":"Renamed"}`,
+			errIndex:  -1,
+		},
+		{
+			name:      "Merge wrong type",
+			mediaType: patch.MergePatchType,
+			patch:     `{"Count":"one"}`,
+			errIndex:  -1,
+		},
+		{
+			name:      "JSON patch operations",
+			mediaType: patch.JSONPatchType,
+			patch: `[
+				{"op":"test","path":"/Count","value":1.0},
+				{"op":"replace","path":"/Name","value":"Renamed"},
+				{"op":"add","path":"/Tags/1","value":"x"},
+				{"op":"add","path":"/Tags/-","value":"z"},
+				{"op":"remove","path":"/Tags/0"},
+				{"op":"copy","from":"/Tags/0","path":"/Tags/0"},
+				{"op":"move","from":"/Due","path":"/Name"},
+				{"op":"add","path":"/Due","value":null}
+			]`,
+			expected: `{"Name":"2024-01-01","Tags":["x","x","b","z"],"Due":null,"Count":1}`,
+		},
+		{
+			name:      "JSON patch escaped pointer",
+			mediaType: patch.JSONPatchType,
+			patch:     `[{"op":"add","path":"/Meta","value":{}},{"op":"add","path":"/Meta/a~1b~0c","value":1}]`,
+			expected:  `{"Name":"Task","Tags":["a","b"],"Due":"2024-01-01","Count":1,"Meta":{"a/b~c":1}}`,
+		},
+		{
+			name:      "JSON patch failed test",
+			mediaType: patch.JSONPatchType,
+			patch:     `[{"op":"replace","path":"/Name","value":"Renamed"},{"op":"test","path":"/Name","value":"Task"}]`,
+			errIndex:  1,
+		},
+		{
+			name:      "JSON patch missing member",
+			mediaType: patch.JSONPatchType,
+			patch:     `[{"op":"replace","path":"/Nme","value":"Renamed"}]`,
+			errIndex:  0,
+		},
+		{
+			name:      "JSON patch index out of bounds",
+			mediaType: patch.JSONPatchType,
+			patch:     `[{"op":"add","path":"/Tags/3","value":"x"}]`,
+			errIndex:  0,
+		},
+		{
+			name:      "JSON patch leading zero index",
+			mediaType: patch.JSONPatchType,
+			patch:     `[{"op":"remove","path":"/Tags/01"}]`,
+			errIndex:  0,
+		},
+		{
+			name:      "JSON patch move into child",
+			mediaType: patch.JSONPatchType,
+			patch:     `[{"op":"move","from":"/Tags","path":"/Tags/0"}]`,
+			errIndex:  0,
+		},
+		{
+			name:      "JSON patch missing value",
+			mediaType: patch.JSONPatchType,
+			patch:     `[{"op":"add","path":"/Name"}]`,
+			errIndex:  0,
+		},
+		{
+			name:      "JSON patch unknown operation",
+			mediaType: patch.JSONPatchType,
+			patch:     `[{"op":"rename","path":"/Name","value":"x"}]`,
+			errIndex:  0,
+		},
+		{
+			name:      "JSON patch not an array",
+			mediaType: patch.JSONPatchType,
+			patch:     `{"op":"remove","path":"/Name"}`,
+			errIndex:  -1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			patched, err := patch.Apply(original, test.mediaType, []byte(test.patch))
+
+			if test.expected == "" {
+				var pe patch.Error
+				if !errors.As(err, &pe) || pe.Index != test.errIndex {
+					t.Fatalf("Error: expected a patch error at %d, got '%v'", test.errIndex, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Error: unexpected '%v'", err)
+			}
+
+			got, _ := json.Marshal(patched)
+			if string(got) != test.expected {
+				t.Errorf("Patched: expected %s, got %s", test.expected, got)
+			}
+		})
+	}
+
+	if len(original.Tags) != 2 || original.Tags[0] != "a" || *original.Due != due {
+		t.Errorf("Original: expected it unchanged, got %v", original)
+	}
+}
+
+func TestApplyUnsupported(t *testing.T) {
+	_, err := patch.Apply(doc{}, "application/json", []byte(`{}`))
+	if !errors.Is(err, patch.UnsupportedTypeErr) {
+		t.Errorf("Error: expected '%v', got '%v'", patch.UnsupportedTypeErr, err)
+	}
+}
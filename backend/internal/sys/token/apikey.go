@@ -0,0 +1,44 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	// APIKeyPrefix marks the keys issued by the service so that they are easy to spot, i.e.: by secret scanners.
+	APIKeyPrefix = "stl_"
+
+	apiKeySize = 32
+	// apiKeyDisplayLen is the number of leading characters of a key kept to identify it.
+	apiKeyDisplayLen = len(APIKeyPrefix) + 8
+)
+
+// NewAPIKey returns a random API key along with the prefix used to identify it.
+func NewAPIKey() (key, prefix string, err error) {
+	b := make([]byte, apiKeySize)
+
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", "", err
+	}
+
+	key = APIKeyPrefix + encoding.EncodeToString(b)
+
+	return key, key[:apiKeyDisplayLen], nil
+}
+
+// HashAPIKey returns the hex encoded SHA-256 of the key.
+// API keys carry enough entropy for a plain hash to be stored in place of a slow password hash,
+// which also allows looking them up by it.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey reports whether the value has the shape of a key returned by NewAPIKey.
+func IsAPIKey(value string) bool {
+	return strings.HasPrefix(value, APIKeyPrefix) && len(value) == len(APIKeyPrefix)+encoding.EncodedLen(apiKeySize)
+}
@@ -0,0 +1,22 @@
+package token
+
+import "crypto/rand"
+
+const (
+	// WebhookSecretPrefix marks the secrets webhook deliveries are signed with.
+	WebhookSecretPrefix = "whsec_"
+
+	webhookSecretSize = 32
+)
+
+// NewWebhookSecret returns a random secret to sign webhook deliveries with.
+func NewWebhookSecret() (secret string, err error) {
+	b := make([]byte, webhookSecretSize)
+
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return WebhookSecretPrefix + encoding.EncodeToString(b), nil
+}
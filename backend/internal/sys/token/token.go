@@ -0,0 +1,110 @@
+// Package token signs and verifies compact JSON Web Tokens using HMAC-SHA256 (HS256).
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+)
+
+const (
+	AccessType  = "access"
+	RefreshType = "refresh"
+)
+
+var (
+	InvalidTokenErr = errors.New("invalid token")
+	ExpiredTokenErr = errors.New("expired token")
+	NoKeyErr        = errors.New("no signing key")
+)
+
+var (
+	encoding = base64.RawURLEncoding
+	header   = encoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+)
+
+type (
+	// Claims carried by the tokens.
+	// SessionID ties access and refresh tokens to a revocable session and
+	// ID identifies a single refresh token so that it can only be used once.
+	Claims struct {
+		Subject   string `json:"sub"`
+		SessionID string `json:"sid"`
+		ID        string `json:"jti,omitempty"`
+		Type      string `json:"typ"`
+		IssuedAt  int64  `json:"iat"`
+		ExpiresAt int64  `json:"exp"`
+	}
+)
+
+// NewClaims returns claims issued now and expiring after ttl.
+func NewClaims(typ, subject, sessionID, id string, now time.Time, ttl time.Duration) Claims {
+	return Claims{
+		Subject:   subject,
+		SessionID: sessionID,
+		ID:        id,
+		Type:      typ,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	}
+}
+
+// Sign returns the claims encoded as a signed token.
+func Sign(claims Claims, key []byte) (string, error) {
+	if len(key) == 0 {
+		return "", NoKeyErr
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", errors.Wrap(err, "sign token error")
+	}
+
+	unsigned := header + "." + encoding.EncodeToString(payload)
+
+	return unsigned + "." + encoding.EncodeToString(signature(unsigned, key)), nil
+}
+
+// Parse verifies the token signature, its type and its expiration and returns its claims.
+func Parse(tok, typ string, key []byte, now time.Time) (claims Claims, err error) {
+	if len(key) == 0 {
+		return claims, NoKeyErr
+	}
+
+	parts := strings.Split(tok, ".")
+	if len(parts) != 3 || parts[0] != header {
+		return claims, InvalidTokenErr
+	}
+
+	sig, err := encoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, signature(parts[0]+"."+parts[1], key)) {
+		return claims, InvalidTokenErr
+	}
+
+	payload, err := encoding.DecodeString(parts[1])
+	if err != nil {
+		return claims, InvalidTokenErr
+	}
+
+	err = json.Unmarshal(payload, &claims)
+	if err != nil || claims.Type != typ || claims.Subject == "" {
+		return Claims{}, InvalidTokenErr
+	}
+
+	if now.Unix() >= claims.ExpiresAt {
+		return Claims{}, ExpiredTokenErr
+	}
+
+	return claims, nil
+}
+
+func signature(unsigned string, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(unsigned))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,111 @@
+package token
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndParse(t *testing.T) {
+	key := []byte("test-key")
+	now := time.Now()
+
+	tok, err := Sign(NewClaims(AccessType, "user", "session", "", now, time.Minute), key)
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	tampered := strings.Split(tok, ".")
+	tampered[1] = encoding.EncodeToString([]byte(`{"sub":"admin","sid":"session","typ":"access","exp":9999999999}`))
+
+	tests := []struct {
+		name        string
+		token       string
+		typ         string
+		key         []byte
+		now         time.Time
+		expectedErr error
+	}{
+		{
+			name:  "Valid token",
+			token: tok,
+			typ:   AccessType,
+			key:   key,
+			now:   now,
+		},
+		{
+			name:        "Wrong key",
+			token:       tok,
+			typ:         AccessType,
+			key:         []byte("other-key"),
+			now:         now,
+			expectedErr: InvalidTokenErr,
+		},
+		{
+			name:        "Wrong type",
+			token:       tok,
+			typ:         RefreshType,
+			key:         key,
+			now:         now,
+			expectedErr: InvalidTokenErr,
+		},
+		{
+			name:        "Tampered claims",
+			token:       strings.Join(tampered, "."),
+			typ:         AccessType,
+			key:         key,
+			now:         now,
+			expectedErr: InvalidTokenErr,
+		},
+		{
+			name:        "Expired",
+			token:       tok,
+			typ:         AccessType,
+			key:         key,
+			now:         now.Add(2 * time.Minute),
+			expectedErr: ExpiredTokenErr,
+		},
+		{
+			name:        "Malformed",
+			token:       "not-a-token",
+			typ:         AccessType,
+			key:         key,
+			now:         now,
+			expectedErr: InvalidTokenErr,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims, err := Parse(test.token, test.typ, test.key, test.now)
+
+			if err != test.expectedErr {
+				t.Fatalf("Error: expected '%v', got '%v'", test.expectedErr, err)
+			}
+
+			if err == nil && (claims.Subject != "user" || claims.SessionID != "session") {
+				t.Errorf("Claims: expected user and session, got %+v", claims)
+			}
+		})
+	}
+}
+
+func TestAPIKey(t *testing.T) {
+	key, prefix, err := NewAPIKey()
+	if err != nil {
+		t.Fatalf("Error: unexpected '%v'", err)
+	}
+
+	if !IsAPIKey(key) || !strings.HasPrefix(key, prefix) {
+		t.Errorf("Key: unexpected shape %s (prefix %s)", key, prefix)
+	}
+
+	other, _, _ := NewAPIKey()
+	if HashAPIKey(key) != HashAPIKey(key) || HashAPIKey(key) == HashAPIKey(other) {
+		t.Errorf("Hash: expected stable and distinct hashes")
+	}
+
+	if IsAPIKey("stl_short") || IsAPIKey(strings.Replace(key, APIKeyPrefix, "xyz_", 1)) {
+		t.Errorf("IsAPIKey: expected false for malformed keys")
+	}
+}
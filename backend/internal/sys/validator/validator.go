@@ -27,22 +27,30 @@ func NewValidator() Validator {
 
 func newValMsg() *valMsg {
 	return &valMsg{
-		RequiredErrMsg:   "required",
-		MinLengthErrMsg:  "too short",
-		MaxLengthErrMsg:  "too long",
-		NotAllowedErrMsg: "not in allowed list",
-		NotEmailErrMsg:   "not an email address",
-		NoMatchErrMsg:    "confirmation does not match",
+		RequiredErrMsg:    "required",
+		MinLengthErrMsg:   "too short",
+		MaxLengthErrMsg:   "too long",
+		NotAllowedErrMsg:  "not in allowed list",
+		NotEmailErrMsg:    "not an email address",
+		NoMatchErrMsg:     "confirmation does not match",
+		OutOfRangeErrMsg:  "out of range",
+		NotUsernameErrMsg: "only letters, digits, '.', '_' and '-' allowed",
+		TakenErrMsg:       "already taken",
+		NotValidErrMsg:    "not a valid value",
 	}
 }
 
 type valMsg struct {
-	RequiredErrMsg   string
-	MinLengthErrMsg  string
-	MaxLengthErrMsg  string
-	NotAllowedErrMsg string
-	NotEmailErrMsg   string
-	NoMatchErrMsg    string
+	RequiredErrMsg    string
+	MinLengthErrMsg   string
+	MaxLengthErrMsg   string
+	NotAllowedErrMsg  string
+	NotEmailErrMsg    string
+	NoMatchErrMsg     string
+	OutOfRangeErrMsg  string
+	NotUsernameErrMsg string
+	TakenErrMsg       string
+	NotValidErrMsg    string
 }
 
 // ValidateRequired value.
@@ -61,11 +69,21 @@ func (v *Validator) ValidateMaxLength(val string, max int) (ok bool) {
 	return utf8.RuneCountInString(val) <= max
 }
 
+// ValidateRange value.
+func (v *Validator) ValidateRange(val, min, max int) (ok bool) {
+	return val >= min && val <= max
+}
+
 // ValidateEmail value.
 func (v *Validator) ValidateEmail(val string) (ok bool) {
 	return len(val) < 254 && v.Regex.EmailRegex.MatchString(val)
 }
 
+// ValidateUsername value.
+func (v *Validator) ValidateUsername(val string) (ok bool) {
+	return v.Regex.UsernameRegex.MatchString(val)
+}
+
 // ValidateConfirmation value.
 func (v *Validator) ValidateConfirmation(val, confirmation string) (ok bool) {
 	return val == confirmation
@@ -96,6 +114,7 @@ type (
 		MatchFirstCap *regexp.Regexp
 		MatchAllCap   *regexp.Regexp
 		EmailRegex    *regexp.Regexp
+		UsernameRegex *regexp.Regexp
 	}
 )
 
@@ -114,6 +133,7 @@ func regexInstance() *Regex {
 				MatchFirstCap: regexp.MustCompile("(.)([A-Z][a-z]+)"),
 				MatchAllCap:   regexp.MustCompile("([a-z0-9])([A-Z])"),
 				EmailRegex:    regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+\\/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$"),
+				UsernameRegex: regexp.MustCompile("^[a-zA-Z0-9._-]+$"),
 			}
 
 		}
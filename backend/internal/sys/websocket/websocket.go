@@ -0,0 +1,444 @@
+// Package websocket implements the server side of the WebSocket protocol (RFC 6455) over net/http:
+// the opening handshake of an HTTP request and the framing of the hijacked connection.
+// Extensions and subprotocols are not negotiated.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+)
+
+// Opcode is the type of a frame.
+type Opcode byte
+
+const (
+	ContinuationFrame Opcode = 0x0
+	TextMessage       Opcode = 0x1
+	BinaryMessage     Opcode = 0x2
+	CloseMessage      Opcode = 0x8
+	PingMessage       Opcode = 0x9
+	PongMessage       Opcode = 0xA
+)
+
+// Close codes, see RFC 6455 section 7.4.
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseUnsupportedData = 1003
+	CloseNoStatus        = 1005
+	CloseAbnormal        = 1006
+	CloseInvalidPayload  = 1007
+	ClosePolicyViolation = 1008
+	CloseTooBig          = 1009
+	CloseInternalError   = 1011
+	CloseTryAgainLater   = 1013
+)
+
+const (
+	Version = "13"
+
+	// acceptGUID is appended to the key of the client to compute the accept key of the handshake.
+	acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	finBit  = 0x80
+	rsvBits = 0x70
+	maskBit = 0x80
+
+	maxControlPayload = 125
+	defaultReadLimit  = 64 << 10
+	closeTimeout      = 5 * time.Second
+)
+
+var (
+	BadHandshakeErr       = errors.NewKind(errors.Invalid, "not a websocket handshake")
+	UnsupportedVersionErr = errors.NewKind(errors.Invalid, "unsupported websocket version")
+	ClosedErr             = errors.New("websocket closed")
+)
+
+type (
+	// Conn is a server side WebSocket connection.
+	// Messages are read by a single goroutine, writes are safe for concurrent use.
+	Conn struct {
+		conn      net.Conn
+		br        *bufio.Reader
+		readLimit int64
+		idle      time.Duration
+		wmu       sync.Mutex
+		closeSent bool
+	}
+
+	// CloseError is the close frame ending the connection, sent by the peer or by this side on a protocol error.
+	CloseError struct {
+		Code int
+		Text string
+	}
+)
+
+// Upgrade completes the opening handshake of the request and takes over its connection.
+// Requests that are not a valid handshake are left untouched and an error is returned, the response is up to
+// the caller, with the supported version header already set if that was the issue.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet ||
+		!headerHas(r.Header, "Connection", "upgrade") ||
+		!headerHas(r.Header, "Upgrade", "websocket") {
+		return nil, BadHandshakeErr
+	}
+
+	if r.Header.Get("Sec-WebSocket-Version") != Version {
+		w.Header().Set("Sec-WebSocket-Version", Version)
+		return nil, UnsupportedVersionErr
+	}
+
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if nonce, err := base64.StdEncoding.DecodeString(key); err != nil || len(nonce) != 16 {
+		return nil, errors.Wrap(BadHandshakeErr, "invalid Sec-WebSocket-Key")
+	}
+
+	nc, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, errors.Wrap(err, "websocket upgrade error")
+	}
+
+	// Handshake response is written straight to the connection, the response writer is no longer usable
+	_, err = fmt.Fprintf(brw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", AcceptKey(key))
+	if err == nil {
+		err = brw.Flush()
+	}
+	if err != nil {
+		nc.Close()
+		return nil, errors.Wrap(err, "websocket upgrade error")
+	}
+
+	return newConn(nc, brw.Reader), nil
+}
+
+// AcceptKey returns the value of the Sec-WebSocket-Accept header answering the key of the client.
+func AcceptKey(key string) string {
+	h := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+func newConn(nc net.Conn, br *bufio.Reader) *Conn {
+	if br == nil {
+		br = bufio.NewReader(nc)
+	}
+
+	return &Conn{
+		conn:      nc,
+		br:        br,
+		readLimit: defaultReadLimit,
+	}
+}
+
+// SetReadLimit sets the maximum size of the messages read, larger ones close the connection.
+func (c *Conn) SetReadLimit(limit int64) {
+	c.readLimit = limit
+}
+
+// SetIdleTimeout sets how long reads wait for the next frame, zero for ever.
+// Peers answer pings with pongs, so pinging them more often keeps idle but live connections open.
+func (c *Conn) SetIdleTimeout(d time.Duration) {
+	c.idle = d
+}
+
+// SetWriteDeadline sets the deadline of the writes on the connection.
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+// RemoteAddr returns the network address of the peer.
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// ReadMessage returns the next text or binary message, joining its fragments.
+// Pings are answered and pongs skipped. It returns a CloseError once the connection is closed by either side,
+// protocol errors of the peer close it with the matching code.
+func (c *Conn) ReadMessage() (op Opcode, msg []byte, err error) {
+	for {
+		if c.idle > 0 {
+			c.conn.SetReadDeadline(time.Now().Add(c.idle))
+		}
+
+		fin, frameOp, payload, err := c.readFrame(int64(len(msg)))
+		if err != nil {
+			return op, nil, c.fail(err)
+		}
+
+		switch frameOp {
+		case PingMessage:
+			err = c.WriteControl(PongMessage, payload)
+			if err != nil && err != ClosedErr {
+				return op, nil, err
+			}
+			continue
+
+		case PongMessage:
+			continue
+
+		case CloseMessage:
+			return op, nil, c.closeReceived(payload)
+
+		case ContinuationFrame:
+			if op == 0 {
+				return op, nil, c.fail(CloseError{Code: CloseProtocolError, Text: "unexpected continuation frame"})
+			}
+
+		case TextMessage, BinaryMessage:
+			if op != 0 {
+				return op, nil, c.fail(CloseError{Code: CloseProtocolError, Text: "unfinished fragmented message"})
+			}
+			op = frameOp
+
+		default:
+			return op, nil, c.fail(CloseError{Code: CloseProtocolError, Text: "reserved opcode"})
+		}
+
+		msg = append(msg, payload...)
+
+		if !fin {
+			continue
+		}
+
+		if op == TextMessage && !utf8.Valid(msg) {
+			return op, nil, c.fail(CloseError{Code: CloseInvalidPayload, Text: "invalid UTF-8 text"})
+		}
+
+		return op, msg, nil
+	}
+}
+
+// readFrame reads a frame whose payload adds to the read bytes of the message being read.
+// Frames of clients must be masked, their payload is returned unmasked.
+func (c *Conn) readFrame(read int64) (fin bool, op Opcode, payload []byte, err error) {
+	var header [2]byte
+	_, err = io.ReadFull(c.br, header[:])
+	if err != nil {
+		return fin, op, nil, err
+	}
+
+	fin = header[0]&finBit != 0
+	op = Opcode(header[0] & 0x0f)
+
+	if header[0]&rsvBits != 0 {
+		return fin, op, nil, CloseError{Code: CloseProtocolError, Text: "reserved bits set"}
+	}
+
+	if header[1]&maskBit == 0 {
+		return fin, op, nil, CloseError{Code: CloseProtocolError, Text: "unmasked client frame"}
+	}
+
+	length := int64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		_, err = io.ReadFull(c.br, ext[:])
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		_, err = io.ReadFull(c.br, ext[:])
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+	if err != nil {
+		return fin, op, nil, err
+	}
+
+	if op >= CloseMessage && (!fin || length > maxControlPayload) {
+		return fin, op, nil, CloseError{Code: CloseProtocolError, Text: "invalid control frame"}
+	}
+
+	if length < 0 || (op < CloseMessage && read+length > c.readLimit) {
+		return fin, op, nil, CloseError{Code: CloseTooBig, Text: "message too big"}
+	}
+
+	var mask [4]byte
+	_, err = io.ReadFull(c.br, mask[:])
+	if err != nil {
+		return fin, op, nil, err
+	}
+
+	payload = make([]byte, length)
+	_, err = io.ReadFull(c.br, payload)
+	if err != nil {
+		return fin, op, nil, err
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return fin, op, payload, nil
+}
+
+// closeReceived answers the close frame of the peer, echoing its code, and closes the connection.
+func (c *Conn) closeReceived(payload []byte) error {
+	ce := CloseError{Code: CloseNoStatus}
+
+	if len(payload) >= 2 {
+		ce.Code = int(binary.BigEndian.Uint16(payload))
+		ce.Text = string(payload[2:])
+	}
+
+	if len(payload) == 1 || (len(payload) >= 2 && !validCloseCode(ce.Code)) {
+		return c.fail(CloseError{Code: CloseProtocolError, Text: "invalid close frame"})
+	}
+
+	if !utf8.ValidString(ce.Text) {
+		return c.fail(CloseError{Code: CloseInvalidPayload, Text: "invalid UTF-8 close reason"})
+	}
+
+	code := ce.Code
+	if code == CloseNoStatus {
+		code = CloseNormal
+	}
+
+	c.WriteClose(code, "")
+	c.conn.Close()
+
+	return ce
+}
+
+// fail closes the connection after a read error, protocol errors are sent to the peer.
+func (c *Conn) fail(err error) error {
+	var ce CloseError
+	if !errors.As(err, &ce) {
+		ce = CloseError{Code: CloseAbnormal, Text: err.Error()}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			ce.Text = "connection closed without close frame"
+		}
+	} else {
+		c.WriteClose(ce.Code, ce.Text)
+	}
+
+	c.conn.Close()
+
+	return ce
+}
+
+// WriteMessage writes a text or binary message in a single frame.
+func (c *Conn) WriteMessage(op Opcode, data []byte) error {
+	if op != TextMessage && op != BinaryMessage {
+		return errors.Newf("invalid message opcode %d", op)
+	}
+
+	return c.writeFrame(op, data)
+}
+
+// WriteControl writes a ping or pong frame.
+func (c *Conn) WriteControl(op Opcode, data []byte) error {
+	if (op != PingMessage && op != PongMessage) || len(data) > maxControlPayload {
+		return errors.Newf("invalid control frame %d", op)
+	}
+
+	return c.writeFrame(op, data)
+}
+
+// WriteClose starts the closing handshake, no other frame can be written afterwards.
+// The peer is expected to answer and close, so reads go on until they return a CloseError.
+func (c *Conn) WriteClose(code int, text string) error {
+	payload := make([]byte, 2, 2+len(text))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, text...)
+
+	if len(payload) > maxControlPayload {
+		payload = payload[:maxControlPayload]
+	}
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	if c.closeSent {
+		return ClosedErr
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(closeTimeout))
+	c.closeSent = true
+
+	return c.write(CloseMessage, payload)
+}
+
+// Close closes the connection without closing handshake.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Conn) writeFrame(op Opcode, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	if c.closeSent {
+		return ClosedErr
+	}
+
+	return c.write(op, payload)
+}
+
+// write writes an unfragmented, unmasked frame, the write lock must be held.
+func (c *Conn) write(op Opcode, payload []byte) error {
+	header := make([]byte, 2, 10)
+	header[0] = finBit | byte(op)
+
+	length := len(payload)
+	switch {
+	case length <= 125:
+		header[1] = byte(length)
+	case length <= 0xffff:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+
+	_, err := (&net.Buffers{header, payload}).WriteTo(c.conn)
+	return err
+}
+
+func (e CloseError) Error() string {
+	if e.Text == "" {
+		return fmt.Sprintf("websocket closed (%d)", e.Code)
+	}
+
+	return fmt.Sprintf("websocket closed (%d): %s", e.Code, e.Text)
+}
+
+// validCloseCode returns true if the code can be sent in a close frame.
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1014:
+		return true
+	case code >= 3000 && code <= 4999:
+		return true
+	}
+
+	return false
+}
+
+// headerHas returns true if the comma separated values of the header contain the token, ignoring case.
+func headerHas(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, v := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
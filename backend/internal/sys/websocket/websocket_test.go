@@ -0,0 +1,227 @@
+package websocket_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vanillazen/stl/backend/internal/sys/errors"
+	"github.com/vanillazen/stl/backend/internal/sys/websocket"
+)
+
+const testKey = "dGhlIHNhbXBsZSBub25jZQ=="
+
+func TestAcceptKey(t *testing.T) {
+	// Sample of RFC 6455 section 1.3
+	if key := websocket.AcceptKey(testKey); key != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Accept key: expected the one of the RFC, got '%s'", key)
+	}
+}
+
+func TestUpgradeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   map[string]string
+		expected error
+	}{
+		{
+			name:     "Not an upgrade",
+			header:   map[string]string{"Sec-WebSocket-Version": "13", "Sec-WebSocket-Key": testKey},
+			expected: websocket.BadHandshakeErr,
+		},
+		{
+			name:     "Unsupported version",
+			header:   map[string]string{"Connection": "keep-alive, Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "8", "Sec-WebSocket-Key": testKey},
+			expected: websocket.UnsupportedVersionErr,
+		},
+		{
+			name:     "Invalid key",
+			header:   map[string]string{"Connection": "Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "13", "Sec-WebSocket-Key": "short"},
+			expected: websocket.BadHandshakeErr,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for name, value := range test.header {
+				r.Header.Set(name, value)
+			}
+
+			_, err := websocket.Upgrade(httptest.NewRecorder(), r)
+			if !errors.Is(err, test.expected) || errors.KindOf(err) != errors.Invalid {
+				t.Errorf("Error: expected '%v', got '%v'", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestConn(t *testing.T) {
+	closed := make(chan error, 1)
+
+	// Echo server with a read limit of 16 bytes
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			t.Errorf("Upgrade: unexpected '%v'", err)
+			return
+		}
+		conn.SetReadLimit(16)
+
+		for {
+			op, msg, err := conn.ReadMessage()
+			if err != nil {
+				closed <- err
+				return
+			}
+
+			conn.WriteMessage(op, msg)
+		}
+	}))
+	defer srv.Close()
+
+	t.Run("Echo", func(t *testing.T) {
+		nc, br := dial(t, srv.URL)
+		defer nc.Close()
+
+		// Fragmented message with a ping in between
+		writeFrame(t, nc, false, websocket.TextMessage, "Hel", true)
+		writeFrame(t, nc, true, websocket.PingMessage, "hi", true)
+		writeFrame(t, nc, true, websocket.ContinuationFrame, "lo", true)
+
+		expectFrame(t, br, websocket.PongMessage, "hi")
+		expectFrame(t, br, websocket.TextMessage, "Hello")
+
+		// Closing handshake
+		writeFrame(t, nc, true, websocket.CloseMessage, closePayload(websocket.CloseNormal, "bye"), true)
+		expectFrame(t, br, websocket.CloseMessage, closePayload(websocket.CloseNormal, ""))
+		expectClosed(t, closed, websocket.CloseNormal)
+	})
+
+	t.Run("Unmasked", func(t *testing.T) {
+		nc, br := dial(t, srv.URL)
+		defer nc.Close()
+
+		writeFrame(t, nc, true, websocket.TextMessage, "Hello", false)
+		expectFrame(t, br, websocket.CloseMessage, closePayload(websocket.CloseProtocolError, "unmasked client frame"))
+		expectClosed(t, closed, websocket.CloseProtocolError)
+	})
+
+	t.Run("Too big", func(t *testing.T) {
+		nc, br := dial(t, srv.URL)
+		defer nc.Close()
+
+		writeFrame(t, nc, false, websocket.BinaryMessage, "0123456789", true)
+		writeFrame(t, nc, true, websocket.ContinuationFrame, "0123456789", true)
+		expectFrame(t, br, websocket.CloseMessage, closePayload(websocket.CloseTooBig, "message too big"))
+		expectClosed(t, closed, websocket.CloseTooBig)
+	})
+
+	t.Run("Invalid text", func(t *testing.T) {
+		nc, br := dial(t, srv.URL)
+		defer nc.Close()
+
+		writeFrame(t, nc, true, websocket.TextMessage, "\xff\xfe", true)
+		expectFrame(t, br, websocket.CloseMessage, closePayload(websocket.CloseInvalidPayload, "invalid UTF-8 text"))
+		expectClosed(t, closed, websocket.CloseInvalidPayload)
+	})
+}
+
+// dial opens a connection to the server and completes the handshake.
+func dial(t *testing.T, url string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+
+	nc, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+
+	_, err = io.WriteString(nc, "GET / HTTP/1.1\r\nHost: test\r\n"+
+		"Connection: Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: "+testKey+"\r\n\r\n")
+	if err != nil {
+		t.Fatalf("Handshake: %v", err)
+	}
+
+	br := bufio.NewReader(nc)
+	res, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("Handshake: %v", err)
+	}
+
+	if res.StatusCode != http.StatusSwitchingProtocols || res.Header.Get("Sec-WebSocket-Accept") != websocket.AcceptKey(testKey) {
+		t.Fatalf("Handshake: expected switching protocols with the accept key, got %d %v", res.StatusCode, res.Header)
+	}
+
+	return nc, br
+}
+
+// writeFrame writes a client frame, clients are required to mask them.
+func writeFrame(t *testing.T, w io.Writer, fin bool, op websocket.Opcode, payload string, masked bool) {
+	t.Helper()
+
+	b0 := byte(op)
+	if fin {
+		b0 |= 0x80
+	}
+
+	b1 := byte(len(payload))
+	mask := []byte{1, 2, 3, 4}
+	data := []byte(payload)
+	if masked {
+		b1 |= 0x80
+		for i := range data {
+			data[i] ^= mask[i%4]
+		}
+	}
+
+	frame := []byte{b0, b1}
+	if masked {
+		frame = append(frame, mask...)
+	}
+
+	_, err := w.Write(append(frame, data...))
+	if err != nil {
+		t.Fatalf("Write frame: %v", err)
+	}
+}
+
+// expectFrame reads a server frame, which are unmasked and small in these tests.
+func expectFrame(t *testing.T, r io.Reader, op websocket.Opcode, payload string) {
+	t.Helper()
+
+	header := make([]byte, 2)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		t.Fatalf("Read frame: %v", err)
+	}
+
+	data := make([]byte, header[1]&0x7f)
+	_, err = io.ReadFull(r, data)
+	if err != nil {
+		t.Fatalf("Read frame: %v", err)
+	}
+
+	if header[0] != 0x80|byte(op) || header[1]&0x80 != 0 || string(data) != payload {
+		t.Errorf("Frame: expected op %d '%q', got %x '%q'", op, payload, header, data)
+	}
+}
+
+func expectClosed(t *testing.T, closed chan error, code int) {
+	t.Helper()
+
+	var ce websocket.CloseError
+	if err := <-closed; !errors.As(err, &ce) || ce.Code != code {
+		t.Errorf("Close: expected code %d, got '%v'", code, err)
+	}
+}
+
+func closePayload(code int, text string) string {
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	return string(append(payload, text...))
+}
@@ -0,0 +1,8 @@
+package transport
+
+type (
+	AcceptInvitationReq struct {
+		UserID       string
+		InvitationID string
+	}
+)
@@ -0,0 +1,26 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	AcceptInvitationRes struct {
+		ServiceRes
+		ListID string
+		Member
+	}
+)
+
+func NewAcceptInvitationRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) AcceptInvitationRes {
+	return AcceptInvitationRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func (res *AcceptInvitationRes) FromMember(m model.Member) {
+	res.ListID = m.ListID.String()
+	res.Member = NewMember(m)
+}
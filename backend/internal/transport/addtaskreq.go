@@ -0,0 +1,37 @@
+package transport
+
+import (
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+)
+
+type (
+	AddTaskReq struct {
+		UserID      string
+		ListID      string
+		Name        string
+		Description string
+		Category    []string
+		Tags        []string
+		Location    []string
+		Done        bool
+		DueAt       *time.Time
+		Priority    int
+		Position    int
+	}
+)
+
+func (req AddTaskReq) ToTask() model.Task {
+	return model.Task{
+		Name:        req.Name,
+		Description: req.Description,
+		Category:    req.Category,
+		Tags:        req.Tags,
+		Location:    req.Location,
+		Done:        req.Done,
+		DueAt:       timeVal(req.DueAt),
+		Priority:    model.Priority(req.Priority),
+		Position:    req.Position,
+	}
+}
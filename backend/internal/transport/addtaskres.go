@@ -0,0 +1,24 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	AddTaskRes struct {
+		ServiceRes
+		Task
+	}
+)
+
+func NewAddTaskRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) AddTaskRes {
+	return AddTaskRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func (res *AddTaskRes) FromTask(m model.Task) {
+	res.Task = NewTask(m)
+}
@@ -0,0 +1,20 @@
+package transport
+
+import "github.com/vanillazen/stl/backend/internal/domain/model"
+
+type (
+	AddTasksReq struct {
+		UserID string
+		ListID string
+		Tasks  []AddTaskReq
+	}
+)
+
+func (req AddTasksReq) ToTasks() []model.Task {
+	var tasks []model.Task
+	for _, t := range req.Tasks {
+		tasks = append(tasks, t.ToTask())
+	}
+
+	return tasks
+}
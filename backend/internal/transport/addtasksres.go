@@ -0,0 +1,27 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	AddTasksRes struct {
+		ServiceRes
+		Tasks []Task
+	}
+)
+
+func NewAddTasksRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) AddTasksRes {
+	return AddTasksRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func (res *AddTasksRes) FromTasks(mm []model.Task) {
+	res.Tasks = []Task{}
+	for _, m := range mm {
+		res.Tasks = append(res.Tasks, NewTask(m))
+	}
+}
@@ -0,0 +1,34 @@
+package transport
+
+import (
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+)
+
+type (
+	// APIKey describes a key without the key itself, which is only returned on creation.
+	APIKey struct {
+		ID         string
+		Name       string
+		Prefix     string
+		Scope      string
+		LastUsedAt *time.Time
+		RevokedAt  *time.Time
+		CreatedAt  time.Time
+		UpdatedAt  time.Time
+	}
+)
+
+func NewAPIKey(m model.APIKey) APIKey {
+	return APIKey{
+		ID:         m.ID.String(),
+		Name:       m.Name,
+		Prefix:     m.Prefix,
+		Scope:      m.Scope,
+		LastUsedAt: timePtr(m.LastUsedAt),
+		RevokedAt:  timePtr(m.RevokedAt),
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+}
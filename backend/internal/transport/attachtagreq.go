@@ -0,0 +1,18 @@
+package transport
+
+import "github.com/vanillazen/stl/backend/internal/domain/model"
+
+type (
+	AttachTagReq struct {
+		UserID string
+		ListID string
+		TaskID string
+		Name   string
+	}
+)
+
+func (req AttachTagReq) ToTag() model.Tag {
+	return model.Tag{
+		Name: req.Name,
+	}
+}
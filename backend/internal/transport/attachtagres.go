@@ -0,0 +1,24 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	AttachTagRes struct {
+		ServiceRes
+		Tag
+	}
+)
+
+func NewAttachTagRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) AttachTagRes {
+	return AttachTagRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func (res *AttachTagRes) FromTag(m model.Tag) {
+	res.Tag = NewTag(m)
+}
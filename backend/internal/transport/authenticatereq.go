@@ -0,0 +1,10 @@
+package transport
+
+type (
+	// AuthenticateReq carries the credential presented by the caller,
+	// either an access token or an API key.
+	AuthenticateReq struct {
+		AccessToken string
+		APIKey      string
+	}
+)
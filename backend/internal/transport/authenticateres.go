@@ -0,0 +1,24 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	// AuthenticateRes identifies the caller.
+	// SessionID is set for access tokens, APIKeyID and Scope for API keys.
+	AuthenticateRes struct {
+		ServiceRes
+		UserID    string
+		SessionID string
+		APIKeyID  string
+		Scope     string
+	}
+)
+
+func NewAuthenticateRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) AuthenticateRes {
+	return AuthenticateRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
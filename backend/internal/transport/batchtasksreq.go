@@ -0,0 +1,74 @@
+package transport
+
+import (
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+)
+
+type (
+	// BatchTasksReq applies many operations to the tasks of a list at once.
+	// Operations are all-or-nothing unless BestEffort is set, in which case each one succeeds or fails on its own.
+	BatchTasksReq struct {
+		UserID     string `json:"-"`
+		ListID     string `json:"-"`
+		BestEffort bool
+		Ops        []TaskOpReq
+	}
+
+	// TaskOpReq is an operation of a batch, one of "create", "update", "delete" or "move".
+	// TaskID addresses the task of all operations but creations, which get a new one.
+	// Task fields are used by creations and updates, moves only use Position, zero to move to the end.
+	TaskOpReq struct {
+		Op          string
+		TaskID      string
+		ToListID    string
+		Name        string
+		Description string
+		Category    []string
+		Tags        []string
+		Location    []string
+		Done        bool
+		DueAt       *time.Time
+		Priority    int
+		Position    int
+		// Version the task must be at, zero for any
+		Version int
+	}
+)
+
+func (req BatchTasksReq) ToTaskOps() []model.TaskOp {
+	var ops []model.TaskOp
+	for _, op := range req.Ops {
+		ops = append(ops, op.ToTaskOp(req.ListID))
+	}
+
+	return ops
+}
+
+func (req TaskOpReq) ToTaskOp(listID string) model.TaskOp {
+	taskID := req.TaskID
+	if model.TaskOpKind(req.Op) == model.CreateTaskOp {
+		taskID = ""
+	}
+
+	return model.TaskOp{
+		Kind: model.TaskOpKind(req.Op),
+		Task: model.Task{
+			ID:          model.NewID(uuid.UUID{Val: taskID}),
+			ListID:      model.NewID(uuid.UUID{Val: listID}),
+			Name:        req.Name,
+			Description: req.Description,
+			Category:    req.Category,
+			Tags:        req.Tags,
+			Location:    req.Location,
+			Done:        req.Done,
+			DueAt:       timeVal(req.DueAt),
+			Priority:    model.Priority(req.Priority),
+			Position:    req.Position,
+			Audit:       model.Audit{Version: req.Version},
+		},
+		ToListID: req.ToListID,
+	}
+}
@@ -0,0 +1,40 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	BatchTasksRes struct {
+		ServiceRes
+		Results []TaskOpRes
+	}
+
+	// TaskOpRes is the outcome of an operation of a batch, the task as left by it unless it was deleted.
+	// A failed operation has no task and its error and validation errors are the ones of its ServiceRes.
+	TaskOpRes struct {
+		ServiceRes
+		Op   string
+		Task *Task
+	}
+)
+
+func NewBatchTasksRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) BatchTasksRes {
+	return BatchTasksRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func NewTaskOpRes(op model.TaskOpKind, valErrSet v.ValErrorSet, err error, cfg *config.Config) TaskOpRes {
+	return TaskOpRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+		Op:         string(op),
+	}
+}
+
+func (res *TaskOpRes) FromTask(m model.Task) {
+	task := NewTask(m)
+	res.Task = &task
+}
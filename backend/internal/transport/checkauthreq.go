@@ -0,0 +1,11 @@
+package transport
+
+type (
+	// CheckAuthReq identifies the session or API key a caller authenticated with,
+	// SessionID is set for access tokens and APIKeyID for API keys.
+	CheckAuthReq struct {
+		UserID    string
+		SessionID string
+		APIKeyID  string
+	}
+)
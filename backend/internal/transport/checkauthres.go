@@ -0,0 +1,18 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	CheckAuthRes struct {
+		ServiceRes
+	}
+)
+
+func NewCheckAuthRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) CheckAuthRes {
+	return CheckAuthRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
@@ -0,0 +1,30 @@
+package transport
+
+import (
+	"strings"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+)
+
+type (
+	CreateAPIKeyReq struct {
+		UserID string
+		Name   string
+		// Scope is either "read" or "read-write", it defaults to "read".
+		Scope string
+	}
+)
+
+func (req CreateAPIKeyReq) ToAPIKey() model.APIKey {
+	scope := strings.TrimSpace(req.Scope)
+	if scope == "" {
+		scope = model.ReadScope
+	}
+
+	return model.APIKey{
+		UserID: model.NewID(uuid.UUID{Val: req.UserID}),
+		Name:   strings.TrimSpace(req.Name),
+		Scope:  scope,
+	}
+}
@@ -0,0 +1,27 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	CreateAPIKeyRes struct {
+		ServiceRes
+		APIKey
+		// Key is only available in this response, it can not be recovered afterwards.
+		Key string
+	}
+)
+
+func NewCreateAPIKeyRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) CreateAPIKeyRes {
+	return CreateAPIKeyRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func (res *CreateAPIKeyRes) FromAPIKey(m model.APIKey, key string) {
+	res.APIKey = NewAPIKey(m)
+	res.Key = key
+}
@@ -11,11 +11,14 @@ import (
 type (
 	CreateListRes struct {
 		ServiceRes
+		ID          string
 		UserID      string
 		Name        string
 		Description string
+		Role        string
 		CreatedAt   time.Time
 		UpdatedAt   time.Time
+		Version     int
 	}
 )
 
@@ -26,9 +29,12 @@ func NewCreateListRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) Cr
 }
 
 func (res *CreateListRes) FromList(m model.List) {
+	res.ID = m.ID.String()
 	res.UserID = m.Owner.ID.String()
 	res.Name = m.Name
 	res.Description = m.Description
+	res.Role = string(m.Role)
 	res.CreatedAt = m.CreatedAt
 	res.UpdatedAt = m.UpdatedAt
+	res.Version = m.Version
 }
@@ -0,0 +1,35 @@
+package transport
+
+import (
+	"strings"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+)
+
+type (
+	CreateWebhookReq struct {
+		UserID string `json:"-"`
+		// ListID limits the webhook to a list, it gets the events of all the lists of the user otherwise.
+		ListID string
+		URL    string
+		Events []string
+		// Secret signs the deliveries, a random one is generated if empty.
+		Secret string
+	}
+)
+
+func (req CreateWebhookReq) ToWebhook() model.Webhook {
+	webhook := model.Webhook{
+		UserID: model.NewID(uuid.UUID{Val: req.UserID}),
+		ListID: model.NewID(uuid.UUID{Val: strings.TrimSpace(req.ListID)}),
+		URL:    strings.TrimSpace(req.URL),
+		Secret: req.Secret,
+	}
+
+	for _, typ := range req.Events {
+		webhook.Events = append(webhook.Events, model.EventType(strings.TrimSpace(typ)))
+	}
+
+	return webhook
+}
@@ -0,0 +1,27 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	CreateWebhookRes struct {
+		ServiceRes
+		Webhook
+		// Secret is only available in this response, it can not be recovered afterwards.
+		Secret string
+	}
+)
+
+func NewCreateWebhookRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) CreateWebhookRes {
+	return CreateWebhookRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func (res *CreateWebhookRes) FromWebhook(m model.Webhook) {
+	res.Webhook = NewWebhook(m)
+	res.Secret = m.Secret
+}
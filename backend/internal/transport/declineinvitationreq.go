@@ -0,0 +1,9 @@
+package transport
+
+type (
+	// DeclineInvitationReq deletes an invitation, either by its recipient or by an owner of the list.
+	DeclineInvitationReq struct {
+		UserID       string
+		InvitationID string
+	}
+)
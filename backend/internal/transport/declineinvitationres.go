@@ -0,0 +1,18 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	DeclineInvitationRes struct {
+		ServiceRes
+	}
+)
+
+func NewDeclineInvitationRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) DeclineInvitationRes {
+	return DeclineInvitationRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
@@ -0,0 +1,10 @@
+package transport
+
+type (
+	DeleteListReq struct {
+		UserID string
+		ListID string
+		// Version the list must be at, zero for any
+		Version int
+	}
+)
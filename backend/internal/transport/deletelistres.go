@@ -0,0 +1,18 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	DeleteListRes struct {
+		ServiceRes
+	}
+)
+
+func NewDeleteListRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) DeleteListRes {
+	return DeleteListRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
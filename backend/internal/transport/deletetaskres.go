@@ -0,0 +1,18 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	DeleteTaskRes struct {
+		ServiceRes
+	}
+)
+
+func NewDeleteTaskRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) DeleteTaskRes {
+	return DeleteTaskRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
@@ -0,0 +1,8 @@
+package transport
+
+type (
+	DeleteWebhookReq struct {
+		UserID    string
+		WebhookID string
+	}
+)
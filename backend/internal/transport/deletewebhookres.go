@@ -0,0 +1,18 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	DeleteWebhookRes struct {
+		ServiceRes
+	}
+)
+
+func NewDeleteWebhookRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) DeleteWebhookRes {
+	return DeleteWebhookRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
@@ -0,0 +1,10 @@
+package transport
+
+type (
+	DetachTagReq struct {
+		UserID string
+		ListID string
+		TaskID string
+		TagID  string
+	}
+)
@@ -0,0 +1,18 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	DetachTagRes struct {
+		ServiceRes
+	}
+)
+
+func NewDetachTagRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) DetachTagRes {
+	return DetachTagRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
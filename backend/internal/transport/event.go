@@ -0,0 +1,44 @@
+package transport
+
+import (
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+)
+
+type (
+	// Event is a change to a list or to one of its tasks, the changed resource is omitted for deletions.
+	Event struct {
+		ID     uint64
+		Type   string
+		ListID string
+		TaskID string `json:",omitempty"`
+		UserID string `json:",omitempty"`
+		List   *List  `json:",omitempty"`
+		Task   *Task  `json:",omitempty"`
+		At     time.Time
+	}
+)
+
+func NewEvent(m model.Event) Event {
+	e := Event{
+		ID:     m.ID,
+		Type:   string(m.Type),
+		ListID: m.ListID,
+		TaskID: m.TaskID,
+		UserID: m.UserID,
+		At:     m.At,
+	}
+
+	if m.List != nil {
+		list := NewList(*m.List)
+		e.List = &list
+	}
+
+	if m.Task != nil {
+		task := NewTask(*m.Task)
+		e.Task = &task
+	}
+
+	return e
+}
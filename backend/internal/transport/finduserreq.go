@@ -0,0 +1,23 @@
+package transport
+
+import (
+	"strings"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+)
+
+type (
+	// FindUserReq looks a user up by username or, if not provided, by email.
+	FindUserReq struct {
+		CallerID string
+		Username string
+		Email    string
+	}
+)
+
+func (req FindUserReq) ToUser() model.User {
+	return model.User{
+		Username: strings.TrimSpace(req.Username),
+		Email:    strings.ToLower(strings.TrimSpace(req.Email)),
+	}
+}
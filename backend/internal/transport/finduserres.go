@@ -0,0 +1,21 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	FindUserRes struct {
+		ServiceRes
+		User
+	}
+)
+
+func NewFindUserRes(valErrSet v.ValErrorSet, err error, cfg *config.Config, user model.User) FindUserRes {
+	return FindUserRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+		User:       NewUser(user),
+	}
+}
@@ -0,0 +1,7 @@
+package transport
+
+type (
+	GetAPIKeysReq struct {
+		UserID string
+	}
+)
@@ -0,0 +1,27 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	GetAPIKeysRes struct {
+		ServiceRes
+		APIKeys []APIKey
+	}
+)
+
+func NewGetAPIKeysRes(valErrSet v.ValErrorSet, err error, cfg *config.Config, keys []model.APIKey) GetAPIKeysRes {
+	res := GetAPIKeysRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+		APIKeys:    []APIKey{},
+	}
+
+	for _, m := range keys {
+		res.APIKeys = append(res.APIKeys, NewAPIKey(m))
+	}
+
+	return res
+}
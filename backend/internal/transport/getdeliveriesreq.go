@@ -0,0 +1,8 @@
+package transport
+
+type (
+	GetDeliveriesReq struct {
+		UserID    string
+		WebhookID string
+	}
+)
@@ -0,0 +1,27 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	GetDeliveriesRes struct {
+		ServiceRes
+		Deliveries []Delivery
+	}
+)
+
+func NewGetDeliveriesRes(valErrSet v.ValErrorSet, err error, cfg *config.Config, deliveries []model.WebhookDelivery) GetDeliveriesRes {
+	res := GetDeliveriesRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+		Deliveries: []Delivery{},
+	}
+
+	for _, m := range deliveries {
+		res.Deliveries = append(res.Deliveries, NewDelivery(m))
+	}
+
+	return res
+}
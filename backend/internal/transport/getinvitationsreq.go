@@ -0,0 +1,7 @@
+package transport
+
+type (
+	GetInvitationsReq struct {
+		UserID string
+	}
+)
@@ -0,0 +1,27 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	GetInvitationsRes struct {
+		ServiceRes
+		Invitations []Invitation
+	}
+)
+
+func NewGetInvitationsRes(valErrSet v.ValErrorSet, err error, cfg *config.Config, invitations []model.Invitation) GetInvitationsRes {
+	res := GetInvitationsRes{
+		ServiceRes:  NewServiceRes(valErrSet, err, cfg),
+		Invitations: []Invitation{},
+	}
+
+	for _, m := range invitations {
+		res.Invitations = append(res.Invitations, NewInvitation(m))
+	}
+
+	return res
+}
@@ -11,62 +11,41 @@ import (
 type (
 	GetListRes struct {
 		ServiceRes
+		ID          string
 		UserID      string
 		Name        string
 		Description string
+		Role        string
 		CreatedAt   time.Time
 		UpdatedAt   time.Time
-		Tasks       []Task
-	}
-
-	Task struct {
-		Name        string
-		Description string
-		Category    []string
-		Tags        []string
-		Location    []string
-		CreatedAt   time.Time
-		UpdatedAt   time.Time
+		Version     int
+		// TasksVersion is the version of the tasks, sent as the ETag of the tasks collection.
+		TasksVersion int
+		Tasks        []Task
 	}
 )
 
 func NewGetListRes(valErrSet v.ValErrorSet, err error, cfg *config.Config, list model.List) GetListRes {
-	var tasks []Task
-	for _, m := range list.Tasks {
-		tasks = append(tasks, Task{
-			Name:        m.Name,
-			Description: m.Description,
-			Category:    m.Category,
-			Tags:        m.Tags,
-			Location:    m.Location,
-		})
+	res := GetListRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
 	}
 
-	return GetListRes{
-		ServiceRes:  NewServiceRes(valErrSet, err, cfg),
-		Name:        list.Name,
-		Description: list.Description,
-		Tasks:       tasks,
-	}
+	res.FromList(list)
+
+	return res
 }
 
 func (res *GetListRes) FromList(m model.List) {
+	res.ID = m.ID.String()
 	res.UserID = m.Owner.ID.String()
 	res.Name = m.Name
 	res.Description = m.Description
+	res.Role = string(m.Role)
 	res.CreatedAt = m.CreatedAt
 	res.UpdatedAt = m.UpdatedAt
+	res.Version = m.Version
+	res.TasksVersion = m.TasksVersion
 	for _, t := range m.Tasks {
-		res.Tasks = append(res.Tasks,
-			Task{
-				Name:        t.Name,
-				Description: t.Description,
-				Category:    t.Category,
-				Tags:        t.Tags,
-				Location:    t.Location,
-				CreatedAt:   t.CreatedAt,
-				UpdatedAt:   t.UpdatedAt,
-			},
-		)
+		res.Tasks = append(res.Tasks, NewTask(t))
 	}
 }
@@ -0,0 +1,8 @@
+package transport
+
+type (
+	GetListsReq struct {
+		UserID string
+		Query  Query
+	}
+)
@@ -0,0 +1,55 @@
+package transport
+
+import (
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	GetListsRes struct {
+		ServiceRes
+		Lists []List
+		Page  Page `json:"-"`
+	}
+
+	List struct {
+		ID          string
+		UserID      string
+		Name        string
+		Description string
+		Role        string
+		CreatedAt   time.Time
+		UpdatedAt   time.Time
+		Version     int
+	}
+)
+
+func NewGetListsRes(valErrSet v.ValErrorSet, err error, cfg *config.Config, lists []model.List, page Page) GetListsRes {
+	res := GetListsRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+		Lists:      []List{},
+		Page:       page,
+	}
+
+	for _, m := range lists {
+		res.Lists = append(res.Lists, NewList(m))
+	}
+
+	return res
+}
+
+func NewList(m model.List) List {
+	return List{
+		ID:          m.ID.String(),
+		UserID:      m.Owner.ID.String(),
+		Name:        m.Name,
+		Description: m.Description,
+		Role:        string(m.Role),
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+		Version:     m.Version,
+	}
+}
@@ -0,0 +1,8 @@
+package transport
+
+type (
+	GetMembersReq struct {
+		UserID string
+		ListID string
+	}
+)
@@ -0,0 +1,27 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	GetMembersRes struct {
+		ServiceRes
+		Members []Member
+	}
+)
+
+func NewGetMembersRes(valErrSet v.ValErrorSet, err error, cfg *config.Config, members []model.Member) GetMembersRes {
+	res := GetMembersRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+		Members:    []Member{},
+	}
+
+	for _, m := range members {
+		res.Members = append(res.Members, NewMember(m))
+	}
+
+	return res
+}
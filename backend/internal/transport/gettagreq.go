@@ -0,0 +1,8 @@
+package transport
+
+type (
+	GetTagReq struct {
+		UserID string
+		TagID  string
+	}
+)
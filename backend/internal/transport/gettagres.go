@@ -0,0 +1,21 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	GetTagRes struct {
+		ServiceRes
+		Tag
+	}
+)
+
+func NewGetTagRes(valErrSet v.ValErrorSet, err error, cfg *config.Config, tag model.Tag) GetTagRes {
+	return GetTagRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+		Tag:        NewTag(tag),
+	}
+}
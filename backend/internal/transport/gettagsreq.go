@@ -0,0 +1,7 @@
+package transport
+
+type (
+	GetTagsReq struct {
+		UserID string
+	}
+)
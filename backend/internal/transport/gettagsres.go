@@ -0,0 +1,27 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	GetTagsRes struct {
+		ServiceRes
+		Tags []Tag
+	}
+)
+
+func NewGetTagsRes(valErrSet v.ValErrorSet, err error, cfg *config.Config, tags []model.Tag) GetTagsRes {
+	res := GetTagsRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+		Tags:       []Tag{},
+	}
+
+	for _, m := range tags {
+		res.Tags = append(res.Tags, NewTag(m))
+	}
+
+	return res
+}
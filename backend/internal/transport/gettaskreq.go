@@ -0,0 +1,9 @@
+package transport
+
+type (
+	GetTaskReq struct {
+		UserID string
+		ListID string
+		TaskID string
+	}
+)
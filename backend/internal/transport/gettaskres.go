@@ -0,0 +1,21 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	GetTaskRes struct {
+		ServiceRes
+		Task
+	}
+)
+
+func NewGetTaskRes(valErrSet v.ValErrorSet, err error, cfg *config.Config, task model.Task) GetTaskRes {
+	return GetTaskRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+		Task:       NewTask(task),
+	}
+}
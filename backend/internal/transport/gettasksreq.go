@@ -0,0 +1,9 @@
+package transport
+
+type (
+	GetTasksReq struct {
+		UserID string
+		ListID string
+		Query  Query
+	}
+)
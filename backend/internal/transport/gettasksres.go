@@ -0,0 +1,31 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	GetTasksRes struct {
+		ServiceRes
+		Tasks []Task
+		Page  Page `json:"-"`
+		// Version of the tasks of the list, sent as the ETag of the collection.
+		Version int `json:"-"`
+	}
+)
+
+func NewGetTasksRes(valErrSet v.ValErrorSet, err error, cfg *config.Config, tasks []model.Task, page Page) GetTasksRes {
+	res := GetTasksRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+		Tasks:      []Task{},
+		Page:       page,
+	}
+
+	for _, m := range tasks {
+		res.Tasks = append(res.Tasks, NewTask(m))
+	}
+
+	return res
+}
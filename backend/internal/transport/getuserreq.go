@@ -0,0 +1,9 @@
+package transport
+
+type (
+	GetUserReq struct {
+		// CallerID is the user performing the request, UserID the one requested.
+		CallerID string
+		UserID   string
+	}
+)
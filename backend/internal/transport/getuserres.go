@@ -0,0 +1,21 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	GetUserRes struct {
+		ServiceRes
+		User
+	}
+)
+
+func NewGetUserRes(valErrSet v.ValErrorSet, err error, cfg *config.Config, user model.User) GetUserRes {
+	return GetUserRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+		User:       NewUser(user),
+	}
+}
@@ -0,0 +1,8 @@
+package transport
+
+type (
+	GetWebhookReq struct {
+		UserID    string
+		WebhookID string
+	}
+)
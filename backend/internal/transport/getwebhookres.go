@@ -0,0 +1,21 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	GetWebhookRes struct {
+		ServiceRes
+		Webhook
+	}
+)
+
+func NewGetWebhookRes(valErrSet v.ValErrorSet, err error, cfg *config.Config, webhook model.Webhook) GetWebhookRes {
+	return GetWebhookRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+		Webhook:    NewWebhook(webhook),
+	}
+}
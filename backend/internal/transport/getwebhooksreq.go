@@ -0,0 +1,7 @@
+package transport
+
+type (
+	GetWebhooksReq struct {
+		UserID string
+	}
+)
@@ -0,0 +1,27 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	GetWebhooksRes struct {
+		ServiceRes
+		Webhooks []Webhook
+	}
+)
+
+func NewGetWebhooksRes(valErrSet v.ValErrorSet, err error, cfg *config.Config, webhooks []model.Webhook) GetWebhooksRes {
+	res := GetWebhooksRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+		Webhooks:   []Webhook{},
+	}
+
+	for _, m := range webhooks {
+		res.Webhooks = append(res.Webhooks, NewWebhook(m))
+	}
+
+	return res
+}
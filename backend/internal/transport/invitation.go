@@ -0,0 +1,33 @@
+package transport
+
+import (
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+)
+
+type (
+	Invitation struct {
+		ID        string
+		ListID    string
+		ListName  string
+		Email     string
+		Role      string
+		InvitedBy string
+		ExpiresAt time.Time
+		CreatedAt time.Time
+	}
+)
+
+func NewInvitation(m model.Invitation) Invitation {
+	return Invitation{
+		ID:        m.ID.String(),
+		ListID:    m.ListID.String(),
+		ListName:  m.ListName,
+		Email:     m.Email,
+		Role:      string(m.Role),
+		InvitedBy: m.InvitedBy.String(),
+		ExpiresAt: m.ExpiresAt,
+		CreatedAt: m.CreatedAt,
+	}
+}
@@ -0,0 +1,33 @@
+package transport
+
+import (
+	"strings"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+)
+
+type (
+	// InviteMemberReq invites whoever owns the email to the list.
+	InviteMemberReq struct {
+		UserID string
+		ListID string
+		Email  string
+		// Role is one of "viewer", "editor" or "owner"; it defaults to "viewer".
+		Role string
+	}
+)
+
+func (req InviteMemberReq) ToInvitation() model.Invitation {
+	role := model.Role(strings.TrimSpace(req.Role))
+	if role == "" {
+		role = model.ViewerRole
+	}
+
+	return model.Invitation{
+		ListID:    model.NewID(uuid.UUID{Val: req.ListID}),
+		Email:     strings.TrimSpace(req.Email),
+		Role:      role,
+		InvitedBy: model.NewID(uuid.UUID{Val: req.UserID}),
+	}
+}
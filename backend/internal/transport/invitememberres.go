@@ -0,0 +1,24 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	InviteMemberRes struct {
+		ServiceRes
+		Invitation
+	}
+)
+
+func NewInviteMemberRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) InviteMemberRes {
+	return InviteMemberRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func (res *InviteMemberRes) FromInvitation(m model.Invitation) {
+	res.Invitation = NewInvitation(m)
+}
@@ -0,0 +1,16 @@
+package transport
+
+import "strings"
+
+type (
+	// LoginReq identifies the user by username or email.
+	LoginReq struct {
+		Username string
+		Password string
+	}
+)
+
+// Identity returns the trimmed username or email.
+func (req LoginReq) Identity() string {
+	return strings.TrimSpace(req.Username)
+}
@@ -0,0 +1,23 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	LoginRes struct {
+		ServiceRes
+		Tokens
+	}
+)
+
+func NewLoginRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) LoginRes {
+	return LoginRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func (res *LoginRes) FromTokens(tokens Tokens) {
+	res.Tokens = tokens
+}
@@ -0,0 +1,8 @@
+package transport
+
+type (
+	LogoutReq struct {
+		UserID    string
+		SessionID string
+	}
+)
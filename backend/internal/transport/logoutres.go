@@ -0,0 +1,18 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	LogoutRes struct {
+		ServiceRes
+	}
+)
+
+func NewLogoutRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) LogoutRes {
+	return LogoutRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
@@ -0,0 +1,31 @@
+package transport
+
+import (
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+)
+
+type (
+	Member struct {
+		UserID    string
+		Username  string
+		Name      string
+		Email     string
+		Role      string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+)
+
+func NewMember(m model.Member) Member {
+	return Member{
+		UserID:    m.User.ID.String(),
+		Username:  m.User.Username,
+		Name:      m.User.Name,
+		Email:     m.User.Email,
+		Role:      string(m.Role),
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
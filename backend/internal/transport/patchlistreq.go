@@ -0,0 +1,13 @@
+package transport
+
+type (
+	// PatchListReq holds a merge patch or JSON patch document, as told by its media type.
+	PatchListReq struct {
+		UserID    string
+		ListID    string
+		MediaType string
+		Patch     []byte
+		// Version the list must be at, zero for any
+		Version int
+	}
+)
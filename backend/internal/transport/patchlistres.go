@@ -0,0 +1,40 @@
+package transport
+
+import (
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	PatchListRes struct {
+		ServiceRes
+		ID          string
+		UserID      string
+		Name        string
+		Description string
+		Role        string
+		CreatedAt   time.Time
+		UpdatedAt   time.Time
+		Version     int
+	}
+)
+
+func NewPatchListRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) PatchListRes {
+	return PatchListRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func (res *PatchListRes) FromList(m model.List) {
+	res.ID = m.ID.String()
+	res.UserID = m.Owner.ID.String()
+	res.Name = m.Name
+	res.Description = m.Description
+	res.Role = string(m.Role)
+	res.CreatedAt = m.CreatedAt
+	res.UpdatedAt = m.UpdatedAt
+	res.Version = m.Version
+}
@@ -0,0 +1,14 @@
+package transport
+
+type (
+	// PatchTaskReq holds a merge patch or JSON patch document, as told by its media type.
+	PatchTaskReq struct {
+		UserID    string
+		ListID    string
+		TaskID    string
+		MediaType string
+		Patch     []byte
+		// Version the task must be at, zero for any
+		Version int
+	}
+)
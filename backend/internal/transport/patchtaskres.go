@@ -0,0 +1,24 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	PatchTaskRes struct {
+		ServiceRes
+		Task
+	}
+)
+
+func NewPatchTaskRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) PatchTaskRes {
+	return PatchTaskRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func (res *PatchTaskRes) FromTask(m model.Task) {
+	res.Task = NewTask(m)
+}
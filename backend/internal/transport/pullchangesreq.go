@@ -0,0 +1,9 @@
+package transport
+
+type (
+	// PullChangesReq asks for the changes made after the token, all current lists and tasks without one.
+	PullChangesReq struct {
+		UserID string
+		Token  string
+	}
+)
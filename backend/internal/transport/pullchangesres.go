@@ -0,0 +1,68 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	// PullChangesRes holds the changes in the order they are to be applied and the token to pull the next ones.
+	// More is true if changes are left after the token, Reset if clients must drop what they have first.
+	PullChangesRes struct {
+		ServiceRes
+		Changes []Change
+		Token   string
+		More    bool
+		Reset   bool
+	}
+
+	// Change is a list or task, as left by the change, or its tombstone if it was deleted.
+	// A tombstone only removes a task from ListID, the list it was in.
+	Change struct {
+		Kind    string
+		ID      string
+		ListID  string
+		Deleted bool  `json:",omitempty"`
+		List    *List `json:",omitempty"`
+		Task    *Task `json:",omitempty"`
+	}
+)
+
+func NewPullChangesRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) PullChangesRes {
+	return PullChangesRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func (res *PullChangesRes) FromChangeSet(m model.ChangeSet, token string) {
+	res.Changes = make([]Change, 0, len(m.Changes))
+	for _, c := range m.Changes {
+		res.Changes = append(res.Changes, NewChange(c))
+	}
+
+	res.Token = token
+	res.More = m.More
+	res.Reset = m.Reset
+}
+
+func NewChange(m model.Change) Change {
+	c := Change{
+		Kind:    string(m.Kind),
+		ID:      m.ItemID,
+		ListID:  m.ListID,
+		Deleted: m.Deleted,
+	}
+
+	if m.List != nil {
+		list := NewList(*m.List)
+		c.List = &list
+	}
+
+	if m.Task != nil {
+		task := NewTask(*m.Task)
+		c.Task = &task
+	}
+
+	return c
+}
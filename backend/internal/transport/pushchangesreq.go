@@ -0,0 +1,84 @@
+package transport
+
+import (
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+)
+
+type (
+	// PushChangesReq applies the mutations a client queued while offline, each one succeeds or fails on its own.
+	PushChangesReq struct {
+		UserID    string `json:"-"`
+		Mutations []MutationReq
+	}
+
+	// MutationReq is a "create", "update" or "delete" of a "list" or a "task".
+	// ID addresses the item, creations may set it to keep the one the client gave it. ListID is the list of tasks.
+	// Lists only use Name and Description, tasks are moved with batches.
+	MutationReq struct {
+		Op          string
+		Kind        string
+		ID          string
+		ListID      string
+		Name        string
+		Description string
+		Category    []string
+		Tags        []string
+		Location    []string
+		Done        bool
+		DueAt       *time.Time
+		Priority    int
+		Position    int
+		// Version the item was at when the client changed it, zero for any
+		Version int
+	}
+)
+
+func (req PushChangesReq) ToMutations() []model.Mutation {
+	var mutations []model.Mutation
+	for _, m := range req.Mutations {
+		mutations = append(mutations, m.ToMutation())
+	}
+
+	return mutations
+}
+
+func (req MutationReq) ToMutation() model.Mutation {
+	id := model.NewID(uuid.UUID{Val: req.ID})
+	audit := model.Audit{Version: req.Version}
+
+	m := model.Mutation{
+		Op:   model.MutationOp(req.Op),
+		Kind: model.ChangeKind(req.Kind),
+	}
+
+	switch m.Kind {
+	case model.ListChange:
+		m.List = model.List{
+			ID:          id,
+			Name:        req.Name,
+			Description: req.Description,
+			Audit:       audit,
+		}
+
+	case model.TaskChange:
+		m.Task = model.Task{
+			ID:          id,
+			ListID:      model.NewID(uuid.UUID{Val: req.ListID}),
+			Name:        req.Name,
+			Description: req.Description,
+			Category:    req.Category,
+			Tags:        req.Tags,
+			Location:    req.Location,
+			Done:        req.Done,
+			DueAt:       timeVal(req.DueAt),
+			Priority:    model.Priority(req.Priority),
+			Position:    req.Position,
+			Audit:       audit,
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,59 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	PushChangesRes struct {
+		ServiceRes
+		Results []MutationRes
+	}
+
+	// MutationRes is the outcome of a mutation, the item as left by it unless it was deleted.
+	// A failed mutation has the error and validation errors of its ServiceRes. If it conflicts with the item
+	// as changed meanwhile, or already created, the item holds its current state for the client to reconcile.
+	MutationRes struct {
+		ServiceRes
+		Op   string
+		Kind string
+		ID   string
+		List *List
+		Task *Task
+	}
+)
+
+func NewPushChangesRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) PushChangesRes {
+	return PushChangesRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func NewMutationRes(m model.Mutation, valErrSet v.ValErrorSet, err error, cfg *config.Config) MutationRes {
+	res := MutationRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+		Op:         string(m.Op),
+		Kind:       string(m.Kind),
+		ID:         m.List.ID.String(),
+	}
+
+	if m.Kind == model.TaskChange {
+		res.ID = m.Task.ID.String()
+	}
+
+	return res
+}
+
+func (res *MutationRes) FromList(m model.List) {
+	list := NewList(m)
+	res.ID = list.ID
+	res.List = &list
+}
+
+func (res *MutationRes) FromTask(m model.Task) {
+	task := NewTask(m)
+	res.ID = task.ID
+	res.Task = &task
+}
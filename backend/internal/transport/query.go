@@ -0,0 +1,28 @@
+package transport
+
+type (
+	// Query holds the collection parameters of a request as sent by the client, the service validates them.
+	// Sort is a comma separated list of fields, each one prefixed with '-' for descending order.
+	Query struct {
+		Limit   string
+		Page    string
+		Sort    string
+		Filters map[string]string
+	}
+
+	// Page describes the window of a collection returned in a response.
+	Page struct {
+		Number int
+		Limit  int
+		Total  int
+	}
+)
+
+// Pages returns the number of pages of the collection, at least one even if empty.
+func (p Page) Pages() int {
+	if p.Limit <= 0 || p.Total <= p.Limit {
+		return 1
+	}
+
+	return (p.Total + p.Limit - 1) / p.Limit
+}
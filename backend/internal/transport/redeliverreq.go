@@ -0,0 +1,9 @@
+package transport
+
+type (
+	RedeliverReq struct {
+		UserID     string
+		WebhookID  string
+		DeliveryID string
+	}
+)
@@ -0,0 +1,24 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	RedeliverRes struct {
+		ServiceRes
+		Delivery
+	}
+)
+
+func NewRedeliverRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) RedeliverRes {
+	return RedeliverRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func (res *RedeliverRes) FromDelivery(m model.WebhookDelivery) {
+	res.Delivery = NewDelivery(m)
+}
@@ -0,0 +1,7 @@
+package transport
+
+type (
+	RefreshTokenReq struct {
+		RefreshToken string
+	}
+)
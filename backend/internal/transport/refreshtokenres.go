@@ -0,0 +1,23 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	RefreshTokenRes struct {
+		ServiceRes
+		Tokens
+	}
+)
+
+func NewRefreshTokenRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) RefreshTokenRes {
+	return RefreshTokenRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func (res *RefreshTokenRes) FromTokens(tokens Tokens) {
+	res.Tokens = tokens
+}
@@ -0,0 +1,26 @@
+package transport
+
+import (
+	"strings"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+)
+
+type (
+	RegisterUserReq struct {
+		Username             string
+		Name                 string
+		Email                string
+		Password             string
+		PasswordConfirmation string
+	}
+)
+
+func (req RegisterUserReq) ToUser() model.User {
+	return model.User{
+		Username: strings.TrimSpace(req.Username),
+		Name:     strings.TrimSpace(req.Name),
+		Email:    strings.ToLower(strings.TrimSpace(req.Email)),
+		Password: req.Password,
+	}
+}
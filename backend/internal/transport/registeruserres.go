@@ -0,0 +1,24 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	RegisterUserRes struct {
+		ServiceRes
+		User
+	}
+)
+
+func NewRegisterUserRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) RegisterUserRes {
+	return RegisterUserRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func (res *RegisterUserRes) FromUser(m model.User) {
+	res.User = NewUser(m)
+}
@@ -0,0 +1,10 @@
+package transport
+
+type (
+	// RemoveMemberReq removes a member from a list, a user can remove itself to leave it.
+	RemoveMemberReq struct {
+		UserID   string
+		ListID   string
+		MemberID string
+	}
+)
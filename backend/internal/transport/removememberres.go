@@ -0,0 +1,18 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	RemoveMemberRes struct {
+		ServiceRes
+	}
+)
+
+func NewRemoveMemberRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) RemoveMemberRes {
+	return RemoveMemberRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
@@ -0,0 +1,8 @@
+package transport
+
+type (
+	RevokeAPIKeyReq struct {
+		UserID   string
+		APIKeyID string
+	}
+)
@@ -0,0 +1,18 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	RevokeAPIKeyRes struct {
+		ServiceRes
+	}
+)
+
+func NewRevokeAPIKeyRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) RevokeAPIKeyRes {
+	return RevokeAPIKeyRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
@@ -0,0 +1,9 @@
+package transport
+
+type (
+	SearchReq struct {
+		UserID string
+		Text   string
+		Query  Query
+	}
+)
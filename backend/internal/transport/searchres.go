@@ -0,0 +1,49 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	SearchRes struct {
+		ServiceRes
+		Results []SearchResult
+		Page    Page `json:"-"`
+	}
+
+	// SearchResult is a list or task matching a search, best matches first.
+	// Name, Snippet and Tags enclose the matching terms in <mark> tags, the rest of the text is not escaped.
+	SearchResult struct {
+		Kind     string
+		ID       string
+		ListID   string
+		ListName string
+		Name     string
+		Snippet  string
+		Tags     string
+	}
+)
+
+func NewSearchRes(valErrSet v.ValErrorSet, err error, cfg *config.Config, results []model.SearchResult, page Page) SearchRes {
+	res := SearchRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+		Results:    []SearchResult{},
+		Page:       page,
+	}
+
+	for _, m := range results {
+		res.Results = append(res.Results, SearchResult{
+			Kind:     string(m.Kind),
+			ID:       m.ItemID.String(),
+			ListID:   m.ListID.String(),
+			ListName: m.ListName,
+			Name:     m.Name,
+			Snippet:  m.Snippet,
+			Tags:     m.Tags,
+		})
+	}
+
+	return res
+}
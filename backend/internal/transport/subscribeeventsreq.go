@@ -0,0 +1,10 @@
+package transport
+
+type (
+	SubscribeEventsReq struct {
+		UserID string
+		ListID string
+		// LastEventID is the ID of the last event the client got, zero for none
+		LastEventID uint64
+	}
+)
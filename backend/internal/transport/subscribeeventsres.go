@@ -0,0 +1,37 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	// SubscribeEventsRes holds the subscription to the events of a list, it must be closed once done with it.
+	// Missed are the events published after the last one the client got, or a reset event if they are no longer kept.
+	SubscribeEventsRes struct {
+		ServiceRes
+		Events <-chan model.Event
+		Missed []model.Event
+		close  func()
+	}
+)
+
+func NewSubscribeEventsRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) SubscribeEventsRes {
+	return SubscribeEventsRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+		close:      func() {},
+	}
+}
+
+// FromSubscription sets the events of the subscription and the function ending it.
+func (res *SubscribeEventsRes) FromSubscription(events <-chan model.Event, close func(), missed []model.Event) {
+	res.Events = events
+	res.Missed = missed
+	res.close = close
+}
+
+// Close ends the subscription.
+func (res *SubscribeEventsRes) Close() {
+	res.close()
+}
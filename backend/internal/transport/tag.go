@@ -0,0 +1,29 @@
+package transport
+
+import (
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+)
+
+type (
+	Tag struct {
+		ID          string
+		Name        string
+		Description string
+		Usage       int
+		CreatedAt   time.Time
+		UpdatedAt   time.Time
+	}
+)
+
+func NewTag(m model.Tag) Tag {
+	return Tag{
+		ID:          m.ID.String(),
+		Name:        m.Name,
+		Description: m.Description,
+		Usage:       m.Usage,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
@@ -0,0 +1,65 @@
+package transport
+
+import (
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+)
+
+type (
+	Task struct {
+		ID          string
+		ListID      string
+		Name        string
+		Description string
+		Category    []string
+		Tags        []string
+		Location    []string
+		Done        bool
+		CompletedAt *time.Time
+		DueAt       *time.Time
+		Priority    int
+		Position    int
+		CreatedAt   time.Time
+		UpdatedAt   time.Time
+		Version     int
+	}
+)
+
+func NewTask(m model.Task) Task {
+	return Task{
+		ID:          m.ID.String(),
+		ListID:      m.ListID.String(),
+		Name:        m.Name,
+		Description: m.Description,
+		Category:    m.Category,
+		Tags:        m.Tags,
+		Location:    m.Location,
+		Done:        m.Done,
+		CompletedAt: timePtr(m.CompletedAt),
+		DueAt:       timePtr(m.DueAt),
+		Priority:    int(m.Priority),
+		Position:    m.Position,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+		Version:     m.Version,
+	}
+}
+
+// timePtr returns nil for the zero time so that it is encoded as null.
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// timeVal returns the zero time for nil.
+func timeVal(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+
+	return *t
+}
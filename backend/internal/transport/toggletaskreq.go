@@ -0,0 +1,11 @@
+package transport
+
+type (
+	ToggleTaskReq struct {
+		UserID string
+		ListID string
+		TaskID string
+		// Version the task must be at, zero for any
+		Version int
+	}
+)
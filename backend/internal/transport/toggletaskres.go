@@ -0,0 +1,24 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	ToggleTaskRes struct {
+		ServiceRes
+		Task
+	}
+)
+
+func NewToggleTaskRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) ToggleTaskRes {
+	return ToggleTaskRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func (res *ToggleTaskRes) FromTask(m model.Task) {
+	res.Task = NewTask(m)
+}
@@ -0,0 +1,14 @@
+package transport
+
+import "time"
+
+type (
+	// Tokens issued on login and refresh.
+	Tokens struct {
+		AccessToken      string
+		RefreshToken     string
+		TokenType        string
+		ExpiresAt        time.Time
+		RefreshExpiresAt time.Time
+	}
+)
@@ -0,0 +1,38 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+)
+
+type (
+	// UpdateListReq body holds the editable fields of a list, the others are set from the request.
+	// It is also the document list patches are applied to.
+	UpdateListReq struct {
+		UserID      string `json:"-"`
+		ListID      string `json:"-"`
+		Name        string
+		Description string
+		// Version the list must be at, zero for any
+		Version int `json:"-"`
+	}
+)
+
+// NewUpdateListReq returns the request that would leave the list as it is.
+func NewUpdateListReq(m model.List) UpdateListReq {
+	return UpdateListReq{
+		ListID:      m.ID.String(),
+		Name:        m.Name,
+		Description: m.Description,
+		Version:     m.Version,
+	}
+}
+
+func (req UpdateListReq) ToList() model.List {
+	return model.List{
+		ID:          model.NewID(uuid.UUID{Val: req.ListID}),
+		Name:        req.Name,
+		Description: req.Description,
+		Audit:       model.Audit{Version: req.Version},
+	}
+}
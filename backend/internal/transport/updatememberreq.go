@@ -0,0 +1,27 @@
+package transport
+
+import (
+	"strings"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+)
+
+type (
+	UpdateMemberReq struct {
+		UserID   string
+		ListID   string
+		MemberID string
+		Role     string
+	}
+)
+
+func (req UpdateMemberReq) ToMember() model.Member {
+	return model.Member{
+		ListID: model.NewID(uuid.UUID{Val: req.ListID}),
+		User: model.User{
+			ID: model.NewID(uuid.UUID{Val: req.MemberID}),
+		},
+		Role: model.Role(strings.TrimSpace(req.Role)),
+	}
+}
@@ -0,0 +1,24 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	UpdateMemberRes struct {
+		ServiceRes
+		Member
+	}
+)
+
+func NewUpdateMemberRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) UpdateMemberRes {
+	return UpdateMemberRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func (res *UpdateMemberRes) FromMember(m model.Member) {
+	res.Member = NewMember(m)
+}
@@ -0,0 +1,64 @@
+package transport
+
+import (
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/uuid"
+)
+
+type (
+	// UpdateTaskReq body holds the editable fields of a task, the others are set from the request.
+	// It is also the document task patches are applied to.
+	UpdateTaskReq struct {
+		UserID      string `json:"-"`
+		ListID      string `json:"-"`
+		TaskID      string `json:"-"`
+		Name        string
+		Description string
+		Category    []string
+		Tags        []string
+		Location    []string
+		Done        bool
+		DueAt       *time.Time
+		Priority    int
+		Position    int
+		// Version the task must be at, zero for any
+		Version int `json:"-"`
+	}
+)
+
+// NewUpdateTaskReq returns the request that would leave the task as it is.
+func NewUpdateTaskReq(m model.Task) UpdateTaskReq {
+	return UpdateTaskReq{
+		ListID:      m.ListID.String(),
+		TaskID:      m.ID.String(),
+		Name:        m.Name,
+		Description: m.Description,
+		Category:    m.Category,
+		Tags:        m.Tags,
+		Location:    m.Location,
+		Done:        m.Done,
+		DueAt:       timePtr(m.DueAt),
+		Priority:    int(m.Priority),
+		Position:    m.Position,
+		Version:     m.Version,
+	}
+}
+
+func (req UpdateTaskReq) ToTask() model.Task {
+	return model.Task{
+		ID:          model.NewID(uuid.UUID{Val: req.TaskID}),
+		ListID:      model.NewID(uuid.UUID{Val: req.ListID}),
+		Name:        req.Name,
+		Description: req.Description,
+		Category:    req.Category,
+		Tags:        req.Tags,
+		Location:    req.Location,
+		Done:        req.Done,
+		DueAt:       timeVal(req.DueAt),
+		Priority:    model.Priority(req.Priority),
+		Position:    req.Position,
+		Audit:       model.Audit{Version: req.Version},
+	}
+}
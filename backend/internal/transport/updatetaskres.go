@@ -0,0 +1,24 @@
+package transport
+
+import (
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+	"github.com/vanillazen/stl/backend/internal/sys/config"
+	v "github.com/vanillazen/stl/backend/internal/sys/validator"
+)
+
+type (
+	UpdateTaskRes struct {
+		ServiceRes
+		Task
+	}
+)
+
+func NewUpdateTaskRes(valErrSet v.ValErrorSet, err error, cfg *config.Config) UpdateTaskRes {
+	return UpdateTaskRes{
+		ServiceRes: NewServiceRes(valErrSet, err, cfg),
+	}
+}
+
+func (res *UpdateTaskRes) FromTask(m model.Task) {
+	res.Task = NewTask(m)
+}
@@ -0,0 +1,32 @@
+package transport
+
+import (
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+)
+
+type (
+	// User never exposes the password hash.
+	User struct {
+		ID        string
+		Username  string
+		Name      string
+		Email     string
+		Role      string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+)
+
+func NewUser(m model.User) User {
+	return User{
+		ID:        m.ID.String(),
+		Username:  m.Username,
+		Name:      m.Name,
+		Email:     m.Email,
+		Role:      string(m.Role),
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
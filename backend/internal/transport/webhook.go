@@ -0,0 +1,96 @@
+package transport
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/vanillazen/stl/backend/internal/domain/model"
+)
+
+type (
+	// Webhook describes a webhook without its secret, which is only returned on creation.
+	Webhook struct {
+		ID        string
+		ListID    string `json:",omitempty"`
+		URL       string
+		Events    []string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	// WebhookEvent is the payload of webhook deliveries, the change is omitted for deletions.
+	// It has no ID, event IDs are only meaningful to resume subscriptions. List and Task are the resource as it
+	// was when the delivery was queued, later changes to it may already show.
+	WebhookEvent struct {
+		Type   string
+		ListID string
+		TaskID string `json:",omitempty"`
+		UserID string `json:",omitempty"`
+		List   *List  `json:",omitempty"`
+		Task   *Task  `json:",omitempty"`
+		At     time.Time
+	}
+
+	// Delivery is an entry of the delivery log of a webhook, Payload is the body sent to it.
+	Delivery struct {
+		ID             string
+		WebhookID      string
+		Event          string
+		Payload        json.RawMessage
+		Status         string
+		Attempts       int
+		NextAttemptAt  *time.Time
+		LastAttemptAt  *time.Time
+		ResponseStatus int    `json:",omitempty"`
+		Error          string `json:",omitempty"`
+		CreatedAt      time.Time
+	}
+)
+
+func NewWebhook(m model.Webhook) Webhook {
+	w := Webhook{
+		ID:        m.ID.String(),
+		ListID:    m.ListID.String(),
+		URL:       m.URL,
+		Events:    []string{},
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+
+	for _, typ := range m.Events {
+		w.Events = append(w.Events, string(typ))
+	}
+
+	return w
+}
+
+// NewWebhookEvent returns the payload the event is delivered with.
+func NewWebhookEvent(m model.Event) WebhookEvent {
+	e := NewEvent(m)
+
+	return WebhookEvent{
+		Type:   string(m.Type),
+		ListID: e.ListID,
+		TaskID: e.TaskID,
+		UserID: e.UserID,
+		List:   e.List,
+		Task:   e.Task,
+		At:     e.At,
+	}
+}
+
+func NewDelivery(m model.WebhookDelivery) Delivery {
+	return Delivery{
+		ID:             m.ID.String(),
+		WebhookID:      m.WebhookID.String(),
+		Event:          string(m.EventType),
+		Payload:        m.Payload,
+		Status:         string(m.Status),
+		Attempts:       m.Attempts,
+		NextAttemptAt:  timePtr(m.NextAttemptAt),
+		LastAttemptAt:  timePtr(m.LastAttemptAt),
+		ResponseStatus: m.ResponseStatus,
+		Error:          m.Error,
+		CreatedAt:      m.CreatedAt,
+	}
+}